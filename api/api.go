@@ -2,7 +2,6 @@ package api
 
 import (
 	"fmt"
-	"log"
 )
 
 type ApiEndpoint struct {
@@ -17,7 +16,7 @@ type ApiEndpoint struct {
 // NewApiEndpoint Creates a new ApiEndpoint structure representing an versioned API EndpointNamePath
 func NewApiEndpoint(version string, endpoint string) *ApiEndpoint {
 	if endpoint == "" {
-		log.Fatalf("EndpointNamePath pattern can't be empty")
+		panic("EndpointNamePath pattern can't be empty")
 	}
 
 	apiEndpoint := &ApiEndpoint{
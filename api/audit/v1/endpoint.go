@@ -0,0 +1,12 @@
+package v1
+
+import "github.com/topfreegames/kaas-management-api/api"
+
+var Endpoint = api.NewApiEndpoint("v1", "audit")
+
+// Query parameters filtering the audit records, the times are in RFC 3339
+const (
+	ClusterQueryParameter = "cluster"
+	SinceQueryParameter   = "since"
+	UntilQueryParameter   = "until"
+)
@@ -0,0 +1,24 @@
+package v1
+
+import "time"
+
+// AuditRecord - a write request to the API, the outcome is one of success, denied or failure
+type AuditRecord struct {
+	Time                time.Time `json:"time"`
+	Subject             string    `json:"subject,omitempty"`
+	Groups              []string  `json:"groups,omitempty"`
+	Method              string    `json:"method"`
+	Route               string    `json:"route"`
+	Path                string    `json:"path"`
+	Cluster             string    `json:"cluster,omitempty"`
+	NodeGroup           string    `json:"nodegroup,omitempty"`
+	BodyHash            string    `json:"bodyhash,omitempty"`
+	Status              int       `json:"status"`
+	Outcome             string    `json:"outcome"`
+	LatencyMilliseconds int64     `json:"latencyms"`
+}
+
+// AuditRecordList - the audit records matching the query, from the oldest to the newest
+type AuditRecordList struct {
+	Items []AuditRecord `json:"items"`
+}
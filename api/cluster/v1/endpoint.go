@@ -4,7 +4,41 @@ import "github.com/topfreegames/kaas-management-api/api"
 
 var Endpoint = api.NewApiEndpoint("v1", "clusters")
 
+// UpgradeEndpointName is the cluster sub-resource used to upgrade its kubernetes version
+const UpgradeEndpointName = "upgrade"
+
+// KubeconfigEndpointName is the cluster sub-resource used to download its kubeconfig
+const KubeconfigEndpointName = "kubeconfig"
+
+// WatchEndpointName is the endpoint streaming the changes of clusters and node groups as server-sent events
+const WatchEndpointName = "watch"
+
+// LastEventIDHeader is the header sent by watch clients reconnecting with the id of the last event they received
+const LastEventIDHeader = "Last-Event-ID"
+
 // Parameters
 const (
 	ClusterNameParameter = "clusterName"
 )
+
+// Query parameters
+const (
+	ConfirmQueryParameter     = "confirm"
+	ContextQueryParameter     = "context"
+	ServerQueryParameter      = "server"
+	ApiEndpointQueryParameter = "apiendpoint"
+	ClusterQueryParameter     = "cluster"
+	LimitQueryParameter       = "limit"
+	ContinueQueryParameter    = "continue"
+)
+
+// Query parameters filtering the cluster list, every parameter accepts a comma separated list of values
+const (
+	RegionQueryParameter                 = "region"
+	EnvironmentQueryParameter            = "environment"
+	ClusterGroupQueryParameter           = "clusterGroup"
+	KubeProviderQueryParameter           = "kubeprovider"
+	InfrastructureProviderQueryParameter = "infrastructureprovider"
+	// SelectorQueryParameter is a Kubernetes label selector over the cluster labels and the filters above, supporting set-based requirements
+	SelectorQueryParameter = "selector"
+)
@@ -1,5 +1,7 @@
 package v1
 
+import "time"
+
 // Cluster - represents a cluster
 type Cluster struct {
 	Name                   string                 `json:"name"`
@@ -7,9 +9,70 @@ type Cluster struct {
 	Metadata               map[string]interface{} `json:"metadata"`
 	KubeProvider           string                 `json:"kubeprovider"`
 	InfrastructureProvider string                 `json:"infrastructureprovider"`
+	KubernetesVersion      string                 `json:"kubernetesversion,omitempty"`
+	Status                 ClusterStatus          `json:"status"`
+}
+
+// ClusterStatus - the state of a cluster, the phase can be Pending, Provisioning, Provisioned, Deleting, Failed or Unknown
+type ClusterStatus struct {
+	Phase               string      `json:"phase"`
+	ControlPlaneReady   bool        `json:"controlplaneready"`
+	InfrastructureReady bool        `json:"infrastructureready"`
+	FailureReason       string      `json:"failurereason,omitempty"`
+	FailureMessage      string      `json:"failuremessage,omitempty"`
+	Conditions          []Condition `json:"conditions,omitempty"`
+}
+
+// Condition - an observation of the state of a resource
+type Condition struct {
+	Type               string    `json:"type"`
+	Status             string    `json:"status"`
+	Severity           string    `json:"severity,omitempty"`
+	Reason             string    `json:"reason,omitempty"`
+	Message            string    `json:"message,omitempty"`
+	LastTransitionTime time.Time `json:"lasttransitiontime"`
 }
 
 // ClusterList - a list of Cluster
 type ClusterList struct {
 	Items []Cluster `json:"items"`
+	// Next is the continue token of the next page, empty in the last page
+	Next string `json:"next,omitempty"`
+}
+
+// ClusterCreateRequest - the provider agnostic specification of a new cluster
+type ClusterCreateRequest struct {
+	Name                   string   `json:"name" binding:"required"`
+	KubernetesVersion      string   `json:"kubernetesversion" binding:"required"`
+	KubeProvider           string   `json:"kubeprovider" binding:"required"`
+	InfrastructureProvider string   `json:"infrastructureprovider" binding:"required"`
+	ClusterGroup           string   `json:"clustergroup"`
+	Region                 string   `json:"region" binding:"required"`
+	Environment            string   `json:"environment"`
+	Zones                  []string `json:"zones" binding:"required"`
+	NetworkCIDR            string   `json:"networkcidr,omitempty"`
+	ServiceCIDR            string   `json:"servicecidr,omitempty"`
+	PodCIDR                string   `json:"podcidr,omitempty"`
+	Topology               string   `json:"topology,omitempty"`
+	APIAccessCIDRs         []string `json:"apiaccesscidrs,omitempty"`
+	SSHAccessCIDRs         []string `json:"sshaccesscidrs,omitempty"`
+}
+
+// ClusterUpgradeRequest - the kubernetes version a cluster must be upgraded to
+type ClusterUpgradeRequest struct {
+	Version    string `json:"version" binding:"required"`
+	NodeGroups bool   `json:"nodegroups"`
 }
+
+// WatchEvent - a change of a cluster or of a node group, the object is a Cluster or a NodeGroup according to the kind
+type WatchEvent struct {
+	Type   string      `json:"type"`
+	Kind   string      `json:"kind"`
+	Object interface{} `json:"object"`
+}
+
+// Watch event kinds
+const (
+	ClusterEventKind   = "Cluster"
+	NodeGroupEventKind = "NodeGroup"
+)
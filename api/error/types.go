@@ -5,4 +5,6 @@ type ClientErrorResponse struct {
 	ErrorCode    int    `json:"errorcode,omitempty"`
 	ErrorType    string `json:"errortype,omitempty"`
 	HttpCode     int    `json:"httpcode,omitempty"`
+	// RequestID identifies the request in the logs of the API, the same ID of the X-Request-ID response header
+	RequestID string `json:"requestid,omitempty"`
 }
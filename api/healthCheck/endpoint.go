@@ -3,3 +3,6 @@ package healthCheck
 import "github.com/topfreegames/kaas-management-api/api"
 
 var Endpoint = api.NewApiEndpoint("", "healthcheck")
+
+// ReadinessEndpoint reports whether the API is ready to serve requests, it isn't ready until the Kubernetes caches have synced
+var ReadinessEndpoint = api.NewApiEndpoint("", "readiness")
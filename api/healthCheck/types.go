@@ -3,3 +3,7 @@ package healthCheck
 type HealthCheck struct {
 	Healthy bool `json:"healthy"`
 }
+
+type Readiness struct {
+	Ready bool `json:"ready"`
+}
@@ -4,7 +4,15 @@ import "github.com/topfreegames/kaas-management-api/api"
 
 var Endpoint = api.NewApiEndpoint("v1", "nodegroups")
 
+// MachinesEndpointName is the node group sub-resource listing its machines
+const MachinesEndpointName = "machines"
+
 // Parameters
 const (
 	NodeGroupNameParameter = "nodeGroupName"
 )
+
+// Query parameters
+const (
+	DrainQueryParameter = "drain"
+)
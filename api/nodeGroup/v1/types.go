@@ -1,16 +1,57 @@
 package v1
 
+import "time"
+
 // NodeGroup - represents a Node Group
 type NodeGroup struct {
 	Name                   string    `json:"name"`
 	Metadata               *Metadata `json:"metadata"`
 	KubeProvider           string    `json:"kubeprovider,omitempty"`
 	InfrastructureProvider string    `json:"infrastructureprovider"`
+	Rollout                *Rollout  `json:"rollout,omitempty"`
+	Status                 Status    `json:"status"`
+}
+
+// Status - the state of the Node Group machines, the state is one of Ready, Scaling, Degraded or Failed
+type Status struct {
+	State               string `json:"state"`
+	Phase               string `json:"phase"`
+	Replicas            int32  `json:"replicas"`
+	ReadyReplicas       int32  `json:"readyreplicas"`
+	AvailableReplicas   int32  `json:"availablereplicas"`
+	UnavailableReplicas int32  `json:"unavailablereplicas"`
+	ObservedGeneration  int64  `json:"observedgeneration"`
+	FailureReason       string `json:"failurereason,omitempty"`
+	FailureMessage      string `json:"failuremessage,omitempty"`
+}
+
+// Rollout - the progress of the replacement of the Node Group machines, only present while a rollout is in progress
+type Rollout struct {
+	Replicas        int32 `json:"replicas"`
+	UpdatedReplicas int32 `json:"updatedreplicas"`
+	ReadyReplicas   int32 `json:"readyreplicas"`
 }
 
 // NodeGroupList - a list of Node Groups
 type NodeGroupList struct {
 	Items []NodeGroup `json:"items"`
+	// Next is the continue token of the next page, empty in the last page
+	Next string `json:"next,omitempty"`
+}
+
+// Machine - a machine of a Node Group, the phase and creation time are only known for machines of MachineDeployments
+type Machine struct {
+	Name         string     `json:"name,omitempty"`
+	ProviderID   string     `json:"providerid"`
+	NodeName     string     `json:"nodename"`
+	Phase        string     `json:"phase,omitempty"`
+	CreationTime *time.Time `json:"creationtime,omitempty"`
+	Version      string     `json:"version"`
+}
+
+// MachineList - a list of the machines of a Node Group
+type MachineList struct {
+	Items []Machine `json:"items"`
 }
 
 // TODO: We want this to be customizable in the future
@@ -24,3 +65,21 @@ type Metadata struct {
 	Min         *int32   `json:"min,omitempty"`
 	Max         *int32   `json:"max,omitempty"`
 }
+
+// NodeGroupCreateRequest - the specification of a new Node Group
+type NodeGroupCreateRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	MachineType string   `json:"machinetype"`
+	Zones       []string `json:"zones"`
+	Replicas    *int32   `json:"replicas"`
+	Min         *int32   `json:"min,omitempty"`
+	Max         *int32   `json:"max,omitempty"`
+}
+
+// NodeGroupUpdateRequest - the changes to an existing Node Group, omitted fields are kept unchanged
+type NodeGroupUpdateRequest struct {
+	MachineType string `json:"machinetype,omitempty"`
+	Replicas    *int32 `json:"replicas,omitempty"`
+	Min         *int32 `json:"min,omitempty"`
+	Max         *int32 `json:"max,omitempty"`
+}
@@ -16,11 +16,86 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/v1/audit/": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the audit records of the write requests from the oldest to the newest, filtered by cluster and time range. Requires the audit action when authorization policies are configured",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "List audit records",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster Name",
+                        "name": "cluster",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the time range in RFC 3339",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the time range in RFC 3339",
+                        "name": "until",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.AuditRecordList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/v1/clusters/": {
             "get": {
                 "security": [
                     {
                         "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
                     }
                 ],
                 "description": "Return a list of clusters with their information",
@@ -34,6 +109,56 @@ const docTemplate = `{
                     "Cluster"
                 ],
                 "summary": "List clusters",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of clusters returned",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token of the next page returned by the previous one",
+                        "name": "continue",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated regions of the clusters",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated environments of the clusters",
+                        "name": "environment",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated cluster groups of the clusters",
+                        "name": "clusterGroup",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated kube providers of the clusters",
+                        "name": "kubeprovider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated infrastructure providers of the clusters",
+                        "name": "infrastructureprovider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Kubernetes label selector over the cluster labels and the filters above, like region in (us-east-1,us-west-2),kubeprovider!=kubeadm",
+                        "name": "selector",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -41,6 +166,136 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.ClusterList"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a cluster with its control plane and infrastructure from a provider agnostic specification",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cluster"
+                ],
+                "summary": "Create a cluster",
+                "parameters": [
+                    {
+                        "description": "Cluster specification",
+                        "name": "cluster",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.ClusterCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/v1.Cluster"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/clusters/watch/": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stream the changes of all clusters and node groups, or of a single cluster, as server-sent events. Each event is a JSON with the type ADDED, MODIFIED or DELETED, the kind Cluster or NodeGroup and the object in the same format returned by the cluster and node group endpoints. The id of each event is its resourceVersion, clients reconnecting with it in the Last-Event-ID header receive the changes after this event. The stream ends when the Kubernetes watch can't be established again and clients should reconnect",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Cluster"
+                ],
+                "summary": "Watch clusters and node groups",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster Name",
+                        "name": "cluster",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of the last event received, to watch the changes after it",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.WatchEvent"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -61,6 +316,9 @@ const docTemplate = `{
                 "security": [
                     {
                         "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
                     }
                 ],
                 "description": "Get cluster by the full name and show its configuration",
@@ -90,6 +348,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.Cluster"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -103,16 +367,17 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/v1/clusters/{clusterName}/nodegroup/{nodeGroupName}/": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
                     }
                 ],
-                "description": "Shows the information about a node group of a cluster",
+                "description": "Delete a cluster and all its resources. The cluster name must be confirmed and protected clusters can't be deleted",
                 "consumes": [
                     "application/json"
                 ],
@@ -122,7 +387,7 @@ const docTemplate = `{
                 "tags": [
                     "Cluster"
                 ],
-                "summary": "Get a specific node group from a cluster",
+                "summary": "Delete a cluster",
                 "parameters": [
                     {
                         "type": "string",
@@ -133,17 +398,26 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Node Group Name",
-                        "name": "nodeGroupName",
-                        "in": "path",
+                        "description": "Cluster Name confirmation",
+                        "name": "confirm",
+                        "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/v1.NodeGroup"
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
                         }
                     },
                     "404": {
@@ -152,6 +426,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/error.ClientErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -161,24 +441,24 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/clusters/{clusterName}/nodegroups/": {
+        "/v1/clusters/{clusterName}/kubeconfig/": {
             "get": {
                 "security": [
                     {
                         "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
                     }
                 ],
-                "description": "List all node groups of a specific cluster with each Node Group information",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Download the kubeconfig of a cluster as YAML, optionally renaming its context and replacing its server. Requires a policy allowing the kubeconfig action on the cluster, the download is disabled when no policies are configured",
                 "produces": [
-                    "application/json"
+                    "application/yaml"
                 ],
                 "tags": [
                     "Cluster"
                 ],
-                "summary": "List node groups from a cluster",
+                "summary": "Download a cluster kubeconfig",
                 "parameters": [
                     {
                         "type": "string",
@@ -186,13 +466,49 @@ const docTemplate = `{
                         "name": "clusterName",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Context name",
+                        "name": "context",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Server URL",
+                        "name": "server",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Use the cluster API endpoint as server",
+                        "name": "apiendpoint",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/v1.NodeGroupList"
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
                         }
                     },
                     "500": {
@@ -203,24 +519,591 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "error.ClientErrorResponse": {
-            "type": "object",
-            "properties": {
-                "errorcode": {
-                    "type": "integer"
-                },
-                "errormessage": {
-                    "type": "string"
-                },
-                "errortype": {
-                    "type": "string"
-                },
-                "httpcode": {
-                    "type": "integer"
-                }
+        },
+        "/v1/clusters/{clusterName}/nodegroup/{nodeGroupName}/": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Shows the information about a node group of a cluster",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cluster"
+                ],
+                "summary": "Get a specific node group from a cluster",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster Name",
+                        "name": "clusterName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Node Group Name",
+                        "name": "nodeGroupName",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.NodeGroup"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/clusters/{clusterName}/nodegroups/": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List all node groups of a specific cluster with each Node Group information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cluster"
+                ],
+                "summary": "List node groups from a cluster",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster Name",
+                        "name": "clusterName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of node groups returned",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token of the next page returned by the previous one",
+                        "name": "continue",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.NodeGroupList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a node group in an existing cluster using the node group resources of the cluster provider. Kops node groups require a machine type and zones, docker node groups only support replicas and refuse the other fields",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cluster"
+                ],
+                "summary": "Create a node group in a cluster",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster Name",
+                        "name": "clusterName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Node Group specification",
+                        "name": "nodeGroup",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.NodeGroupCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/v1.NodeGroup"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/clusters/{clusterName}/nodegroups/{nodeGroupName}/": {
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a node group with its infrastructure and bootstrap resources. With drain the node group is scaled to zero before the response and deleted in the background once all its machines are gone, it is left scaled to zero if they aren't gone in 10 minutes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cluster"
+                ],
+                "summary": "Delete a node group of a cluster",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster Name",
+                        "name": "clusterName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Node Group Name",
+                        "name": "nodeGroupName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Scale the node group to zero before deleting it",
+                        "name": "drain",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the replicas, min, max and machine type of a node group. Omitted fields are kept unchanged and min \u003c= replicas \u003c= max must hold. A new machine type is supported by kops node groups and by MachineDeployment node groups of AWS machine templates, it replaces the machines with a rolling update tracked in the rollout of the node group",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cluster"
+                ],
+                "summary": "Update a node group of a cluster",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster Name",
+                        "name": "clusterName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Node Group Name",
+                        "name": "nodeGroupName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Node Group changes",
+                        "name": "nodeGroup",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.NodeGroupUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.NodeGroup"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/clusters/{clusterName}/nodegroups/{nodeGroupName}/machines/": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the machines of a node group with their provider ID, node name, phase, creation time and kubernetes version. The phase and creation time are only known for the machines of MachineDeployments",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cluster"
+                ],
+                "summary": "List the machines of a node group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster Name",
+                        "name": "clusterName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Node Group Name",
+                        "name": "nodeGroupName",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.MachineList"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/clusters/{clusterName}/upgrade/": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Upgrade the kubernetes version of a cluster control plane, only one minor version at a time. With nodegroups set, the node groups are upgraded instead, which is only allowed once the control plane runs the requested version. Kops clusters don't report the version their control plane runs, so their node groups can't be upgraded",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cluster"
+                ],
+                "summary": "Upgrade a cluster",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cluster Name",
+                        "name": "clusterName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Kubernetes version",
+                        "name": "upgrade",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.ClusterUpgradeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/v1.Cluster"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/error.ClientErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "error.ClientErrorResponse": {
+            "type": "object",
+            "properties": {
+                "errorcode": {
+                    "type": "integer"
+                },
+                "errormessage": {
+                    "type": "string"
+                },
+                "errortype": {
+                    "type": "string"
+                },
+                "httpcode": {
+                    "type": "integer"
+                },
+                "requestid": {
+                    "description": "RequestID identifies the request in the logs of the API, the same ID of the X-Request-ID response header",
+                    "type": "string"
+                }
+            }
+        },
+        "v1.AuditRecord": {
+            "type": "object",
+            "properties": {
+                "bodyhash": {
+                    "type": "string"
+                },
+                "cluster": {
+                    "type": "string"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "latencyms": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "nodegroup": {
+                    "type": "string"
+                },
+                "outcome": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "route": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.AuditRecordList": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.AuditRecord"
+                    }
+                }
             }
         },
         "v1.Cluster": {
@@ -235,12 +1118,82 @@ const docTemplate = `{
                 "kubeprovider": {
                     "type": "string"
                 },
+                "kubernetesversion": {
+                    "type": "string"
+                },
                 "metadata": {
                     "type": "object",
                     "additionalProperties": true
                 },
                 "name": {
                     "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/v1.ClusterStatus"
+                }
+            }
+        },
+        "v1.ClusterCreateRequest": {
+            "type": "object",
+            "required": [
+                "infrastructureprovider",
+                "kubeprovider",
+                "kubernetesversion",
+                "name",
+                "region",
+                "zones"
+            ],
+            "properties": {
+                "apiaccesscidrs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "clustergroup": {
+                    "type": "string"
+                },
+                "environment": {
+                    "type": "string"
+                },
+                "infrastructureprovider": {
+                    "type": "string"
+                },
+                "kubeprovider": {
+                    "type": "string"
+                },
+                "kubernetesversion": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "networkcidr": {
+                    "type": "string"
+                },
+                "podcidr": {
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "servicecidr": {
+                    "type": "string"
+                },
+                "sshaccesscidrs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "topology": {
+                    "type": "string"
+                },
+                "zones": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
@@ -252,6 +1205,107 @@ const docTemplate = `{
                     "items": {
                         "$ref": "#/definitions/v1.Cluster"
                     }
+                },
+                "next": {
+                    "description": "Next is the continue token of the next page, empty in the last page",
+                    "type": "string"
+                }
+            }
+        },
+        "v1.ClusterStatus": {
+            "type": "object",
+            "properties": {
+                "conditions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.Condition"
+                    }
+                },
+                "controlplaneready": {
+                    "type": "boolean"
+                },
+                "failuremessage": {
+                    "type": "string"
+                },
+                "failurereason": {
+                    "type": "string"
+                },
+                "infrastructureready": {
+                    "type": "boolean"
+                },
+                "phase": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.ClusterUpgradeRequest": {
+            "type": "object",
+            "required": [
+                "version"
+            ],
+            "properties": {
+                "nodegroups": {
+                    "type": "boolean"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.Condition": {
+            "type": "object",
+            "properties": {
+                "lasttransitiontime": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "severity": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.Machine": {
+            "type": "object",
+            "properties": {
+                "creationtime": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nodename": {
+                    "type": "string"
+                },
+                "phase": {
+                    "type": "string"
+                },
+                "providerid": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.MachineList": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.Machine"
+                    }
                 }
             }
         },
@@ -301,6 +1355,41 @@ const docTemplate = `{
                 },
                 "name": {
                     "type": "string"
+                },
+                "rollout": {
+                    "$ref": "#/definitions/v1.Rollout"
+                },
+                "status": {
+                    "$ref": "#/definitions/v1.Status"
+                }
+            }
+        },
+        "v1.NodeGroupCreateRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "machinetype": {
+                    "type": "string"
+                },
+                "max": {
+                    "type": "integer"
+                },
+                "min": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "replicas": {
+                    "type": "integer"
+                },
+                "zones": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
@@ -312,6 +1401,85 @@ const docTemplate = `{
                     "items": {
                         "$ref": "#/definitions/v1.NodeGroup"
                     }
+                },
+                "next": {
+                    "description": "Next is the continue token of the next page, empty in the last page",
+                    "type": "string"
+                }
+            }
+        },
+        "v1.NodeGroupUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "machinetype": {
+                    "type": "string"
+                },
+                "max": {
+                    "type": "integer"
+                },
+                "min": {
+                    "type": "integer"
+                },
+                "replicas": {
+                    "type": "integer"
+                }
+            }
+        },
+        "v1.Rollout": {
+            "type": "object",
+            "properties": {
+                "readyreplicas": {
+                    "type": "integer"
+                },
+                "replicas": {
+                    "type": "integer"
+                },
+                "updatedreplicas": {
+                    "type": "integer"
+                }
+            }
+        },
+        "v1.Status": {
+            "type": "object",
+            "properties": {
+                "availablereplicas": {
+                    "type": "integer"
+                },
+                "failuremessage": {
+                    "type": "string"
+                },
+                "failurereason": {
+                    "type": "string"
+                },
+                "observedgeneration": {
+                    "type": "integer"
+                },
+                "phase": {
+                    "type": "string"
+                },
+                "readyreplicas": {
+                    "type": "integer"
+                },
+                "replicas": {
+                    "type": "integer"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "unavailablereplicas": {
+                    "type": "integer"
+                }
+            }
+        },
+        "v1.WatchEvent": {
+            "type": "object",
+            "properties": {
+                "kind": {
+                    "type": "string"
+                },
+                "object": {},
+                "type": {
+                    "type": "string"
                 }
             }
         }
@@ -319,6 +1487,11 @@ const docTemplate = `{
     "securityDefinitions": {
         "BasicAuth": {
             "type": "basic"
+        },
+        "BearerAuth": {
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"os"
+	"sort"
+	"time"
+)
+
+// Environment variables configuring where the audit records are written, the stdout sink by default
+const (
+	SinkEnv     = "AUDIT_SINK"
+	FilePathEnv = "AUDIT_FILE_PATH"
+)
+
+// Audit sinks
+const (
+	SinkStdout = "stdout"
+	SinkFile   = "file"
+	SinkEvents = "events"
+)
+
+const DefaultSink = SinkStdout
+
+const DefaultFilePath = "/var/log/kaas-management-api/audit.log"
+
+// Outcomes of the audited requests
+const (
+	OutcomeSuccess = "success"
+	OutcomeDenied  = "denied"
+	OutcomeFailure = "failure"
+)
+
+// ErrQueryNotSupported is returned by the sinks that can't read back their records
+var ErrQueryNotSupported = errors.New("the audit sink doesn't support queries")
+
+// Record is a write request to the API, the body is only kept as its SHA-256 hash
+type Record struct {
+	Time                time.Time `json:"time"`
+	Subject             string    `json:"subject,omitempty"`
+	Groups              []string  `json:"groups,omitempty"`
+	Method              string    `json:"method"`
+	Route               string    `json:"route"`
+	Path                string    `json:"path"`
+	Cluster             string    `json:"cluster,omitempty"`
+	NodeGroup           string    `json:"nodegroup,omitempty"`
+	BodyHash            string    `json:"bodyhash,omitempty"`
+	Status              int       `json:"status"`
+	Outcome             string    `json:"outcome"`
+	LatencyMilliseconds int64     `json:"latencyms"`
+}
+
+// Filter selects the records of a cluster in a time range, the empty fields match any record
+type Filter struct {
+	Cluster string
+	Since   time.Time
+	Until   time.Time
+}
+
+// Matches returns true if the record is selected by the filter
+func (f Filter) Matches(record *Record) bool {
+	if f.Cluster != "" && record.Cluster != f.Cluster {
+		return false
+	}
+	if !f.Since.IsZero() && record.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && record.Time.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Sink stores the audit records
+type Sink interface {
+	// Write stores a record
+	Write(record *Record) error
+	// Query returns the records matching the filter from the oldest to the newest, or ErrQueryNotSupported
+	Query(filter Filter) ([]*Record, error)
+}
+
+// NewSinkFromEnv creates the audit sink configured in the environment
+func NewSinkFromEnv(k *k8s.Kubernetes) (Sink, error) {
+	sink := os.Getenv(SinkEnv)
+	if sink == "" {
+		sink = DefaultSink
+	}
+
+	switch sink {
+	case SinkStdout:
+		return &WriterSink{Writer: os.Stdout}, nil
+	case SinkFile:
+		path := os.Getenv(FilePathEnv)
+		if path == "" {
+			path = DefaultFilePath
+		}
+		return NewFileSink(path)
+	case SinkEvents:
+		return &EventSink{K8sInstance: k}, nil
+	default:
+		return nil, fmt.Errorf("unknown audit sink %s, it must be %s, %s or %s", sink, SinkStdout, SinkFile, SinkEvents)
+	}
+}
+
+// sortRecords sorts the records from the oldest to the newest
+func sortRecords(records []*Record) {
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+}
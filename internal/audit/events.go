@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+const (
+	// EventLabel marks the events with audit records
+	EventLabel = "kaas-management-api/audit"
+	// EventRecordAnnotation is the annotation of the events with the audit record in JSON
+	EventRecordAnnotation = "kaas-management-api/audit-record"
+	// EventReason is the reason of the events with audit records
+	EventReason = "Audit"
+	// EventComponent is the source of the events with audit records
+	EventComponent = "kaas-management-api"
+)
+
+// EventSink writes the records as Kubernetes Events of the cluster in its namespace, the records without a cluster or whose
+// cluster namespace can't be resolved or written, like clusters that failed to be created, go to the namespace of the configuration.
+// It is best-effort: the apiserver deletes the Events after its --event-ttl, one hour by default, so the older records are lost,
+// and every query lists the audit Events of all namespaces. Use the file sink on a persistent volume for a durable audit trail.
+type EventSink struct {
+	K8sInstance *k8s.Kubernetes
+}
+
+func (s *EventSink) Write(record *Record) error {
+	rawRecord, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	configNamespace, _ := k8s.GetConfigMapLocation()
+	namespace := configNamespace
+	if record.Cluster != "" {
+		clusterNamespace, err := s.K8sInstance.ClusterNamespace(record.Cluster)
+		if err == nil {
+			namespace = clusterNamespace
+		}
+	}
+
+	event := newAuditEvent(namespace, record, string(rawRecord))
+	err = s.K8sInstance.CreateEvent(event)
+	if err != nil && namespace != configNamespace {
+		s.K8sInstance.Log().Warn("Could not write the audit event in the cluster namespace, writing it in the configuration namespace", zap.String("namespace", namespace), zap.String("configNamespace", configNamespace), zap.Error(err))
+		err = s.K8sInstance.CreateEvent(newAuditEvent(configNamespace, record, string(rawRecord)))
+	}
+	return err
+}
+
+func (s *EventSink) Query(filter Filter) ([]*Record, error) {
+	events, err := s.K8sInstance.ListEvents(metav1.NamespaceAll, EventLabel+"=true")
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*Record
+	for _, event := range events {
+		record := &Record{}
+		if err := json.Unmarshal([]byte(event.Annotations[EventRecordAnnotation]), record); err != nil {
+			s.K8sInstance.Log().Warn("Skipping the invalid audit event", zap.String("namespace", event.Namespace), zap.String("name", event.Name), zap.Error(err))
+			continue
+		}
+		if filter.Matches(record) {
+			records = append(records, record)
+		}
+	}
+
+	sortRecords(records)
+	return records, nil
+}
+
+func newAuditEvent(namespace string, record *Record, rawRecord string) *corev1.Event {
+	eventType := corev1.EventTypeNormal
+	if record.Outcome != OutcomeSuccess {
+		eventType = corev1.EventTypeWarning
+	}
+
+	subject := record.Subject
+	if subject == "" {
+		subject = "an anonymous caller"
+	}
+
+	involvedObject := corev1.ObjectReference{Kind: "Namespace", APIVersion: "v1", Name: namespace}
+	if record.Cluster != "" {
+		involvedObject = corev1.ObjectReference{Kind: "Cluster", APIVersion: clusterapiv1beta1.GroupVersion.String(), Namespace: namespace, Name: record.Cluster}
+	}
+
+	timestamp := metav1.NewTime(record.Time)
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s.%x", EventComponent, record.Time.UnixNano()),
+			Namespace:   namespace,
+			Labels:      map[string]string{EventLabel: "true"},
+			Annotations: map[string]string{EventRecordAnnotation: rawRecord},
+		},
+		InvolvedObject: involvedObject,
+		Reason:         EventReason,
+		Message:        fmt.Sprintf("%s %s by %s: %d %s", record.Method, record.Path, subject, record.Status, record.Outcome),
+		Source:         corev1.EventSource{Component: EventComponent},
+		FirstTimestamp: timestamp,
+		LastTimestamp:  timestamp,
+		Count:          1,
+		Type:           eventType,
+	}
+}
@@ -0,0 +1,61 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/test"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEventSink(t *testing.T) {
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{DynamicClient: test.NewK8sFakeDynamicClient(), Clientset: test.NewK8sFakeClientset()}}
+	sink := &EventSink{K8sInstance: k}
+
+	start := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	records := []*Record{
+		{Time: start, Subject: "admin", Method: http.MethodPost, Path: "/v1/clusters/", Cluster: "games", Status: http.StatusAccepted, Outcome: OutcomeSuccess},
+		{Time: start.Add(time.Hour), Subject: "admin", Method: http.MethodDelete, Path: "/v1/clusters/games/", Cluster: "games", Status: http.StatusForbidden, Outcome: OutcomeDenied},
+		{Time: start.Add(2 * time.Hour), Subject: "admin", Method: http.MethodDelete, Path: "/v1/clusters/platform/", Cluster: "platform", Status: http.StatusAccepted, Outcome: OutcomeSuccess},
+		{Time: start.Add(3 * time.Hour), Method: http.MethodPost, Path: "/v1/clusters/", Status: http.StatusUnauthorized, Outcome: OutcomeDenied},
+	}
+	// Written out of order to check the records are sorted by time
+	for _, i := range []int{3, 1, 0, 2} {
+		assert.Nil(t, sink.Write(records[i]))
+	}
+
+	events, err := k.K8sAuth.Clientset.CoreV1().Events(test.GetTestClusterNamespace("games")).List(context.TODO(), metav1.ListOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, events.Items, 2)
+	assert.Equal(t, "Cluster", events.Items[0].InvolvedObject.Kind)
+	assert.Equal(t, "games", events.Items[0].InvolvedObject.Name)
+
+	events, err = k.K8sAuth.Clientset.CoreV1().Events(k8s.DefaultConfigNamespace).List(context.TODO(), metav1.ListOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, events.Items, 1)
+	assert.Equal(t, "Warning", events.Items[0].Type)
+	assert.Equal(t, "POST /v1/clusters/ by an anonymous caller: 401 denied", events.Items[0].Message)
+
+	testCases := []struct {
+		Name     string
+		Filter   Filter
+		Expected []*Record
+	}{
+		{Name: "Query should return every record without filters", Filter: Filter{}, Expected: records},
+		{Name: "Query should return the records of a cluster", Filter: Filter{Cluster: "games"}, Expected: records[:2]},
+		{Name: "Query should return the records of a time range", Filter: Filter{Since: start.Add(time.Hour), Until: start.Add(2 * time.Hour)}, Expected: records[1:3]},
+		{Name: "Query should return no records for an unknown cluster", Filter: Filter{Cluster: "unknown"}, Expected: nil},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			queried, err := sink.Query(testCase.Filter)
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.Expected, queried)
+		})
+	}
+}
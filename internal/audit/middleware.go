@@ -0,0 +1,160 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	clusterv1 "github.com/topfreegames/kaas-management-api/api/cluster/v1"
+	nodegroupv1 "github.com/topfreegames/kaas-management-api/api/nodeGroup/v1"
+	"github.com/topfreegames/kaas-management-api/internal/auth"
+	"github.com/topfreegames/kaas-management-api/internal/logging"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"go.uber.org/zap"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// MaxBodySize is the size limit of the bodies of the write requests, read by the audit before the authentication so any caller
+// can send them. The bodies over the limit are refused.
+const MaxBodySize = 1 << 20
+
+// MaxAnonymousRecordsPerSecond is the number of records of anonymous write requests logged per second, the others are
+// dropped and counted in the next logged one
+const MaxAnonymousRecordsPerSecond = 10
+
+// Middleware returns a middleware writing an audit record of every authenticated write request to the sink. It must run
+// before the authentication middleware, so the requests refused without valid credentials are recorded too. Their records
+// are only logged, at most MaxAnonymousRecordsPerSecond, so any caller can't flood the sink.
+func Middleware(sink Sink) gin.HandlerFunc {
+	limiter := &recordLimiter{limit: MaxAnonymousRecordsPerSecond, period: time.Second}
+	return func(c *gin.Context) {
+		if !isWrite(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			body, err = ioutil.ReadAll(io.LimitReader(c.Request.Body, MaxBodySize+1))
+			if err != nil {
+				logging.FromContext(c.Request.Context()).Error("Could not read the body of the request", zap.String("handler", "Audit"), zap.String("path", c.Request.URL.Path), zap.Error(err))
+			}
+			c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+		if len(body) > MaxBodySize {
+			body = nil
+			err := clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("The request body is larger than %d bytes", MaxBodySize))
+			clientError.ErrorHandler(c, err, "Request body too large", http.StatusRequestEntityTooLarge)
+			c.Abort()
+		}
+
+		c.Next()
+
+		record := &Record{
+			Time:                start.UTC(),
+			Method:              c.Request.Method,
+			Route:               c.FullPath(),
+			Path:                c.Request.URL.Path,
+			Cluster:             c.Param(clusterv1.ClusterNameParameter),
+			NodeGroup:           c.Param(nodegroupv1.NodeGroupNameParameter),
+			Status:              c.Writer.Status(),
+			Outcome:             outcomeOf(c.Writer.Status()),
+			LatencyMilliseconds: time.Since(start).Milliseconds(),
+		}
+		if identity, ok := auth.GetIdentity(c); ok {
+			record.Subject = identity.Subject
+			record.Groups = identity.Groups
+		}
+		if len(body) != 0 {
+			hash := sha256.Sum256(body)
+			record.BodyHash = "sha256:" + hex.EncodeToString(hash[:])
+		}
+		// The name of a new cluster is only in the body of the create request
+		if record.Cluster == "" && record.Route == clusterv1.Endpoint.Path {
+			var clusterCreateRequest clusterv1.ClusterCreateRequest
+			if json.Unmarshal(body, &clusterCreateRequest) == nil {
+				record.Cluster = clusterCreateRequest.Name
+			}
+		}
+
+		if record.Subject == "" {
+			logAnonymousRecord(c, record, limiter)
+			return
+		}
+
+		if err := sink.Write(record); err != nil {
+			logging.FromContext(c.Request.Context()).Error("Could not write the audit record", zap.String("handler", "Audit"), zap.String("method", record.Method), zap.String("path", record.Path), zap.String("subject", record.Subject), zap.Error(err))
+		}
+	}
+}
+
+// logAnonymousRecord logs the record of an anonymous write request, if the limiter allows it
+func logAnonymousRecord(c *gin.Context, record *Record, limiter *recordLimiter) {
+	allowed, dropped := limiter.allow(time.Now())
+	if !allowed {
+		return
+	}
+	logging.FromContext(c.Request.Context()).Warn("Anonymous write request",
+		zap.String("handler", "Audit"),
+		zap.String("method", record.Method),
+		zap.String("path", record.Path),
+		zap.Int("status", record.Status),
+		zap.String("outcome", record.Outcome),
+		zap.String("bodyHash", record.BodyHash),
+		zap.Int("droppedRecords", dropped),
+	)
+}
+
+// recordLimiter allows limit records per period, counting the records it drops
+type recordLimiter struct {
+	limit  int
+	period time.Duration
+
+	mu          sync.Mutex
+	periodStart time.Time
+	allowed     int
+	dropped     int
+}
+
+// allow returns true if a record can be written at the time, with the number of records dropped since the last allowed one
+func (l *recordLimiter) allow(now time.Time) (bool, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.periodStart) >= l.period {
+		l.periodStart = now
+		l.allowed = 0
+	}
+	if l.allowed >= l.limit {
+		l.dropped++
+		return false, 0
+	}
+
+	l.allowed++
+	dropped := l.dropped
+	l.dropped = 0
+	return true, dropped
+}
+
+func isWrite(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch || method == http.MethodDelete
+}
+
+func outcomeOf(status int) string {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return OutcomeDenied
+	case status >= http.StatusBadRequest:
+		return OutcomeFailure
+	default:
+		return OutcomeSuccess
+	}
+}
@@ -0,0 +1,193 @@
+package audit
+
+import (
+	"bytes"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	clusterv1 "github.com/topfreegames/kaas-management-api/api/cluster/v1"
+	nodegroupv1 "github.com/topfreegames/kaas-management-api/api/nodeGroup/v1"
+	"github.com/topfreegames/kaas-management-api/internal/auth"
+	"github.com/topfreegames/kaas-management-api/test"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// newTestAuditedRouter returns a router audited to the sink whose callers are admin, except for the requests with the X-Anonymous header
+func newTestAuditedRouter(sink Sink) *gin.Engine {
+	router := gin.New()
+	router.Use(Middleware(sink))
+	router.Use(func(c *gin.Context) {
+		if c.GetHeader("X-Anonymous") != "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Set(auth.SubjectContextKey, "admin")
+		c.Set(auth.GroupsContextKey, []string{"platform"})
+	})
+
+	respond := func(c *gin.Context) {
+		c.Status(http.StatusAccepted)
+	}
+	router.Handle(http.MethodGet, clusterv1.Endpoint.Path, respond)
+	router.Handle(http.MethodPost, clusterv1.Endpoint.Path, respond)
+	router.Handle(http.MethodDelete, clusterv1.Endpoint.Path+test.Param(clusterv1.ClusterNameParameter), func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+	router.Handle(http.MethodPatch, clusterv1.Endpoint.Path+test.Param(clusterv1.ClusterNameParameter)+test.Path(nodegroupv1.Endpoint.EndpointName)+test.Param(nodegroupv1.NodeGroupNameParameter), respond)
+	return router
+}
+
+// observeTestLogs replaces the global logger with one observing the logs until the test ends
+func observeTestLogs(t *testing.T) *observer.ObservedLogs {
+	core, logs := observer.New(zapcore.DebugLevel)
+	t.Cleanup(zap.ReplaceGlobals(zap.New(core)))
+	return logs
+}
+
+func TestMiddleware(t *testing.T) {
+	logs := observeTestLogs(t)
+	sink, err := NewFileSink(filepath.Join(t.TempDir(), "audit.log"))
+	assert.Nil(t, err)
+	router := newTestAuditedRouter(sink)
+
+	requests := []*test.HTTPTestRequest{
+		{Method: http.MethodGet, Path: clusterv1.Endpoint.Path},
+		{Method: http.MethodPost, Path: clusterv1.Endpoint.Path, Body: bytes.NewReader([]byte(`{"name":"new-cluster"}`))},
+		{Method: http.MethodPatch, Path: clusterv1.Endpoint.Path + "games/nodegroups/nodes/", Body: bytes.NewReader([]byte(`{"min":3}`))},
+		{Method: http.MethodDelete, Path: clusterv1.Endpoint.Path + "unknown/"},
+		{Method: http.MethodDelete, Path: clusterv1.Endpoint.Path + "games/", Header: http.Header{"X-Anonymous": []string{"true"}}},
+	}
+	for _, request := range requests {
+		request.RunHTTPTest(router)
+	}
+
+	records, err := sink.Query(Filter{})
+	assert.Nil(t, err)
+	assert.Len(t, records, 3)
+	for _, record := range records {
+		record.Time = time.Time{}
+		record.LatencyMilliseconds = 0
+	}
+
+	expected := []*Record{
+		{
+			Subject:  "admin",
+			Groups:   []string{"platform"},
+			Method:   http.MethodPost,
+			Route:    "/v1/clusters/",
+			Path:     "/v1/clusters/",
+			Cluster:  "new-cluster",
+			BodyHash: "sha256:cccca4a460606a0f3d0edd8bb2112c4fda5541cd1f81e76df1c987db29437853",
+			Status:   http.StatusAccepted,
+			Outcome:  OutcomeSuccess,
+		},
+		{
+			Subject:   "admin",
+			Groups:    []string{"platform"},
+			Method:    http.MethodPatch,
+			Route:     "/v1/clusters/:clusterName/nodegroups/:nodeGroupName/",
+			Path:      "/v1/clusters/games/nodegroups/nodes/",
+			Cluster:   "games",
+			NodeGroup: "nodes",
+			BodyHash:  "sha256:83ec1d921b18623a4b72792e7f4c3a158ae046a3fef588f5183d2dbbe6bfe0b5",
+			Status:    http.StatusAccepted,
+			Outcome:   OutcomeSuccess,
+		},
+		{
+			Subject: "admin",
+			Groups:  []string{"platform"},
+			Method:  http.MethodDelete,
+			Route:   "/v1/clusters/:clusterName/",
+			Path:    "/v1/clusters/unknown/",
+			Cluster: "unknown",
+			Status:  http.StatusNotFound,
+			Outcome: OutcomeFailure,
+		},
+	}
+	assert.Equal(t, expected, records)
+
+	// The anonymous requests are only logged
+	anonymousLogs := logs.FilterMessage("Anonymous write request").All()
+	assert.Len(t, anonymousLogs, 1)
+	if len(anonymousLogs) == 1 {
+		fields := anonymousLogs[0].ContextMap()
+		assert.Equal(t, "/v1/clusters/games/", fields["path"])
+		assert.Equal(t, int64(http.StatusUnauthorized), fields["status"])
+		assert.Equal(t, OutcomeDenied, fields["outcome"])
+	}
+}
+
+func TestMiddleware_BodyTooLarge(t *testing.T) {
+	logs := observeTestLogs(t)
+	sink, err := NewFileSink(filepath.Join(t.TempDir(), "audit.log"))
+	assert.Nil(t, err)
+	router := newTestAuditedRouter(sink)
+
+	request := &test.HTTPTestRequest{
+		Method: http.MethodPost,
+		Path:   clusterv1.Endpoint.Path,
+		Body:   bytes.NewReader(bytes.Repeat([]byte(" "), MaxBodySize+1)),
+	}
+	w := request.RunHTTPTest(router)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	records, err := sink.Query(Filter{})
+	assert.Nil(t, err)
+	assert.Empty(t, records)
+
+	anonymousLogs := logs.FilterMessage("Anonymous write request").All()
+	assert.Len(t, anonymousLogs, 1)
+	if len(anonymousLogs) == 1 {
+		fields := anonymousLogs[0].ContextMap()
+		assert.Equal(t, int64(http.StatusRequestEntityTooLarge), fields["status"])
+		assert.Equal(t, "", fields["bodyHash"])
+	}
+}
+
+func TestMiddleware_AnonymousRecordsLimit(t *testing.T) {
+	logs := observeTestLogs(t)
+	sink, err := NewFileSink(filepath.Join(t.TempDir(), "audit.log"))
+	assert.Nil(t, err)
+	router := newTestAuditedRouter(sink)
+
+	for i := 0; i < 3*MaxAnonymousRecordsPerSecond; i++ {
+		request := &test.HTTPTestRequest{Method: http.MethodDelete, Path: clusterv1.Endpoint.Path + "games/", Header: http.Header{"X-Anonymous": []string{"true"}}}
+		request.RunHTTPTest(router)
+	}
+
+	records, err := sink.Query(Filter{})
+	assert.Nil(t, err)
+	assert.Empty(t, records)
+	// The requests may cross a period, each one logging at most MaxAnonymousRecordsPerSecond records
+	anonymousLogs := logs.FilterMessage("Anonymous write request").Len()
+	assert.GreaterOrEqual(t, anonymousLogs, MaxAnonymousRecordsPerSecond)
+	assert.Less(t, anonymousLogs, 3*MaxAnonymousRecordsPerSecond)
+}
+
+func TestRecordLimiter(t *testing.T) {
+	limiter := &recordLimiter{limit: 2, period: time.Second}
+	now := time.Now()
+
+	allowed, dropped := limiter.allow(now)
+	assert.True(t, allowed)
+	assert.Equal(t, 0, dropped)
+	allowed, _ = limiter.allow(now)
+	assert.True(t, allowed)
+	allowed, _ = limiter.allow(now.Add(500 * time.Millisecond))
+	assert.False(t, allowed)
+	allowed, _ = limiter.allow(now.Add(900 * time.Millisecond))
+	assert.False(t, allowed)
+
+	allowed, dropped = limiter.allow(now.Add(time.Second))
+	assert.True(t, allowed)
+	assert.Equal(t, 2, dropped)
+	allowed, dropped = limiter.allow(now.Add(time.Second))
+	assert.True(t, allowed)
+	assert.Equal(t, 0, dropped)
+}
@@ -0,0 +1,92 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"sync"
+)
+
+// WriterSink writes the records as JSON lines to a writer, like stdout, and can't query them
+type WriterSink struct {
+	Writer io.Writer
+	mutex  sync.Mutex
+}
+
+func (s *WriterSink) Write(record *Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, err = s.Writer.Write(append(line, '\n'))
+	return err
+}
+
+func (s *WriterSink) Query(filter Filter) ([]*Record, error) {
+	return nil, ErrQueryNotSupported
+}
+
+// FileSink appends the records as JSON lines to a file and queries them by reading the whole file
+type FileSink struct {
+	path  string
+	mutex sync.Mutex
+	file  *os.File
+}
+
+// NewFileSink opens the file of the records, creating it if it doesn't exist
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("could not open the audit file %s: %v", path, err)
+	}
+	return &FileSink{path: path, file: file}, nil
+}
+
+func (s *FileSink) Write(record *Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+func (s *FileSink) Query(filter Filter) ([]*Record, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open the audit file %s: %v", s.path, err)
+	}
+	defer file.Close()
+
+	var records []*Record
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		record := &Record{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			zap.L().Warn("Skipping an invalid line of the audit file", zap.String("path", s.path), zap.Error(err))
+			continue
+		}
+		if filter.Matches(record) {
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read the audit file %s: %v", s.path, err)
+	}
+
+	sortRecords(records)
+	return records, nil
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ModesEnv is the environment variable with the comma separated authentication modes, DefaultModes by default
+const ModesEnv = "AUTH_MODES"
+
+// Authentication modes
+const (
+	ModeBasic = "basic"
+	ModeJWT   = "jwt"
+)
+
+const DefaultModes = ModeBasic
+
+// Keys of the authenticated identity in the gin context
+const (
+	SubjectContextKey = "subject"
+	GroupsContextKey  = "groups"
+	ClaimsContextKey  = "claims"
+)
+
+// ErrNoCredentials is returned by authenticators when the request doesn't have credentials of their kind
+var ErrNoCredentials = errors.New("the request has no credentials")
+
+// Identity is who made a request, the claims are only set for token authentication
+type Identity struct {
+	Subject string
+	Groups  []string
+	Claims  map[string]interface{}
+}
+
+// Authenticator authenticates the requests with one kind of credentials
+type Authenticator interface {
+	// Authenticate returns the identity of the request, or ErrNoCredentials when the request doesn't have credentials of this kind
+	Authenticate(request *http.Request) (*Identity, error)
+	// Challenge returns the WWW-Authenticate challenge for requests without valid credentials
+	Challenge() string
+}
+
+// NewAuthenticatorsFromEnv creates the authenticators of the modes configured in the environment,
+// their credentials and keys are reloaded in background until stopCh is closed
+func NewAuthenticatorsFromEnv(stopCh <-chan struct{}) ([]Authenticator, error) {
+	modes := os.Getenv(ModesEnv)
+	if modes == "" {
+		modes = DefaultModes
+	}
+
+	var authenticators []Authenticator
+	for _, mode := range strings.Split(modes, ",") {
+		switch mode = strings.TrimSpace(mode); mode {
+		case ModeBasic:
+			credentials, err := NewCredentialsFromEnv()
+			if err != nil {
+				return nil, err
+			}
+			go credentials.Watch(CredentialsReloadPeriod, stopCh)
+			authenticators = append(authenticators, &BasicAuthenticator{Credentials: credentials})
+		case ModeJWT:
+			authenticator, err := NewJWTAuthenticatorFromEnv(stopCh)
+			if err != nil {
+				return nil, err
+			}
+			authenticators = append(authenticators, authenticator)
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown authentication mode %s", mode)
+		}
+	}
+
+	if len(authenticators) == 0 {
+		return nil, fmt.Errorf("%s must have at least one authentication mode", ModesEnv)
+	}
+	return authenticators, nil
+}
+
+// GetIdentity returns the identity set in the context by the authentication middleware, or false for unauthenticated requests
+func GetIdentity(c *gin.Context) (*Identity, bool) {
+	subject := c.GetString(SubjectContextKey)
+	if subject == "" {
+		return nil, false
+	}
+
+	identity := &Identity{Subject: subject, Groups: c.GetStringSlice(GroupsContextKey)}
+	if claims, ok := c.Get(ClaimsContextKey); ok {
+		identity.Claims, _ = claims.(map[string]interface{})
+	}
+	return identity, true
+}
@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	corev1 "k8s.io/api/core/v1"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CredentialsPathEnv is the environment variable with the path of the Basic Auth credentials, either an htpasswd file
+// or the directory where a Secret of type kubernetes.io/basic-auth is mounted
+const CredentialsPathEnv = "AUTH_CREDENTIALS_PATH"
+
+// CredentialsReloadPeriod is how often the credentials are read again to apply their changes without a restart
+const CredentialsReloadPeriod = 30 * time.Second
+
+// Credentials keeps the users and password hashes allowed to use the API, reloading them when their source changes
+type Credentials struct {
+	path  string
+	mutex sync.RWMutex
+	raw   []byte
+	users map[string]string
+}
+
+// NewCredentialsFromEnv loads the credentials from the path in CredentialsPathEnv
+func NewCredentialsFromEnv() (*Credentials, error) {
+	path := os.Getenv(CredentialsPathEnv)
+	if path == "" {
+		return nil, fmt.Errorf("%s must be set with the path of the Basic Auth credentials", CredentialsPathEnv)
+	}
+	return NewCredentials(path)
+}
+
+// NewCredentials loads the credentials from an htpasswd file or from a mounted kubernetes.io/basic-auth Secret
+func NewCredentials(path string) (*Credentials, error) {
+	credentials := &Credentials{path: path}
+	_, err := credentials.Reload()
+	if err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+// Reload reads the credentials again, returning whether they have changed. On errors the previous credentials are kept.
+func (c *Credentials) Reload() (bool, error) {
+	raw, users, err := readCredentials(c.path)
+	if err != nil {
+		return false, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.users != nil && bytes.Equal(raw, c.raw) {
+		return false, nil
+	}
+	c.raw = raw
+	c.users = users
+	return true, nil
+}
+
+// Watch reloads the credentials every period until stopCh is closed
+func (c *Credentials) Watch(period time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			changed, err := c.Reload()
+			if err != nil {
+				zap.L().Error("Could not reload the Basic Auth credentials, keeping the previous ones", zap.String("path", c.path), zap.Error(err))
+			} else if changed {
+				zap.L().Info("Reloaded the Basic Auth credentials", zap.String("path", c.path))
+			}
+		}
+	}
+}
+
+// Authenticate returns true if the user exists and the password matches its hash
+func (c *Credentials) Authenticate(user string, password string) bool {
+	c.mutex.RLock()
+	hash, ok := c.users[user]
+	c.mutex.RUnlock()
+	if !ok {
+		return false
+	}
+	return matchPassword(hash, password)
+}
+
+// BasicAuthenticator authenticates the requests with Basic Auth credentials
+type BasicAuthenticator struct {
+	Credentials *Credentials
+}
+
+func (a *BasicAuthenticator) Authenticate(request *http.Request) (*Identity, error) {
+	user, password, ok := request.BasicAuth()
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	if !a.Credentials.Authenticate(user, password) {
+		return nil, fmt.Errorf("invalid Basic Auth credentials for the user %s", user)
+	}
+	return &Identity{Subject: user}, nil
+}
+
+func (a *BasicAuthenticator) Challenge() string {
+	return `Basic realm="kaas-management-api"`
+}
+
+// readCredentials reads the raw credentials and the users of a path, a directory is read as a mounted kubernetes.io/basic-auth Secret
+func readCredentials(path string) ([]byte, map[string]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !info.IsDir() {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		users, err := ParseHtpasswd(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid htpasswd file %s: %v", path, err)
+		}
+		return raw, users, nil
+	}
+
+	user, err := os.ReadFile(filepath.Join(path, corev1.BasicAuthUsernameKey))
+	if err != nil {
+		return nil, nil, err
+	}
+	password, err := os.ReadFile(filepath.Join(path, corev1.BasicAuthPasswordKey))
+	if err != nil {
+		return nil, nil, err
+	}
+	// Secrets created from files usually end with a line break that isn't part of the credentials
+	user = bytes.TrimRight(user, "\r\n")
+	password = bytes.TrimRight(password, "\r\n")
+	if len(user) == 0 || len(password) == 0 {
+		return nil, nil, fmt.Errorf("the Secret mounted in %s must have a %s and a %s", path, corev1.BasicAuthUsernameKey, corev1.BasicAuthPasswordKey)
+	}
+
+	raw := append(append(user, ':'), password...)
+	return raw, map[string]string{string(user): string(password)}, nil
+}
+
+// ParseHtpasswd parses the user:hash lines of an htpasswd file, the hashes can be bcrypt, {SHA} or plain text
+func ParseHtpasswd(data []byte) (map[string]string, error) {
+	users := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(entry, ":", 2)
+		if len(fields) != 2 || fields[0] == "" || fields[1] == "" {
+			return nil, fmt.Errorf("line %d must be in the format user:hash", line)
+		}
+		user, hash := fields[0], fields[1]
+		if strings.HasPrefix(hash, "$") && !isBcrypt(hash) {
+			return nil, fmt.Errorf("the hash of the user %s isn't supported, only bcrypt, {SHA} and plain text passwords are", user)
+		}
+		users[user] = hash
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(users) == 0 {
+		return nil, fmt.Errorf("no users were found")
+	}
+	return users, nil
+}
+
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// matchPassword compares a password with a bcrypt, {SHA} or plain text hash
+func matchPassword(hash string, password string) bool {
+	switch {
+	case isBcrypt(hash):
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	case strings.HasPrefix(hash, "{SHA}"):
+		sum := sha1.Sum([]byte(password))
+		return subtle.ConstantTimeCompare([]byte(hash), []byte("{SHA}"+base64.StdEncoding.EncodeToString(sum[:]))) == 1
+	default:
+		return subtle.ConstantTimeCompare([]byte(hash), []byte(password)) == 1
+	}
+}
@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// writeTestFile writes a file in the directory, replacing it if it exists
+func writeTestFile(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	err := os.WriteFile(path, []byte(content), 0600)
+	assert.Nil(t, err)
+	return path
+}
+
+func TestCredentials_Authenticate(t *testing.T) {
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("bcrypt-password"), bcrypt.MinCost)
+	assert.Nil(t, err)
+
+	htpasswd := "# users of the API\n" +
+		"bcrypt:" + string(bcryptHash) + "\n" +
+		"sha:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\n" +
+		"\n" +
+		"plain:plain-password\n"
+	path := writeTestFile(t, t.TempDir(), "htpasswd", htpasswd)
+
+	credentials, err := NewCredentials(path)
+	assert.Nil(t, err)
+
+	testCases := []struct {
+		Name     string
+		User     string
+		Password string
+		Expected bool
+	}{
+		{Name: "Should authenticate a bcrypt password", User: "bcrypt", Password: "bcrypt-password", Expected: true},
+		{Name: "Should authenticate a SHA password", User: "sha", Password: "password", Expected: true},
+		{Name: "Should authenticate a plain text password", User: "plain", Password: "plain-password", Expected: true},
+		{Name: "Should refuse a wrong bcrypt password", User: "bcrypt", Password: "wrong", Expected: false},
+		{Name: "Should refuse a wrong SHA password", User: "sha", Password: "wrong", Expected: false},
+		{Name: "Should refuse a wrong plain text password", User: "plain", Password: "wrong", Expected: false},
+		{Name: "Should refuse an unknown user", User: "unknown", Password: "plain-password", Expected: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			assert.Equal(t, testCase.Expected, credentials.Authenticate(testCase.User, testCase.Password))
+		})
+	}
+}
+
+func TestCredentials_Secret(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, corev1.BasicAuthUsernameKey, "admin")
+	writeTestFile(t, dir, corev1.BasicAuthPasswordKey, "secret\n")
+
+	credentials, err := NewCredentials(dir)
+	assert.Nil(t, err)
+	assert.True(t, credentials.Authenticate("admin", "secret"))
+
+	writeTestFile(t, dir, corev1.BasicAuthPasswordKey, "rotated")
+	changed, err := credentials.Reload()
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	assert.False(t, credentials.Authenticate("admin", "secret"))
+	assert.True(t, credentials.Authenticate("admin", "rotated"))
+
+	changed, err = credentials.Reload()
+	assert.Nil(t, err)
+	assert.False(t, changed)
+}
+
+func TestCredentials_Watch(t *testing.T) {
+	path := writeTestFile(t, t.TempDir(), "htpasswd", "admin:password\n")
+	credentials, err := NewCredentials(path)
+	assert.Nil(t, err)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go credentials.Watch(10*time.Millisecond, stopCh)
+
+	writeTestFile(t, filepath.Dir(path), "htpasswd", "invalid")
+	time.Sleep(50 * time.Millisecond)
+	assert.True(t, credentials.Authenticate("admin", "password"), "invalid credentials should not replace the previous ones")
+
+	writeTestFile(t, filepath.Dir(path), "htpasswd", "admin:rotated\n")
+	assert.Eventually(t, func() bool {
+		return credentials.Authenticate("admin", "rotated")
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.False(t, credentials.Authenticate("admin", "password"))
+}
+
+func TestNewCredentials_Error(t *testing.T) {
+	dir := t.TempDir()
+
+	testCases := []struct {
+		Name          string
+		Path          string
+		ExpectedError string
+	}{
+		{
+			Name:          "Should return Error for a malformed line",
+			Path:          writeTestFile(t, dir, "malformed", "admin\n"),
+			ExpectedError: "line 1 must be in the format user:hash",
+		},
+		{
+			Name:          "Should return Error for an unsupported hash",
+			Path:          writeTestFile(t, dir, "md5", "admin:$apr1$salt$hash\n"),
+			ExpectedError: "the hash of the user admin isn't supported",
+		},
+		{
+			Name:          "Should return Error for a file without users",
+			Path:          writeTestFile(t, dir, "empty", "# no users\n"),
+			ExpectedError: "no users were found",
+		},
+		{
+			Name:          "Should return Error for a directory without the basic-auth Secret keys",
+			Path:          dir,
+			ExpectedError: corev1.BasicAuthUsernameKey,
+		},
+		{
+			Name:          "Should return Error for a missing path",
+			Path:          filepath.Join(dir, "missing"),
+			ExpectedError: "no such file or directory",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := NewCredentials(testCase.Path)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.ExpectedError)
+		})
+	}
+}
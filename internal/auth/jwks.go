@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"gopkg.in/square/go-jose.v2"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// KeySetRefreshPeriod is how often a remote JWKS is downloaded again to get the rotated keys
+const KeySetRefreshPeriod = 5 * time.Minute
+
+// keySetMinRefreshInterval limits the refreshes of a remote JWKS caused by tokens signed with unknown keys
+const keySetMinRefreshInterval = time.Minute
+
+var keySetHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// KeySet keeps the JSON Web Key Set verifying the tokens, loaded from a local file or from a URL
+type KeySet struct {
+	source      string
+	load        func() ([]byte, error)
+	remote      bool
+	mutex       sync.RWMutex
+	raw         []byte
+	keys        *jose.JSONWebKeySet
+	lastRefresh time.Time
+}
+
+// NewKeySetFromFile loads a JWKS from a local file, used for tests and setups without access to the issuer
+func NewKeySetFromFile(path string) (*KeySet, error) {
+	keySet := &KeySet{
+		source: path,
+		load: func() ([]byte, error) {
+			return os.ReadFile(path)
+		},
+	}
+	_, err := keySet.Refresh()
+	if err != nil {
+		return nil, err
+	}
+	return keySet, nil
+}
+
+// NewKeySetFromURL downloads a JWKS from a URL, it is downloaded again when a token is signed with an unknown key
+func NewKeySetFromURL(url string) (*KeySet, error) {
+	keySet := &KeySet{
+		source: url,
+		load: func() ([]byte, error) {
+			return httpGet(url)
+		},
+		remote: true,
+	}
+	_, err := keySet.Refresh()
+	if err != nil {
+		return nil, err
+	}
+	return keySet, nil
+}
+
+// Refresh loads the key set again, returning whether it has changed. On errors the previous keys are kept.
+func (s *KeySet) Refresh() (bool, error) {
+	raw, err := s.load()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.lastRefresh = time.Now()
+	if err != nil {
+		return false, fmt.Errorf("could not load the JWKS from %s: %v", s.source, err)
+	}
+	if s.keys != nil && bytes.Equal(raw, s.raw) {
+		return false, nil
+	}
+
+	keys := &jose.JSONWebKeySet{}
+	err = json.Unmarshal(raw, keys)
+	if err != nil {
+		return false, fmt.Errorf("invalid JWKS in %s: %v", s.source, err)
+	}
+	if len(keys.Keys) == 0 {
+		return false, fmt.Errorf("the JWKS in %s has no keys", s.source)
+	}
+
+	s.raw = raw
+	s.keys = keys
+	return true, nil
+}
+
+// Watch refreshes the key set every period until stopCh is closed
+func (s *KeySet) Watch(period time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			changed, err := s.Refresh()
+			if err != nil {
+				zap.L().Error("Could not refresh the JWKS, keeping the previous keys", zap.String("source", s.source), zap.Error(err))
+			} else if changed {
+				zap.L().Info("Reloaded the JWKS", zap.String("source", s.source))
+			}
+		}
+	}
+}
+
+// Keys returns the signing keys with the key ID, or every signing key when the ID is empty.
+// A remote key set is refreshed when the key ID isn't found, so keys rotated by the issuer are found before the next refresh.
+func (s *KeySet) Keys(keyID string) []jose.JSONWebKey {
+	keys := s.signingKeys(keyID)
+	if len(keys) == 0 && keyID != "" && s.remote && s.canRefresh() {
+		_, err := s.Refresh()
+		if err != nil {
+			zap.L().Warn("Could not refresh the JWKS looking for the key", zap.String("keyID", keyID), zap.Error(err))
+		}
+		keys = s.signingKeys(keyID)
+	}
+	return keys
+}
+
+func (s *KeySet) signingKeys(keyID string) []jose.JSONWebKey {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var keys []jose.JSONWebKey
+	for _, key := range s.keys.Keys {
+		if key.Use == "enc" || (keyID != "" && key.KeyID != keyID) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (s *KeySet) canRefresh() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return time.Since(s.lastRefresh) >= keySetMinRefreshInterval
+}
+
+// httpGet returns the body of a successful GET request
+func httpGet(url string) ([]byte, error) {
+	response, err := keySetHTTPClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned the status %s", url, response.Status)
+	}
+	return io.ReadAll(response.Body)
+}
@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Environment variables configuring the JWT authentication
+const (
+	// JWTIssuerEnv is the issuer the tokens must have in the iss claim, it is required
+	JWTIssuerEnv = "JWT_ISSUER"
+	// JWTAudienceEnv is the audience the tokens must have in the aud claim, like the OIDC client ID, it is required so the
+	// tokens the issuer signs for other applications are refused
+	JWTAudienceEnv = "JWT_AUDIENCE"
+	// JWTJWKSFileEnv is the path of a local JWKS verifying the tokens
+	JWTJWKSFileEnv = "JWT_JWKS_FILE"
+	// JWTJWKSURLEnv is the URL of the JWKS verifying the tokens, discovered from the OIDC configuration of the issuer when it and JWTJWKSFileEnv are empty
+	JWTJWKSURLEnv = "JWT_JWKS_URL"
+	// JWTGroupsClaimEnv is the claim with the groups of the subject, DefaultGroupsClaim by default
+	JWTGroupsClaimEnv = "JWT_GROUPS_CLAIM"
+)
+
+const DefaultGroupsClaim = "groups"
+
+// jwtLeeway is the clock skew tolerated validating the time claims
+const jwtLeeway = time.Minute
+
+// signatureAlgorithms are the accepted token signature algorithms, only asymmetric ones since the keys are public
+var signatureAlgorithms = map[string]bool{
+	string(jose.RS256): true, string(jose.RS384): true, string(jose.RS512): true,
+	string(jose.PS256): true, string(jose.PS384): true, string(jose.PS512): true,
+	string(jose.ES256): true, string(jose.ES384): true, string(jose.ES512): true,
+	string(jose.EdDSA): true,
+}
+
+// JWTAuthenticator authenticates the requests with bearer JWTs signed by the keys of an issuer, like OIDC ID tokens
+type JWTAuthenticator struct {
+	Issuer      string
+	Audience    string
+	GroupsClaim string
+	Keys        *KeySet
+}
+
+// NewJWTAuthenticatorFromEnv creates the JWT authenticator configured in the environment, its keys are reloaded in background until stopCh is closed
+func NewJWTAuthenticatorFromEnv(stopCh <-chan struct{}) (*JWTAuthenticator, error) {
+	issuer := os.Getenv(JWTIssuerEnv)
+	if issuer == "" {
+		return nil, fmt.Errorf("%s must be set to use the JWT authentication", JWTIssuerEnv)
+	}
+	audience := os.Getenv(JWTAudienceEnv)
+	if audience == "" {
+		return nil, fmt.Errorf("%s must be set to use the JWT authentication", JWTAudienceEnv)
+	}
+
+	groupsClaim := os.Getenv(JWTGroupsClaimEnv)
+	if groupsClaim == "" {
+		groupsClaim = DefaultGroupsClaim
+	}
+
+	var keys *KeySet
+	var err error
+	if path := os.Getenv(JWTJWKSFileEnv); path != "" {
+		keys, err = NewKeySetFromFile(path)
+		if err != nil {
+			return nil, err
+		}
+		go keys.Watch(CredentialsReloadPeriod, stopCh)
+	} else {
+		url := os.Getenv(JWTJWKSURLEnv)
+		if url == "" {
+			url, err = discoverJWKSURL(issuer)
+			if err != nil {
+				return nil, err
+			}
+		}
+		keys, err = NewKeySetFromURL(url)
+		if err != nil {
+			return nil, err
+		}
+		go keys.Watch(KeySetRefreshPeriod, stopCh)
+	}
+
+	return &JWTAuthenticator{
+		Issuer:      issuer,
+		Audience:    audience,
+		GroupsClaim: groupsClaim,
+		Keys:        keys,
+	}, nil
+}
+
+// discoverJWKSURL returns the JWKS URL of the OIDC configuration of an issuer
+func discoverJWKSURL(issuer string) (string, error) {
+	discoveryURL := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	raw, err := httpGet(discoveryURL)
+	if err != nil {
+		return "", fmt.Errorf("could not discover the JWKS of the issuer %s: %v", issuer, err)
+	}
+
+	var configuration struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	err = json.Unmarshal(raw, &configuration)
+	if err != nil || configuration.JWKSURI == "" {
+		return "", fmt.Errorf("the OIDC configuration of the issuer %s has no jwks_uri", issuer)
+	}
+	return configuration.JWKSURI, nil
+}
+
+func (a *JWTAuthenticator) Authenticate(request *http.Request) (*Identity, error) {
+	authorization := request.Header.Get("Authorization")
+	if len(authorization) < len("Bearer ") || !strings.EqualFold(authorization[:len("Bearer ")], "Bearer ") {
+		return nil, ErrNoCredentials
+	}
+	return a.Verify(strings.TrimSpace(authorization[len("Bearer "):]))
+}
+
+func (a *JWTAuthenticator) Challenge() string {
+	return `Bearer realm="kaas-management-api"`
+}
+
+// Verify validates the signature and the claims of a token, returning the identity of its subject
+func (a *JWTAuthenticator) Verify(rawToken string) (*Identity, error) {
+	token, err := jwt.ParseSigned(rawToken)
+	if err != nil {
+		return nil, fmt.Errorf("malformed token: %v", err)
+	}
+	if len(token.Headers) != 1 || !signatureAlgorithms[token.Headers[0].Algorithm] {
+		return nil, fmt.Errorf("the token signature algorithm isn't supported")
+	}
+
+	keyID := token.Headers[0].KeyID
+	var standardClaims jwt.Claims
+	var claims map[string]interface{}
+	verified := false
+	for _, key := range a.Keys.Keys(keyID) {
+		if token.Claims(key.Key, &standardClaims, &claims) == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, fmt.Errorf("the token signature could not be verified with the key %q", keyID)
+	}
+
+	// The audience is always checked, an authenticator without one refuses every token
+	expected := jwt.Expected{Issuer: a.Issuer, Audience: jwt.Audience{a.Audience}, Time: time.Now()}
+	err = standardClaims.ValidateWithLeeway(expected, jwtLeeway)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token claims: %v", err)
+	}
+	if standardClaims.Expiry == nil {
+		return nil, fmt.Errorf("the token has no expiration")
+	}
+	if standardClaims.Subject == "" {
+		return nil, fmt.Errorf("the token has no subject")
+	}
+
+	return &Identity{
+		Subject: standardClaims.Subject,
+		Groups:  claimStrings(claims[a.GroupsClaim]),
+		Claims:  claims,
+	}, nil
+}
+
+// claimStrings returns the strings of a claim with a string or a list of strings
+func claimStrings(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		var values []string
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
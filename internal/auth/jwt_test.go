@@ -0,0 +1,232 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/topfreegames/kaas-management-api/test"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+const testIssuer = "https://sso.example.com"
+
+// newTestJWTAuthenticator returns a JWT authenticator whose JWKS is loaded from a file with the public key of the returned private key
+func newTestJWTAuthenticator(t *testing.T) (*JWTAuthenticator, *rsa.PrivateKey) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: privateKey.Public(), KeyID: "test-key", Algorithm: string(jose.RS256), Use: "sig"},
+	}})
+	assert.Nil(t, err)
+
+	keys, err := NewKeySetFromFile(writeTestFile(t, t.TempDir(), "jwks.json", string(jwks)))
+	assert.Nil(t, err)
+
+	return &JWTAuthenticator{Issuer: testIssuer, Audience: "kaas-management-api", GroupsClaim: DefaultGroupsClaim, Keys: keys}, privateKey
+}
+
+// signTestToken returns a token with the claims signed by the key
+func signTestToken(t *testing.T, algorithm jose.SignatureAlgorithm, key interface{}, keyID string, claims map[string]interface{}) string {
+	options := (&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", keyID)
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: algorithm, Key: key}, options)
+	assert.Nil(t, err)
+
+	token, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+	assert.Nil(t, err)
+	return token
+}
+
+// validTestClaims returns the claims of a valid token, with the overrides applied and the nil overrides removed
+func validTestClaims(overrides map[string]interface{}) map[string]interface{} {
+	claims := map[string]interface{}{
+		"iss":    testIssuer,
+		"sub":    "user@example.com",
+		"aud":    []string{"kaas-management-api", "another-api"},
+		"exp":    time.Now().Add(time.Hour).Unix(),
+		"iat":    time.Now().Unix(),
+		"groups": []string{"platform", "games"},
+		"email":  "user@example.com",
+	}
+	for claim, value := range overrides {
+		if value == nil {
+			delete(claims, claim)
+		} else {
+			claims[claim] = value
+		}
+	}
+	return claims
+}
+
+func TestJWTAuthenticator_Verify(t *testing.T) {
+	authenticator, privateKey := newTestJWTAuthenticator(t)
+	anotherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+
+	testCases := []struct {
+		Name            string
+		Token           string
+		ExpectedSubject string
+		ExpectedGroups  []string
+		ExpectedError   string
+	}{
+		{
+			Name:            "Verify should return the identity of a valid token",
+			Token:           signTestToken(t, jose.RS256, privateKey, "test-key", validTestClaims(nil)),
+			ExpectedSubject: "user@example.com",
+			ExpectedGroups:  []string{"platform", "games"},
+		},
+		{
+			Name:            "Verify should accept a single group",
+			Token:           signTestToken(t, jose.RS256, privateKey, "test-key", validTestClaims(map[string]interface{}{"groups": "platform"})),
+			ExpectedSubject: "user@example.com",
+			ExpectedGroups:  []string{"platform"},
+		},
+		{
+			Name:          "Verify should return Error for a token of another issuer",
+			Token:         signTestToken(t, jose.RS256, privateKey, "test-key", validTestClaims(map[string]interface{}{"iss": "https://another.example.com"})),
+			ExpectedError: "invalid token claims",
+		},
+		{
+			Name:          "Verify should return Error for a token of another audience",
+			Token:         signTestToken(t, jose.RS256, privateKey, "test-key", validTestClaims(map[string]interface{}{"aud": "another-api"})),
+			ExpectedError: "invalid token claims",
+		},
+		{
+			Name:          "Verify should return Error for an expired token",
+			Token:         signTestToken(t, jose.RS256, privateKey, "test-key", validTestClaims(map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()})),
+			ExpectedError: "invalid token claims",
+		},
+		{
+			Name:          "Verify should return Error for a token without expiration",
+			Token:         signTestToken(t, jose.RS256, privateKey, "test-key", validTestClaims(map[string]interface{}{"exp": nil})),
+			ExpectedError: "the token has no expiration",
+		},
+		{
+			Name:          "Verify should return Error for a token without subject",
+			Token:         signTestToken(t, jose.RS256, privateKey, "test-key", validTestClaims(map[string]interface{}{"sub": nil})),
+			ExpectedError: "the token has no subject",
+		},
+		{
+			Name:          "Verify should return Error for a token signed by another key",
+			Token:         signTestToken(t, jose.RS256, anotherKey, "test-key", validTestClaims(nil)),
+			ExpectedError: "the token signature could not be verified",
+		},
+		{
+			Name:          "Verify should return Error for a token signed by an unknown key",
+			Token:         signTestToken(t, jose.RS256, anotherKey, "unknown-key", validTestClaims(nil)),
+			ExpectedError: "the token signature could not be verified",
+		},
+		{
+			Name:          "Verify should return Error for a token signed with a symmetric algorithm",
+			Token:         signTestToken(t, jose.HS256, []byte("a shared secret of at least 256 bits"), "test-key", validTestClaims(nil)),
+			ExpectedError: "the token signature algorithm isn't supported",
+		},
+		{
+			Name:          "Verify should return Error for a malformed token",
+			Token:         "not-a-token",
+			ExpectedError: "malformed token",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			identity, err := authenticator.Verify(testCase.Token)
+			if testCase.ExpectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), testCase.ExpectedError)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.ExpectedSubject, identity.Subject)
+			assert.Equal(t, testCase.ExpectedGroups, identity.Groups)
+			assert.Equal(t, "user@example.com", identity.Claims["email"])
+		})
+	}
+}
+
+func TestMiddleware_JWT(t *testing.T) {
+	authenticator, privateKey := newTestJWTAuthenticator(t)
+	credentials, err := NewCredentials(writeTestFile(t, t.TempDir(), "htpasswd", "admin:password\n"))
+	assert.Nil(t, err)
+
+	router := gin.New()
+	router.Use(Middleware([]Authenticator{&BasicAuthenticator{Credentials: credentials}, authenticator}, nil))
+	router.NoRoute(func(c *gin.Context) {
+		identity, _ := GetIdentity(c)
+		c.JSON(http.StatusOK, identity)
+	})
+
+	request := &test.HTTPTestRequest{
+		Method: http.MethodGet,
+		Path:   "/v1/clusters/",
+		Header: http.Header{"Authorization": []string{"Bearer " + signTestToken(t, jose.RS256, privateKey, "test-key", validTestClaims(nil))}},
+	}
+	w := request.RunHTTPTest(router)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var identity Identity
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &identity))
+	assert.Equal(t, "user@example.com", identity.Subject)
+	assert.Equal(t, []string{"platform", "games"}, identity.Groups)
+	assert.Equal(t, "user@example.com", identity.Claims["email"])
+
+	request.Header = http.Header{"Authorization": []string{"Bearer " + signTestToken(t, jose.RS256, privateKey, "test-key", validTestClaims(map[string]interface{}{"iss": "https://another.example.com"}))}}
+	w = request.RunHTTPTest(router)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, []string{`Basic realm="kaas-management-api"`, `Bearer realm="kaas-management-api"`}, w.Header().Values("WWW-Authenticate"))
+}
+
+func TestNewJWTAuthenticatorFromEnv_Discovery(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": server.URL, "jwks_uri": server.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: privateKey.Public(), KeyID: "test-key", Use: "sig"}}})
+	})
+
+	t.Setenv(JWTIssuerEnv, server.URL)
+	t.Setenv(JWTAudienceEnv, "kaas-management-api")
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	authenticator, err := NewJWTAuthenticatorFromEnv(stopCh)
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultGroupsClaim, authenticator.GroupsClaim)
+
+	token := signTestToken(t, jose.RS256, privateKey, "test-key", validTestClaims(map[string]interface{}{"iss": server.URL}))
+	identity, err := authenticator.Verify(token)
+	assert.Nil(t, err)
+	assert.Equal(t, "user@example.com", identity.Subject)
+
+	t.Setenv(JWTAudienceEnv, "")
+	_, err = NewJWTAuthenticatorFromEnv(stopCh)
+	assert.EqualError(t, err, "JWT_AUDIENCE must be set to use the JWT authentication")
+
+	t.Setenv(JWTIssuerEnv, "")
+	_, err = NewJWTAuthenticatorFromEnv(stopCh)
+	assert.Error(t, err)
+}
+
+func TestJWTAuthenticator_VerifyWithoutAudience(t *testing.T) {
+	authenticator, privateKey := newTestJWTAuthenticator(t)
+	authenticator.Audience = ""
+
+	_, err := authenticator.Verify(signTestToken(t, jose.RS256, privateKey, "test-key", validTestClaims(nil)))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid token claims")
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/topfreegames/kaas-management-api/internal/logging"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"go.uber.org/zap"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ExemptPathsEnv is the environment variable with the comma separated paths served without authentication, DefaultExemptPaths by default
+const ExemptPathsEnv = "AUTH_EXEMPT_PATHS"
+
+// DefaultExemptPaths are the health checks used by the probes and the API docs. The metrics have the fleet inventory,
+// so Prometheus scrapes them with credentials.
+const DefaultExemptPaths = "/healthcheck,/readiness,/docs"
+
+// GetExemptPaths returns the paths served without authentication from the environment
+func GetExemptPaths() []string {
+	paths := os.Getenv(ExemptPathsEnv)
+	if paths == "" {
+		paths = DefaultExemptPaths
+	}
+
+	var exemptPaths []string
+	for _, path := range strings.Split(paths, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			exemptPaths = append(exemptPaths, strings.TrimSuffix(path, "/"))
+		}
+	}
+	return exemptPaths
+}
+
+// isExempt returns true if the path is one of the exempt paths or is below one of them
+func isExempt(path string, exemptPaths []string) bool {
+	for _, exemptPath := range exemptPaths {
+		if path == exemptPath || strings.HasPrefix(path, exemptPath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware returns a middleware refusing the requests to paths that aren't exempt without valid credentials for one of the authenticators,
+// the first authenticator finding credentials in the request decides. The identity is set in the context to be read with GetIdentity.
+func Middleware(authenticators []Authenticator, exemptPaths []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isExempt(c.Request.URL.Path, exemptPaths) {
+			c.Next()
+			return
+		}
+
+		for _, authenticator := range authenticators {
+			identity, err := authenticator.Authenticate(c.Request)
+			if err == ErrNoCredentials {
+				continue
+			}
+			if err != nil {
+				logging.FromContext(c.Request.Context()).Warn("Refusing request", zap.String("handler", "Auth"), zap.String("path", c.Request.URL.Path), zap.Error(err))
+				break
+			}
+
+			c.Set(SubjectContextKey, identity.Subject)
+			c.Set(GroupsContextKey, identity.Groups)
+			if identity.Claims != nil {
+				c.Set(ClaimsContextKey, identity.Claims)
+			}
+			c.Next()
+			return
+		}
+
+		for _, authenticator := range authenticators {
+			c.Writer.Header().Add("WWW-Authenticate", authenticator.Challenge())
+		}
+		err := clientError.NewClientError(nil, clientError.Unauthorized, "Invalid or missing credentials")
+		clientError.ErrorHandler(c, err, "Unauthorized", http.StatusUnauthorized)
+		c.Abort()
+	}
+}
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	apiError "github.com/topfreegames/kaas-management-api/api/error"
+	"github.com/topfreegames/kaas-management-api/test"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+)
+
+func basicAuthHeader(user string, password string) http.Header {
+	request, _ := http.NewRequest(http.MethodGet, "/", nil)
+	request.SetBasicAuth(user, password)
+	return request.Header
+}
+
+func TestMiddleware_BasicAuth(t *testing.T) {
+	unauthorized := test.HTTPTestExpectedResponse{
+		ExpectedBody: apiError.ClientErrorResponse{
+			ErrorMessage: "Unauthorized",
+			ErrorType:    clientError.Unauthorized,
+			HttpCode:     http.StatusUnauthorized,
+		},
+		ExpectedCode: http.StatusUnauthorized,
+	}
+
+	testCases := []test.TestCase{
+		{
+			Name: "Middleware should authorize valid credentials",
+			ExpectedSuccess: test.HTTPTestExpectedResponse{
+				ExpectedBody: gin.H{"user": "admin"},
+				ExpectedCode: http.StatusOK,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodGet,
+				Path:   "/v1/clusters/",
+				Header: basicAuthHeader("admin", "password"),
+			},
+		},
+		{
+			Name:            "Middleware should refuse a wrong password",
+			ExpectedSuccess: unauthorized,
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodGet,
+				Path:   "/v1/clusters/",
+				Header: basicAuthHeader("admin", "wrong"),
+			},
+		},
+		{
+			Name:            "Middleware should refuse requests without credentials",
+			ExpectedSuccess: unauthorized,
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodGet,
+				Path:   "/v1/clusters/",
+			},
+		},
+		{
+			Name: "Middleware should not authenticate exempt paths",
+			ExpectedSuccess: test.HTTPTestExpectedResponse{
+				ExpectedBody: gin.H{"user": ""},
+				ExpectedCode: http.StatusOK,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodGet,
+				Path:   "/docs/swagger/index.html",
+			},
+		},
+		{
+			Name:            "Middleware should not exempt paths only starting like an exempt path",
+			ExpectedSuccess: unauthorized,
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodGet,
+				Path:   "/healthcheck-admin",
+			},
+		},
+	}
+
+	path := writeTestFile(t, t.TempDir(), "htpasswd", "admin:password\n")
+	credentials, err := NewCredentials(path)
+	assert.Nil(t, err)
+
+	router := gin.New()
+	router.Use(Middleware([]Authenticator{&BasicAuthenticator{Credentials: credentials}}, []string{"/healthcheck", "/docs"}))
+	router.NoRoute(func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user": c.GetString(SubjectContextKey)})
+	})
+
+	for _, testCase := range testCases {
+		request := testCase.GetHTTPRequest()
+		expectedResponse, ok := testCase.ExpectedSuccess.(test.HTTPTestExpectedResponse)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to *test.HTTPTestExpectedResponse", testCase.Name)
+		}
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			w := request.RunHTTPTest(router)
+
+			assert.Equal(t, expectedResponse.ExpectedCode, w.Code)
+			expected, err := json.Marshal(expectedResponse.ExpectedBody)
+			assert.Nil(t, err)
+			assert.Equal(t, string(expected), w.Body.String())
+			if expectedResponse.ExpectedCode == http.StatusUnauthorized {
+				assert.Equal(t, `Basic realm="kaas-management-api"`, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestGetExemptPaths(t *testing.T) {
+	assert.Equal(t, []string{"/healthcheck", "/readiness", "/docs"}, GetExemptPaths())
+
+	t.Setenv(ExemptPathsEnv, "/healthcheck/, /metrics,,")
+	assert.Equal(t, []string{"/healthcheck", "/metrics"}, GetExemptPaths())
+}
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"fmt"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"go.uber.org/zap"
+	"net/http"
+	"sigs.k8s.io/yaml"
+)
+
+// PoliciesConfigKey is the key of the configuration ConfigMap with the authorization policies in YAML
+const PoliciesConfigKey = "policies.yaml"
+
+// Actions on the clusters and their node groups authorized by the policies
+const (
+	ActionRead       = "read"
+	ActionKubeconfig = "kubeconfig"
+	ActionCreate     = "create"
+	ActionUpdate     = "update"
+	ActionDelete     = "delete"
+	ActionAll        = "*"
+	// ActionAudit reads the audit records of every cluster, so it's only allowed by policies without cluster groups and environments
+	ActionAudit = "audit"
+)
+
+var policyActions = map[string]bool{
+	ActionRead:       true,
+	ActionKubeconfig: true,
+	ActionCreate:     true,
+	ActionUpdate:     true,
+	ActionDelete:     true,
+	ActionAll:        true,
+	ActionAudit:      true,
+}
+
+// denyAll are the policies used when the configured ones are invalid, so a broken configuration never grants access
+var denyAll = &Policies{DeniedReadStatus: http.StatusNotFound}
+
+// Policy allows its subjects and the members of its groups to do the actions on the clusters of its cluster groups and environments.
+// Empty cluster groups or environments match any cluster.
+type Policy struct {
+	Name          string   `json:"name"`
+	Subjects      []string `json:"subjects,omitempty"`
+	Groups        []string `json:"groups,omitempty"`
+	Actions       []string `json:"actions"`
+	ClusterGroups []string `json:"clusterGroups,omitempty"`
+	Environments  []string `json:"environments,omitempty"`
+}
+
+// Policies authorize the actions of the callers, anything that isn't allowed by a policy is denied
+type Policies struct {
+	// DeniedReadStatus is the status returned when a caller tries to use a cluster it can't read, 404 hides that the cluster exists
+	DeniedReadStatus int      `json:"deniedReadStatus,omitempty"`
+	Policies         []Policy `json:"policies"`
+}
+
+// GetPolicies returns the policies of the configuration ConfigMap, parsed again only when they change so their changes apply without a restart.
+// It returns nil when no policies are configured and authorization is disabled, and policies denying everything when they are invalid.
+func GetPolicies(k *k8s.Kubernetes) *Policies {
+	return k.GetParsedConfig(PoliciesConfigKey, parseConfiguredPolicies).(*Policies)
+}
+
+// parseConfiguredPolicies parses the policies of the configuration ConfigMap
+func parseConfiguredPolicies(rawPolicies string, _ interface{}) interface{} {
+	if rawPolicies == "" {
+		return (*Policies)(nil)
+	}
+
+	policies, err := ParsePolicies([]byte(rawPolicies))
+	if err != nil {
+		zap.L().Error("Denying every request, the configured authorization policies are invalid", zap.Error(err))
+		return denyAll
+	}
+	zap.L().Info("Loaded the authorization policies", zap.Int("policies", len(policies.Policies)))
+	return policies
+}
+
+// ParsePolicies reads and validates the authorization policies in YAML
+func ParsePolicies(rawPolicies []byte) (*Policies, error) {
+	policies := &Policies{}
+	err := yaml.UnmarshalStrict(rawPolicies, policies)
+	if err != nil {
+		return nil, fmt.Errorf("could not Unmarshal the authorization policies: %v", err)
+	}
+
+	if policies.DeniedReadStatus == 0 {
+		policies.DeniedReadStatus = http.StatusNotFound
+	}
+	if policies.DeniedReadStatus != http.StatusNotFound && policies.DeniedReadStatus != http.StatusForbidden {
+		return nil, fmt.Errorf("deniedReadStatus must be %d or %d", http.StatusForbidden, http.StatusNotFound)
+	}
+
+	for _, policy := range policies.Policies {
+		if len(policy.Subjects) == 0 && len(policy.Groups) == 0 {
+			return nil, fmt.Errorf("the policy %s must have subjects or groups", policy.Name)
+		}
+		if len(policy.Actions) == 0 {
+			return nil, fmt.Errorf("the policy %s must have actions", policy.Name)
+		}
+		for _, action := range policy.Actions {
+			if !policyActions[action] {
+				return nil, fmt.Errorf("the action %s of the policy %s isn't supported", action, policy.Name)
+			}
+		}
+	}
+	return policies, nil
+}
+
+// Allowed returns true if a policy allows the identity to do the action on the clusters of the cluster group and environment.
+// Every action is allowed when the policies are nil.
+func (p *Policies) Allowed(identity *Identity, action string, clusterGroup string, environment string) bool {
+	if p == nil {
+		return true
+	}
+	if identity == nil {
+		return false
+	}
+
+	for _, policy := range p.Policies {
+		if policy.appliesTo(identity) &&
+			(contains(policy.Actions, action) || contains(policy.Actions, ActionAll)) &&
+			(len(policy.ClusterGroups) == 0 || contains(policy.ClusterGroups, clusterGroup)) &&
+			(len(policy.Environments) == 0 || contains(policy.Environments, environment)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) appliesTo(identity *Identity) bool {
+	if contains(p.Subjects, identity.Subject) {
+		return true
+	}
+	for _, group := range identity.Groups {
+		if contains(p.Groups, group) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPolicies = `
+deniedReadStatus: 403
+policies:
+  - name: team-x-read-games
+    groups: ["team-x"]
+    actions: ["read"]
+    clusterGroups: ["games"]
+  - name: team-x-scale-staging
+    groups: ["team-x"]
+    actions: ["update"]
+    clusterGroups: ["games"]
+    environments: ["staging"]
+  - name: admin
+    subjects: ["admin"]
+    actions: ["*"]
+`
+
+func TestPolicies_Allowed(t *testing.T) {
+	policies, err := ParsePolicies([]byte(testPolicies))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusForbidden, policies.DeniedReadStatus)
+
+	teamX := &Identity{Subject: "user@example.com", Groups: []string{"team-y", "team-x"}}
+	testCases := []struct {
+		Name         string
+		Policies     *Policies
+		Identity     *Identity
+		Action       string
+		ClusterGroup string
+		Environment  string
+		Expected     bool
+	}{
+		{Name: "Should allow reading a cluster of the cluster group of a group", Policies: policies, Identity: teamX, Action: ActionRead, ClusterGroup: "games", Environment: "production", Expected: true},
+		{Name: "Should allow updating a cluster of the environment of a group", Policies: policies, Identity: teamX, Action: ActionUpdate, ClusterGroup: "games", Environment: "staging", Expected: true},
+		{Name: "Should deny updating a cluster of another environment", Policies: policies, Identity: teamX, Action: ActionUpdate, ClusterGroup: "games", Environment: "production", Expected: false},
+		{Name: "Should deny reading a cluster of another cluster group", Policies: policies, Identity: teamX, Action: ActionRead, ClusterGroup: "platform", Environment: "staging", Expected: false},
+		{Name: "Should deny actions that aren't in any policy", Policies: policies, Identity: teamX, Action: ActionDelete, ClusterGroup: "games", Environment: "staging", Expected: false},
+		{Name: "Should allow every action to a subject with all actions", Policies: policies, Identity: &Identity{Subject: "admin"}, Action: ActionKubeconfig, ClusterGroup: "platform", Environment: "production", Expected: true},
+		{Name: "Should deny identities without policies", Policies: policies, Identity: &Identity{Subject: "someone", Groups: []string{"team-y"}}, Action: ActionRead, ClusterGroup: "games", Environment: "staging", Expected: false},
+		{Name: "Should deny anonymous callers", Policies: policies, Identity: nil, Action: ActionRead, ClusterGroup: "games", Environment: "staging", Expected: false},
+		{Name: "Should allow everything without policies", Policies: nil, Identity: nil, Action: ActionDelete, ClusterGroup: "games", Environment: "production", Expected: true},
+		{Name: "Should deny everything with the policies of an invalid configuration", Policies: denyAll, Identity: &Identity{Subject: "admin"}, Action: ActionRead, ClusterGroup: "games", Environment: "production", Expected: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			assert.Equal(t, testCase.Expected, testCase.Policies.Allowed(testCase.Identity, testCase.Action, testCase.ClusterGroup, testCase.Environment))
+		})
+	}
+}
+
+func TestParsePolicies_Error(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Policies      string
+		ExpectedError string
+	}{
+		{
+			Name:          "Should return Error for an unknown field",
+			Policies:      "policies:\n  - name: p\n    groups: [a]\n    actions: [read]\n    clusters: [a]\n",
+			ExpectedError: "could not Unmarshal the authorization policies",
+		},
+		{
+			Name:          "Should return Error for an invalid denied read status",
+			Policies:      "deniedReadStatus: 401\n",
+			ExpectedError: "deniedReadStatus must be 403 or 404",
+		},
+		{
+			Name:          "Should return Error for a policy without subjects and groups",
+			Policies:      "policies:\n  - name: p\n    actions: [read]\n",
+			ExpectedError: "the policy p must have subjects or groups",
+		},
+		{
+			Name:          "Should return Error for a policy without actions",
+			Policies:      "policies:\n  - name: p\n    groups: [a]\n",
+			ExpectedError: "the policy p must have actions",
+		},
+		{
+			Name:          "Should return Error for an unknown action",
+			Policies:      "policies:\n  - name: p\n    groups: [a]\n    actions: [scale]\n",
+			ExpectedError: "the action scale of the policy p isn't supported",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := ParsePolicies([]byte(testCase.Policies))
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.ExpectedError)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "github.com/topfreegames/kaas-management-api/api/audit/v1"
+	"github.com/topfreegames/kaas-management-api/internal/audit"
+	"github.com/topfreegames/kaas-management-api/internal/auth"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+// AuditHandler godoc
+// @Summary      List audit records
+// @Description  List the audit records of the write requests from the oldest to the newest, filtered by cluster and time range. Requires the audit action when authorization policies are configured
+// @Tags         Audit
+// @Produce      json
+// @Param        cluster  query     string  false  "Cluster Name"
+// @Param        since    query     string  false  "Start of the time range in RFC 3339"
+// @Param        until    query     string  false  "End of the time range in RFC 3339"
+// @Success      200  {object}  v1.AuditRecordList
+// @Failure      400  {object}  error.ClientErrorResponse
+// @Failure      403  {object}  error.ClientErrorResponse
+// @Failure      500  {object}  error.ClientErrorResponse
+// @Failure      501  {object}  error.ClientErrorResponse
+// @Router       /v1/audit/ [get]
+// @Security BasicAuth
+// @Security BearerAuth
+func (controller ControllerConfig) AuditHandler(c *gin.Context) {
+	identity, _ := auth.GetIdentity(c)
+	if !auth.GetPolicies(controller.K8sInstance).Allowed(identity, auth.ActionAudit, "", "") {
+		controller.log(c).Warn("Action denied", zap.String("handler", "Authorization"), zap.String("action", auth.ActionAudit), zap.String("subject", subjectOf(identity)))
+		err := clientError.NewClientError(nil, clientError.Forbidden, "Reading the audit records isn't allowed")
+		clientError.ErrorHandler(c, err, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	filter := audit.Filter{Cluster: c.Query(v1.ClusterQueryParameter)}
+	for parameter, value := range map[string]*time.Time{v1.SinceQueryParameter: &filter.Since, v1.UntilQueryParameter: &filter.Until} {
+		if rawValue := c.Query(parameter); rawValue != "" {
+			parsedValue, err := time.Parse(time.RFC3339, rawValue)
+			if err != nil {
+				controller.logError(c, "AuditHandler", "Invalid query parameter", err, zap.String("parameter", parameter))
+				err = clientError.NewClientError(err, clientError.InvalidRequest, "The "+parameter+" query parameter must be a time in RFC 3339")
+				clientError.ErrorHandler(c, err, "The "+parameter+" query parameter must be a time in RFC 3339", http.StatusBadRequest)
+				return
+			}
+			*value = parsedValue
+		}
+	}
+
+	if controller.AuditSink == nil {
+		err := clientError.NewClientError(audit.ErrQueryNotSupported, clientError.InvalidConfiguration, "The audit records can't be queried")
+		clientError.ErrorHandler(c, err, "The audit records can't be queried", http.StatusNotImplemented)
+		return
+	}
+	records, err := controller.AuditSink.Query(filter)
+	if err == audit.ErrQueryNotSupported {
+		err = clientError.NewClientError(err, clientError.InvalidConfiguration, "The configured audit sink can't be queried")
+		clientError.ErrorHandler(c, err, "The configured audit sink can't be queried", http.StatusNotImplemented)
+		return
+	}
+	if err != nil {
+		controller.logError(c, "AuditHandler", "Error querying the audit records", err)
+		clientError.ErrorHandler(c, err, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	recordList := v1.AuditRecordList{Items: []v1.AuditRecord{}}
+	for _, record := range records {
+		recordList.Items = append(recordList.Items, v1.AuditRecord{
+			Time:                record.Time,
+			Subject:             record.Subject,
+			Groups:              record.Groups,
+			Method:              record.Method,
+			Route:               record.Route,
+			Path:                record.Path,
+			Cluster:             record.Cluster,
+			NodeGroup:           record.NodeGroup,
+			BodyHash:            record.BodyHash,
+			Status:              record.Status,
+			Outcome:             record.Outcome,
+			LatencyMilliseconds: record.LatencyMilliseconds,
+		})
+	}
+	c.JSON(http.StatusOK, recordList)
+}
@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"encoding/json"
+	apiError "github.com/topfreegames/kaas-management-api/api/error"
+	"github.com/topfreegames/kaas-management-api/internal/audit"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	auditv1 "github.com/topfreegames/kaas-management-api/api/audit/v1"
+	"github.com/topfreegames/kaas-management-api/test"
+)
+
+func Test_AuditHandler(t *testing.T) {
+	fileSink, err := audit.NewFileSink(filepath.Join(t.TempDir(), "audit.log"))
+	assert.Nil(t, err)
+	start := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	assert.Nil(t, fileSink.Write(&audit.Record{Time: start, Subject: "admin", Method: http.MethodPost, Route: "/v1/clusters/", Path: "/v1/clusters/", Cluster: "games", Status: http.StatusAccepted, Outcome: audit.OutcomeSuccess}))
+	assert.Nil(t, fileSink.Write(&audit.Record{Time: start.Add(time.Hour), Subject: "admin", Method: http.MethodDelete, Route: "/v1/clusters/:clusterName/", Path: "/v1/clusters/platform/", Cluster: "platform", Status: http.StatusAccepted, Outcome: audit.OutcomeSuccess}))
+
+	testCases := []struct {
+		test.TestCase
+		Sink audit.Sink
+	}{
+		{
+			TestCase: test.TestCase{
+				Name: "Should return the records of the cluster",
+				ExpectedSuccess: test.HTTPTestExpectedResponse{
+					ExpectedBody: auditv1.AuditRecordList{Items: []auditv1.AuditRecord{
+						{Time: start, Subject: "admin", Method: http.MethodPost, Route: "/v1/clusters/", Path: "/v1/clusters/", Cluster: "games", Status: http.StatusAccepted, Outcome: audit.OutcomeSuccess},
+					}},
+					ExpectedCode: http.StatusOK,
+				},
+				Request: &test.HTTPTestRequest{Method: http.MethodGet, Path: auditv1.Endpoint.Path + "?cluster=games"},
+			},
+			Sink: fileSink,
+		},
+		{
+			TestCase: test.TestCase{
+				Name: "Should return no records outside of the time range",
+				ExpectedSuccess: test.HTTPTestExpectedResponse{
+					ExpectedBody: auditv1.AuditRecordList{Items: []auditv1.AuditRecord{}},
+					ExpectedCode: http.StatusOK,
+				},
+				Request: &test.HTTPTestRequest{Method: http.MethodGet, Path: auditv1.Endpoint.Path + "?since=2022-03-02T00:00:00Z"},
+			},
+			Sink: fileSink,
+		},
+		{
+			TestCase: test.TestCase{
+				Name: "Should return Error for an invalid time",
+				ExpectedHTTPError: &apiError.ClientErrorResponse{
+					ErrorMessage: "The until query parameter must be a time in RFC 3339",
+					ErrorType:    clientError.InvalidRequest,
+					HttpCode:     http.StatusBadRequest,
+				},
+				Request: &test.HTTPTestRequest{Method: http.MethodGet, Path: auditv1.Endpoint.Path + "?until=yesterday"},
+			},
+			Sink: fileSink,
+		},
+		{
+			TestCase: test.TestCase{
+				Name: "Should return Error for a sink that can't be queried",
+				ExpectedHTTPError: &apiError.ClientErrorResponse{
+					ErrorMessage: "The configured audit sink can't be queried",
+					ErrorType:    clientError.InvalidConfiguration,
+					HttpCode:     http.StatusNotImplemented,
+				},
+				Request: &test.HTTPTestRequest{Method: http.MethodGet, Path: auditv1.Endpoint.Path},
+			},
+			Sink: &audit.WriterSink{Writer: ioutil.Discard},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			controller := ConfigureControllers(&k8s.Kubernetes{})
+			controller.AuditSink = testCase.Sink
+			router := gin.New()
+			router.Handle(http.MethodGet, auditv1.Endpoint.Path, controller.AuditHandler)
+			w := testCase.GetHTTPRequest().RunHTTPTest(router)
+
+			if testCase.ExpectedHTTPError != nil {
+				assert.Equal(t, testCase.ExpectedHTTPError.HttpCode, w.Code)
+				expected, err := json.Marshal(testCase.ExpectedHTTPError)
+				assert.Nil(t, err)
+				assert.Equal(t, string(expected), w.Body.String())
+				return
+			}
+			expectedResponse := testCase.ExpectedSuccess.(test.HTTPTestExpectedResponse)
+			assert.Equal(t, expectedResponse.ExpectedCode, w.Code)
+			expected, err := json.Marshal(expectedResponse.ExpectedBody)
+			assert.Nil(t, err)
+			assert.Equal(t, string(expected), w.Body.String())
+		})
+	}
+}
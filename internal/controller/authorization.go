@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/topfreegames/kaas-management-api/internal/auth"
+	"github.com/topfreegames/kaas-management-api/internal/kaas"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// authorizeCluster returns true if the caller can do the action on the cluster, otherwise it writes the error response and returns false.
+// Callers that can't read the cluster get the denied read status of the policies, so a 404 doesn't reveal that the cluster exists.
+func (controller ControllerConfig) authorizeCluster(c *gin.Context, action string, cluster *kaas.Cluster) bool {
+	policies := auth.GetPolicies(controller.K8sInstance)
+	identity, _ := auth.GetIdentity(c)
+	if policies.Allowed(identity, action, cluster.ClusterGroup, cluster.Environment) {
+		return true
+	}
+
+	controller.log(c).Warn("Action denied", zap.String("handler", "Authorization"), zap.String("action", action), zap.String("subject", subjectOf(identity)), zap.String("cluster", cluster.Name))
+	if action != auth.ActionCreate && !policies.Allowed(identity, auth.ActionRead, cluster.ClusterGroup, cluster.Environment) && policies.DeniedReadStatus == http.StatusNotFound {
+		err := clientError.NewClientError(nil, clientError.ResourceNotFound, fmt.Sprintf("The requested cluster %s was not found!", cluster.Name))
+		clientError.ErrorHandler(c, err, "Cluster not found", http.StatusNotFound)
+		return false
+	}
+
+	err := clientError.NewClientError(nil, clientError.Forbidden, fmt.Sprintf("%s isn't allowed on the cluster %s", action, cluster.Name))
+	clientError.ErrorHandler(c, err, "Forbidden", http.StatusForbidden)
+	return false
+}
+
+// authorizeClusterName authorizes an action on a cluster that the handler doesn't read. When the cluster doesn't exist the request
+// is authorized, so the handler returns the same not found error it would return without authorization.
+func (controller ControllerConfig) authorizeClusterName(c *gin.Context, action string, clusterName string) bool {
+	if auth.GetPolicies(controller.K8sInstance) == nil {
+		return true
+	}
+
+	cluster, err := kaas.GetCluster(controller.k8sInstance(c), clusterName)
+	if err != nil {
+		clientErr, ok := err.(*clientError.ClientError)
+		if ok && clientErr.ErrorMessage == clientError.ResourceNotFound {
+			return true
+		}
+		controller.logError(c, "Authorization", "Error getting Cluster", err, zap.String("cluster", clusterName))
+		clientError.ErrorHandler(c, err, "Internal Server Error", http.StatusInternalServerError)
+		return false
+	}
+	return controller.authorizeCluster(c, action, cluster)
+}
+
+// readableClusters returns the clusters the caller can read
+func (controller ControllerConfig) readableClusters(c *gin.Context, clusters []*kaas.Cluster) []*kaas.Cluster {
+	policies := auth.GetPolicies(controller.K8sInstance)
+	if policies == nil {
+		return clusters
+	}
+
+	identity, _ := auth.GetIdentity(c)
+	var readable []*kaas.Cluster
+	for _, cluster := range clusters {
+		if policies.Allowed(identity, auth.ActionRead, cluster.ClusterGroup, cluster.Environment) {
+			readable = append(readable, cluster)
+		}
+	}
+	return readable
+}
+
+// readableEvent returns true if the caller can read the cluster of the event, the node group events are authorized
+// with the environment of the node group when its cluster was already deleted
+func (controller ControllerConfig) readableEvent(c *gin.Context, event *kaas.Event) bool {
+	policies := auth.GetPolicies(controller.K8sInstance)
+	if policies == nil {
+		return true
+	}
+
+	identity, _ := auth.GetIdentity(c)
+	cluster := event.Cluster
+	if cluster == nil {
+		var err error
+		cluster, err = kaas.GetCluster(controller.k8sInstance(c), event.NodeGroup.Cluster)
+		if err != nil {
+			cluster = &kaas.Cluster{Name: event.NodeGroup.Cluster, Environment: event.NodeGroup.Environment}
+		}
+	}
+	return policies.Allowed(identity, auth.ActionRead, cluster.ClusterGroup, cluster.Environment)
+}
+
+func subjectOf(identity *auth.Identity) string {
+	if identity == nil {
+		return "an anonymous caller"
+	}
+	return identity.Subject
+}
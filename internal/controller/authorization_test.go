@@ -0,0 +1,221 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	apiError "github.com/topfreegames/kaas-management-api/api/error"
+	"github.com/topfreegames/kaas-management-api/internal/auth"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"k8s.io/apimachinery/pkg/runtime"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	clusterv1 "github.com/topfreegames/kaas-management-api/api/cluster/v1"
+	"github.com/topfreegames/kaas-management-api/test"
+)
+
+const testTeamPolicies = `
+deniedReadStatus: %d
+policies:
+  - name: team-x-read-games
+    groups: ["team-x"]
+    actions: ["read"]
+    clusterGroups: ["games"]
+  - name: team-x-scale-staging
+    groups: ["team-x"]
+    actions: ["update"]
+    clusterGroups: ["games"]
+    environments: ["staging"]
+  - name: team-x-kubeconfig-staging
+    groups: ["team-x"]
+    actions: ["kubeconfig"]
+    clusterGroups: ["games"]
+    environments: ["staging"]
+`
+
+// newTestAuthorizedRouter returns a router with the cluster routes for a caller of the group team-x,
+// with the team policies in the configuration ConfigMap
+func newTestAuthorizedRouter(t *testing.T, policies string) *gin.Engine {
+	controller := ConfigureControllers(&k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClientWithResources(
+				newTestTeamCluster("games-staging", "games", "staging"),
+				newTestTeamCluster("games-production", "games", "production"),
+				newTestTeamCluster("platform-production", "platform", "production"),
+			),
+			Clientset: test.NewK8sFakeClientset(
+				test.NewTestKubeconfigSecret("games-staging", test.NewTestKubeconfig("games-staging", "https://10.0.0.1:6443")),
+				test.NewTestKubeconfigSecret("games-production", test.NewTestKubeconfig("games-production", "https://10.0.0.2:6443")),
+			),
+		},
+		Config: newTestPoliciesConfig(t, policies),
+	})
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(auth.SubjectContextKey, "user@example.com")
+		c.Set(auth.GroupsContextKey, []string{"team-x"})
+	})
+	router.Handle(http.MethodGet, clusterv1.Endpoint.Path, controller.ClusterListHandler)
+	router.Handle(http.MethodPost, clusterv1.Endpoint.Path, controller.ClusterCreateHandler)
+	router.Handle(http.MethodGet, clusterv1.Endpoint.Path+test.Param(clusterv1.ClusterNameParameter), controller.ClusterHandler)
+	router.Handle(http.MethodDelete, clusterv1.Endpoint.Path+test.Param(clusterv1.ClusterNameParameter), controller.ClusterDeleteHandler)
+	router.Handle(http.MethodPost, clusterv1.Endpoint.Path+test.Param(clusterv1.ClusterNameParameter)+test.Path(clusterv1.UpgradeEndpointName), controller.ClusterUpgradeHandler)
+	router.Handle(http.MethodGet, clusterv1.Endpoint.Path+test.Param(clusterv1.ClusterNameParameter)+test.Path(clusterv1.KubeconfigEndpointName), controller.ClusterKubeconfigHandler)
+	return router
+}
+
+// newTestPoliciesConfig returns a synced configuration watcher with the policies in the configuration ConfigMap
+func newTestPoliciesConfig(t *testing.T, policies string) *k8s.ConfigWatcher {
+	stopCh := make(chan struct{})
+	t.Cleanup(func() { close(stopCh) })
+
+	clientset := test.NewK8sFakeClientset(test.NewTestConfigMap(k8s.DefaultConfigNamespace, k8s.DefaultConfigName, map[string]string{auth.PoliciesConfigKey: policies}))
+	config := k8s.NewConfigWatcher(clientset, k8s.DefaultConfigNamespace, k8s.DefaultConfigName, time.Minute)
+	config.Start(stopCh)
+	if !config.WaitForCacheSync(stopCh) {
+		t.Fatal("Configuration didn't sync")
+	}
+	return config
+}
+
+func newTestTeamCluster(name string, clusterGroup string, environment string) runtime.Object {
+	cluster := test.NewTestCluster(name, name+"-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", name+"-kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1")
+	cluster.Labels["clusterGroup"] = clusterGroup
+	cluster.Labels["environment"] = environment
+	return cluster
+}
+
+func Test_ClusterListHandler_Authorization(t *testing.T) {
+	router := newTestAuthorizedRouter(t, fmt.Sprintf(testTeamPolicies, http.StatusNotFound))
+
+	request := &test.HTTPTestRequest{Method: http.MethodGet, Path: clusterv1.Endpoint.Path}
+	w := request.RunHTTPTest(router)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var clusterList clusterv1.ClusterList
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &clusterList))
+	var names []string
+	for _, cluster := range clusterList.Items {
+		names = append(names, cluster.Name)
+	}
+	assert.ElementsMatch(t, []string{"games-staging", "games-production"}, names)
+}
+
+func Test_ClusterHandlers_Authorization(t *testing.T) {
+	upgradeBody, err := json.Marshal(clusterv1.ClusterUpgradeRequest{Version: "1.22.0"})
+	assert.Nil(t, err)
+	createBody, err := json.Marshal(clusterv1.ClusterCreateRequest{
+		Name:                   "platform-staging",
+		KubernetesVersion:      "1.22.0",
+		KubeProvider:           "kops",
+		InfrastructureProvider: "aws",
+		ClusterGroup:           "platform",
+		Region:                 "us-east-1",
+		Environment:            "staging",
+		Zones:                  []string{"us-east-1a"},
+	})
+	assert.Nil(t, err)
+
+	notFound := &apiError.ClientErrorResponse{
+		ErrorMessage: "Cluster not found",
+		ErrorType:    clientError.ResourceNotFound,
+		HttpCode:     http.StatusNotFound,
+	}
+	forbidden := &apiError.ClientErrorResponse{
+		ErrorMessage: "Forbidden",
+		ErrorType:    clientError.Forbidden,
+		HttpCode:     http.StatusForbidden,
+	}
+
+	testCases := []struct {
+		test.TestCase
+		DeniedReadStatus int
+	}{
+		{
+			TestCase: test.TestCase{
+				Name:            "Should allow reading a cluster of a readable cluster group",
+				ExpectedSuccess: test.HTTPTestExpectedResponse{ExpectedCode: http.StatusOK},
+				Request:         &test.HTTPTestRequest{Method: http.MethodGet, Path: clusterv1.Endpoint.Path + "games-production/"},
+			},
+			DeniedReadStatus: http.StatusNotFound,
+		},
+		{
+			TestCase: test.TestCase{
+				Name:              "Should return 404 reading an unreadable cluster",
+				ExpectedHTTPError: notFound,
+				Request:           &test.HTTPTestRequest{Method: http.MethodGet, Path: clusterv1.Endpoint.Path + "platform-production/"},
+			},
+			DeniedReadStatus: http.StatusNotFound,
+		},
+		{
+			TestCase: test.TestCase{
+				Name:              "Should return 403 reading an unreadable cluster when configured",
+				ExpectedHTTPError: forbidden,
+				Request:           &test.HTTPTestRequest{Method: http.MethodGet, Path: clusterv1.Endpoint.Path + "platform-production/"},
+			},
+			DeniedReadStatus: http.StatusForbidden,
+		},
+		{
+			TestCase: test.TestCase{
+				Name:              "Should return 404 deleting an unreadable cluster",
+				ExpectedHTTPError: notFound,
+				Request:           &test.HTTPTestRequest{Method: http.MethodDelete, Path: clusterv1.Endpoint.Path + "platform-production/"},
+			},
+			DeniedReadStatus: http.StatusNotFound,
+		},
+		{
+			TestCase: test.TestCase{
+				Name:              "Should return 403 upgrading a readable cluster of another environment",
+				ExpectedHTTPError: forbidden,
+				Request:           &test.HTTPTestRequest{Method: http.MethodPost, Path: clusterv1.Endpoint.Path + "games-production/upgrade/", Body: bytes.NewReader(upgradeBody)},
+			},
+			DeniedReadStatus: http.StatusNotFound,
+		},
+		{
+			TestCase: test.TestCase{
+				Name:            "Should allow downloading the kubeconfig of a cluster with the kubeconfig action",
+				ExpectedSuccess: test.HTTPTestExpectedResponse{ExpectedCode: http.StatusOK},
+				Request:         &test.HTTPTestRequest{Method: http.MethodGet, Path: clusterv1.Endpoint.Path + "games-staging/kubeconfig/"},
+			},
+			DeniedReadStatus: http.StatusNotFound,
+		},
+		{
+			TestCase: test.TestCase{
+				Name:              "Should return 403 downloading the kubeconfig of a readable cluster without the kubeconfig action",
+				ExpectedHTTPError: forbidden,
+				Request:           &test.HTTPTestRequest{Method: http.MethodGet, Path: clusterv1.Endpoint.Path + "games-production/kubeconfig/"},
+			},
+			DeniedReadStatus: http.StatusNotFound,
+		},
+		{
+			TestCase: test.TestCase{
+				Name:              "Should return 403 creating a cluster in a cluster group without create policies",
+				ExpectedHTTPError: forbidden,
+				Request:           &test.HTTPTestRequest{Method: http.MethodPost, Path: clusterv1.Endpoint.Path, Body: bytes.NewReader(createBody)},
+			},
+			DeniedReadStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			router := newTestAuthorizedRouter(t, fmt.Sprintf(testTeamPolicies, testCase.DeniedReadStatus))
+			w := testCase.GetHTTPRequest().RunHTTPTest(router)
+
+			if testCase.ExpectedHTTPError != nil {
+				assert.Equal(t, testCase.ExpectedHTTPError.HttpCode, w.Code)
+				expected, err := json.Marshal(testCase.ExpectedHTTPError)
+				assert.Nil(t, err)
+				assert.Equal(t, string(expected), w.Body.String())
+				return
+			}
+			assert.Equal(t, testCase.ExpectedSuccess.(test.HTTPTestExpectedResponse).ExpectedCode, w.Code)
+		})
+	}
+}
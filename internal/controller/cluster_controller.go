@@ -1,15 +1,26 @@
 package controller
 
 import (
+	"fmt"
+	"github.com/gin-contrib/sse"
+	"github.com/topfreegames/kaas-management-api/internal/auth"
 	"github.com/topfreegames/kaas-management-api/internal/kaas"
 	"github.com/topfreegames/kaas-management-api/util/clientError"
-	"log"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	v1 "github.com/topfreegames/kaas-management-api/api/cluster/v1"
 )
 
+// WatchHeartbeatPeriod is how often an idle watch stream receives a heartbeat comment
+const WatchHeartbeatPeriod = 30 * time.Second
+
 // ClusterHandler godoc
 // @Summary      Get a cluster
 // @Description  Get cluster by the full name and show its configuration
@@ -18,16 +29,18 @@ import (
 // @Produce      json
 // @Param        clusterName   path      string  true  "Cluster Name"
 // @Success      200  {object}  v1.Cluster
+// @Failure      403  {object}  error.ClientErrorResponse
 // @Failure      404  {object}  error.ClientErrorResponse
 // @Failure      500  {object}  error.ClientErrorResponse
 // @Router       /v1/clusters/{clusterName}/ [get]
 // @Security BasicAuth
+// @Security BearerAuth
 func (controller ControllerConfig) ClusterHandler(c *gin.Context) {
 	clusterName := c.Param(v1.ClusterNameParameter)
 
-	cluster, err := kaas.GetCluster(controller.K8sInstance, clusterName)
+	cluster, err := kaas.GetCluster(controller.k8sInstance(c), clusterName)
 	if err != nil {
-		log.Printf("[ClusterHandler] Error getting Cluster: %s", err.Error())
+		controller.logError(c, "ClusterHandler", "Error getting Cluster", err)
 		clientErr, ok := err.(*clientError.ClientError)
 		if !ok {
 			clientError.ErrorHandler(c, err, "Internal Server Error", http.StatusInternalServerError)
@@ -43,6 +56,10 @@ func (controller ControllerConfig) ClusterHandler(c *gin.Context) {
 		return
 	}
 
+	if !controller.authorizeCluster(c, auth.ActionRead, cluster) {
+		return
+	}
+
 	clusterResponse := writeClusterV1Response(cluster)
 	c.JSON(http.StatusOK, clusterResponse)
 }
@@ -53,17 +70,43 @@ func (controller ControllerConfig) ClusterHandler(c *gin.Context) {
 // @Tags         Cluster
 // @Accept       json
 // @Produce      json
+// @Param        limit                   query     int     false  "Maximum number of clusters returned"
+// @Param        continue                query     string  false  "Token of the next page returned by the previous one"
+// @Param        region                  query     string  false  "Comma separated regions of the clusters"
+// @Param        environment             query     string  false  "Comma separated environments of the clusters"
+// @Param        clusterGroup            query     string  false  "Comma separated cluster groups of the clusters"
+// @Param        kubeprovider            query     string  false  "Comma separated kube providers of the clusters"
+// @Param        infrastructureprovider  query     string  false  "Comma separated infrastructure providers of the clusters"
+// @Param        selector                query     string  false  "Kubernetes label selector over the cluster labels and the filters above, like region in (us-east-1,us-west-2),kubeprovider!=kubeadm"
 // @Success      200  {object}  v1.ClusterList
+// @Failure      400  {object}  error.ClientErrorResponse
 // @Failure      404  {object}  error.ClientErrorResponse
 // @Failure      500  {object}  error.ClientErrorResponse
 // @Router       /v1/clusters/ [get]
 // @Security BasicAuth
+// @Security BearerAuth
 func (controller ControllerConfig) ClusterListHandler(c *gin.Context) {
 	var clusterListResponse v1.ClusterList
 
-	clusterList, err := kaas.ListClusters(controller.K8sInstance)
+	options, err := getListOptions(c)
 	if err != nil {
-		log.Printf("[ClusterListHandler] Error getting Cluster List: %s", err.Error())
+		controller.logError(c, "ClusterListHandler", "Invalid pagination parameters", err)
+		clientErr := err.(*clientError.ClientError)
+		clientError.ErrorHandler(c, err, clientErr.ErrorDetailedMessage, http.StatusBadRequest)
+		return
+	}
+
+	options.LabelSelector, err = getClusterSelector(c)
+	if err != nil {
+		controller.logError(c, "ClusterListHandler", "Invalid filter parameters", err)
+		clientErr := err.(*clientError.ClientError)
+		clientError.ErrorHandler(c, err, clientErr.ErrorDetailedMessage, http.StatusBadRequest)
+		return
+	}
+
+	clusterList, next, err := kaas.ListClusters(controller.k8sInstance(c), options)
+	if err != nil {
+		controller.logError(c, "ClusterListHandler", "Error getting Cluster List", err)
 		clientErr, ok := err.(*clientError.ClientError)
 		if !ok {
 			clientError.ErrorHandler(c, err, "Internal Server Error", http.StatusInternalServerError)
@@ -72,6 +115,8 @@ func (controller ControllerConfig) ClusterListHandler(c *gin.Context) {
 				clientError.ErrorHandler(c, err, "No clusters were found", http.StatusNotFound)
 			} else if clientErr.ErrorMessage == clientError.EmptyResponse {
 				clientError.ErrorHandler(c, err, "No clusters were found", http.StatusNotFound)
+			} else if clientErr.ErrorMessage == clientError.InvalidRequest {
+				clientError.ErrorHandler(c, err, clientErr.ErrorDetailedMessage, http.StatusBadRequest)
 			} else {
 				clientError.ErrorHandler(c, err, "Unhandled Error", http.StatusInternalServerError)
 			}
@@ -79,12 +124,14 @@ func (controller ControllerConfig) ClusterListHandler(c *gin.Context) {
 		return
 	}
 
-	for _, cluster := range clusterList {
+	// The clusters the caller can't read are left out without errors
+	for _, cluster := range controller.readableClusters(c, clusterList) {
 		clusterResponse := writeClusterV1Response(cluster)
 		clusterListResponse.Items = append(clusterListResponse.Items, clusterResponse)
 	}
+	clusterListResponse.Next = next
 
-	if len(clusterListResponse.Items) == 0 {
+	if len(clusterListResponse.Items) == 0 && next == "" {
 		err := clientError.NewClientError(nil, clientError.EmptyResponse, "No Clusters were found")
 		clientErr := err.(*clientError.ClientError)
 		clientError.ErrorHandler(c, err, clientErr.ErrorDetailedMessage, http.StatusNotFound)
@@ -94,19 +141,423 @@ func (controller ControllerConfig) ClusterListHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, clusterListResponse)
 }
 
+// clusterFilterQueryParameters maps the filter query parameters of the cluster list to the metadata keys or properties they select
+var clusterFilterQueryParameters = map[string]string{
+	v1.RegionQueryParameter:                 kaas.RegionMetadataKey,
+	v1.EnvironmentQueryParameter:            kaas.EnvironmentMetadataKey,
+	v1.ClusterGroupQueryParameter:           kaas.ClusterGroupMetadataKey,
+	v1.KubeProviderQueryParameter:           kaas.KubeProviderProperty,
+	v1.InfrastructureProviderQueryParameter: kaas.InfrastructureProviderProperty,
+}
+
+// getClusterSelector combines the filter query parameters of the cluster list into a single selector
+func getClusterSelector(c *gin.Context) (labels.Selector, error) {
+	selector, err := labels.Parse(c.Query(v1.SelectorQueryParameter))
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidRequest, fmt.Sprintf("The %s query parameter is invalid: %s", v1.SelectorQueryParameter, err.Error()))
+	}
+
+	for parameter, key := range clusterFilterQueryParameters {
+		var values []string
+		for _, value := range c.QueryArray(parameter) {
+			values = append(values, strings.Split(value, ",")...)
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		requirement, err := labels.NewRequirement(key, selection.In, values)
+		if err != nil {
+			return nil, clientError.NewClientError(err, clientError.InvalidRequest, fmt.Sprintf("The %s query parameter has an invalid value", parameter))
+		}
+		selector = selector.Add(*requirement)
+	}
+
+	return selector, nil
+}
+
+// ClusterCreateHandler godoc
+// @Summary      Create a cluster
+// @Description  Create a cluster with its control plane and infrastructure from a provider agnostic specification
+// @Tags         Cluster
+// @Accept       json
+// @Produce      json
+// @Param        cluster  body      v1.ClusterCreateRequest  true  "Cluster specification"
+// @Success      201  {object}  v1.Cluster
+// @Failure      400  {object}  error.ClientErrorResponse
+// @Failure      403  {object}  error.ClientErrorResponse
+// @Failure      409  {object}  error.ClientErrorResponse
+// @Failure      500  {object}  error.ClientErrorResponse
+// @Router       /v1/clusters/ [post]
+// @Security BasicAuth
+// @Security BearerAuth
+func (controller ControllerConfig) ClusterCreateHandler(c *gin.Context) {
+	var clusterCreateRequest v1.ClusterCreateRequest
+
+	err := c.ShouldBindJSON(&clusterCreateRequest)
+	if err != nil {
+		controller.logError(c, "ClusterCreateHandler", "Invalid cluster specification", err)
+		err = clientError.NewClientError(err, clientError.InvalidRequest, "Invalid cluster specification")
+		clientError.ErrorHandler(c, err, "Invalid cluster specification", http.StatusBadRequest)
+		return
+	}
+
+	clusterSpec := &kaas.ClusterSpec{
+		Name:                   clusterCreateRequest.Name,
+		KubernetesVersion:      clusterCreateRequest.KubernetesVersion,
+		KubeProvider:           clusterCreateRequest.KubeProvider,
+		InfrastructureProvider: clusterCreateRequest.InfrastructureProvider,
+		Region:                 clusterCreateRequest.Region,
+		ClusterGroup:           clusterCreateRequest.ClusterGroup,
+		Environment:            clusterCreateRequest.Environment,
+		Zones:                  clusterCreateRequest.Zones,
+		NetworkCIDR:            clusterCreateRequest.NetworkCIDR,
+		ServiceCIDR:            clusterCreateRequest.ServiceCIDR,
+		PodCIDR:                clusterCreateRequest.PodCIDR,
+		Topology:               clusterCreateRequest.Topology,
+		APIAccessCIDRs:         clusterCreateRequest.APIAccessCIDRs,
+		SSHAccessCIDRs:         clusterCreateRequest.SSHAccessCIDRs,
+	}
+
+	newCluster := &kaas.Cluster{Name: clusterSpec.Name, ClusterGroup: clusterSpec.ClusterGroup, Environment: clusterSpec.Environment}
+	if !controller.authorizeCluster(c, auth.ActionCreate, newCluster) {
+		return
+	}
+
+	cluster, err := kaas.CreateCluster(controller.k8sInstance(c), clusterSpec)
+	if err != nil {
+		controller.logError(c, "ClusterCreateHandler", "Error creating Cluster", err)
+		clientErr, ok := err.(*clientError.ClientError)
+		if !ok {
+			clientError.ErrorHandler(c, err, "Internal Server Error", http.StatusInternalServerError)
+		} else {
+			if clientErr.ErrorMessage == clientError.InvalidRequest {
+				clientError.ErrorHandler(c, err, clientErr.ErrorDetailedMessage, http.StatusBadRequest)
+			} else if clientErr.ErrorMessage == clientError.ResourceAlreadyExists {
+				clientError.ErrorHandler(c, err, "Cluster already exists", http.StatusConflict)
+			} else if clientErr.ErrorMessage == clientError.InvalidConfiguration {
+				clientError.ErrorHandler(c, err, clientErr.ErrorDetailedMessage, http.StatusInternalServerError)
+			} else {
+				clientError.ErrorHandler(c, err, "Unhandled Error", http.StatusInternalServerError)
+			}
+		}
+		return
+	}
+
+	clusterResponse := writeClusterV1Response(cluster)
+	c.JSON(http.StatusCreated, clusterResponse)
+}
+
+// ClusterDeleteHandler godoc
+// @Summary      Delete a cluster
+// @Description  Delete a cluster and all its resources. The cluster name must be confirmed and protected clusters can't be deleted
+// @Tags         Cluster
+// @Accept       json
+// @Produce      json
+// @Param        clusterName   path      string  true  "Cluster Name"
+// @Param        confirm       query     string  true  "Cluster Name confirmation"
+// @Success      202
+// @Failure      400  {object}  error.ClientErrorResponse
+// @Failure      403  {object}  error.ClientErrorResponse
+// @Failure      404  {object}  error.ClientErrorResponse
+// @Failure      409  {object}  error.ClientErrorResponse
+// @Failure      500  {object}  error.ClientErrorResponse
+// @Router       /v1/clusters/{clusterName}/ [delete]
+// @Security BasicAuth
+// @Security BearerAuth
+func (controller ControllerConfig) ClusterDeleteHandler(c *gin.Context) {
+	clusterName := c.Param(v1.ClusterNameParameter)
+	confirmation := c.Query(v1.ConfirmQueryParameter)
+
+	if !controller.authorizeClusterName(c, auth.ActionDelete, clusterName) {
+		return
+	}
+
+	err := kaas.DeleteCluster(controller.k8sInstance(c), clusterName, confirmation)
+	if err != nil {
+		controller.logError(c, "ClusterDeleteHandler", "Error deleting Cluster", err)
+		clientErr, ok := err.(*clientError.ClientError)
+		if !ok {
+			clientError.ErrorHandler(c, err, "Internal Server Error", http.StatusInternalServerError)
+		} else {
+			if clientErr.ErrorMessage == clientError.ResourceNotFound {
+				clientError.ErrorHandler(c, err, "Cluster not found", http.StatusNotFound)
+			} else if clientErr.ErrorMessage == clientError.InvalidRequest {
+				clientError.ErrorHandler(c, err, clientErr.ErrorDetailedMessage, http.StatusBadRequest)
+			} else if clientErr.ErrorMessage == clientError.DeletionProtected {
+				clientError.ErrorHandler(c, err, clientErr.ErrorDetailedMessage, http.StatusConflict)
+			} else {
+				clientError.ErrorHandler(c, err, "Unhandled Error", http.StatusInternalServerError)
+			}
+		}
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// ClusterUpgradeHandler godoc
+// @Summary      Upgrade a cluster
+// @Description  Upgrade the kubernetes version of a cluster control plane, only one minor version at a time. With nodegroups set, the node groups are upgraded instead, which is only allowed once the control plane runs the requested version. Kops clusters don't report the version their control plane runs, so their node groups can't be upgraded
+// @Tags         Cluster
+// @Accept       json
+// @Produce      json
+// @Param        clusterName   path      string                     true  "Cluster Name"
+// @Param        upgrade       body      v1.ClusterUpgradeRequest   true  "Kubernetes version"
+// @Success      202  {object}  v1.Cluster
+// @Failure      400  {object}  error.ClientErrorResponse
+// @Failure      403  {object}  error.ClientErrorResponse
+// @Failure      404  {object}  error.ClientErrorResponse
+// @Failure      500  {object}  error.ClientErrorResponse
+// @Router       /v1/clusters/{clusterName}/upgrade/ [post]
+// @Security BasicAuth
+// @Security BearerAuth
+func (controller ControllerConfig) ClusterUpgradeHandler(c *gin.Context) {
+	clusterName := c.Param(v1.ClusterNameParameter)
+	var clusterUpgradeRequest v1.ClusterUpgradeRequest
+
+	err := c.ShouldBindJSON(&clusterUpgradeRequest)
+	if err != nil {
+		controller.logError(c, "ClusterUpgradeHandler", "Invalid cluster upgrade", err)
+		err = clientError.NewClientError(err, clientError.InvalidRequest, "Invalid cluster upgrade")
+		clientError.ErrorHandler(c, err, "Invalid cluster upgrade", http.StatusBadRequest)
+		return
+	}
+
+	if !controller.authorizeClusterName(c, auth.ActionUpdate, clusterName) {
+		return
+	}
+
+	cluster, err := kaas.UpgradeCluster(controller.k8sInstance(c), clusterName, clusterUpgradeRequest.Version, clusterUpgradeRequest.NodeGroups)
+	if err != nil {
+		controller.logError(c, "ClusterUpgradeHandler", "Error upgrading Cluster", err)
+		clientErr, ok := err.(*clientError.ClientError)
+		if !ok {
+			clientError.ErrorHandler(c, err, "Internal Server Error", http.StatusInternalServerError)
+		} else {
+			if clientErr.ErrorMessage == clientError.ResourceNotFound {
+				clientError.ErrorHandler(c, err, "Cluster not found", http.StatusNotFound)
+			} else if clientErr.ErrorMessage == clientError.InvalidRequest {
+				clientError.ErrorHandler(c, err, clientErr.ErrorDetailedMessage, http.StatusBadRequest)
+			} else if clientErr.ErrorMessage == clientError.InvalidConfiguration {
+				clientError.ErrorHandler(c, err, clientErr.ErrorDetailedMessage, http.StatusInternalServerError)
+			} else {
+				clientError.ErrorHandler(c, err, "Unhandled Error", http.StatusInternalServerError)
+			}
+		}
+		return
+	}
+
+	clusterResponse := writeClusterV1Response(cluster)
+	c.JSON(http.StatusAccepted, clusterResponse)
+}
+
+// ClusterKubeconfigHandler godoc
+// @Summary      Download a cluster kubeconfig
+// @Description  Download the kubeconfig of a cluster as YAML, optionally renaming its context and replacing its server. Requires a policy allowing the kubeconfig action on the cluster, the download is disabled when no policies are configured
+// @Tags         Cluster
+// @Produce      application/yaml
+// @Param        clusterName   path      string  true   "Cluster Name"
+// @Param        context       query     string  false  "Context name"
+// @Param        server        query     string  false  "Server URL"
+// @Param        apiendpoint   query     bool    false  "Use the cluster API endpoint as server"
+// @Success      200  {string}  string
+// @Failure      400  {object}  error.ClientErrorResponse
+// @Failure      403  {object}  error.ClientErrorResponse
+// @Failure      404  {object}  error.ClientErrorResponse
+// @Failure      500  {object}  error.ClientErrorResponse
+// @Router       /v1/clusters/{clusterName}/kubeconfig/ [get]
+// @Security BasicAuth
+// @Security BearerAuth
+func (controller ControllerConfig) ClusterKubeconfigHandler(c *gin.Context) {
+	clusterName := c.Param(v1.ClusterNameParameter)
+
+	// A kubeconfig grants access to the cluster, so it's never downloaded without policies authorizing the caller
+	if auth.GetPolicies(controller.K8sInstance) == nil {
+		err := clientError.NewClientError(nil, clientError.Forbidden, "Kubeconfig download is disabled without authorization policies")
+		controller.logError(c, "ClusterKubeconfigHandler", "Kubeconfig download denied", err, zap.String("cluster", clusterName))
+		clientError.ErrorHandler(c, err, err.(*clientError.ClientError).ErrorDetailedMessage, http.StatusForbidden)
+		return
+	}
+
+	if !controller.authorizeClusterName(c, auth.ActionKubeconfig, clusterName) {
+		return
+	}
+
+	var err error
+
+	options := &kaas.KubeconfigOptions{
+		ContextName: c.Query(v1.ContextQueryParameter),
+		Server:      c.Query(v1.ServerQueryParameter),
+	}
+	if apiEndpoint := c.Query(v1.ApiEndpointQueryParameter); apiEndpoint != "" {
+		options.UseApiEndpoint, err = strconv.ParseBool(apiEndpoint)
+		if err != nil {
+			controller.logError(c, "ClusterKubeconfigHandler", "Invalid apiendpoint query parameter", err)
+			err = clientError.NewClientError(err, clientError.InvalidRequest, "The apiendpoint query parameter must be a boolean")
+			clientError.ErrorHandler(c, err, "The apiendpoint query parameter must be a boolean", http.StatusBadRequest)
+			return
+		}
+	}
+
+	kubeconfig, err := kaas.GetClusterKubeconfig(controller.k8sInstance(c), clusterName, options)
+	if err != nil {
+		controller.logError(c, "ClusterKubeconfigHandler", "Error getting Cluster kubeconfig", err)
+		clientErr, ok := err.(*clientError.ClientError)
+		if !ok {
+			clientError.ErrorHandler(c, err, "Internal Server Error", http.StatusInternalServerError)
+		} else {
+			if clientErr.ErrorMessage == clientError.ResourceNotFound {
+				clientError.ErrorHandler(c, err, clientErr.ErrorDetailedMessage, http.StatusNotFound)
+			} else if clientErr.ErrorMessage == clientError.InvalidRequest {
+				clientError.ErrorHandler(c, err, clientErr.ErrorDetailedMessage, http.StatusBadRequest)
+			} else {
+				clientError.ErrorHandler(c, err, "Unhandled Error", http.StatusInternalServerError)
+			}
+		}
+		return
+	}
+
+	c.Data(http.StatusOK, "application/yaml", kubeconfig)
+}
+
+// ClusterWatchHandler godoc
+// @Summary      Watch clusters and node groups
+// @Description  Stream the changes of all clusters and node groups, or of a single cluster, as server-sent events. Each event is a JSON with the type ADDED, MODIFIED or DELETED, the kind Cluster or NodeGroup and the object in the same format returned by the cluster and node group endpoints. The id of each event is its resourceVersion, clients reconnecting with it in the Last-Event-ID header receive the changes after this event. The stream ends when the Kubernetes watch can't be established again and clients should reconnect
+// @Tags         Cluster
+// @Produce      text/event-stream
+// @Param        cluster   query     string  false  "Cluster Name"
+// @Param        Last-Event-ID  header  string  false  "Id of the last event received, to watch the changes after it"
+// @Success      200  {object}  v1.WatchEvent
+// @Failure      403  {object}  error.ClientErrorResponse
+// @Failure      404  {object}  error.ClientErrorResponse
+// @Failure      500  {object}  error.ClientErrorResponse
+// @Router       /v1/clusters/watch/ [get]
+// @Security BasicAuth
+// @Security BearerAuth
+func (controller ControllerConfig) ClusterWatchHandler(c *gin.Context) {
+	clusterName := c.Query(v1.ClusterQueryParameter)
+
+	if clusterName != "" {
+		cluster, err := kaas.GetCluster(controller.k8sInstance(c), clusterName)
+		if err != nil {
+			controller.logError(c, "ClusterWatchHandler", "Error getting Cluster", err)
+			clientErr, ok := err.(*clientError.ClientError)
+			if ok && clientErr.ErrorMessage == clientError.ResourceNotFound {
+				clientError.ErrorHandler(c, err, "Cluster not found", http.StatusNotFound)
+			} else {
+				clientError.ErrorHandler(c, err, "Internal Server Error", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		if !controller.authorizeCluster(c, auth.ActionRead, cluster) {
+			return
+		}
+	}
+
+	// Clients reconnecting send the id of the last event received, which is the resourceVersion to watch from
+	events, err := kaas.Watch(c.Request.Context(), controller.K8sInstance, clusterName, c.GetHeader(v1.LastEventIDHeader))
+	if err != nil {
+		controller.logError(c, "ClusterWatchHandler", "Error watching Clusters", err)
+		clientError.ErrorHandler(c, err, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	// The headers are sent right away so clients know the watch has started before the first event
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(WatchHeartbeatPeriod)
+	defer heartbeat.Stop()
+
+	// The events channel is closed when the client disconnects, since the watch uses the request context,
+	// and returning on shutdown ends the request so the server doesn't wait for the watch
+	for {
+		select {
+		case <-controller.ShutdownCh:
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if !controller.readableEvent(c, &event) {
+				continue
+			}
+			c.Render(-1, sse.Event{
+				Id:    event.ResourceVersion,
+				Event: "message",
+				Data:  controller.writeWatchEventV1Response(&event),
+			})
+		case <-heartbeat.C:
+			// SSE comments keep idle connections open behind proxies and load balancers
+			_, err := c.Writer.WriteString(": heartbeat\n\n")
+			if err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// writeWatchEventV1Response Write the event of the watch version 1 endpoint
+func (controller ControllerConfig) writeWatchEventV1Response(event *kaas.Event) v1.WatchEvent {
+	if event.Cluster != nil {
+		return v1.WatchEvent{
+			Type:   event.Type,
+			Kind:   v1.ClusterEventKind,
+			Object: writeClusterV1Response(event.Cluster),
+		}
+	}
+
+	cluster, err := kaas.GetCluster(controller.K8sInstance, event.NodeGroup.Cluster)
+	if err != nil {
+		// The cluster may be deleted before its node groups
+		cluster = &kaas.Cluster{Name: event.NodeGroup.Cluster}
+	}
+	return v1.WatchEvent{
+		Type:   event.Type,
+		Kind:   v1.NodeGroupEventKind,
+		Object: writeNodeGroupV1Response(cluster, event.NodeGroup),
+	}
+}
+
 // writeClusterV1Response Write the response of the cluster version 1 endpoint
 func writeClusterV1Response(cluster *kaas.Cluster) v1.Cluster {
 	clusterResponse := v1.Cluster{
-		Name:      cluster.Name,
-		ApiServer: cluster.ApiEndpoint,
-		Metadata: map[string]interface{}{ // TODO, load this mapping from config (kubernetes CR for the management API)
-			"clusterGroup": cluster.ClusterGroup,
-			"region":       cluster.Region,
-			"environment":  cluster.Environment,
-			"CIDR":         cluster.CIDR,
-		},
+		Name:                   cluster.Name,
+		ApiServer:              cluster.ApiEndpoint,
+		Metadata:               map[string]interface{}{},
 		KubeProvider:           cluster.ControlPlane.Provider,
 		InfrastructureProvider: cluster.Infrastructure.Provider,
+		KubernetesVersion:      cluster.ControlPlane.Version,
+		Status: v1.ClusterStatus{
+			Phase:               cluster.Status.Phase,
+			ControlPlaneReady:   cluster.Status.ControlPlaneReady,
+			InfrastructureReady: cluster.Status.InfrastructureReady,
+			FailureReason:       cluster.Status.FailureReason,
+			FailureMessage:      cluster.Status.FailureMessage,
+		},
+	}
+
+	// The metadata keys are configured in the metadata mapping, the CIDR is always returned
+	for key, value := range cluster.Metadata {
+		clusterResponse.Metadata[key] = value
+	}
+	clusterResponse.Metadata["CIDR"] = cluster.CIDR
+
+	for _, condition := range cluster.Status.Conditions {
+		clusterResponse.Status.Conditions = append(clusterResponse.Status.Conditions, v1.Condition{
+			Type:               condition.Type,
+			Status:             condition.Status,
+			Severity:           condition.Severity,
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+			LastTransitionTime: condition.LastTransitionTime,
+		})
 	}
 	return clusterResponse
 }
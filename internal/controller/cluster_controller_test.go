@@ -1,22 +1,62 @@
 package controller
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
 	apiError "github.com/topfreegames/kaas-management-api/api/error"
+	"github.com/topfreegames/kaas-management-api/internal/auth"
 	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/internal/k8s/providers/kops"
+	"github.com/topfreegames/kaas-management-api/internal/kaas"
+	"github.com/topfreegames/kaas-management-api/internal/logging"
 	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
 	"log"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	clusterv1 "github.com/topfreegames/kaas-management-api/api/cluster/v1"
+	nodegroupv1 "github.com/topfreegames/kaas-management-api/api/nodeGroup/v1"
 	"github.com/topfreegames/kaas-management-api/test"
 )
 
 func Test_ClusterHandler_Success(t *testing.T) {
+	failedCluster := test.NewTestCluster("failed-cluster.cluster.example.com", "failedcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1")
+	failureReason := capierrors.CreateClusterError
+	failureMessage := "Failure detected from referenced resource"
+	failedCluster.Status = clusterapiv1beta1.ClusterStatus{
+		Phase:               string(clusterapiv1beta1.ClusterPhaseFailed),
+		InfrastructureReady: true,
+		FailureReason:       &failureReason,
+		FailureMessage:      &failureMessage,
+		Conditions: clusterapiv1beta1.Conditions{
+			{
+				Type:               clusterapiv1beta1.ControlPlaneReadyCondition,
+				Status:             corev1.ConditionFalse,
+				Severity:           clusterapiv1beta1.ConditionSeverityError,
+				Reason:             "ControlPlaneFailed",
+				Message:            "kops update failed",
+				LastTransitionTime: metav1.NewTime(time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)),
+			},
+		},
+	}
+
 	testCases := []test.TestCase{
 		{
 			Name: "Success getting test-cluster in clusterV1 endpoint",
@@ -45,6 +85,50 @@ func Test_ClusterHandler_Success(t *testing.T) {
 				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
 			},
 		},
+		{
+			Name: "Success getting failed-cluster with its status in clusterV1 endpoint",
+			ExpectedSuccess: test.HTTPTestExpectedResponse{
+				ExpectedBody: clusterv1.Cluster{
+					Name:      "failed-cluster.cluster.example.com",
+					ApiServer: "https://api.failed-cluster.cluster.example.com.cluster.example.com:443",
+					Metadata: map[string]interface{}{
+						"clusterGroup": "test-clusters",
+						"region":       "us-east-1",
+						"environment":  "test",
+						"CIDR":         []string{"192.168.0.0/24"},
+					},
+					KubeProvider:           "kops",
+					InfrastructureProvider: "kops",
+					Status: clusterv1.ClusterStatus{
+						Phase:               "Failed",
+						ControlPlaneReady:   false,
+						InfrastructureReady: true,
+						FailureReason:       "CreateError",
+						FailureMessage:      "Failure detected from referenced resource",
+						Conditions: []clusterv1.Condition{
+							{
+								Type:               "ControlPlaneReady",
+								Status:             "False",
+								Severity:           "Error",
+								Reason:             "ControlPlaneFailed",
+								Message:            "kops update failed",
+								LastTransitionTime: time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC),
+							},
+						},
+					},
+				},
+				ExpectedCode: http.StatusOK,
+			},
+			ExpectedHTTPError: nil,
+			Request: &test.HTTPTestRequest{
+				Method: "GET",
+				Body:   nil,
+				Path:   clusterv1.Endpoint.Path + "failed-cluster.cluster.example.com/",
+			},
+			K8sTestResources: []runtime.Object{
+				failedCluster,
+			},
+		},
 	}
 
 	k := &k8s.Kubernetes{
@@ -151,6 +235,49 @@ func Test_ClusterHandler_Error(t *testing.T) {
 	}
 }
 
+func Test_ClusterHandler_RequestID(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core)
+
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClient(),
+		},
+	}
+	controller := ConfigureControllers(k)
+	controller.Logger = logger
+	router := gin.Default()
+	router.Use(logging.Middleware(logger))
+	router.Handle(http.MethodGet, clusterv1.Endpoint.Path+test.Param(clusterv1.ClusterNameParameter), controller.ClusterHandler)
+
+	header := http.Header{}
+	header.Set(logging.RequestIDHeader, "test-request-id")
+	request := &test.HTTPTestRequest{
+		Method: http.MethodGet,
+		Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/",
+		Header: header,
+	}
+	w := request.RunHTTPTest(router)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "test-request-id", w.Header().Get(logging.RequestIDHeader))
+
+	expected, err := json.Marshal(&apiError.ClientErrorResponse{
+		ErrorMessage: "Cluster not found",
+		ErrorType:    clientError.ResourceNotFound,
+		HttpCode:     http.StatusNotFound,
+		RequestID:    "test-request-id",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), w.Body.String())
+
+	handlerLogs := logs.FilterField(zap.String("handler", "ClusterHandler")).All()
+	assert.Len(t, handlerLogs, 1)
+	assert.Equal(t, logs.Len(), len(logs.FilterField(zap.String(logging.RequestIDField, "test-request-id")).All()))
+	if len(handlerLogs) == 1 {
+		assert.Equal(t, zapcore.WarnLevel, handlerLogs[0].Level)
+	}
+}
+
 func Test_ClusterListHandler_Success(t *testing.T) {
 	testCases := []test.TestCase{
 		{
@@ -228,6 +355,38 @@ func Test_ClusterListHandler_Success(t *testing.T) {
 				test.NewTestCluster("test-cluster2.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
 			},
 		},
+		{
+			Name: "Success getting the clusters selected by the filters in clusterV1 endpoint",
+			ExpectedSuccess: test.HTTPTestExpectedResponse{
+				ExpectedBody: clusterv1.ClusterList{
+					Items: []clusterv1.Cluster{
+						{
+							Name:      "test-cluster.cluster.example.com",
+							ApiServer: "https://api.test-cluster.cluster.example.com.cluster.example.com:443",
+							Metadata: map[string]interface{}{
+								"clusterGroup": "test-clusters",
+								"region":       "us-east-1",
+								"environment":  "test",
+								"CIDR":         []string{"192.168.0.0/24"},
+							},
+							KubeProvider:           "kops",
+							InfrastructureProvider: "kops",
+						},
+					},
+				},
+				ExpectedCode: http.StatusOK,
+			},
+			ExpectedHTTPError: nil,
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodGet,
+				Body:   nil,
+				Path:   clusterv1.Endpoint.Path + "?region=us-east-1,us-west-2&kubeprovider=kops&selector=environment%20in%20(test)",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestCluster("test-cluster2.cluster.example.com", "testcluster-kubeadm-cp", "KubeadmControlPlane", "controlplane.cluster.x-k8s.io/v1beta1", "docker-cluster", "DockerCluster", "infrastructure.cluster.x-k8s.io/v1beta1"),
+			},
+		},
 	}
 
 	k := &k8s.Kubernetes{
@@ -303,3 +462,930 @@ func Test_ClusterListHandler_ErrorEmptyResponse(t *testing.T) {
 		})
 	}
 }
+
+func Test_ClusterListHandler_ErrorInvalidParameters(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "Error getting cluster list in clusterV1 endpoint should return bad request for a non numeric limit",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "The limit query parameter must be a positive integer",
+				ErrorType:    clientError.InvalidRequest,
+				HttpCode:     http.StatusBadRequest,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodGet,
+				Body:   nil,
+				Path:   clusterv1.Endpoint.Path + "?limit=all",
+			},
+		},
+		{
+			Name:            "Error getting cluster list in clusterV1 endpoint should return bad request for a zero limit",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "The limit query parameter must be a positive integer",
+				ErrorType:    clientError.InvalidRequest,
+				HttpCode:     http.StatusBadRequest,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodGet,
+				Body:   nil,
+				Path:   clusterv1.Endpoint.Path + "?limit=0",
+			},
+		},
+		{
+			Name:            "Error getting cluster list in clusterV1 endpoint should return bad request for an invalid continue token",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "The continue token invalid is invalid",
+				ErrorType:    clientError.InvalidRequest,
+				HttpCode:     http.StatusBadRequest,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodGet,
+				Body:   nil,
+				Path:   clusterv1.Endpoint.Path + "?limit=1&continue=invalid",
+			},
+		},
+		{
+			Name:            "Error getting cluster list in clusterV1 endpoint should return bad request for an invalid selector",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "The selector query parameter is invalid: unable to parse requirement: found 'us-east-1' expected: '('",
+				ErrorType:    clientError.InvalidRequest,
+				HttpCode:     http.StatusBadRequest,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodGet,
+				Body:   nil,
+				Path:   clusterv1.Endpoint.Path + "?selector=region%20in%20us-east-1",
+			},
+		},
+		{
+			Name:            "Error getting cluster list in clusterV1 endpoint should return bad request for an invalid filter value",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "The region query parameter has an invalid value",
+				ErrorType:    clientError.InvalidRequest,
+				HttpCode:     http.StatusBadRequest,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodGet,
+				Body:   nil,
+				Path:   clusterv1.Endpoint.Path + "?region=us%20east",
+			},
+		},
+	}
+
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClient(),
+		},
+	}
+
+	controller := ConfigureControllers(k)
+	router := gin.Default()
+	router.Handle(http.MethodGet, clusterv1.Endpoint.Path, controller.ClusterListHandler)
+
+	for _, testCase := range testCases {
+		request := testCase.GetHTTPRequest()
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			w := request.RunHTTPTest(router)
+			assert.Equal(t, testCase.ExpectedHTTPError.HttpCode, w.Code)
+			expected, err := json.Marshal(testCase.ExpectedHTTPError)
+			assert.Nil(t, err)
+			assert.Equal(t, string(expected), w.Body.String())
+		})
+	}
+}
+
+func Test_ClusterCreateHandler_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name: "Success creating test-cluster in clusterV1 endpoint",
+			ExpectedSuccess: test.HTTPTestExpectedResponse{
+				ExpectedBody: clusterv1.Cluster{
+					Name:      "test-cluster.cluster.example.com",
+					ApiServer: "https://api.test-cluster.cluster.example.com:443",
+					Metadata: map[string]interface{}{
+						"clusterGroup": "test-clusters",
+						"region":       "us-east-1",
+						"environment":  "test",
+						"CIDR":         []string{"100.64.0.0/13"},
+					},
+					KubeProvider:           "kops",
+					InfrastructureProvider: "kops",
+					KubernetesVersion:      "1.21.5",
+				},
+				ExpectedCode: http.StatusCreated,
+			},
+			ExpectedHTTPError: nil,
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodPost,
+				Body: strings.NewReader(`{
+					"name": "test-cluster.cluster.example.com",
+					"kubernetesversion": "1.21.5",
+					"kubeprovider": "kops",
+					"infrastructureprovider": "kops",
+					"clustergroup": "test-clusters",
+					"region": "us-east-1",
+					"environment": "test",
+					"zones": ["us-east-1a"]
+				}`),
+				Path: clusterv1.Endpoint.Path,
+			},
+			K8sTestResources: []runtime.Object{},
+		},
+	}
+
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClient(),
+		},
+	}
+	controller := ConfigureControllers(k)
+	router := gin.Default()
+	router.Handle(http.MethodPost, clusterv1.Endpoint.Path, controller.ClusterCreateHandler)
+
+	os.Setenv(kops.StateStoreEnv, "s3://test-state-store")
+	defer os.Unsetenv(kops.StateStoreEnv)
+
+	for _, testCase := range testCases {
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		request := testCase.GetHTTPRequest()
+		expectedResponse, ok := testCase.ExpectedSuccess.(test.HTTPTestExpectedResponse)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to *test.HTTPTestExpectedResponse", testCase.Name)
+		}
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			w := request.RunHTTPTest(router)
+			assert.Equal(t, expectedResponse.ExpectedCode, w.Code)
+			expected, err := json.Marshal(expectedResponse.ExpectedBody)
+			assert.Nil(t, err)
+			assert.Equal(t, string(expected), w.Body.String())
+		})
+	}
+}
+
+func Test_ClusterCreateHandler_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "Error creating test-cluster in clusterV1 endpoint should return bad request for an incomplete specification",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "Invalid cluster specification",
+				ErrorType:    clientError.InvalidRequest,
+				HttpCode:     http.StatusBadRequest,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodPost,
+				Body:   strings.NewReader(`{"name": "test-cluster.cluster.example.com"}`),
+				Path:   clusterv1.Endpoint.Path,
+			},
+			K8sTestResources: []runtime.Object{},
+		},
+		{
+			Name:            "Error creating test-cluster in clusterV1 endpoint should return bad request for an unsupported provider",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "The providers kubeadm/docker are not supported for cluster creation",
+				ErrorType:    clientError.InvalidRequest,
+				HttpCode:     http.StatusBadRequest,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodPost,
+				Body: strings.NewReader(`{
+					"name": "test-cluster.cluster.example.com",
+					"kubernetesversion": "1.21.5",
+					"kubeprovider": "kubeadm",
+					"infrastructureprovider": "docker",
+					"region": "us-east-1",
+					"zones": ["local"]
+				}`),
+				Path: clusterv1.Endpoint.Path,
+			},
+			K8sTestResources: []runtime.Object{},
+		},
+		{
+			Name:            "Error creating test-cluster in clusterV1 endpoint should return conflict for an existent cluster",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "Cluster already exists",
+				ErrorType:    clientError.ResourceAlreadyExists,
+				HttpCode:     http.StatusConflict,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodPost,
+				Body: strings.NewReader(`{
+					"name": "test-cluster.cluster.example.com",
+					"kubernetesversion": "1.21.5",
+					"kubeprovider": "kops",
+					"infrastructureprovider": "kops",
+					"region": "us-east-1",
+					"zones": ["us-east-1a"]
+				}`),
+				Path: clusterv1.Endpoint.Path,
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+	}
+
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClient(),
+		},
+	}
+	controller := ConfigureControllers(k)
+	router := gin.Default()
+	router.Handle(http.MethodPost, clusterv1.Endpoint.Path, controller.ClusterCreateHandler)
+
+	os.Setenv(kops.StateStoreEnv, "s3://test-state-store")
+	defer os.Unsetenv(kops.StateStoreEnv)
+
+	for _, testCase := range testCases {
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+		request := testCase.GetHTTPRequest()
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			w := request.RunHTTPTest(router)
+			assert.Equal(t, testCase.ExpectedHTTPError.HttpCode, w.Code)
+			expected, err := json.Marshal(testCase.ExpectedHTTPError)
+			assert.Nil(t, err)
+			assert.Equal(t, string(expected), w.Body.String())
+		})
+	}
+}
+
+func Test_ClusterDeleteHandler_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name: "Success deleting test-cluster in clusterV1 endpoint",
+			ExpectedSuccess: test.HTTPTestExpectedResponse{
+				ExpectedBody: nil,
+				ExpectedCode: http.StatusAccepted,
+			},
+			ExpectedHTTPError: nil,
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodDelete,
+				Body:   nil,
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/?confirm=test-cluster.cluster.example.com",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+	}
+
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClient(),
+		},
+	}
+	controller := ConfigureControllers(k)
+	router := gin.Default()
+	router.Handle(http.MethodDelete, clusterv1.Endpoint.Path+test.Param(clusterv1.ClusterNameParameter), controller.ClusterDeleteHandler)
+
+	for _, testCase := range testCases {
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		request := testCase.GetHTTPRequest()
+		expectedResponse, ok := testCase.ExpectedSuccess.(test.HTTPTestExpectedResponse)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to *test.HTTPTestExpectedResponse", testCase.Name)
+		}
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			w := request.RunHTTPTest(router)
+			assert.Equal(t, expectedResponse.ExpectedCode, w.Code)
+			assert.Equal(t, "", w.Body.String())
+		})
+	}
+}
+
+func Test_ClusterDeleteHandler_Error(t *testing.T) {
+	protectedCluster := test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1")
+	protectedCluster.Labels[kaas.DeletionProtectionKey] = "true"
+
+	testCases := []test.TestCase{
+		{
+			Name:            "Error deleting test-cluster in clusterV1 endpoint should return bad request without confirmation",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "The cluster name must be confirmed to delete the cluster test-cluster.cluster.example.com",
+				ErrorType:    clientError.InvalidRequest,
+				HttpCode:     http.StatusBadRequest,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodDelete,
+				Body:   nil,
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+		{
+			Name:            "Error deleting test-cluster in clusterV1 endpoint should return not found",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "Cluster not found",
+				ErrorType:    clientError.ResourceNotFound,
+				HttpCode:     http.StatusNotFound,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodDelete,
+				Body:   nil,
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/?confirm=test-cluster.cluster.example.com",
+			},
+			K8sTestResources: []runtime.Object{},
+		},
+		{
+			Name:            "Error deleting test-cluster in clusterV1 endpoint should return conflict for a protected cluster",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "Cluster test-cluster.cluster.example.com is protected against deletion, remove the deletionProtection label and annotation to delete it",
+				ErrorType:    clientError.DeletionProtected,
+				HttpCode:     http.StatusConflict,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodDelete,
+				Body:   nil,
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/?confirm=test-cluster.cluster.example.com",
+			},
+			K8sTestResources: []runtime.Object{
+				protectedCluster,
+			},
+		},
+	}
+
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClient(),
+		},
+	}
+	controller := ConfigureControllers(k)
+	router := gin.Default()
+	router.Handle(http.MethodDelete, clusterv1.Endpoint.Path+test.Param(clusterv1.ClusterNameParameter), controller.ClusterDeleteHandler)
+
+	for _, testCase := range testCases {
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+		request := testCase.GetHTTPRequest()
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			w := request.RunHTTPTest(router)
+			assert.Equal(t, testCase.ExpectedHTTPError.HttpCode, w.Code)
+			expected, err := json.Marshal(testCase.ExpectedHTTPError)
+			assert.Nil(t, err)
+			assert.Equal(t, string(expected), w.Body.String())
+		})
+	}
+}
+
+func Test_ClusterUpgradeHandler_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name: "Success upgrading test-cluster in clusterV1 endpoint",
+			ExpectedSuccess: test.HTTPTestExpectedResponse{
+				ExpectedBody: clusterv1.Cluster{
+					Name:      "test-cluster.cluster.example.com",
+					ApiServer: "https://api.test-cluster.cluster.example.com.cluster.example.com:443",
+					Metadata: map[string]interface{}{
+						"clusterGroup": "test-clusters",
+						"region":       "us-east-1",
+						"environment":  "test",
+						"CIDR":         []string{"192.168.0.0/24"},
+					},
+					KubeProvider:           "kops",
+					InfrastructureProvider: "kops",
+					KubernetesVersion:      "1.22.3",
+				},
+				ExpectedCode: http.StatusAccepted,
+			},
+			ExpectedHTTPError: nil,
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodPost,
+				Body:   strings.NewReader(`{"version": "1.22.3"}`),
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/upgrade/",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestKopsControlPlane("testcluster-kops-cp", "test-cluster.cluster.example.com", "1.21.5"),
+			},
+		},
+	}
+
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClient(),
+		},
+	}
+	controller := ConfigureControllers(k)
+	router := gin.Default()
+	router.Handle(http.MethodPost, clusterv1.Endpoint.Path+test.Param(clusterv1.ClusterNameParameter)+test.Path(clusterv1.UpgradeEndpointName), controller.ClusterUpgradeHandler)
+
+	for _, testCase := range testCases {
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		request := testCase.GetHTTPRequest()
+		expectedResponse, ok := testCase.ExpectedSuccess.(test.HTTPTestExpectedResponse)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to *test.HTTPTestExpectedResponse", testCase.Name)
+		}
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			w := request.RunHTTPTest(router)
+			assert.Equal(t, expectedResponse.ExpectedCode, w.Code)
+			expected, err := json.Marshal(expectedResponse.ExpectedBody)
+			assert.Nil(t, err)
+			assert.Equal(t, string(expected), w.Body.String())
+		})
+	}
+}
+
+func Test_ClusterUpgradeHandler_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "Error upgrading test-cluster in clusterV1 endpoint should return bad request without version",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "Invalid cluster upgrade",
+				ErrorType:    clientError.InvalidRequest,
+				HttpCode:     http.StatusBadRequest,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodPost,
+				Body:   strings.NewReader(`{"nodegroups": true}`),
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/upgrade/",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestKopsControlPlane("testcluster-kops-cp", "test-cluster.cluster.example.com", "1.21.5"),
+			},
+		},
+		{
+			Name:            "Error upgrading test-cluster in clusterV1 endpoint should return bad request skipping a minor version",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "Cluster test-cluster.cluster.example.com can't skip minor versions, it must be upgraded from 1.21.5 to 1.22 first",
+				ErrorType:    clientError.InvalidRequest,
+				HttpCode:     http.StatusBadRequest,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodPost,
+				Body:   strings.NewReader(`{"version": "1.23.1"}`),
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/upgrade/",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestKopsControlPlane("testcluster-kops-cp", "test-cluster.cluster.example.com", "1.21.5"),
+			},
+		},
+		{
+			Name:            "Error upgrading test-cluster in clusterV1 endpoint should return not found",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "Cluster not found",
+				ErrorType:    clientError.ResourceNotFound,
+				HttpCode:     http.StatusNotFound,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodPost,
+				Body:   strings.NewReader(`{"version": "1.22.3"}`),
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/upgrade/",
+			},
+			K8sTestResources: []runtime.Object{},
+		},
+	}
+
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClient(),
+		},
+	}
+	controller := ConfigureControllers(k)
+	router := gin.Default()
+	router.Handle(http.MethodPost, clusterv1.Endpoint.Path+test.Param(clusterv1.ClusterNameParameter)+test.Path(clusterv1.UpgradeEndpointName), controller.ClusterUpgradeHandler)
+
+	for _, testCase := range testCases {
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+		request := testCase.GetHTTPRequest()
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			w := request.RunHTTPTest(router)
+			assert.Equal(t, testCase.ExpectedHTTPError.HttpCode, w.Code)
+			expected, err := json.Marshal(testCase.ExpectedHTTPError)
+			assert.Nil(t, err)
+			assert.Equal(t, string(expected), w.Body.String())
+		})
+	}
+}
+
+const testKubeconfigPolicies = `
+policies:
+  - name: kubeconfig
+    subjects: ["user@example.com"]
+    actions: ["read", "kubeconfig"]
+`
+
+// testSubjectHeader overrides the subject of the caller of the kubeconfig router
+const testSubjectHeader = "X-Test-Subject"
+
+// newTestKubeconfigRouter returns a router with the kubeconfig route for the caller user@example.com
+func newTestKubeconfigRouter(controller ControllerConfig) *gin.Engine {
+	router := gin.Default()
+	router.Use(func(c *gin.Context) {
+		subject := c.GetHeader(testSubjectHeader)
+		if subject == "" {
+			subject = "user@example.com"
+		}
+		c.Set(auth.SubjectContextKey, subject)
+	})
+	router.Handle(http.MethodGet, clusterv1.Endpoint.Path+test.Param(clusterv1.ClusterNameParameter)+test.Path(clusterv1.KubeconfigEndpointName), controller.ClusterKubeconfigHandler)
+	return router
+}
+
+func Test_ClusterKubeconfigHandler_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:                "Success downloading the test-cluster kubeconfig in clusterV1 endpoint",
+			ExpectedSuccess:     "https://10.0.0.1:6443",
+			ExpectedClientError: nil,
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodGet,
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/kubeconfig/?context=test-cluster",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+		{
+			Name:                "Success downloading the test-cluster kubeconfig with the API endpoint as server in clusterV1 endpoint",
+			ExpectedSuccess:     "https://api.test-cluster.cluster.example.com.cluster.example.com:443",
+			ExpectedClientError: nil,
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodGet,
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/kubeconfig/?context=test-cluster&apiendpoint=true",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+	}
+
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClient(),
+			Clientset:     test.NewK8sFakeClientset(),
+		},
+		Config: newTestPoliciesConfig(t, testKubeconfigPolicies),
+	}
+	router := newTestKubeconfigRouter(ConfigureControllers(k))
+
+	for _, testCase := range testCases {
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+		k.K8sAuth.Clientset = test.NewK8sFakeClientset(test.NewTestKubeconfigSecret("test-cluster.cluster.example.com", test.NewTestKubeconfig("test-cluster.cluster.example.com", "https://10.0.0.1:6443")))
+		request := testCase.GetHTTPRequest()
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			w := request.RunHTTPTest(router)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "application/yaml", w.Header().Get("Content-Type"))
+
+			kubeconfig, err := clientcmd.Load(w.Body.Bytes())
+			assert.Nil(t, err)
+			assert.Equal(t, "test-cluster", kubeconfig.CurrentContext)
+			assert.Equal(t, testCase.ExpectedSuccess, kubeconfig.Clusters["test-cluster.cluster.example.com"].Server)
+		})
+	}
+}
+
+func Test_ClusterKubeconfigHandler_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "Error downloading the test-cluster kubeconfig in clusterV1 endpoint should return not found for a caller without policies on the cluster",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "Cluster not found",
+				ErrorType:    clientError.ResourceNotFound,
+				HttpCode:     http.StatusNotFound,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodGet,
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/kubeconfig/",
+				Header: http.Header{testSubjectHeader: []string{"other@example.com"}},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+		{
+			Name:            "Error downloading the test-cluster kubeconfig in clusterV1 endpoint should return bad request with an invalid apiendpoint",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "The apiendpoint query parameter must be a boolean",
+				ErrorType:    clientError.InvalidRequest,
+				HttpCode:     http.StatusBadRequest,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodGet,
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/kubeconfig/?apiendpoint=maybe",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+		{
+			Name:            "Error downloading the test-cluster kubeconfig in clusterV1 endpoint should return bad request with a http server",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "The kubeconfig server http://test-cluster.example.com must be a https URL",
+				ErrorType:    clientError.InvalidRequest,
+				HttpCode:     http.StatusBadRequest,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodGet,
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/kubeconfig/?server=http://test-cluster.example.com",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+		{
+			Name:            "Error downloading the test-cluster kubeconfig in clusterV1 endpoint should return not found",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "Could not find cluster test-cluster.cluster.example.com",
+				ErrorType:    clientError.ResourceNotFound,
+				HttpCode:     http.StatusNotFound,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodGet,
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/kubeconfig/",
+			},
+			K8sTestResources: []runtime.Object{},
+		},
+	}
+
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClient(),
+			Clientset:     test.NewK8sFakeClientset(),
+		},
+		Config: newTestPoliciesConfig(t, testKubeconfigPolicies),
+	}
+	router := newTestKubeconfigRouter(ConfigureControllers(k))
+
+	for _, testCase := range testCases {
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+		request := testCase.GetHTTPRequest()
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			w := request.RunHTTPTest(router)
+			assert.Equal(t, testCase.ExpectedHTTPError.HttpCode, w.Code)
+			expected, err := json.Marshal(testCase.ExpectedHTTPError)
+			assert.Nil(t, err)
+			assert.Equal(t, string(expected), w.Body.String())
+		})
+	}
+}
+
+func Test_ClusterKubeconfigHandler_ErrorWithoutPolicies(t *testing.T) {
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClientWithResources(test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1")),
+			Clientset:     test.NewK8sFakeClientset(test.NewTestKubeconfigSecret("test-cluster.cluster.example.com", test.NewTestKubeconfig("test-cluster.cluster.example.com", "https://10.0.0.1:6443"))),
+		},
+	}
+	router := newTestKubeconfigRouter(ConfigureControllers(k))
+
+	request := &test.HTTPTestRequest{Method: http.MethodGet, Path: clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/kubeconfig/"}
+	w := request.RunHTTPTest(router)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	expected, err := json.Marshal(&apiError.ClientErrorResponse{
+		ErrorMessage: "Kubeconfig download is disabled without authorization policies",
+		ErrorType:    clientError.Forbidden,
+		HttpCode:     http.StatusForbidden,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), w.Body.String())
+}
+
+// clusterWatchTestRequest is the request of the watch handler tests, the resources are created after the stream has started
+// and IDs are the ids expected in the events
+type clusterWatchTestRequest struct {
+	Path    string
+	Created []runtime.Object
+	IDs     []string
+}
+
+// newTestWatchedCluster returns a test cluster with a resourceVersion
+func newTestWatchedCluster(name string, resourceVersion string) runtime.Object {
+	cluster := test.NewTestCluster(name, "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1")
+	cluster.ResourceVersion = resourceVersion
+	return cluster
+}
+
+func Test_ClusterWatchHandler_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name: "Success watching clusters and node groups in clusterV1 endpoint",
+			ExpectedSuccess: []clusterv1.WatchEvent{
+				{
+					Type: "ADDED",
+					Kind: clusterv1.ClusterEventKind,
+					Object: clusterv1.Cluster{
+						Name:      "test-cluster.cluster.example.com",
+						ApiServer: "https://api.test-cluster.cluster.example.com.cluster.example.com:443",
+						Metadata: map[string]interface{}{
+							"clusterGroup": "test-clusters",
+							"region":       "us-east-1",
+							"environment":  "test",
+							"CIDR":         []string{"192.168.0.0/24"},
+						},
+						KubeProvider:           "kops",
+						InfrastructureProvider: "kops",
+					},
+				},
+			},
+			Request: &clusterWatchTestRequest{
+				Path: clusterv1.Endpoint.Path + "watch/",
+				Created: []runtime.Object{
+					newTestWatchedCluster("test-cluster.cluster.example.com", "42"),
+				},
+				IDs: []string{"id:42"},
+			},
+			K8sTestResources: []runtime.Object{},
+		},
+		{
+			Name: "Success watching the node groups of test-cluster in clusterV1 endpoint",
+			ExpectedSuccess: []clusterv1.WatchEvent{
+				{
+					Type: "ADDED",
+					Kind: clusterv1.NodeGroupEventKind,
+					Object: nodegroupv1.NodeGroup{
+						Name: "nodes",
+						Metadata: &nodegroupv1.Metadata{
+							Cluster:     "test-cluster.cluster.example.com",
+							MachineType: "m5.xlarge",
+							Zones:       []string{"us-east-1a"},
+							Environment: "test",
+							Region:      "us-east-1",
+						},
+						InfrastructureProvider: "kops",
+						Status:                 nodegroupv1.Status{State: "Ready"},
+					},
+				},
+			},
+			Request: &clusterWatchTestRequest{
+				Path: clusterv1.Endpoint.Path + "watch/?cluster=test-cluster.cluster.example.com",
+				Created: []runtime.Object{
+					test.NewTestMachinePool("test-cluster.cluster.example.com-nodes", "test-cluster.cluster.example.com", "KopsMachinePool", "test-cluster.cluster.example.com-TestKopsMachinePool", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestKopsMachinePool("test-cluster.cluster.example.com-TestKopsMachinePool", "test-cluster.cluster.example.com"),
+			},
+		},
+	}
+
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClient(),
+		},
+	}
+	controller := ConfigureControllers(k)
+	router := gin.Default()
+	router.Handle(http.MethodGet, clusterv1.Endpoint.Path+test.Path(clusterv1.WatchEndpointName), controller.ClusterWatchHandler)
+	router.Handle(http.MethodGet, clusterv1.Endpoint.Path+test.Param(clusterv1.ClusterNameParameter), controller.ClusterHandler)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	for _, testCase := range testCases {
+		client := test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+		k.K8sAuth.DynamicClient = client
+		request, ok := testCase.Request.(*clusterWatchTestRequest)
+		if !ok {
+			log.Fatalf("Failed converting Request struct from test \"%s\" to *clusterWatchTestRequest", testCase.Name)
+		}
+		expectedEvents, ok := testCase.ExpectedSuccess.([]clusterv1.WatchEvent)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to []clusterv1.WatchEvent", testCase.Name)
+		}
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			httpRequest, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+request.Path, nil)
+			assert.Nil(t, err)
+
+			// The response headers are only sent after the watch has started
+			response, err := http.DefaultClient.Do(httpRequest)
+			assert.Nil(t, err)
+			defer response.Body.Close()
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+			assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+			for _, resource := range request.Created {
+				object, err := k8s.ToUnstructured(resource)
+				assert.Nil(t, err)
+				assert.Nil(t, client.Tracker().Add(object))
+			}
+
+			var expectedData []string
+			for _, event := range expectedEvents {
+				data, err := json.Marshal(event)
+				assert.Nil(t, err)
+				expectedData = append(expectedData, "data:"+string(data))
+			}
+
+			var receivedData, receivedIDs []string
+			scanner := bufio.NewScanner(response.Body)
+			for len(receivedData) < len(expectedData) && scanner.Scan() {
+				if strings.HasPrefix(scanner.Text(), "data:") {
+					receivedData = append(receivedData, scanner.Text())
+				} else if strings.HasPrefix(scanner.Text(), "id:") {
+					receivedIDs = append(receivedIDs, scanner.Text())
+				}
+			}
+			assert.ElementsMatch(t, expectedData, receivedData)
+			assert.ElementsMatch(t, request.IDs, receivedIDs)
+		})
+	}
+}
+
+func Test_ClusterWatchHandler_EndsOnShutdown(t *testing.T) {
+	shutdownCh := make(chan struct{})
+	controller := ConfigureControllers(&k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClient(),
+		},
+	})
+	controller.ShutdownCh = shutdownCh
+	router := gin.Default()
+	router.Handle(http.MethodGet, clusterv1.Endpoint.Path+test.Path(clusterv1.WatchEndpointName), controller.ClusterWatchHandler)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+clusterv1.Endpoint.Path+"watch/", nil)
+	assert.Nil(t, err)
+
+	response, err := http.DefaultClient.Do(httpRequest)
+	assert.Nil(t, err)
+	defer response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	// The stream ends before the request times out
+	close(shutdownCh)
+	_, err = io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	assert.Nil(t, ctx.Err())
+}
+
+func Test_ClusterWatchHandler_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "Error watching test-cluster in clusterV1 endpoint should return not found",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "Cluster not found",
+				ErrorType:    clientError.ResourceNotFound,
+				HttpCode:     http.StatusNotFound,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodGet,
+				Path:   clusterv1.Endpoint.Path + "watch/?cluster=test-cluster.cluster.example.com",
+			},
+			K8sTestResources: []runtime.Object{},
+		},
+	}
+
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClient(),
+		},
+	}
+	controller := ConfigureControllers(k)
+	router := gin.Default()
+	router.Handle(http.MethodGet, clusterv1.Endpoint.Path+test.Path(clusterv1.WatchEndpointName), controller.ClusterWatchHandler)
+
+	for _, testCase := range testCases {
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+		request := testCase.GetHTTPRequest()
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			w := request.RunHTTPTest(router)
+			assert.Equal(t, testCase.ExpectedHTTPError.HttpCode, w.Code)
+			expected, err := json.Marshal(testCase.ExpectedHTTPError)
+			assert.Nil(t, err)
+			assert.Equal(t, string(expected), w.Body.String())
+		})
+	}
+}
@@ -1,11 +1,66 @@
 package controller
 
-import "github.com/topfreegames/kaas-management-api/internal/k8s"
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	v1 "github.com/topfreegames/kaas-management-api/api/cluster/v1"
+	"github.com/topfreegames/kaas-management-api/internal/audit"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/internal/logging"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"go.uber.org/zap"
+	"strconv"
+)
 
 type ControllerConfig struct {
 	K8sInstance *k8s.Kubernetes
+	// AuditSink stores the audit records of the write requests, the audit endpoint returns 501 when it can't query them
+	AuditSink audit.Sink
+	// Logger logs the errors of the handlers, when nil the global logger is used
+	Logger *zap.Logger
+	// ShutdownCh is closed when the server shuts down to end the watches, the other requests are left to finish
+	ShutdownCh <-chan struct{}
 }
 
 func ConfigureControllers(k8sInstance *k8s.Kubernetes) ControllerConfig {
 	return ControllerConfig{K8sInstance: k8sInstance}
 }
+
+// log returns the logger of the handlers with the request ID and trace ID of the request
+func (controller ControllerConfig) log(c *gin.Context) *zap.Logger {
+	logger := controller.Logger
+	if logger == nil {
+		logger = zap.L()
+	}
+	return logging.ForRequest(logger, c.Request.Context())
+}
+
+// logError logs the error of a handler, the client errors are logged as warnings and the unexpected ones as errors
+func (controller ControllerConfig) logError(c *gin.Context, handler string, message string, err error, fields ...zap.Field) {
+	fields = append([]zap.Field{zap.String("handler", handler), zap.Error(err)}, fields...)
+	if _, ok := err.(*clientError.ClientError); ok {
+		controller.log(c).Warn(message, fields...)
+		return
+	}
+	controller.log(c).Error(message, fields...)
+}
+
+// k8sInstance returns the Kubernetes instance making its calls with the context of the request, so they are part of its trace
+func (controller ControllerConfig) k8sInstance(c *gin.Context) *k8s.Kubernetes {
+	return controller.K8sInstance.WithContext(c.Request.Context())
+}
+
+// getListOptions reads the pagination query parameters of the list endpoints
+func getListOptions(c *gin.Context) (k8s.ListOptions, error) {
+	options := k8s.ListOptions{Continue: c.Query(v1.ContinueQueryParameter)}
+
+	if limit := c.Query(v1.LimitQueryParameter); limit != "" {
+		var err error
+		options.Limit, err = strconv.ParseInt(limit, 10, 64)
+		if err != nil || options.Limit < 1 {
+			return options, clientError.NewClientError(err, clientError.InvalidRequest, fmt.Sprintf("The %s query parameter must be a positive integer", v1.LimitQueryParameter))
+		}
+	}
+
+	return options, nil
+}
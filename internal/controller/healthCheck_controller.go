@@ -12,3 +12,14 @@ func HealthCheckHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, healthCheck)
 }
+
+// ReadinessHandler - returns whether the API is ready to serve requests, with status 503 until the Kubernetes caches have synced
+func (controller ControllerConfig) ReadinessHandler(c *gin.Context) {
+	readiness := healthCheck.Readiness{Ready: controller.K8sInstance.IsReady()}
+
+	if !readiness.Ready {
+		c.JSON(http.StatusServiceUnavailable, readiness)
+		return
+	}
+	c.JSON(http.StatusOK, readiness)
+}
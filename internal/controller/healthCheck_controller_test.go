@@ -3,9 +3,11 @@ package controller
 import (
 	"encoding/json"
 	"github.com/gin-gonic/gin"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
 	"log"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	healthCheckv1 "github.com/topfreegames/kaas-management-api/api/healthCheck"
@@ -48,3 +50,70 @@ func TestHealthCheckHandler(t *testing.T) {
 		assert.Equal(t, string(expected), w.Body.String())
 	})
 }
+
+func TestReadinessHandler(t *testing.T) {
+	testCases := []struct {
+		test.TestCase
+		Cache *k8s.Cache
+	}{
+		{
+			TestCase: test.TestCase{
+				Name: "Readiness should return ok without cache",
+				ExpectedSuccess: test.HTTPTestExpectedResponse{
+					ExpectedBody: healthCheckv1.Readiness{
+						Ready: true,
+					},
+					ExpectedCode: http.StatusOK,
+				},
+				Request: &test.HTTPTestRequest{
+					Method: http.MethodGet,
+					Path:   healthCheckv1.ReadinessEndpoint.Path,
+				},
+			},
+			Cache: nil,
+		},
+		{
+			TestCase: test.TestCase{
+				Name: "Readiness should return service unavailable before the cache has synced",
+				ExpectedSuccess: test.HTTPTestExpectedResponse{
+					ExpectedBody: healthCheckv1.Readiness{
+						Ready: false,
+					},
+					ExpectedCode: http.StatusServiceUnavailable,
+				},
+				Request: &test.HTTPTestRequest{
+					Method: http.MethodGet,
+					Path:   healthCheckv1.ReadinessEndpoint.Path,
+				},
+			},
+			Cache: k8s.NewCache(test.NewK8sFakeDynamicClient(), time.Minute),
+		},
+	}
+
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClient(),
+		},
+	}
+	controller := ConfigureControllers(k)
+	router := gin.Default()
+	router.Handle(http.MethodGet, healthCheckv1.ReadinessEndpoint.Path, controller.ReadinessHandler)
+
+	for _, testCase := range testCases {
+		k.Cache = testCase.Cache
+		request := testCase.GetHTTPRequest()
+		expectedResponse, ok := testCase.ExpectedSuccess.(test.HTTPTestExpectedResponse)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to *test.HTTPTestExpectedResponse", testCase.Name)
+		}
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			w := request.RunHTTPTest(router)
+
+			assert.Equal(t, expectedResponse.ExpectedCode, w.Code)
+			expected, err := json.Marshal(expectedResponse.ExpectedBody)
+			assert.Nil(t, err)
+			assert.Equal(t, string(expected), w.Body.String())
+		})
+	}
+}
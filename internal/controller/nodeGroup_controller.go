@@ -5,10 +5,12 @@ import (
 	"github.com/gin-gonic/gin"
 	clusterv1 "github.com/topfreegames/kaas-management-api/api/cluster/v1"
 	nodegroupv1 "github.com/topfreegames/kaas-management-api/api/nodeGroup/v1"
+	"github.com/topfreegames/kaas-management-api/internal/auth"
 	"github.com/topfreegames/kaas-management-api/internal/kaas"
 	"github.com/topfreegames/kaas-management-api/util/clientError"
-	"log"
+	"go.uber.org/zap"
 	"net/http"
+	"strconv"
 )
 
 // NodeGroupByClusterHandler godoc
@@ -20,17 +22,19 @@ import (
 // @Param        clusterName   path      string  true  "Cluster Name"
 // @Param        nodeGroupName   path      string  true  "Node Group Name"
 // @Success      200  {object}  nodegroupv1.NodeGroup
+// @Failure      403  {object}  error.ClientErrorResponse
 // @Failure      404  {object}  error.ClientErrorResponse
 // @Failure      500  {object}  error.ClientErrorResponse
 // @Router       /v1/clusters/{clusterName}/nodegroup/{nodeGroupName}/ [get]
 // @Security BasicAuth
+// @Security BearerAuth
 func (controller ControllerConfig) NodeGroupByClusterHandler(c *gin.Context) {
 	clusterName := c.Param(clusterv1.ClusterNameParameter)
 	nodeGroupName := c.Param(nodegroupv1.NodeGroupNameParameter)
 
-	cluster, err := kaas.GetCluster(controller.K8sInstance, clusterName)
+	cluster, err := kaas.GetCluster(controller.k8sInstance(c), clusterName)
 	if err != nil {
-		log.Printf("[NodeGroupByClusterHandler] Error getting clusterAPI CR: %s", err.Error())
+		controller.logError(c, "NodeGroupByClusterHandler", "Error getting clusterAPI CR", err)
 		clienterr, ok := err.(*clientError.ClientError)
 		if !ok {
 			clientError.ErrorHandler(c, err, "Internal Server Error", http.StatusInternalServerError)
@@ -44,9 +48,13 @@ func (controller ControllerConfig) NodeGroupByClusterHandler(c *gin.Context) {
 		return
 	}
 
-	nodeGroup, err := kaas.GetNodeGroup(controller.K8sInstance, clusterName, nodeGroupName)
+	if !controller.authorizeCluster(c, auth.ActionRead, cluster) {
+		return
+	}
+
+	nodeGroup, err := kaas.GetNodeGroup(controller.k8sInstance(c), clusterName, nodeGroupName)
 	if err != nil {
-		log.Printf("[NodeGroupByClusterHandler] Error getting NodeGroup: %s", err.Error())
+		controller.logError(c, "NodeGroupByClusterHandler", "Error getting NodeGroup", err)
 		clienterr, ok := err.(*clientError.ClientError)
 		if !ok {
 			clientError.ErrorHandler(c, err, "Internal Server Error", http.StatusInternalServerError)
@@ -75,18 +83,32 @@ func (controller ControllerConfig) NodeGroupByClusterHandler(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        clusterName   path      string  true  "Cluster Name"
+// @Param        limit         query     int     false  "Maximum number of node groups returned"
+// @Param        continue      query     string  false  "Token of the next page returned by the previous one"
 // @Success      200  {object}  nodegroupv1.NodeGroupList
+// @Failure      400  {object}  error.ClientErrorResponse
+// @Failure      403  {object}  error.ClientErrorResponse
+// @Failure      404  {object}  error.ClientErrorResponse
 // @Failure      500  {object}  error.ClientErrorResponse
 // @Router       /v1/clusters/{clusterName}/nodegroups/ [get]
 // @Security BasicAuth
+// @Security BearerAuth
 func (controller ControllerConfig) NodeGroupListByClusterHandler(c *gin.Context) {
 	clusterName := c.Param(clusterv1.ClusterNameParameter)
 
 	var nodegroupV1List nodegroupv1.NodeGroupList
 
-	cluster, err := kaas.GetCluster(controller.K8sInstance, clusterName)
+	options, err := getListOptions(c)
 	if err != nil {
-		log.Printf("[NodeGroupByClusterHandler] Error getting clusterAPI CR: %s", err.Error())
+		controller.logError(c, "NodeGroupListByClusterHandler", "Invalid pagination parameters", err)
+		clientErr := err.(*clientError.ClientError)
+		clientError.ErrorHandler(c, err, clientErr.ErrorDetailedMessage, http.StatusBadRequest)
+		return
+	}
+
+	cluster, err := kaas.GetCluster(controller.k8sInstance(c), clusterName)
+	if err != nil {
+		controller.logError(c, "NodeGroupByClusterHandler", "Error getting clusterAPI CR", err)
 		clienterr, ok := err.(*clientError.ClientError)
 		if !ok {
 			clientError.ErrorHandler(c, err, "Internal Server Error", http.StatusInternalServerError)
@@ -100,15 +122,21 @@ func (controller ControllerConfig) NodeGroupListByClusterHandler(c *gin.Context)
 		return
 	}
 
-	nodeGroups, err := kaas.ListNodeGroups(controller.K8sInstance, clusterName)
+	if !controller.authorizeCluster(c, auth.ActionRead, cluster) {
+		return
+	}
+
+	nodeGroups, next, err := kaas.ListNodeGroups(controller.k8sInstance(c), clusterName, options)
 	if err != nil {
-		log.Printf("[NodeGroupListByClusterHandler] Error Listing NodeGroup: %s", err.Error())
+		controller.logError(c, "NodeGroupListByClusterHandler", "Error Listing NodeGroup", err)
 		clienterr, ok := err.(*clientError.ClientError)
 		if !ok {
 			clientError.ErrorHandler(c, err, "Internal Server Error", http.StatusInternalServerError)
 		} else {
 			if clienterr.ErrorMessage == clientError.EmptyResponse {
 				clientError.ErrorHandler(c, err, fmt.Sprintf("No node groups were found for the cluster %s", clusterName), http.StatusNotFound)
+			} else if clienterr.ErrorMessage == clientError.InvalidRequest {
+				clientError.ErrorHandler(c, err, clienterr.ErrorDetailedMessage, http.StatusBadRequest)
 			} else {
 				clientError.ErrorHandler(c, err, "Unhandled Error", http.StatusInternalServerError)
 			}
@@ -120,8 +148,9 @@ func (controller ControllerConfig) NodeGroupListByClusterHandler(c *gin.Context)
 		nodeGroupV1 := writeNodeGroupV1Response(cluster, nodeGroup)
 		nodegroupV1List.Items = append(nodegroupV1List.Items, nodeGroupV1)
 	}
+	nodegroupV1List.Next = next
 
-	if len(nodegroupV1List.Items) == 0 {
+	if len(nodegroupV1List.Items) == 0 && next == "" {
 		err := clientError.NewClientError(nil, clientError.EmptyResponse, fmt.Sprintf("No NodeGroups were found for the cluster %s", clusterName))
 		clientErr := err.(*clientError.ClientError)
 		clientError.ErrorHandler(c, err, clientErr.ErrorDetailedMessage, http.StatusNotFound)
@@ -130,6 +159,321 @@ func (controller ControllerConfig) NodeGroupListByClusterHandler(c *gin.Context)
 	c.JSON(http.StatusOK, nodegroupV1List)
 }
 
+// NodeGroupCreateHandler godoc
+// @Summary      Create a node group in a cluster
+// @Description  Create a node group in an existing cluster using the node group resources of the cluster provider. Kops node groups require a machine type and zones, docker node groups only support replicas and refuse the other fields
+// @Tags         Cluster
+// @Accept       json
+// @Produce      json
+// @Param        clusterName   path      string  true  "Cluster Name"
+// @Param        nodeGroup     body      nodegroupv1.NodeGroupCreateRequest  true  "Node Group specification"
+// @Success      201  {object}  nodegroupv1.NodeGroup
+// @Failure      400  {object}  error.ClientErrorResponse
+// @Failure      403  {object}  error.ClientErrorResponse
+// @Failure      404  {object}  error.ClientErrorResponse
+// @Failure      409  {object}  error.ClientErrorResponse
+// @Failure      500  {object}  error.ClientErrorResponse
+// @Router       /v1/clusters/{clusterName}/nodegroups/ [post]
+// @Security BasicAuth
+// @Security BearerAuth
+func (controller ControllerConfig) NodeGroupCreateHandler(c *gin.Context) {
+	clusterName := c.Param(clusterv1.ClusterNameParameter)
+
+	var nodeGroupCreateRequest nodegroupv1.NodeGroupCreateRequest
+
+	err := c.ShouldBindJSON(&nodeGroupCreateRequest)
+	if err != nil {
+		controller.logError(c, "NodeGroupCreateHandler", "Invalid node group specification", err)
+		err = clientError.NewClientError(err, clientError.InvalidRequest, "Invalid node group specification")
+		clientError.ErrorHandler(c, err, "Invalid node group specification", http.StatusBadRequest)
+		return
+	}
+
+	cluster, err := kaas.GetCluster(controller.k8sInstance(c), clusterName)
+	if err != nil {
+		controller.logError(c, "NodeGroupCreateHandler", "Error getting clusterAPI CR", err)
+		clienterr, ok := err.(*clientError.ClientError)
+		if !ok {
+			clientError.ErrorHandler(c, err, "Internal Server Error", http.StatusInternalServerError)
+		} else {
+			if clienterr.ErrorMessage == clientError.ResourceNotFound {
+				clientError.ErrorHandler(c, err, "Cluster not found", http.StatusNotFound)
+			} else {
+				clientError.ErrorHandler(c, err, "Unhandled Error", http.StatusInternalServerError)
+			}
+		}
+		return
+	}
+
+	if !controller.authorizeCluster(c, auth.ActionCreate, cluster) {
+		return
+	}
+
+	nodeGroupSpec := &kaas.NodeGroupSpec{
+		Name:        nodeGroupCreateRequest.Name,
+		MachineType: nodeGroupCreateRequest.MachineType,
+		Zones:       nodeGroupCreateRequest.Zones,
+		Replicas:    nodeGroupCreateRequest.Replicas,
+		Min:         nodeGroupCreateRequest.Min,
+		Max:         nodeGroupCreateRequest.Max,
+	}
+
+	nodeGroup, err := kaas.CreateNodeGroup(controller.k8sInstance(c), clusterName, nodeGroupSpec)
+	if err != nil {
+		controller.logError(c, "NodeGroupCreateHandler", "Error creating NodeGroup", err)
+		clienterr, ok := err.(*clientError.ClientError)
+		if !ok {
+			clientError.ErrorHandler(c, err, "Internal Server Error", http.StatusInternalServerError)
+		} else {
+			if clienterr.ErrorMessage == clientError.InvalidRequest {
+				clientError.ErrorHandler(c, err, clienterr.ErrorDetailedMessage, http.StatusBadRequest)
+			} else if clienterr.ErrorMessage == clientError.ResourceAlreadyExists {
+				clientError.ErrorHandler(c, err, "Node group already exists", http.StatusConflict)
+			} else {
+				clientError.ErrorHandler(c, err, "Unhandled Error", http.StatusInternalServerError)
+			}
+		}
+		return
+	}
+
+	nodeGroupV1 := writeNodeGroupV1Response(cluster, nodeGroup)
+	c.JSON(http.StatusCreated, nodeGroupV1)
+}
+
+// NodeGroupUpdateHandler godoc
+// @Summary      Update a node group of a cluster
+// @Description  Change the replicas, min, max and machine type of a node group. Omitted fields are kept unchanged and min <= replicas <= max must hold. A new machine type is supported by kops node groups and by MachineDeployment node groups of AWS machine templates, it replaces the machines with a rolling update tracked in the rollout of the node group
+// @Tags         Cluster
+// @Accept       json
+// @Produce      json
+// @Param        clusterName   path      string  true  "Cluster Name"
+// @Param        nodeGroupName   path      string  true  "Node Group Name"
+// @Param        nodeGroup     body      nodegroupv1.NodeGroupUpdateRequest  true  "Node Group changes"
+// @Success      200  {object}  nodegroupv1.NodeGroup
+// @Failure      400  {object}  error.ClientErrorResponse
+// @Failure      403  {object}  error.ClientErrorResponse
+// @Failure      404  {object}  error.ClientErrorResponse
+// @Failure      500  {object}  error.ClientErrorResponse
+// @Router       /v1/clusters/{clusterName}/nodegroups/{nodeGroupName}/ [patch]
+// @Security BasicAuth
+// @Security BearerAuth
+func (controller ControllerConfig) NodeGroupUpdateHandler(c *gin.Context) {
+	clusterName := c.Param(clusterv1.ClusterNameParameter)
+	nodeGroupName := c.Param(nodegroupv1.NodeGroupNameParameter)
+
+	var nodeGroupUpdateRequest nodegroupv1.NodeGroupUpdateRequest
+
+	err := c.ShouldBindJSON(&nodeGroupUpdateRequest)
+	if err != nil {
+		controller.logError(c, "NodeGroupUpdateHandler", "Invalid node group changes", err)
+		err = clientError.NewClientError(err, clientError.InvalidRequest, "Invalid node group changes")
+		clientError.ErrorHandler(c, err, "Invalid node group changes", http.StatusBadRequest)
+		return
+	}
+
+	cluster, err := kaas.GetCluster(controller.k8sInstance(c), clusterName)
+	if err != nil {
+		controller.logError(c, "NodeGroupUpdateHandler", "Error getting clusterAPI CR", err)
+		clienterr, ok := err.(*clientError.ClientError)
+		if !ok {
+			clientError.ErrorHandler(c, err, "Internal Server Error", http.StatusInternalServerError)
+		} else {
+			if clienterr.ErrorMessage == clientError.ResourceNotFound {
+				clientError.ErrorHandler(c, err, "Cluster not found", http.StatusNotFound)
+			} else {
+				clientError.ErrorHandler(c, err, "Unhandled Error", http.StatusInternalServerError)
+			}
+		}
+		return
+	}
+
+	if !controller.authorizeCluster(c, auth.ActionUpdate, cluster) {
+		return
+	}
+
+	nodeGroupUpdateSpec := &kaas.NodeGroupUpdateSpec{
+		MachineType: nodeGroupUpdateRequest.MachineType,
+		Replicas:    nodeGroupUpdateRequest.Replicas,
+		Min:         nodeGroupUpdateRequest.Min,
+		Max:         nodeGroupUpdateRequest.Max,
+	}
+
+	nodeGroup, err := kaas.UpdateNodeGroup(controller.k8sInstance(c), clusterName, nodeGroupName, nodeGroupUpdateSpec)
+	if err != nil {
+		controller.logError(c, "NodeGroupUpdateHandler", "Error updating NodeGroup", err)
+		clienterr, ok := err.(*clientError.ClientError)
+		if !ok {
+			clientError.ErrorHandler(c, err, "Internal Server Error", http.StatusInternalServerError)
+		} else {
+			if clienterr.ErrorMessage == clientError.InvalidRequest {
+				clientError.ErrorHandler(c, err, clienterr.ErrorDetailedMessage, http.StatusBadRequest)
+			} else if clienterr.ErrorMessage == clientError.ResourceNotFound {
+				clientError.ErrorHandler(c, err, "Node group not found", http.StatusNotFound)
+			} else if clienterr.ErrorMessage == clientError.InvalidResource {
+				clientError.ErrorHandler(c, err, "Node group resource is invalid", http.StatusInternalServerError)
+			} else if clienterr.ErrorMessage == clientError.InvalidConfiguration {
+				clientError.ErrorHandler(c, err, "Node group configuration is invalid", http.StatusInternalServerError)
+			} else {
+				clientError.ErrorHandler(c, err, "Unhandled Error", http.StatusInternalServerError)
+			}
+		}
+		return
+	}
+
+	nodeGroupV1 := writeNodeGroupV1Response(cluster, nodeGroup)
+	c.JSON(http.StatusOK, nodeGroupV1)
+}
+
+// NodeGroupDeleteHandler godoc
+// @Summary      Delete a node group of a cluster
+// @Description  Delete a node group with its infrastructure and bootstrap resources. With drain the node group is scaled to zero before the response and deleted in the background once all its machines are gone, it is left scaled to zero if they aren't gone in 10 minutes
+// @Tags         Cluster
+// @Accept       json
+// @Produce      json
+// @Param        clusterName   path      string  true  "Cluster Name"
+// @Param        nodeGroupName   path      string  true  "Node Group Name"
+// @Param        drain         query     bool    false  "Scale the node group to zero before deleting it"
+// @Success      202
+// @Failure      400  {object}  error.ClientErrorResponse
+// @Failure      403  {object}  error.ClientErrorResponse
+// @Failure      404  {object}  error.ClientErrorResponse
+// @Failure      500  {object}  error.ClientErrorResponse
+// @Router       /v1/clusters/{clusterName}/nodegroups/{nodeGroupName}/ [delete]
+// @Security BasicAuth
+// @Security BearerAuth
+func (controller ControllerConfig) NodeGroupDeleteHandler(c *gin.Context) {
+	clusterName := c.Param(clusterv1.ClusterNameParameter)
+	nodeGroupName := c.Param(nodegroupv1.NodeGroupNameParameter)
+
+	drain := false
+	if drainQuery := c.Query(nodegroupv1.DrainQueryParameter); drainQuery != "" {
+		var err error
+		drain, err = strconv.ParseBool(drainQuery)
+		if err != nil {
+			controller.logError(c, "NodeGroupDeleteHandler", "Invalid drain parameter", err)
+			err = clientError.NewClientError(err, clientError.InvalidRequest, "Invalid drain parameter")
+			clientError.ErrorHandler(c, err, "Invalid drain parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	cluster, err := kaas.GetCluster(controller.k8sInstance(c), clusterName)
+	if err != nil {
+		controller.logError(c, "NodeGroupDeleteHandler", "Error getting clusterAPI CR", err)
+		clienterr, ok := err.(*clientError.ClientError)
+		if !ok {
+			clientError.ErrorHandler(c, err, "Internal Server Error", http.StatusInternalServerError)
+		} else {
+			if clienterr.ErrorMessage == clientError.ResourceNotFound {
+				clientError.ErrorHandler(c, err, "Cluster not found", http.StatusNotFound)
+			} else {
+				clientError.ErrorHandler(c, err, "Unhandled Error", http.StatusInternalServerError)
+			}
+		}
+		return
+	}
+
+	if !controller.authorizeCluster(c, auth.ActionDelete, cluster) {
+		return
+	}
+
+	if drain {
+		err = kaas.DrainNodeGroup(controller.k8sInstance(c), clusterName, nodeGroupName)
+		if err == nil {
+			controller.deleteDrainedNodeGroup(c, clusterName, nodeGroupName)
+		}
+	} else {
+		err = kaas.DeleteNodeGroup(controller.k8sInstance(c), clusterName, nodeGroupName)
+	}
+	if err != nil {
+		controller.logError(c, "NodeGroupDeleteHandler", "Error deleting NodeGroup", err)
+		clienterr, ok := err.(*clientError.ClientError)
+		if !ok {
+			clientError.ErrorHandler(c, err, "Internal Server Error", http.StatusInternalServerError)
+		} else {
+			if clienterr.ErrorMessage == clientError.ResourceNotFound {
+				clientError.ErrorHandler(c, err, "Node group not found", http.StatusNotFound)
+			} else {
+				clientError.ErrorHandler(c, err, "Unhandled Error", http.StatusInternalServerError)
+			}
+		}
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// deleteDrainedNodeGroup deletes a drained node group in the background, once the request has ended its errors can only be logged
+func (controller ControllerConfig) deleteDrainedNodeGroup(c *gin.Context, clusterName string, nodeGroupName string) {
+	logger := controller.log(c).With(zap.String("handler", "NodeGroupDeleteHandler"), zap.String("cluster", clusterName), zap.String("nodeGroup", nodeGroupName))
+	// The deletion outlives the request, so it keeps the request ID and the trace of its context without its cancellation
+	k := controller.k8sInstance(c).Detached()
+
+	go func() {
+		err := kaas.DeleteDrainedNodeGroup(k, clusterName, nodeGroupName)
+		if err != nil {
+			logger.Error("Could not delete the drained NodeGroup", zap.Error(err))
+			return
+		}
+		logger.Info("Deleted the drained NodeGroup")
+	}()
+}
+
+// NodeGroupMachinesHandler godoc
+// @Summary      List the machines of a node group
+// @Description  List the machines of a node group with their provider ID, node name, phase, creation time and kubernetes version. The phase and creation time are only known for the machines of MachineDeployments
+// @Tags         Cluster
+// @Accept       json
+// @Produce      json
+// @Param        clusterName   path      string  true  "Cluster Name"
+// @Param        nodeGroupName   path      string  true  "Node Group Name"
+// @Success      200  {object}  nodegroupv1.MachineList
+// @Failure      403  {object}  error.ClientErrorResponse
+// @Failure      404  {object}  error.ClientErrorResponse
+// @Failure      500  {object}  error.ClientErrorResponse
+// @Router       /v1/clusters/{clusterName}/nodegroups/{nodeGroupName}/machines/ [get]
+// @Security BasicAuth
+// @Security BearerAuth
+func (controller ControllerConfig) NodeGroupMachinesHandler(c *gin.Context) {
+	clusterName := c.Param(clusterv1.ClusterNameParameter)
+	nodeGroupName := c.Param(nodegroupv1.NodeGroupNameParameter)
+
+	if !controller.authorizeClusterName(c, auth.ActionRead, clusterName) {
+		return
+	}
+
+	machines, err := kaas.ListNodeGroupMachines(controller.k8sInstance(c), clusterName, nodeGroupName)
+	if err != nil {
+		controller.logError(c, "NodeGroupMachinesHandler", "Error listing NodeGroup machines", err)
+		clienterr, ok := err.(*clientError.ClientError)
+		if !ok {
+			clientError.ErrorHandler(c, err, "Internal Server Error", http.StatusInternalServerError)
+		} else {
+			if clienterr.ErrorMessage == clientError.ResourceNotFound {
+				clientError.ErrorHandler(c, err, "Node group not found", http.StatusNotFound)
+			} else {
+				clientError.ErrorHandler(c, err, "Unhandled Error", http.StatusInternalServerError)
+			}
+		}
+		return
+	}
+
+	machineList := nodegroupv1.MachineList{
+		Items: []nodegroupv1.Machine{},
+	}
+	for _, machine := range machines {
+		machineList.Items = append(machineList.Items, nodegroupv1.Machine{
+			Name:         machine.Name,
+			ProviderID:   machine.ProviderID,
+			NodeName:     machine.NodeName,
+			Phase:        machine.Phase,
+			CreationTime: machine.CreationTime,
+			Version:      machine.Version,
+		})
+	}
+	c.JSON(http.StatusOK, machineList)
+}
+
 // writeNodeGroupV1Response Write the response of the nodeGroup version 1 endpoint
 func writeNodeGroupV1Response(cluster *kaas.Cluster, nodeGroup *kaas.NodeGroup) nodegroupv1.NodeGroup {
 	metadata := &nodegroupv1.Metadata{
@@ -146,6 +490,24 @@ func writeNodeGroupV1Response(cluster *kaas.Cluster, nodeGroup *kaas.NodeGroup)
 		Name:                   nodeGroup.Name,
 		Metadata:               metadata,
 		InfrastructureProvider: nodeGroup.Infrastructure.Provider,
+		Status: nodegroupv1.Status{
+			State:               nodeGroup.GetState(),
+			Phase:               nodeGroup.Status.Phase,
+			Replicas:            nodeGroup.Status.Replicas,
+			ReadyReplicas:       nodeGroup.Status.ReadyReplicas,
+			AvailableReplicas:   nodeGroup.Status.AvailableReplicas,
+			UnavailableReplicas: nodeGroup.Status.UnavailableReplicas,
+			ObservedGeneration:  nodeGroup.Status.ObservedGeneration,
+			FailureReason:       nodeGroup.Status.FailureReason,
+			FailureMessage:      nodeGroup.Status.FailureMessage,
+		},
+	}
+	if nodeGroup.Rollout != nil {
+		nodeGroupV1.Rollout = &nodegroupv1.Rollout{
+			Replicas:        nodeGroup.Rollout.Replicas,
+			UpdatedReplicas: nodeGroup.Rollout.UpdatedReplicas,
+			ReadyReplicas:   nodeGroup.Rollout.ReadyReplicas,
+		}
 	}
 	return nodeGroupV1
 }
@@ -6,11 +6,18 @@ import (
 	apiError "github.com/topfreegames/kaas-management-api/api/error"
 	nodegroupv1 "github.com/topfreegames/kaas-management-api/api/nodeGroup/v1"
 	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/internal/tracing"
 	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"k8s.io/apimachinery/pkg/runtime"
 	"log"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	clusterv1 "github.com/topfreegames/kaas-management-api/api/cluster/v1"
@@ -35,6 +42,7 @@ func Test_NodeGroupByClusterHandler_Success(t *testing.T) {
 						Max:         nil,
 					},
 					InfrastructureProvider: "kops",
+					Status:                 nodegroupv1.Status{State: "Ready"},
 				},
 				ExpectedCode: http.StatusOK,
 			},
@@ -80,6 +88,52 @@ func Test_NodeGroupByClusterHandler_Success(t *testing.T) {
 	}
 }
 
+func Test_NodeGroupByClusterHandler_Tracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: &k8s.InstrumentedDynamicClient{Client: test.NewK8sFakeDynamicClientWithResources(
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestMachinePool("test-cluster.cluster.example.com-nodes", "test-cluster.cluster.example.com", "KopsMachinePool", "test-cluster.cluster.example.com-TestKopsMachinePool", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestKopsMachinePool("test-cluster.cluster.example.com-TestKopsMachinePool", "test-cluster.cluster.example.com"),
+			)},
+		},
+	}
+	controller := ConfigureControllers(k)
+	router := gin.Default()
+	router.Use(tracing.Middleware())
+	router.Handle(http.MethodGet, clusterv1.Endpoint.Path+test.Param(clusterv1.ClusterNameParameter)+test.Path(nodegroupv1.Endpoint.EndpointName)+test.Param(nodegroupv1.NodeGroupNameParameter), controller.NodeGroupByClusterHandler)
+
+	request := &test.HTTPTestRequest{
+		Method: http.MethodGet,
+		Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/nodegroups/nodes/",
+		Header: http.Header{"Traceparent": []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}},
+	}
+	w := request.RunHTTPTest(router)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range exporter.GetSpans() {
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+		spans[span.Name] = span
+	}
+
+	for _, name := range []string{"GET /v1/clusters/:clusterName/nodegroups/:nodeGroupName/", "kaas.GetCluster", "kaas.GetNodeGroup", "kaas.getNodeGroupConfig", "Kubernetes get machinepools", "kaas.getNodeInfrastructure", "Kubernetes get kopsmachinepools"} {
+		assert.Contains(t, spans, name)
+	}
+	serverSpan := spans["GET /v1/clusters/:clusterName/nodegroups/:nodeGroupName/"]
+	assert.Equal(t, "00f067aa0ba902b7", serverSpan.Parent.SpanID().String())
+	assert.Equal(t, serverSpan.SpanContext.SpanID(), spans["kaas.GetCluster"].Parent.SpanID())
+	assert.Equal(t, serverSpan.SpanContext.SpanID(), spans["kaas.GetNodeGroup"].Parent.SpanID())
+	assert.Equal(t, spans["kaas.GetNodeGroup"].SpanContext.SpanID(), spans["kaas.getNodeGroupConfig"].Parent.SpanID())
+	assert.Equal(t, spans["kaas.getNodeGroupConfig"].SpanContext.SpanID(), spans["Kubernetes get machinepools"].Parent.SpanID())
+	assert.Equal(t, spans["kaas.GetNodeGroup"].SpanContext.SpanID(), spans["kaas.getNodeInfrastructure"].Parent.SpanID())
+	assert.Equal(t, spans["kaas.getNodeInfrastructure"].SpanContext.SpanID(), spans["Kubernetes get kopsmachinepools"].Parent.SpanID())
+}
+
 func Test_NodeGroupByClusterHandler_Error(t *testing.T) {
 	testCases := []test.TestCase{
 		{
@@ -196,6 +250,7 @@ func Test_NodeGroupListByClusterHandler_Success(t *testing.T) {
 								Max:         nil,
 							},
 							InfrastructureProvider: "kops",
+							Status:                 nodegroupv1.Status{State: "Ready"},
 						},
 					},
 				},
@@ -231,6 +286,7 @@ func Test_NodeGroupListByClusterHandler_Success(t *testing.T) {
 								Max:         nil,
 							},
 							InfrastructureProvider: "kops",
+							Status:                 nodegroupv1.Status{State: "Ready"},
 						},
 						{
 							Name: "nodes3",
@@ -245,6 +301,7 @@ func Test_NodeGroupListByClusterHandler_Success(t *testing.T) {
 								Max:         nil,
 							},
 							InfrastructureProvider: "kops",
+							Status:                 nodegroupv1.Status{State: "Ready"},
 						},
 					},
 				},
@@ -361,3 +418,549 @@ func Test_NodeGroupListByClusterHandler_ErrorEmptyResponse(t *testing.T) {
 		})
 	}
 }
+
+func Test_NodeGroupCreateHandler_Success(t *testing.T) {
+	replicas := int32(2)
+	min := int32(1)
+	max := int32(3)
+	testCases := []test.TestCase{
+		{
+			Name: "Success creating nodeGroup in clusterV1 endpoint",
+			ExpectedSuccess: test.HTTPTestExpectedResponse{
+				ExpectedBody: nodegroupv1.NodeGroup{
+					Name: "nodes",
+					Metadata: &nodegroupv1.Metadata{
+						Cluster:     "test-cluster.cluster.example.com",
+						Replicas:    &replicas,
+						MachineType: "m5.xlarge",
+						Zones:       []string{"us-east-1a"},
+						Environment: "test",
+						Region:      "us-east-1",
+						Min:         &min,
+						Max:         &max,
+					},
+					InfrastructureProvider: "kops",
+					Status:                 nodegroupv1.Status{State: "Scaling"},
+				},
+				ExpectedCode: http.StatusCreated,
+			},
+			ExpectedHTTPError: nil,
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodPost,
+				Body: strings.NewReader(`{
+					"name": "nodes",
+					"machinetype": "m5.xlarge",
+					"zones": ["us-east-1a"],
+					"replicas": 2,
+					"min": 1,
+					"max": 3
+				}`),
+				Path: clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/nodegroups/",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+	}
+
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClient(),
+		},
+	}
+	controller := ConfigureControllers(k)
+	router := gin.Default()
+	router.Handle(http.MethodPost, clusterv1.Endpoint.Path+test.Param(clusterv1.ClusterNameParameter)+test.Path(nodegroupv1.Endpoint.EndpointName), controller.NodeGroupCreateHandler)
+
+	for _, testCase := range testCases {
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		request := testCase.GetHTTPRequest()
+		expectedResponse, ok := testCase.ExpectedSuccess.(test.HTTPTestExpectedResponse)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to *test.HTTPTestExpectedResponse", testCase.Name)
+		}
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			w := request.RunHTTPTest(router)
+			assert.Equal(t, expectedResponse.ExpectedCode, w.Code)
+			expected, err := json.Marshal(expectedResponse.ExpectedBody)
+			assert.Nil(t, err)
+			assert.Equal(t, string(expected), w.Body.String())
+		})
+	}
+}
+
+func Test_NodeGroupCreateHandler_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "Error creating nodeGroup in clusterV1 endpoint should return bad request for an incomplete specification",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "Invalid node group specification",
+				ErrorType:    clientError.InvalidRequest,
+				HttpCode:     http.StatusBadRequest,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodPost,
+				Body:   strings.NewReader(`{"machinetype": "m5.xlarge"}`),
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/nodegroups/",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+		{
+			Name:            "Error creating nodeGroup in clusterV1 endpoint should return bad request for replicas lower than min",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "NodeGroup nodes replicas can't be lower than min",
+				ErrorType:    clientError.InvalidRequest,
+				HttpCode:     http.StatusBadRequest,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodPost,
+				Body:   strings.NewReader(`{"name": "nodes", "machinetype": "m5.xlarge", "zones": ["us-east-1a"], "replicas": 0, "min": 1}`),
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/nodegroups/",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+		{
+			Name:            "Error creating nodeGroup in clusterV1 endpoint should return not found for a non-existent cluster",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "Cluster not found",
+				ErrorType:    clientError.ResourceNotFound,
+				HttpCode:     http.StatusNotFound,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodPost,
+				Body:   strings.NewReader(`{"name": "nodes", "machinetype": "m5.xlarge", "zones": ["us-east-1a"]}`),
+				Path:   clusterv1.Endpoint.Path + "nonexistent/nodegroups/",
+			},
+			K8sTestResources: []runtime.Object{},
+		},
+		{
+			Name:            "Error creating nodeGroup in clusterV1 endpoint should return conflict for an existent nodeGroup",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "Node group already exists",
+				ErrorType:    clientError.ResourceAlreadyExists,
+				HttpCode:     http.StatusConflict,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodPost,
+				Body:   strings.NewReader(`{"name": "nodes", "machinetype": "m5.xlarge", "zones": ["us-east-1a"]}`),
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/nodegroups/",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestMachinePool("test-cluster.cluster.example.com-nodes", "test-cluster.cluster.example.com", "KopsMachinePool", "test-cluster.cluster.example.com-nodes", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestKopsMachinePool("test-cluster.cluster.example.com-nodes", "test-cluster.cluster.example.com"),
+			},
+		},
+	}
+
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClient(),
+		},
+	}
+	controller := ConfigureControllers(k)
+	router := gin.Default()
+	router.Handle(http.MethodPost, clusterv1.Endpoint.Path+test.Param(clusterv1.ClusterNameParameter)+test.Path(nodegroupv1.Endpoint.EndpointName), controller.NodeGroupCreateHandler)
+
+	for _, testCase := range testCases {
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+		request := testCase.GetHTTPRequest()
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			w := request.RunHTTPTest(router)
+			assert.Equal(t, testCase.ExpectedHTTPError.HttpCode, w.Code)
+			expected, err := json.Marshal(testCase.ExpectedHTTPError)
+			assert.Nil(t, err)
+			assert.Equal(t, string(expected), w.Body.String())
+		})
+	}
+}
+
+func Test_NodeGroupUpdateHandler_Success(t *testing.T) {
+	replicas := int32(4)
+	min := int32(2)
+	max := int32(5)
+	testCases := []test.TestCase{
+		{
+			Name: "Success scaling nodeGroup in clusterV1 endpoint",
+			ExpectedSuccess: test.HTTPTestExpectedResponse{
+				ExpectedBody: nodegroupv1.NodeGroup{
+					Name: "nodes",
+					Metadata: &nodegroupv1.Metadata{
+						Cluster:     "test-cluster.cluster.example.com",
+						Replicas:    &replicas,
+						MachineType: "m5.xlarge",
+						Zones:       []string{"us-east-1a"},
+						Environment: "test",
+						Region:      "us-east-1",
+						Min:         &min,
+						Max:         &max,
+					},
+					InfrastructureProvider: "kops",
+					Status:                 nodegroupv1.Status{State: "Scaling"},
+				},
+				ExpectedCode: http.StatusOK,
+			},
+			ExpectedHTTPError: nil,
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodPatch,
+				Body:   strings.NewReader(`{"replicas": 4, "min": 2, "max": 5}`),
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/nodegroups/nodes/",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestMachinePool("test-cluster.cluster.example.com-nodes", "test-cluster.cluster.example.com", "KopsMachinePool", "test-cluster.cluster.example.com-TestKopsMachinePool", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestKopsMachinePool("test-cluster.cluster.example.com-TestKopsMachinePool", "test-cluster.cluster.example.com"),
+			},
+		},
+	}
+
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClient(),
+		},
+	}
+	controller := ConfigureControllers(k)
+	router := gin.Default()
+	router.Handle(http.MethodPatch, clusterv1.Endpoint.Path+test.Param(clusterv1.ClusterNameParameter)+test.Path(nodegroupv1.Endpoint.EndpointName)+test.Param(nodegroupv1.NodeGroupNameParameter), controller.NodeGroupUpdateHandler)
+
+	for _, testCase := range testCases {
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		request := testCase.GetHTTPRequest()
+		expectedResponse, ok := testCase.ExpectedSuccess.(test.HTTPTestExpectedResponse)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to *test.HTTPTestExpectedResponse", testCase.Name)
+		}
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			w := request.RunHTTPTest(router)
+			assert.Equal(t, expectedResponse.ExpectedCode, w.Code)
+			expected, err := json.Marshal(expectedResponse.ExpectedBody)
+			assert.Nil(t, err)
+			assert.Equal(t, string(expected), w.Body.String())
+		})
+	}
+}
+
+func Test_NodeGroupUpdateHandler_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "Error scaling nodeGroup in clusterV1 endpoint should return bad request for min greater than max",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "NodeGroup nodes min can't be greater than max",
+				ErrorType:    clientError.InvalidRequest,
+				HttpCode:     http.StatusBadRequest,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodPatch,
+				Body:   strings.NewReader(`{"min": 3, "max": 2}`),
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/nodegroups/nodes/",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestMachinePool("test-cluster.cluster.example.com-nodes", "test-cluster.cluster.example.com", "KopsMachinePool", "test-cluster.cluster.example.com-TestKopsMachinePool", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestKopsMachinePool("test-cluster.cluster.example.com-TestKopsMachinePool", "test-cluster.cluster.example.com"),
+			},
+		},
+		{
+			Name:            "Error updating nodeGroup in clusterV1 endpoint should return bad request for a machine type not supported by the infrastructure",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "Changing the machine type of NodeGroup nodes is not supported by the infrastructure provider docker",
+				ErrorType:    clientError.InvalidRequest,
+				HttpCode:     http.StatusBadRequest,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodPatch,
+				Body:   strings.NewReader(`{"machinetype": "m5.2xlarge"}`),
+				Path:   clusterv1.Endpoint.Path + "docker-cluster/nodegroups/nodes/",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("docker-cluster", "docker-cluster-control-plane", "KubeadmControlPlane", "controlplane.cluster.x-k8s.io/v1beta1", "docker-cluster", "DockerCluster", "infrastructure.cluster.x-k8s.io/v1beta1"),
+				test.NewTestMachineDeployment("docker-cluster-nodes", "docker-cluster", "DockerMachineTemplate", "docker-cluster-nodes", "infrastructure.cluster.x-k8s.io/v1beta1"),
+			},
+		},
+		{
+			Name:            "Error scaling non-existent nodeGroup in clusterV1 endpoint should return not found",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "Node group not found",
+				ErrorType:    clientError.ResourceNotFound,
+				HttpCode:     http.StatusNotFound,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodPatch,
+				Body:   strings.NewReader(`{"replicas": 1}`),
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/nodegroups/nonexistent/",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+	}
+
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClient(),
+		},
+	}
+	controller := ConfigureControllers(k)
+	router := gin.Default()
+	router.Handle(http.MethodPatch, clusterv1.Endpoint.Path+test.Param(clusterv1.ClusterNameParameter)+test.Path(nodegroupv1.Endpoint.EndpointName)+test.Param(nodegroupv1.NodeGroupNameParameter), controller.NodeGroupUpdateHandler)
+
+	for _, testCase := range testCases {
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+		request := testCase.GetHTTPRequest()
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			w := request.RunHTTPTest(router)
+			assert.Equal(t, testCase.ExpectedHTTPError.HttpCode, w.Code)
+			expected, err := json.Marshal(testCase.ExpectedHTTPError)
+			assert.Nil(t, err)
+			assert.Equal(t, string(expected), w.Body.String())
+		})
+	}
+}
+
+func Test_NodeGroupDeleteHandler_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name: "Success deleting nodeGroup in clusterV1 endpoint",
+			ExpectedSuccess: test.HTTPTestExpectedResponse{
+				ExpectedBody: nil,
+				ExpectedCode: http.StatusAccepted,
+			},
+			ExpectedHTTPError: nil,
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodDelete,
+				Body:   nil,
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/nodegroups/nodes/?drain=true",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestMachinePool("test-cluster.cluster.example.com-nodes", "test-cluster.cluster.example.com", "KopsMachinePool", "test-cluster.cluster.example.com-TestKopsMachinePool", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestKopsMachinePool("test-cluster.cluster.example.com-TestKopsMachinePool", "test-cluster.cluster.example.com"),
+			},
+		},
+	}
+
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClient(),
+		},
+	}
+	controller := ConfigureControllers(k)
+	router := gin.Default()
+	router.Handle(http.MethodDelete, clusterv1.Endpoint.Path+test.Param(clusterv1.ClusterNameParameter)+test.Path(nodegroupv1.Endpoint.EndpointName)+test.Param(nodegroupv1.NodeGroupNameParameter), controller.NodeGroupDeleteHandler)
+
+	for _, testCase := range testCases {
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		request := testCase.GetHTTPRequest()
+		expectedResponse, ok := testCase.ExpectedSuccess.(test.HTTPTestExpectedResponse)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to *test.HTTPTestExpectedResponse", testCase.Name)
+		}
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			w := request.RunHTTPTest(router)
+			assert.Equal(t, expectedResponse.ExpectedCode, w.Code)
+			assert.Equal(t, "", w.Body.String())
+		})
+	}
+}
+
+func Test_NodeGroupDeleteHandler_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "Error deleting nodeGroup in clusterV1 endpoint should return bad request for an invalid drain parameter",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "Invalid drain parameter",
+				ErrorType:    clientError.InvalidRequest,
+				HttpCode:     http.StatusBadRequest,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodDelete,
+				Body:   nil,
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/nodegroups/nodes/?drain=maybe",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+		{
+			Name:            "Error deleting non-existent nodeGroup in clusterV1 endpoint should return not found",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "Node group not found",
+				ErrorType:    clientError.ResourceNotFound,
+				HttpCode:     http.StatusNotFound,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodDelete,
+				Body:   nil,
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/nodegroups/nonexistent/",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("test-cluster.cluster.example.com", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+	}
+
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClient(),
+		},
+	}
+	controller := ConfigureControllers(k)
+	router := gin.Default()
+	router.Handle(http.MethodDelete, clusterv1.Endpoint.Path+test.Param(clusterv1.ClusterNameParameter)+test.Path(nodegroupv1.Endpoint.EndpointName)+test.Param(nodegroupv1.NodeGroupNameParameter), controller.NodeGroupDeleteHandler)
+
+	for _, testCase := range testCases {
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+		request := testCase.GetHTTPRequest()
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			w := request.RunHTTPTest(router)
+			assert.Equal(t, testCase.ExpectedHTTPError.HttpCode, w.Code)
+			expected, err := json.Marshal(testCase.ExpectedHTTPError)
+			assert.Nil(t, err)
+			assert.Equal(t, string(expected), w.Body.String())
+		})
+	}
+}
+
+func Test_NodeGroupMachinesHandler_Success(t *testing.T) {
+	creationTime := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	testCases := []test.TestCase{
+		{
+			Name: "Success listing the machines of nodeGroup in clusterV1 endpoint",
+			ExpectedSuccess: test.HTTPTestExpectedResponse{
+				ExpectedBody: nodegroupv1.MachineList{
+					Items: []nodegroupv1.Machine{
+						{
+							Name:         "test-cluster.cluster.example.com-nodes-abcde",
+							ProviderID:   "aws:///us-east-1a/i-0123456789abcdef0",
+							NodeName:     "ip-10-0-0-1.ec2.internal",
+							Phase:        "Running",
+							CreationTime: &creationTime,
+							Version:      "v1.21.5",
+						},
+					},
+				},
+				ExpectedCode: http.StatusOK,
+			},
+			ExpectedHTTPError: nil,
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodGet,
+				Body:   nil,
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/nodegroups/nodes/machines/",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachineDeployment("test-cluster.cluster.example.com-nodes", "test-cluster.cluster.example.com", "DockerMachineTemplate", "test-cluster.cluster.example.com-nodes", "infrastructure.cluster.x-k8s.io/v1beta1"),
+				test.NewTestMachine("test-cluster.cluster.example.com-nodes-abcde", "test-cluster.cluster.example.com", "test-cluster.cluster.example.com-nodes", "aws:///us-east-1a/i-0123456789abcdef0", "ip-10-0-0-1.ec2.internal"),
+			},
+		},
+		{
+			Name: "Success listing no machines of nodeGroup in clusterV1 endpoint",
+			ExpectedSuccess: test.HTTPTestExpectedResponse{
+				ExpectedBody: nodegroupv1.MachineList{
+					Items: []nodegroupv1.Machine{},
+				},
+				ExpectedCode: http.StatusOK,
+			},
+			ExpectedHTTPError: nil,
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodGet,
+				Body:   nil,
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/nodegroups/nodes/machines/",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachinePool("test-cluster.cluster.example.com-nodes", "test-cluster.cluster.example.com", "KopsMachinePool", "test-cluster.cluster.example.com-nodes", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+	}
+
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClient(),
+		},
+	}
+	controller := ConfigureControllers(k)
+	router := gin.Default()
+	router.Handle(http.MethodGet, clusterv1.Endpoint.Path+test.Param(clusterv1.ClusterNameParameter)+test.Path(nodegroupv1.Endpoint.EndpointName)+test.Param(nodegroupv1.NodeGroupNameParameter)+test.Path(nodegroupv1.MachinesEndpointName), controller.NodeGroupMachinesHandler)
+
+	for _, testCase := range testCases {
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		request := testCase.GetHTTPRequest()
+		expectedResponse, ok := testCase.ExpectedSuccess.(test.HTTPTestExpectedResponse)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to *test.HTTPTestExpectedResponse", testCase.Name)
+		}
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			w := request.RunHTTPTest(router)
+			assert.Equal(t, expectedResponse.ExpectedCode, w.Code)
+			expected, err := json.Marshal(expectedResponse.ExpectedBody)
+			assert.Nil(t, err)
+			assert.Equal(t, string(expected), w.Body.String())
+		})
+	}
+}
+
+func Test_NodeGroupMachinesHandler_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "Error listing the machines of nodeGroup in clusterV1 endpoint should return not found",
+			ExpectedSuccess: nil,
+			ExpectedHTTPError: &apiError.ClientErrorResponse{
+				ErrorMessage: "Node group not found",
+				ErrorType:    clientError.ResourceNotFound,
+				HttpCode:     http.StatusNotFound,
+			},
+			Request: &test.HTTPTestRequest{
+				Method: http.MethodGet,
+				Body:   nil,
+				Path:   clusterv1.Endpoint.Path + "test-cluster.cluster.example.com/nodegroups/nonexistent/machines/",
+			},
+			K8sTestResources: []runtime.Object{},
+		},
+	}
+
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClient(),
+		},
+	}
+	controller := ConfigureControllers(k)
+	router := gin.Default()
+	router.Handle(http.MethodGet, clusterv1.Endpoint.Path+test.Param(clusterv1.ClusterNameParameter)+test.Path(nodegroupv1.Endpoint.EndpointName)+test.Param(nodegroupv1.NodeGroupNameParameter)+test.Path(nodegroupv1.MachinesEndpointName), controller.NodeGroupMachinesHandler)
+
+	for _, testCase := range testCases {
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+		request := testCase.GetHTTPRequest()
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			w := request.RunHTTPTest(router)
+			assert.Equal(t, testCase.ExpectedHTTPError.HttpCode, w.Code)
+			expected, err := json.Marshal(testCase.ExpectedHTTPError)
+			assert.Nil(t, err)
+			assert.Equal(t, string(expected), w.Body.String())
+		})
+	}
+}
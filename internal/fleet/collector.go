@@ -0,0 +1,193 @@
+package fleet
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/internal/kaas"
+	"github.com/topfreegames/kaas-management-api/internal/tracing"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// RefreshPeriod is how often the fleet inventory is listed again
+const RefreshPeriod = time.Minute
+
+var (
+	clusterInfoDesc = prometheus.NewDesc("kaas_cluster_info",
+		"Information about each cluster, always 1",
+		[]string{"name", "region", "environment", "clusterGroup", "kubeprovider", "infraprovider"}, nil)
+	nodeGroupReplicasDesc = prometheus.NewDesc("kaas_nodegroup_replicas",
+		"Desired replicas of each node group",
+		[]string{"cluster", "nodegroup", "machinetype"}, nil)
+	nodeGroupReadyReplicasDesc = prometheus.NewDesc("kaas_nodegroup_ready_replicas",
+		"Ready replicas of each node group",
+		[]string{"cluster", "nodegroup", "machinetype"}, nil)
+	nodeGroupMinReplicasDesc = prometheus.NewDesc("kaas_nodegroup_min_replicas",
+		"Minimum replicas of each node group with autoscaling bounds",
+		[]string{"cluster", "nodegroup"}, nil)
+	nodeGroupMaxReplicasDesc = prometheus.NewDesc("kaas_nodegroup_max_replicas",
+		"Maximum replicas of each node group with autoscaling bounds",
+		[]string{"cluster", "nodegroup"}, nil)
+	lastRefreshDesc = prometheus.NewDesc("kaas_fleet_last_refresh_timestamp_seconds",
+		"Time of the last successful listing of the fleet inventory",
+		nil, nil)
+)
+
+// Collector exports gauges describing the clusters and node groups of the fleet. The inventory is listed on a refresh
+// period with the same models of the API, so scrapes never wait for the Kubernetes API and always see a complete listing.
+type Collector struct {
+	K8sInstance *k8s.Kubernetes
+	mutex       sync.RWMutex
+	clusters    []*kaas.Cluster
+	nodeGroups  []*kaas.NodeGroup
+	lastRefresh time.Time
+}
+
+func NewCollector(k *k8s.Kubernetes) *Collector {
+	return &Collector{K8sInstance: k}
+}
+
+// Run refreshes the inventory right away and then every period until stopCh is closed,
+// the refreshes are skipped until the Kubernetes caches have synced so a partial inventory is never exported
+func (c *Collector) Run(period time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		if c.K8sInstance.IsReady() {
+			if err := c.Refresh(); err != nil {
+				c.K8sInstance.Log().Error("Could not refresh the fleet inventory, keeping the previous one", zap.Error(err))
+			}
+		}
+
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh lists every cluster and its node groups. The node groups of a cluster that can't be listed are left out,
+// while an error listing the clusters keeps the previous inventory.
+func (c *Collector) Refresh() (err error) {
+	ctx, span := tracing.Start(c.K8sInstance.Context(), "fleet.Refresh")
+	defer func() { tracing.End(span, err) }()
+	k := c.K8sInstance.WithContext(ctx)
+
+	clusters, err := listClusters(k)
+	if err != nil {
+		return err
+	}
+
+	var nodeGroups []*kaas.NodeGroup
+	for _, cluster := range clusters {
+		clusterNodeGroups, err := listNodeGroups(k, cluster.Name)
+		if err != nil {
+			k.Log().Warn("Could not list the node groups of the cluster for the fleet inventory", zap.String("cluster", cluster.Name), zap.Error(err))
+			continue
+		}
+		nodeGroups = append(nodeGroups, clusterNodeGroups...)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.clusters = clusters
+	c.nodeGroups = nodeGroups
+	c.lastRefresh = time.Now()
+	return nil
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- clusterInfoDesc
+	ch <- nodeGroupReplicasDesc
+	ch <- nodeGroupReadyReplicasDesc
+	ch <- nodeGroupMinReplicasDesc
+	ch <- nodeGroupMaxReplicasDesc
+	ch <- lastRefreshDesc
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if c.lastRefresh.IsZero() {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(lastRefreshDesc, prometheus.GaugeValue, float64(c.lastRefresh.Unix()))
+
+	for _, cluster := range c.clusters {
+		var kubeProvider, infrastructureProvider string
+		if cluster.ControlPlane != nil {
+			kubeProvider = cluster.ControlPlane.Provider
+		}
+		if cluster.Infrastructure != nil {
+			infrastructureProvider = cluster.Infrastructure.Provider
+		}
+		ch <- prometheus.MustNewConstMetric(clusterInfoDesc, prometheus.GaugeValue, 1,
+			cluster.Name, cluster.Region, cluster.Environment, cluster.ClusterGroup, kubeProvider, infrastructureProvider)
+	}
+
+	for _, nodeGroup := range c.nodeGroups {
+		var machineType string
+		if nodeGroup.Infrastructure != nil {
+			machineType = nodeGroup.Infrastructure.MachineType
+		}
+		replicas := nodeGroup.Status.Replicas
+		if nodeGroup.Replicas != nil {
+			replicas = *nodeGroup.Replicas
+		}
+		ch <- prometheus.MustNewConstMetric(nodeGroupReplicasDesc, prometheus.GaugeValue, float64(replicas), nodeGroup.Cluster, nodeGroup.Name, machineType)
+		ch <- prometheus.MustNewConstMetric(nodeGroupReadyReplicasDesc, prometheus.GaugeValue, float64(nodeGroup.Status.ReadyReplicas), nodeGroup.Cluster, nodeGroup.Name, machineType)
+
+		if nodeGroup.Infrastructure == nil {
+			continue
+		}
+		if nodeGroup.Infrastructure.Min != nil {
+			ch <- prometheus.MustNewConstMetric(nodeGroupMinReplicasDesc, prometheus.GaugeValue, float64(*nodeGroup.Infrastructure.Min), nodeGroup.Cluster, nodeGroup.Name)
+		}
+		if nodeGroup.Infrastructure.Max != nil {
+			ch <- prometheus.MustNewConstMetric(nodeGroupMaxReplicasDesc, prometheus.GaugeValue, float64(*nodeGroup.Infrastructure.Max), nodeGroup.Cluster, nodeGroup.Name)
+		}
+	}
+}
+
+// listClusters lists every page of the clusters, no clusters is an empty list
+func listClusters(k *k8s.Kubernetes) ([]*kaas.Cluster, error) {
+	var clusters []*kaas.Cluster
+	options := k8s.ListOptions{}
+	for {
+		page, next, err := kaas.ListClusters(k, options)
+		if err != nil && !isEmptyResponse(err) {
+			return nil, err
+		}
+		clusters = append(clusters, page...)
+		if next == "" {
+			return clusters, nil
+		}
+		options.Continue = next
+	}
+}
+
+// listNodeGroups lists every page of the node groups of a cluster, no node groups is an empty list
+func listNodeGroups(k *k8s.Kubernetes, clusterName string) ([]*kaas.NodeGroup, error) {
+	var nodeGroups []*kaas.NodeGroup
+	options := k8s.ListOptions{}
+	for {
+		page, next, err := kaas.ListNodeGroups(k, clusterName, options)
+		if err != nil && !isEmptyResponse(err) {
+			return nil, err
+		}
+		nodeGroups = append(nodeGroups, page...)
+		if next == "" {
+			return nodeGroups, nil
+		}
+		options.Continue = next
+	}
+}
+
+func isEmptyResponse(err error) bool {
+	clientErr, ok := err.(*clientError.ClientError)
+	return ok && clientErr.ErrorMessage == clientError.EmptyResponse
+}
@@ -0,0 +1,58 @@
+package fleet
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/test"
+)
+
+func TestCollector(t *testing.T) {
+	int32Ptr := func(value int32) *int32 { return &value }
+
+	cluster := test.NewTestCluster("test-cluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1")
+	machinePool := test.NewTestMachinePool("test-cluster-nodes", "test-cluster", "KopsMachinePool", "test-cluster-nodes", "infrastructure.cluster.x-k8s.io/v1alpha1")
+	machinePool.Spec.Replicas = int32Ptr(3)
+	machinePool.Status.ReadyReplicas = 2
+	kopsMachinePool := test.NewTestKopsMachinePool("test-cluster-nodes", "test-cluster")
+	kopsMachinePool.Spec.KopsInstanceGroupSpec.MinSize = int32Ptr(1)
+	kopsMachinePool.Spec.KopsInstanceGroupSpec.MaxSize = int32Ptr(5)
+
+	collector := NewCollector(&k8s.Kubernetes{K8sAuth: &k8s.Auth{DynamicClient: test.NewK8sFakeDynamicClientWithResources(cluster, machinePool, kopsMachinePool)}})
+
+	// Nothing is exported before the first refresh
+	assert.Equal(t, 0, testutil.CollectAndCount(collector))
+
+	assert.Nil(t, collector.Refresh())
+	expected := `
+# HELP kaas_cluster_info Information about each cluster, always 1
+# TYPE kaas_cluster_info gauge
+kaas_cluster_info{clusterGroup="test-clusters",environment="test",infraprovider="kops",kubeprovider="kops",name="test-cluster",region="us-east-1"} 1
+# HELP kaas_nodegroup_max_replicas Maximum replicas of each node group with autoscaling bounds
+# TYPE kaas_nodegroup_max_replicas gauge
+kaas_nodegroup_max_replicas{cluster="test-cluster",nodegroup="nodes"} 5
+# HELP kaas_nodegroup_min_replicas Minimum replicas of each node group with autoscaling bounds
+# TYPE kaas_nodegroup_min_replicas gauge
+kaas_nodegroup_min_replicas{cluster="test-cluster",nodegroup="nodes"} 1
+# HELP kaas_nodegroup_ready_replicas Ready replicas of each node group
+# TYPE kaas_nodegroup_ready_replicas gauge
+kaas_nodegroup_ready_replicas{cluster="test-cluster",machinetype="m5.xlarge",nodegroup="nodes"} 2
+# HELP kaas_nodegroup_replicas Desired replicas of each node group
+# TYPE kaas_nodegroup_replicas gauge
+kaas_nodegroup_replicas{cluster="test-cluster",machinetype="m5.xlarge",nodegroup="nodes"} 3
+`
+	assert.Nil(t, testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"kaas_cluster_info", "kaas_nodegroup_replicas", "kaas_nodegroup_ready_replicas", "kaas_nodegroup_min_replicas", "kaas_nodegroup_max_replicas"))
+}
+
+func TestCollector_NoValidClusters(t *testing.T) {
+	invalidCluster := test.NewTestCluster("invalid-cluster", "", "", "", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1")
+	collector := NewCollector(&k8s.Kubernetes{K8sAuth: &k8s.Auth{DynamicClient: test.NewK8sFakeDynamicClientWithResources(invalidCluster)}})
+
+	assert.Nil(t, collector.Refresh())
+	assert.Equal(t, 1, testutil.CollectAndCount(collector, "kaas_fleet_last_refresh_timestamp_seconds"))
+	assert.Equal(t, 0, testutil.CollectAndCount(collector, "kaas_cluster_info"))
+}
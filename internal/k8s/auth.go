@@ -2,58 +2,74 @@ package k8s
 
 import (
 	"fmt"
+	"go.uber.org/zap"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
-	"log"
 	"os"
 )
 
 type Auth struct {
 	AuthConfig    *rest.Config
 	DynamicClient dynamic.Interface
+	// Clientset is the typed client used for the core kubernetes resources, like secrets
+	Clientset kubernetes.Interface
 }
 
-func Authenticate() *Auth {
+// Authenticate creates the clients with the Service Account of the pod, or with the local Kubeconfig outside of a cluster
+func Authenticate(logger *zap.Logger) (*Auth, error) {
 	config, err := rest.InClusterConfig()
 	if err != nil {
-		log.Printf("Could not retrieve pod Service Account configuration: %v", err)
-		log.Print("Trying local authentication")
-		return LocalAuthenticate()
+		logger.Info("Could not retrieve pod Service Account configuration, trying local authentication", zap.Error(err))
+		return LocalAuthenticate(logger)
 	}
 
 	client, err := dynamic.NewForConfig(config)
 	if err != nil {
-		log.Fatalf("Could not create client as a pod: %v", err)
+		return nil, fmt.Errorf("could not create client as a pod: %v", err)
 	}
 
-	log.Print("Using local authentication")
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("could not create typed client as a pod: %v", err)
+	}
+
+	logger.Info("Using the pod Service Account authentication")
 	return &Auth{
 		AuthConfig:    config,
-		DynamicClient: client,
-	}
+		DynamicClient: &InstrumentedDynamicClient{Client: client},
+		Clientset:     clientset,
+	}, nil
 }
 
-func LocalAuthenticate() *Auth {
+// LocalAuthenticate creates the clients with the Kubeconfig of KUBECONFIG, or of $HOME/.kube/config when it is unset
+func LocalAuthenticate(logger *zap.Logger) (*Auth, error) {
 	var kubeConfigPath string
 	kubeConfigPath = os.Getenv("KUBECONFIG")
 	if kubeConfigPath == "" {
 		kubeConfigPath = fmt.Sprintf("%s/.kube/config", os.Getenv("HOME"))
-		log.Printf("KUBECONFIG env is unset, using default location in $HOME: %s", kubeConfigPath)
+		logger.Info("KUBECONFIG env is unset, using default location in $HOME", zap.String("kubeconfig", kubeConfigPath))
 	}
 
 	config, err := clientcmd.BuildConfigFromFlags("", kubeConfigPath)
 	if err != nil {
-		log.Fatalf("Could not retrieve Kubeconfig configuration: %v", err)
+		return nil, fmt.Errorf("could not retrieve Kubeconfig configuration: %v", err)
 	}
 
 	client, err := dynamic.NewForConfig(config)
 	if err != nil {
-		log.Fatalf("Could not create client using Kubeconfig: %v", err)
+		return nil, fmt.Errorf("could not create client using Kubeconfig: %v", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("could not create typed client using Kubeconfig: %v", err)
 	}
 
 	return &Auth{
 		AuthConfig:    config,
-		DynamicClient: client,
-	}
+		DynamicClient: &InstrumentedDynamicClient{Client: client},
+		Clientset:     clientset,
+	}, nil
 }
@@ -0,0 +1,128 @@
+package k8s
+
+import (
+	"fmt"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"time"
+)
+
+// CacheResyncPeriod is how often the informers replay every cached resource, in addition to the watch events
+const CacheResyncPeriod = 10 * time.Minute
+
+// CachedResources are the resources served from the informer cache, every other resource is always read from the Kubernetes API
+var CachedResources = []schema.GroupVersionResource{
+	ClusterResourceSchemaV1beta1,
+	MachinePoolSchemaV1beta1,
+	MachineDeploymentSchemaV1beta1,
+	KopsMachinePoolSchemaV1alpha1,
+	KopsControlPlaneSchemaV1alpha1,
+	KubeadmControlPlaneSchemaV1beta1,
+}
+
+// Cache keeps the cached resources in memory using shared dynamic informers watching all namespaces
+type Cache struct {
+	factory   dynamicinformer.DynamicSharedInformerFactory
+	informers map[schema.GroupVersionResource]informers.GenericInformer
+}
+
+// NewCache creates the informers of all CachedResources, they only start watching the Kubernetes API after Start is called
+func NewCache(client dynamic.Interface, resyncPeriod time.Duration) *Cache {
+	return NewCacheForResources(client, CachedResources, resyncPeriod)
+}
+
+// NewCacheForResources creates the informers of some of the CachedResources, the other resources are read from the Kubernetes API
+func NewCacheForResources(client dynamic.Interface, resources []schema.GroupVersionResource, resyncPeriod time.Duration) *Cache {
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(client, resyncPeriod)
+
+	cache := &Cache{
+		factory:   factory,
+		informers: map[schema.GroupVersionResource]informers.GenericInformer{},
+	}
+	for _, resource := range resources {
+		cache.informers[resource] = factory.ForResource(resource)
+	}
+
+	return cache
+}
+
+// Start starts all informers in background, they stop when stopCh is closed
+func (c *Cache) Start(stopCh <-chan struct{}) {
+	c.factory.Start(stopCh)
+}
+
+// WaitForCacheSync blocks until all informers have synced or stopCh is closed, returning whether they have synced
+func (c *Cache) WaitForCacheSync(stopCh <-chan struct{}) bool {
+	for _, synced := range c.factory.WaitForCacheSync(stopCh) {
+		if !synced {
+			return false
+		}
+	}
+	return true
+}
+
+// HasSynced returns whether all informers have done their initial list
+func (c *Cache) HasSynced() bool {
+	for _, informer := range c.informers {
+		if !informer.Informer().HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// informer returns the informer of a resource only if the resource is cached and its informer has synced
+func (c *Cache) informer(resource schema.GroupVersionResource) (informers.GenericInformer, bool) {
+	informer, ok := c.informers[resource]
+	if !ok || !informer.Informer().HasSynced() {
+		return nil, false
+	}
+	return informer, true
+}
+
+// ServedResources splits the resources in the ones served by the Kubernetes API and the ones it doesn't serve, like the
+// resources of CRDs that aren't installed. The informers of resources that aren't served would never sync.
+func ServedResources(client discovery.DiscoveryInterface, resources []schema.GroupVersionResource) ([]schema.GroupVersionResource, []schema.GroupVersionResource, error) {
+	groups, err := client.ServerGroups()
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not discover the API groups: %v", err)
+	}
+	servedGroupVersions := map[string]bool{}
+	for _, group := range groups.Groups {
+		for _, groupVersion := range group.Versions {
+			servedGroupVersions[groupVersion.GroupVersion] = true
+		}
+	}
+
+	var served, unserved []schema.GroupVersionResource
+	servedResources := map[string]map[string]bool{}
+	for _, resource := range resources {
+		groupVersion := resource.GroupVersion().String()
+		if !servedGroupVersions[groupVersion] {
+			unserved = append(unserved, resource)
+			continue
+		}
+
+		if _, ok := servedResources[groupVersion]; !ok {
+			resourceList, err := client.ServerResourcesForGroupVersion(groupVersion)
+			if err != nil {
+				return nil, nil, fmt.Errorf("could not discover the resources of %s: %v", groupVersion, err)
+			}
+			servedResources[groupVersion] = map[string]bool{}
+			for _, apiResource := range resourceList.APIResources {
+				servedResources[groupVersion][apiResource.Name] = true
+			}
+		}
+
+		if servedResources[groupVersion][resource.Resource] {
+			served = append(served, resource)
+		} else {
+			unserved = append(unserved, resource)
+		}
+	}
+
+	return served, unserved, nil
+}
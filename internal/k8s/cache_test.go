@@ -0,0 +1,190 @@
+package k8s
+
+import (
+	"github.com/topfreegames/kaas-management-api/test"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"log"
+	"reflect"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterapiexpv1beta1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	"testing"
+	"time"
+)
+
+// newTestCachedKubernetes returns a Kubernetes instance with a synced cache of the resources and a dynamic client without any resource,
+// so the tests only succeed when the reads are served from the cache
+func newTestCachedKubernetes(t *testing.T, resources ...runtime.Object) *Kubernetes {
+	stopCh := make(chan struct{})
+	t.Cleanup(func() { close(stopCh) })
+
+	cache := NewCache(test.NewK8sFakeDynamicClientWithResources(resources...), time.Minute)
+	cache.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh) {
+		t.Fatal("Cache didn't sync")
+	}
+
+	return &Kubernetes{
+		K8sAuth: &Auth{DynamicClient: test.NewK8sFakeDynamicClient()},
+		Cache:   cache,
+	}
+}
+
+func Test_Cache_GetCluster_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:                "GetCluster should return Success for one cluster from the cache",
+			ExpectedSuccess:     test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				ResourceName: "testcluster",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestCluster("testcluster2", "testcluster-kops-cp2", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster2", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestMachinePool("TestMachinePool1", "testcluster", "KopsMachinePool", "TestKopsMachinePool1", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestMachineDeployment("TestMachineDeployment1", "testcluster2", "AWSMachineTemplate", "TestAWSMachineTemplate1", "infrastructure.cluster.x-k8s.io/v1beta1"),
+				test.NewTestKopsMachinePool("TestKopsMachinePool1", "testcluster"),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		expectedCluster, ok := testCase.ExpectedSuccess.(*clusterapiv1beta1.Cluster)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to *clusterapiv1beta1.Cluster", testCase.Name)
+		}
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			k := newTestCachedKubernetes(t, testCase.K8sTestResources...)
+			response, err := k.GetCluster(request.ResourceName)
+			assert.NilError(t, err)
+			assert.Assert(t, reflect.DeepEqual(expectedCluster, response))
+		})
+	}
+}
+
+func Test_Cache_GetMachineDeployment_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "GetMachineDeployment should return Error for non-existent MachineDeployment in the cache",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The requested MachineDeployment nonexistent was not found for the cluster testcluster!",
+				ErrorMessage:         clientError.ResourceNotFound,
+			},
+			Request: &test.K8sRequest{
+				Cluster:      "testcluster",
+				ResourceName: "nonexistent",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestMachinePool("TestMachinePool1", "testcluster", "KopsMachinePool", "TestKopsMachinePool1", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestMachineDeployment("TestMachineDeployment1", "testcluster", "AWSMachineTemplate", "TestAWSMachineTemplate1", "infrastructure.cluster.x-k8s.io/v1beta1"),
+				test.NewTestKopsMachinePool("TestKopsMachinePool1", "testcluster"),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			k := newTestCachedKubernetes(t, testCase.K8sTestResources...)
+			_, err := k.GetMachineDeployment(request.Cluster, request.ResourceName)
+			assert.ErrorContains(t, err, testCase.ExpectedClientError.Error())
+			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
+		})
+	}
+}
+
+func Test_Cache_ListMachinePool_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name: "ListMachinePool should return Success for two MachinePools sorted by name from the cache",
+			ExpectedSuccess: &clusterapiexpv1beta1.MachinePoolList{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "MachinePoolList",
+					APIVersion: "cluster.x-k8s.io/v1beta1",
+				},
+				ListMeta: metav1.ListMeta{},
+				Items: []clusterapiexpv1beta1.MachinePool{
+					*test.NewTestMachinePool("TestMachinePool1", "TestCluster1", "KopsMachinePool", "TestKopsMachinePool1", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+					*test.NewTestMachinePool("TestMachinePool2", "TestCluster1", "KopsMachinePool", "TestKopsMachinePool2", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				},
+			},
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				Cluster: "TestCluster1",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("TestCluster1", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestMachinePool("TestMachinePool2", "TestCluster1", "KopsMachinePool", "TestKopsMachinePool2", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestMachinePool("TestMachinePool1", "TestCluster1", "KopsMachinePool", "TestKopsMachinePool1", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestMachinePool("TestMachinePool3", "TestCluster2", "KopsMachinePool", "TestKopsMachinePool3", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestMachineDeployment("TestMachineDeployment1", "TestCluster2", "AWSMachineTemplate", "TestAWSMachineTemplate1", "infrastructure.cluster.x-k8s.io/v1beta1"),
+				test.NewTestKopsMachinePool("TestKopsMachinePool1", "TestCluster1"),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		expectedList, ok := testCase.ExpectedSuccess.(*clusterapiexpv1beta1.MachinePoolList)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to *clusterapiexpv1beta1.MachinePoolList", testCase.Name)
+		}
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			k := newTestCachedKubernetes(t, testCase.K8sTestResources...)
+			response, err := k.ListMachinePool(request.Cluster, ListOptions{})
+			assert.NilError(t, err)
+			assert.Assert(t, reflect.DeepEqual(expectedList, response))
+		})
+	}
+}
+
+func Test_Kubernetes_IsReady(t *testing.T) {
+	k := &Kubernetes{K8sAuth: &Auth{DynamicClient: test.NewK8sFakeDynamicClient()}}
+	assert.Assert(t, k.IsReady(), "an instance without cache should be ready")
+
+	k.Cache = NewCache(k.K8sAuth.DynamicClient, time.Minute)
+	assert.Assert(t, !k.IsReady(), "an instance should not be ready before its cache has synced")
+
+	k = newTestCachedKubernetes(t,
+		test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+		test.NewTestMachinePool("TestMachinePool1", "testcluster", "KopsMachinePool", "TestKopsMachinePool1", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+		test.NewTestMachineDeployment("TestMachineDeployment1", "testcluster", "AWSMachineTemplate", "TestAWSMachineTemplate1", "infrastructure.cluster.x-k8s.io/v1beta1"),
+		test.NewTestKopsMachinePool("TestKopsMachinePool1", "testcluster"),
+	)
+	assert.Assert(t, k.IsReady(), "an instance should be ready after its cache has synced")
+	assert.Assert(t, k.Uncached().Cache == nil)
+	assert.Assert(t, k.Cache != nil, "Uncached should not change the original instance")
+}
+
+func Test_ServedResources(t *testing.T) {
+	discovery, ok := test.NewK8sFakeClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	if !ok {
+		t.Fatal("Failed converting the discovery client of the fake clientset to *fakediscovery.FakeDiscovery")
+	}
+	discovery.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: ClusterResourceSchemaV1beta1.GroupVersion().String(),
+			APIResources: []metav1.APIResource{
+				{Name: ClusterResourceSchemaV1beta1.Resource},
+				{Name: MachineDeploymentSchemaV1beta1.Resource},
+			},
+		},
+	}
+
+	served, unserved, err := ServedResources(discovery, CachedResources)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []schema.GroupVersionResource{ClusterResourceSchemaV1beta1, MachineDeploymentSchemaV1beta1}, served)
+	assert.DeepEqual(t, []schema.GroupVersionResource{MachinePoolSchemaV1beta1, KopsMachinePoolSchemaV1alpha1, KopsControlPlaneSchemaV1alpha1, KubeadmControlPlaneSchemaV1beta1}, unserved)
+}
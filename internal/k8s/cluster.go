@@ -1,32 +1,21 @@
 package k8s
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/topfreegames/kaas-management-api/util/clientError"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
-	"strings"
 )
 
-func GetClusterNamespace(clusterName string) string {
-
-	prefix := "kubernetes"
-	clusterNamespace := strings.ReplaceAll(clusterName, ".", "-")
-	namespace := fmt.Sprintf("%s-%s", prefix, clusterNamespace)
-	return namespace
-}
-
 // GetCluster gets a cluster-API cluster CR by name from the Kubernetes API. We follow the standard of one cluster per namespace.
 func (k Kubernetes) GetCluster(clusterName string) (*clusterapiv1beta1.Cluster, error) {
-	client := k.K8sAuth.DynamicClient
-
-	resource := client.Resource(ClusterResourceSchemaV1beta1)
-
-	namespace := GetClusterNamespace(clusterName)
-	clustersRaw, err := resource.Namespace(namespace).Get(context.TODO(), clusterName, metav1.GetOptions{})
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	clustersRaw, err := k.GetResource(ClusterResourceSchemaV1beta1, namespace, clusterName)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			return nil, clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("The requested cluster %s was not found in namespace %s!", clusterName, namespace))
@@ -50,13 +39,14 @@ func (k Kubernetes) GetCluster(clusterName string) (*clusterapiv1beta1.Cluster,
 	return &cluster, nil
 }
 
-// ListClusters list all cluster-api clusters CR in the kubernetes API and returns as cluster-api struct. We follow the standard of one cluster per namespace
-func (k Kubernetes) ListClusters() (*clusterapiv1beta1.ClusterList, error) {
-	client := k.K8sAuth.DynamicClient
-
-	clustersRaw, err := client.Resource(ClusterResourceSchemaV1beta1).List(context.TODO(), metav1.ListOptions{})
+// ListClusters list all cluster-api clusters CR in the kubernetes API and returns as cluster-api struct. We follow the standard of one cluster per namespace.
+// The options limit the clusters returned to one page, with the token of the next page in the list continue field.
+func (k Kubernetes) ListClusters(options ListOptions) (*clusterapiv1beta1.ClusterList, error) {
+	clustersRaw, err := k.ListResources(ClusterResourceSchemaV1beta1, "", options)
 
-	if errors.IsNotFound(err) {
+	if clientErr, isClientErr := err.(*clientError.ClientError); isClientErr {
+		return nil, clientErr
+	} else if errors.IsNotFound(err) {
 		return nil, clientError.NewClientError(err, clientError.ResourceNotFound, "could not find any cluster in the Kubernetes API")
 	} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
 		return nil, fmt.Errorf("Error getting Cluster from Server API %s\n", statusError.ErrStatus.Message)
@@ -82,3 +72,67 @@ func (k Kubernetes) ListClusters() (*clusterapiv1beta1.ClusterList, error) {
 
 	return &clusters, nil
 }
+
+// CreateCluster creates a cluster-API cluster CR in the namespace of the cluster and returns the created resource
+func (k Kubernetes) CreateCluster(cluster *clusterapiv1beta1.Cluster) (*clusterapiv1beta1.Cluster, error) {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(ClusterResourceSchemaV1beta1)
+
+	namespace, err := k.ClusterNamespace(cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+	clusterRaw, err := ToUnstructured(cluster)
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidResource, fmt.Sprintf("Cluster %s could not be converted to a Kubernetes resource", cluster.Name))
+	}
+
+	clusterRaw.SetNamespace(namespace)
+	createdRaw, err := resource.Namespace(namespace).Create(k.Context(), clusterRaw, metav1.CreateOptions{})
+	if err != nil {
+		if errors.IsAlreadyExists(err) {
+			return nil, clientError.NewClientError(err, clientError.ResourceAlreadyExists, fmt.Sprintf("The cluster %s already exists in namespace %s!", cluster.Name, namespace))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return nil, fmt.Errorf("Error creating Cluster in Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return nil, fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	var createdCluster clusterapiv1beta1.Cluster
+	createdRawJson, err := createdRaw.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("could not Marshal Cluster response: %v", err)
+	}
+
+	err = json.Unmarshal(createdRawJson, &createdCluster)
+	if err != nil {
+		return nil, fmt.Errorf("could not Unmarshal Cluster JSON into clusterAPI: %v", err)
+	}
+
+	return &createdCluster, nil
+}
+
+// DeleteCluster deletes a cluster-API cluster CR, the resources owned by the cluster are deleted by the garbage collector
+func (k Kubernetes) DeleteCluster(clusterName string) error {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(ClusterResourceSchemaV1beta1)
+
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return err
+	}
+	propagationPolicy := metav1.DeletePropagationBackground
+	err = resource.Namespace(namespace).Delete(k.Context(), clusterName, metav1.DeleteOptions{PropagationPolicy: &propagationPolicy})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("The requested cluster %s was not found in namespace %s!", clusterName, namespace))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return fmt.Errorf("Error deleting Cluster from Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	return nil
+}
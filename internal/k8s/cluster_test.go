@@ -140,9 +140,160 @@ func Test_ListClusters_Success(t *testing.T) {
 		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
 
 		t.Run(testCase.Name, func(t *testing.T) {
-			response, err := k.ListClusters()
+			response, err := k.ListClusters(ListOptions{})
 			assert.NilError(t, err)
 			assert.Assert(t, reflect.DeepEqual(expectedInfra, response))
 		})
 	}
 }
+
+func Test_CreateCluster_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:                "CreateCluster should return Success for a new cluster",
+			ExpectedSuccess:     test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				ResourceName: "testcluster",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster2", "testcluster-kops-cp2", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster2", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &Kubernetes{K8sAuth: &Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		expectedCluster, ok := testCase.ExpectedSuccess.(*clusterapiv1beta1.Cluster)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to *clusterapiv1beta1.Cluster", testCase.Name)
+		}
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			response, err := k.CreateCluster(expectedCluster.DeepCopy())
+			assert.NilError(t, err)
+			assert.Assert(t, reflect.DeepEqual(expectedCluster, response))
+
+			createdCluster, err := k.GetCluster(request.ResourceName)
+			assert.NilError(t, err)
+			assert.Assert(t, reflect.DeepEqual(expectedCluster, createdCluster))
+		})
+	}
+}
+
+func Test_CreateCluster_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "CreateCluster should return Error for an existent cluster",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The cluster testcluster already exists in namespace kubernetes-testcluster!",
+				ErrorMessage:         clientError.ResourceAlreadyExists,
+			},
+			Request: &test.K8sRequest{
+				ResourceName: "testcluster",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &Kubernetes{K8sAuth: &Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			cluster := test.NewTestCluster(request.ResourceName, "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1")
+			_, err := k.CreateCluster(cluster)
+			assert.ErrorContains(t, err, testCase.ExpectedClientError.Error())
+			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
+		})
+	}
+}
+
+func Test_DeleteCluster_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:                "DeleteCluster should return Success for an existent cluster",
+			ExpectedSuccess:     nil,
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				ResourceName: "testcluster",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestCluster("testcluster2", "testcluster-kops-cp2", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster2", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &Kubernetes{K8sAuth: &Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			err := k.DeleteCluster(request.ResourceName)
+			assert.NilError(t, err)
+
+			_, err = k.GetCluster(request.ResourceName)
+			assert.Assert(t, test.AssertClientError(err, &clientError.ClientError{
+				ErrorMessage:         clientError.ResourceNotFound,
+				ErrorDetailedMessage: "The requested cluster testcluster was not found in namespace kubernetes-testcluster!",
+			}))
+		})
+	}
+}
+
+func Test_DeleteCluster_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "DeleteCluster should return Error for non-existent cluster",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The requested cluster nonexistentcluster was not found in namespace kubernetes-nonexistentcluster!",
+				ErrorMessage:         clientError.ResourceNotFound,
+			},
+			Request: &test.K8sRequest{
+				ResourceName: "nonexistentcluster",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &Kubernetes{K8sAuth: &Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			err := k.DeleteCluster(request.ResourceName)
+			assert.ErrorContains(t, err, testCase.ExpectedClientError.Error())
+			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package k8s
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"os"
+	"sync"
+	"time"
+)
+
+// Environment variables with the ConfigMap configuring the API, by default the ConfigMap DefaultConfigName in the namespace DefaultConfigNamespace
+const (
+	ConfigNamespaceEnv = "CONFIG_NAMESPACE"
+	ConfigNameEnv      = "CONFIG_NAME"
+)
+
+const (
+	DefaultConfigNamespace = "manager"
+	DefaultConfigName      = "kaas-management-api"
+)
+
+// ConfigWatcher keeps the latest version of the ConfigMap configuring the API in memory, so its changes apply without a restart
+type ConfigWatcher struct {
+	namespace string
+	name      string
+	factory   informers.SharedInformerFactory
+	informer  cache.SharedIndexInformer
+	// parsed are the values parsed from the keys of the ConfigMap, parsed again by the informer event handler when they change
+	mutex  sync.RWMutex
+	parsed map[string]*parsedConfigKey
+}
+
+// ConfigParser parses the value of a key of the ConfigMap, which is empty when the key or the ConfigMap don't exist.
+// It receives the previous parsed value, nil the first time, so an invalid value can keep it.
+type ConfigParser func(value string, previous interface{}) interface{}
+
+// parsedConfigKey is the value parsed from a key of the ConfigMap and the raw value it was parsed from
+type parsedConfigKey struct {
+	parse ConfigParser
+	raw   string
+	value interface{}
+}
+
+// GetConfigMapLocation returns the namespace and name of the ConfigMap configuring the API from the environment
+func GetConfigMapLocation() (string, string) {
+	namespace := os.Getenv(ConfigNamespaceEnv)
+	if namespace == "" {
+		namespace = DefaultConfigNamespace
+	}
+	name := os.Getenv(ConfigNameEnv)
+	if name == "" {
+		name = DefaultConfigName
+	}
+	return namespace, name
+}
+
+// NewConfigWatcher creates an informer watching only the ConfigMap, it only starts watching the Kubernetes API after Start is called
+func NewConfigWatcher(clientset kubernetes.Interface, namespace string, name string, resyncPeriod time.Duration) *ConfigWatcher {
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, resyncPeriod,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}),
+	)
+
+	c := &ConfigWatcher{
+		namespace: namespace,
+		name:      name,
+		factory:   factory,
+		informer:  factory.Core().V1().ConfigMaps().Informer(),
+		parsed:    map[string]*parsedConfigKey{},
+	}
+	c.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(object interface{}) { c.parseChanges(object) },
+		UpdateFunc: func(_, object interface{}) { c.parseChanges(object) },
+		DeleteFunc: func(interface{}) { c.parseChanges(nil) },
+	})
+	return c
+}
+
+// Parsed returns the value parsed from a key of the ConfigMap. The key is parsed the first time it is read,
+// then only again when its value changes.
+func (c *ConfigWatcher) Parsed(key string, parse ConfigParser) interface{} {
+	c.mutex.RLock()
+	parsed, ok := c.parsed[key]
+	var value interface{}
+	if ok {
+		value = parsed.value
+	}
+	c.mutex.RUnlock()
+	if ok {
+		return value
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if parsed, ok := c.parsed[key]; ok {
+		return parsed.value
+	}
+
+	// The changes received after reading the data are parsed by the event handler, which waits for the lock
+	data, _ := c.Data()
+	parsed = &parsedConfigKey{parse: parse, raw: data[key], value: parse(data[key], nil)}
+	c.parsed[key] = parsed
+	return parsed.value
+}
+
+// parseChanges parses again the keys whose value changed in a new version of the ConfigMap, nil when it was deleted
+func (c *ConfigWatcher) parseChanges(object interface{}) {
+	var data map[string]string
+	if configMap, ok := object.(*corev1.ConfigMap); ok {
+		data = configMap.Data
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for key, parsed := range c.parsed {
+		if data[key] == parsed.raw {
+			continue
+		}
+		parsed.raw = data[key]
+		parsed.value = parsed.parse(parsed.raw, parsed.value)
+	}
+}
+
+// Start starts the informer in background, it stops when stopCh is closed
+func (c *ConfigWatcher) Start(stopCh <-chan struct{}) {
+	c.factory.Start(stopCh)
+}
+
+// WaitForCacheSync blocks until the informer has synced or stopCh is closed, returning whether it has synced
+func (c *ConfigWatcher) WaitForCacheSync(stopCh <-chan struct{}) bool {
+	return cache.WaitForCacheSync(stopCh, c.informer.HasSynced)
+}
+
+// HasSynced returns whether the informer has done its initial list
+func (c *ConfigWatcher) HasSynced() bool {
+	return c.informer.HasSynced()
+}
+
+// Data returns the data of the ConfigMap, or false when it doesn't exist or the informer hasn't synced
+func (c *ConfigWatcher) Data() (map[string]string, bool) {
+	if !c.informer.HasSynced() {
+		return nil, false
+	}
+
+	object, exists, err := c.informer.GetStore().GetByKey(c.namespace + "/" + c.name)
+	if err != nil || !exists {
+		return nil, false
+	}
+
+	configMap, ok := object.(*corev1.ConfigMap)
+	if !ok {
+		return nil, false
+	}
+	return configMap.Data, true
+}
@@ -0,0 +1,113 @@
+package k8s
+
+import (
+	"context"
+	"github.com/topfreegames/kaas-management-api/test"
+	"gotest.tools/assert"
+	"gotest.tools/poll"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_ConfigWatcher_Data(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	clientset := test.NewK8sFakeClientset(
+		test.NewTestConfigMap("manager", "kaas-management-api", map[string]string{"key": "value"}),
+		test.NewTestConfigMap("manager", "another-config", map[string]string{"key": "another-value"}),
+	)
+	k := &Kubernetes{K8sAuth: &Auth{Clientset: clientset}}
+	k.Config = NewConfigWatcher(clientset, "manager", "kaas-management-api", time.Minute)
+
+	_, ok := k.GetConfigData()
+	assert.Assert(t, !ok, "the data should not be returned before the watcher has synced")
+	assert.Assert(t, !k.IsReady(), "an instance should not be ready before its configuration has synced")
+
+	k.Config.Start(stopCh)
+	assert.Assert(t, k.Config.WaitForCacheSync(stopCh))
+	assert.Assert(t, k.IsReady())
+
+	data, ok := k.GetConfigData()
+	assert.Assert(t, ok)
+	assert.Assert(t, reflect.DeepEqual(map[string]string{"key": "value"}, data))
+
+	updated := test.NewTestConfigMap("manager", "kaas-management-api", map[string]string{"key": "updated"})
+	_, err := clientset.CoreV1().ConfigMaps("manager").Update(context.TODO(), updated, metav1.UpdateOptions{})
+	assert.NilError(t, err)
+
+	poll.WaitOn(t, func(t poll.LogT) poll.Result {
+		data, _ := k.GetConfigData()
+		if data["key"] != "updated" {
+			return poll.Continue("the ConfigMap update wasn't received, the data is %v", data)
+		}
+		return poll.Success()
+	}, poll.WithTimeout(5*time.Second), poll.WithDelay(10*time.Millisecond))
+
+	err = clientset.CoreV1().ConfigMaps("manager").Delete(context.TODO(), "kaas-management-api", metav1.DeleteOptions{})
+	assert.NilError(t, err)
+
+	poll.WaitOn(t, func(t poll.LogT) poll.Result {
+		if _, ok := k.GetConfigData(); ok {
+			return poll.Continue("the ConfigMap deletion wasn't received")
+		}
+		return poll.Success()
+	}, poll.WithTimeout(5*time.Second), poll.WithDelay(10*time.Millisecond))
+}
+
+func Test_ConfigWatcher_Parsed(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	clientset := test.NewK8sFakeClientset(test.NewTestConfigMap("manager", "kaas-management-api", map[string]string{"key": "value", "other": "value"}))
+	config := NewConfigWatcher(clientset, "manager", "kaas-management-api", time.Minute)
+	config.Start(stopCh)
+	assert.Assert(t, config.WaitForCacheSync(stopCh))
+
+	var mutex sync.Mutex
+	var parsedValues []string
+	parse := func(value string, previous interface{}) interface{} {
+		mutex.Lock()
+		defer mutex.Unlock()
+		parsedValues = append(parsedValues, value)
+		return "parsed " + value
+	}
+	parsedTimes := func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(parsedValues)
+	}
+	waitParsed := func(expected string) {
+		poll.WaitOn(t, func(t poll.LogT) poll.Result {
+			if value := config.Parsed("key", parse); value != expected {
+				return poll.Continue("the parsed value is %v", value)
+			}
+			return poll.Success()
+		}, poll.WithTimeout(5*time.Second), poll.WithDelay(10*time.Millisecond))
+	}
+
+	assert.Equal(t, "parsed value", config.Parsed("key", parse))
+	assert.Equal(t, "parsed value", config.Parsed("key", parse))
+	assert.Equal(t, 1, parsedTimes(), "the key should only be parsed again when it changes")
+
+	// A change to another key doesn't parse the key again
+	updated := test.NewTestConfigMap("manager", "kaas-management-api", map[string]string{"key": "value", "other": "updated"})
+	_, err := clientset.CoreV1().ConfigMaps("manager").Update(context.TODO(), updated, metav1.UpdateOptions{})
+	assert.NilError(t, err)
+
+	updated = test.NewTestConfigMap("manager", "kaas-management-api", map[string]string{"key": "updated", "other": "updated"})
+	_, err = clientset.CoreV1().ConfigMaps("manager").Update(context.TODO(), updated, metav1.UpdateOptions{})
+	assert.NilError(t, err)
+	waitParsed("parsed updated")
+
+	err = clientset.CoreV1().ConfigMaps("manager").Delete(context.TODO(), "kaas-management-api", metav1.DeleteOptions{})
+	assert.NilError(t, err)
+	waitParsed("parsed ")
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Assert(t, reflect.DeepEqual([]string{"value", "updated", ""}, parsedValues), "got %v", parsedValues)
+}
@@ -0,0 +1,38 @@
+package k8s
+
+import (
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateEvent creates an event in its namespace
+func (k Kubernetes) CreateEvent(event *corev1.Event) error {
+	client := k.K8sAuth.Clientset
+
+	_, err := client.CoreV1().Events(event.Namespace).Create(k.Context(), event, metav1.CreateOptions{})
+	if err != nil {
+		if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return fmt.Errorf("Error creating Event in Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	return nil
+}
+
+// ListEvents lists the events matching the label selector in a namespace, or in every namespace when it's empty
+func (k Kubernetes) ListEvents(namespace string, labelSelector string) ([]corev1.Event, error) {
+	client := k.K8sAuth.Clientset
+
+	events, err := client.CoreV1().Events(namespace).List(k.Context(), metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return nil, fmt.Errorf("Error listing Events from Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return nil, fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	return events.Items, nil
+}
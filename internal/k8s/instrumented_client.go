@@ -0,0 +1,137 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"github.com/topfreegames/kaas-management-api/internal/metrics"
+	"github.com/topfreegames/kaas-management-api/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"time"
+)
+
+// InstrumentedDynamicClient is a dynamic client observing the latency and errors of its calls by resource and verb in the metrics,
+// and tracing each call in a span child of the span in its context
+type InstrumentedDynamicClient struct {
+	Client dynamic.Interface
+}
+
+func (c *InstrumentedDynamicClient) Resource(resource schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	client := c.Client.Resource(resource)
+	return &instrumentedNamespaceableResource{instrumentedResource: instrumentedResource{client: client, resource: resource}, namespaceable: client}
+}
+
+type instrumentedNamespaceableResource struct {
+	instrumentedResource
+	namespaceable dynamic.NamespaceableResourceInterface
+}
+
+func (r *instrumentedNamespaceableResource) Namespace(namespace string) dynamic.ResourceInterface {
+	return &instrumentedResource{client: r.namespaceable.Namespace(namespace), resource: r.resource, namespace: namespace}
+}
+
+type instrumentedResource struct {
+	client    dynamic.ResourceInterface
+	resource  schema.GroupVersionResource
+	namespace string
+}
+
+// start starts the span of a call and returns the function ending it, which records the latency of the call and the reason of its error
+func (r *instrumentedResource) start(ctx context.Context, verb string) (context.Context, func(err error)) {
+	start := time.Now()
+	attributes := []attribute.KeyValue{
+		attribute.String("k8s.group", r.resource.Group),
+		attribute.String("k8s.version", r.resource.Version),
+		attribute.String("k8s.resource", r.resource.Resource),
+		attribute.String("k8s.verb", verb),
+	}
+	if r.namespace != "" {
+		attributes = append(attributes, attribute.String("k8s.namespace", r.namespace))
+	}
+	ctx, span := tracing.Tracer().Start(ctx, fmt.Sprintf("Kubernetes %s %s", verb, r.resource.Resource),
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes...))
+
+	return ctx, func(err error) {
+		var reason string
+		if err != nil {
+			reason = string(errors.ReasonForError(err))
+			if reason == "" {
+				reason = string(metav1.StatusReasonUnknown)
+			}
+			span.SetAttributes(attribute.String("k8s.reason", reason))
+		}
+		metrics.ObserveKubernetesRequest(r.resource, verb, time.Since(start), reason)
+		tracing.End(span, err)
+	}
+}
+
+func (r *instrumentedResource) Create(ctx context.Context, obj *unstructured.Unstructured, options metav1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	ctx, end := r.start(ctx, "create")
+	result, err := r.client.Create(ctx, obj, options, subresources...)
+	end(err)
+	return result, err
+}
+
+func (r *instrumentedResource) Update(ctx context.Context, obj *unstructured.Unstructured, options metav1.UpdateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	ctx, end := r.start(ctx, "update")
+	result, err := r.client.Update(ctx, obj, options, subresources...)
+	end(err)
+	return result, err
+}
+
+func (r *instrumentedResource) UpdateStatus(ctx context.Context, obj *unstructured.Unstructured, options metav1.UpdateOptions) (*unstructured.Unstructured, error) {
+	ctx, end := r.start(ctx, "updatestatus")
+	result, err := r.client.UpdateStatus(ctx, obj, options)
+	end(err)
+	return result, err
+}
+
+func (r *instrumentedResource) Delete(ctx context.Context, name string, options metav1.DeleteOptions, subresources ...string) error {
+	ctx, end := r.start(ctx, "delete")
+	err := r.client.Delete(ctx, name, options, subresources...)
+	end(err)
+	return err
+}
+
+func (r *instrumentedResource) DeleteCollection(ctx context.Context, options metav1.DeleteOptions, listOptions metav1.ListOptions) error {
+	ctx, end := r.start(ctx, "deletecollection")
+	err := r.client.DeleteCollection(ctx, options, listOptions)
+	end(err)
+	return err
+}
+
+func (r *instrumentedResource) Get(ctx context.Context, name string, options metav1.GetOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	ctx, end := r.start(ctx, "get")
+	result, err := r.client.Get(ctx, name, options, subresources...)
+	end(err)
+	return result, err
+}
+
+func (r *instrumentedResource) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	ctx, end := r.start(ctx, "list")
+	result, err := r.client.List(ctx, opts)
+	end(err)
+	return result, err
+}
+
+// Watch only observes and traces opening the watch
+func (r *instrumentedResource) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	ctx, end := r.start(ctx, "watch")
+	result, err := r.client.Watch(ctx, opts)
+	end(err)
+	return result, err
+}
+
+func (r *instrumentedResource) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	ctx, end := r.start(ctx, "patch")
+	result, err := r.client.Patch(ctx, name, pt, data, options, subresources...)
+	end(err)
+	return result, err
+}
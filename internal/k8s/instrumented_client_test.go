@@ -0,0 +1,87 @@
+package k8s
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/topfreegames/kaas-management-api/internal/metrics"
+	"github.com/topfreegames/kaas-management-api/test"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_InstrumentedDynamicClient(t *testing.T) {
+	cluster := test.NewTestCluster("test-cluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1")
+	client := &InstrumentedDynamicClient{Client: test.NewK8sFakeDynamicClientWithResources(cluster)}
+
+	_, err := client.Resource(ClusterResourceSchemaV1beta1).Namespace(cluster.Namespace).Get(context.TODO(), "test-cluster", metav1.GetOptions{})
+	assert.NilError(t, err)
+	_, err = client.Resource(ClusterResourceSchemaV1beta1).Namespace(cluster.Namespace).Get(context.TODO(), "nonexistent", metav1.GetOptions{})
+	assert.ErrorContains(t, err, "not found")
+	_, err = client.Resource(ClusterResourceSchemaV1beta1).List(context.TODO(), metav1.ListOptions{})
+	assert.NilError(t, err)
+
+	expectedErrors := `
+# HELP kaas_management_api_kubernetes_request_errors_total Number of failed Kubernetes API calls by group, version, resource, verb and the reason of the status error
+# TYPE kaas_management_api_kubernetes_request_errors_total counter
+kaas_management_api_kubernetes_request_errors_total{group="cluster.x-k8s.io",reason="NotFound",resource="clusters",verb="get",version="v1beta1"} 1
+`
+	assert.NilError(t, testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expectedErrors), "kaas_management_api_kubernetes_request_errors_total"))
+
+	metricFamilies, err := metrics.Registry.Gather()
+	assert.NilError(t, err)
+	observations := map[string]uint64{}
+	for _, metricFamily := range metricFamilies {
+		if metricFamily.GetName() != "kaas_management_api_kubernetes_request_duration_seconds" {
+			continue
+		}
+		for _, metric := range metricFamily.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "verb" {
+					observations[label.GetValue()] = metric.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+	assert.DeepEqual(t, map[string]uint64{"get": 2, "list": 1}, observations)
+}
+
+func Test_InstrumentedDynamicClient_Tracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+
+	cluster := test.NewTestCluster("test-cluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1")
+	client := &InstrumentedDynamicClient{Client: test.NewK8sFakeDynamicClientWithResources(cluster)}
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	_, err := client.Resource(ClusterResourceSchemaV1beta1).Namespace(cluster.Namespace).Get(ctx, "nonexistent", metav1.GetOptions{})
+	assert.ErrorContains(t, err, "not found")
+	parent.End()
+
+	spans := exporter.GetSpans()
+	assert.Equal(t, 2, len(spans))
+	span := spans[0]
+	assert.Equal(t, "Kubernetes get clusters", span.Name)
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind)
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID())
+	assert.Equal(t, codes.Error, span.Status.Code)
+	attributes := map[string]string{}
+	for _, attr := range span.Attributes {
+		attributes[string(attr.Key)] = attr.Value.Emit()
+	}
+	assert.DeepEqual(t, map[string]string{
+		"k8s.group":     "cluster.x-k8s.io",
+		"k8s.version":   "v1beta1",
+		"k8s.resource":  "clusters",
+		"k8s.verb":      "get",
+		"k8s.namespace": cluster.Namespace,
+		"k8s.reason":    "NotFound",
+	}, attributes)
+}
@@ -1,10 +1,273 @@
 package k8s
 
+import (
+	"context"
+	"fmt"
+	"github.com/topfreegames/kaas-management-api/internal/logging"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sort"
+	"time"
+)
+
 type Kubernetes struct {
 	K8sAuth *Auth
+	// Cache serves the reads of the cached resources, when nil every read goes to the Kubernetes API
+	Cache *Cache
+	// Config keeps the ConfigMap configuring the API, when nil the defaults are used
+	Config *ConfigWatcher
+	// Namespaces resolves the namespace of the clusters, when nil every cluster has its own namespace with the default prefix
+	Namespaces NamespaceResolver
+	// Logger logs the operations of the instance, when nil the global logger is used
+	Logger *zap.Logger
+	// ctx is the context of the request served with the instance, it carries the trace of the Kubernetes API calls
+	ctx context.Context
+}
+
+// CreateK8sInstance authenticates in the Kubernetes API and starts the caches and the configuration watch, which stop
+// when stopCh is closed. Only the cached resources served by the Kubernetes API are cached, the others are read from it.
+func CreateK8sInstance(logger *zap.Logger, stopCh <-chan struct{}) (*Kubernetes, error) {
+	auth, err := Authenticate(logger)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces, err := NewNamespaceResolverFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("could not configure the namespace resolution of the clusters: %v", err)
+	}
+
+	cachedResources, unservedResources, err := ServedResources(auth.Clientset.Discovery(), CachedResources)
+	if err != nil {
+		return nil, err
+	}
+	for _, resource := range unservedResources {
+		logger.Warn("Resource isn't served by the Kubernetes API, it won't be cached until the API is restarted", zap.String("resource", resource.String()))
+	}
+
+	cache := NewCacheForResources(auth.DynamicClient, cachedResources, CacheResyncPeriod)
+	cache.Start(stopCh)
+	go func() {
+		if cache.WaitForCacheSync(stopCh) {
+			logger.Info("Informer caches synced")
+		}
+	}()
+
+	configNamespace, configName := GetConfigMapLocation()
+	config := NewConfigWatcher(auth.Clientset, configNamespace, configName, CacheResyncPeriod)
+	config.Start(stopCh)
+	go func() {
+		if config.WaitForCacheSync(stopCh) {
+			logger.Info("Watching the configuration ConfigMap", zap.String("namespace", configNamespace), zap.String("name", configName))
+		}
+	}()
+
+	return &Kubernetes{K8sAuth: auth, Cache: cache, Config: config, Namespaces: namespaces, Logger: logger}, nil
+}
+
+// IsReady returns whether the cache and the configuration have synced and the instance is ready to serve requests
+func (k Kubernetes) IsReady() bool {
+	return (k.Cache == nil || k.Cache.HasSynced()) && (k.Config == nil || k.Config.HasSynced())
+}
+
+// GetConfigData returns the data of the ConfigMap configuring the API, or false when it isn't watched or doesn't exist
+func (k Kubernetes) GetConfigData() (map[string]string, bool) {
+	if k.Config == nil {
+		return nil, false
+	}
+	return k.Config.Data()
+}
+
+// GetParsedConfig returns the value parsed from a key of the ConfigMap configuring the API, parsed only when the key changes.
+// When the ConfigMap isn't watched the empty value is parsed on every call.
+func (k Kubernetes) GetParsedConfig(key string, parse ConfigParser) interface{} {
+	if k.Config == nil {
+		return parse("", nil)
+	}
+	return k.Config.Parsed(key, parse)
 }
 
-func CreateK8sInstance() *Kubernetes {
-	auth := Authenticate()
-	return &Kubernetes{K8sAuth: auth}
+// Uncached returns a copy of the instance that reads everything from the Kubernetes API, used to read a resource right after writing it
+func (k Kubernetes) Uncached() *Kubernetes {
+	k.Cache = nil
+	return &k
+}
+
+// ForNewClusters returns a copy of the instance resolving the namespace of every cluster to the namespace of a new cluster,
+// used to manage the resources of a cluster being created before it can be looked up
+func (k Kubernetes) ForNewClusters() *Kubernetes {
+	k.Namespaces = &newClusterNamespaceResolver{k.namespaceResolver()}
+	return &k
+}
+
+// WithContext returns a copy of the instance making the Kubernetes API calls with the context of a request,
+// so they are traced and canceled with it
+func (k Kubernetes) WithContext(ctx context.Context) *Kubernetes {
+	k.ctx = ctx
+	return &k
+}
+
+// Detached returns a copy of the instance making the Kubernetes API calls with the context of its request without its
+// cancellation and deadline, for the rollbacks and the work that must finish even when the request is canceled
+func (k Kubernetes) Detached() *Kubernetes {
+	k.ctx = detachedContext{parent: k.Context()}
+	return &k
+}
+
+// detachedContext keeps the values of its parent, like the request ID and the trace, but is never canceled
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+// Context returns the context of the Kubernetes API calls, the background context when the instance doesn't serve a request
+func (k Kubernetes) Context() context.Context {
+	if k.ctx == nil {
+		return context.Background()
+	}
+	return k.ctx
+}
+
+// Log returns the logger of the instance with the request ID and trace ID of the request it serves
+func (k Kubernetes) Log() *zap.Logger {
+	logger := k.Logger
+	if logger == nil {
+		logger = zap.L()
+	}
+	return logging.ForRequest(logger, k.ctx)
+}
+
+// ClusterNamespace returns the namespace of an existing cluster
+func (k Kubernetes) ClusterNamespace(clusterName string) (string, error) {
+	return k.namespaceResolver().ClusterNamespace(k, clusterName)
+}
+
+// NewClusterNamespace returns the namespace where a new cluster is created
+func (k Kubernetes) NewClusterNamespace(clusterName string) string {
+	return k.namespaceResolver().NewClusterNamespace(clusterName)
+}
+
+// NewClusterLabels returns the labels a new cluster must have to be found in its namespace
+func (k Kubernetes) NewClusterLabels(clusterName string) map[string]string {
+	return k.namespaceResolver().NewClusterLabels(clusterName)
+}
+
+func (k Kubernetes) namespaceResolver() NamespaceResolver {
+	if k.Namespaces == nil {
+		return &PrefixNamespaceResolver{Prefix: DefaultNamespacePrefix}
+	}
+	return k.Namespaces
+}
+
+// GetResource gets a resource from the cache when it is cached and synced, otherwise from the Kubernetes API.
+// Both return the same errors for resources not found.
+func (k Kubernetes) GetResource(resource schema.GroupVersionResource, namespace string, name string) (*unstructured.Unstructured, error) {
+	if k.Cache != nil {
+		if informer, ok := k.Cache.informer(resource); ok {
+			object, err := informer.Lister().ByNamespace(namespace).Get(name)
+			if err != nil {
+				return nil, err
+			}
+			return object.(*unstructured.Unstructured).DeepCopy(), nil
+		}
+	}
+
+	return k.K8sAuth.DynamicClient.Resource(resource).Namespace(namespace).Get(k.Context(), name, metav1.GetOptions{})
+}
+
+// ListResources lists the resources of a namespace, or of all namespaces when it is empty, selected by the options label selector,
+// from the cache when it is cached and synced,
+// otherwise from the Kubernetes API. The cached items are sorted by namespace and name like the Kubernetes API does.
+// The list continue token is an opaque token that must be sent in the options to get the next page.
+func (k Kubernetes) ListResources(resource schema.GroupVersionResource, namespace string, options ListOptions) (*unstructured.UnstructuredList, error) {
+	var token *continueToken
+	if options.Continue != "" {
+		var err error
+		token, err = decodeContinueToken(options.Continue, resource)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Pages started in the Kubernetes API keep being read from it, even if the cache has synced since the first page
+	if k.Cache != nil && (token == nil || token.After != "") {
+		if informer, ok := k.Cache.informer(resource); ok {
+			var cached []runtime.Object
+			var err error
+			if namespace == "" {
+				cached, err = informer.Lister().List(options.labelSelector())
+			} else {
+				cached, err = informer.Lister().ByNamespace(namespace).List(options.labelSelector())
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			list := &unstructured.UnstructuredList{Object: map[string]interface{}{}}
+			list.SetAPIVersion(resource.GroupVersion().String())
+			for _, object := range cached {
+				list.Items = append(list.Items, *object.(*unstructured.Unstructured))
+			}
+			if len(list.Items) > 0 {
+				list.SetKind(list.Items[0].GetKind() + "List")
+			}
+			sort.Slice(list.Items, func(i, j int) bool {
+				return cachedItemKey(&list.Items[i]) < cachedItemKey(&list.Items[j])
+			})
+			paginateCachedList(list, resource, options, token)
+			for i := range list.Items {
+				list.Items[i] = *list.Items[i].DeepCopy()
+			}
+			return list, nil
+		}
+	}
+
+	if token != nil && token.After != "" {
+		return nil, clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("The continue token %s has expired, the list must be started again", options.Continue))
+	}
+
+	listOptions := metav1.ListOptions{Limit: options.Limit, LabelSelector: options.labelSelector().String()}
+	if token != nil {
+		listOptions.Continue = token.Continue
+	}
+
+	var list *unstructured.UnstructuredList
+	var err error
+	if namespace == "" {
+		list, err = k.K8sAuth.DynamicClient.Resource(resource).List(k.Context(), listOptions)
+	} else {
+		list, err = k.K8sAuth.DynamicClient.Resource(resource).Namespace(namespace).List(k.Context(), listOptions)
+	}
+	if err != nil {
+		if errors.IsResourceExpired(err) {
+			return nil, clientError.NewClientError(err, clientError.InvalidRequest, fmt.Sprintf("The continue token %s has expired, the list must be started again", options.Continue))
+		}
+		return nil, err
+	}
+
+	if list.GetContinue() != "" {
+		next := &continueToken{Resource: resource.Resource, Continue: list.GetContinue()}
+		list.SetContinue(next.encode())
+	}
+	return list, nil
 }
@@ -0,0 +1,22 @@
+package k8s
+
+import (
+	"context"
+	"github.com/topfreegames/kaas-management-api/internal/logging"
+	"gotest.tools/assert"
+	"testing"
+)
+
+func Test_Kubernetes_Detached(t *testing.T) {
+	ctx, cancel := context.WithCancel(logging.WithRequestID(context.Background(), "test-request-id"))
+	k := (&Kubernetes{}).WithContext(ctx)
+	detached := k.Detached()
+	cancel()
+
+	assert.ErrorContains(t, k.Context().Err(), "canceled")
+	assert.NilError(t, detached.Context().Err())
+	assert.Assert(t, detached.Context().Done() == nil)
+	_, hasDeadline := detached.Context().Deadline()
+	assert.Assert(t, !hasDeadline)
+	assert.Equal(t, "test-request-id", logging.RequestID(detached.Context()))
+}
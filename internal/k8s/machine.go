@@ -0,0 +1,56 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// ListMachines Returns the Machine CRs from a specific cluster matching a label selector, an empty selector returns all of them
+func (k Kubernetes) ListMachines(clusterName string, labelSelector string) (*clusterapiv1beta1.MachineList, error) {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(MachineSchemaV1beta1)
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	machinesRaw, err := resource.Namespace(namespace).List(k.Context(), metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("No Machine was found for the cluster %s!", clusterName))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return nil, fmt.Errorf("Error getting Machine list from Kubernetes API: %v\n", statusError.ErrStatus.Message)
+		}
+		return nil, fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	var machines clusterapiv1beta1.MachineList
+	machinesRawJson, err := machinesRaw.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("could not Marshal Machine response: %v", err)
+	}
+
+	err = json.Unmarshal(machinesRawJson, &machines)
+	if err != nil {
+		return nil, fmt.Errorf("could not Unmarshal Machine JSON into clusterAPI list: %v", err)
+	}
+
+	// The namespace may be shared by other clusters
+	items := machines.Items[:0]
+	for _, machine := range machines.Items {
+		if machine.Spec.ClusterName == clusterName {
+			items = append(items, machine)
+		}
+	}
+	machines.Items = items
+
+	if len(machines.Items) == 0 {
+		return nil, clientError.NewClientError(err, clientError.EmptyResponse, fmt.Sprintf("no Machines were found for the cluster %s!", clusterName))
+	}
+
+	return &machines, nil
+}
@@ -1,22 +1,22 @@
 package k8s
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/topfreegames/kaas-management-api/util/clientError"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
 // GetMachineDeployment Returns a MachineDeployment CR from a specific cluster
 func (k Kubernetes) GetMachineDeployment(clusterName string, machineDeploymentName string) (*clusterapiv1beta1.MachineDeployment, error) {
-	client := k.K8sAuth.DynamicClient
-
-	resource := client.Resource(MachineDeploymentSchemaV1beta1)
-	namespace := GetClusterNamespace(clusterName)
-	machineDeploymentRaw, err := resource.Namespace(namespace).Get(context.TODO(), machineDeploymentName, metav1.GetOptions{})
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	machineDeploymentRaw, err := k.GetResource(MachineDeploymentSchemaV1beta1, namespace, machineDeploymentName)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			return nil, clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("The requested MachineDeployment %s was not found for the cluster %s!", machineDeploymentName, clusterName))
@@ -45,15 +45,17 @@ func (k Kubernetes) GetMachineDeployment(clusterName string, machineDeploymentNa
 	return &machineDeployment, nil
 }
 
-// ListMachineDeployment Show a list of MachineDeployment CR from a specific cluster
-func (k Kubernetes) ListMachineDeployment(clusterName string) (*clusterapiv1beta1.MachineDeploymentList, error) {
-	client := k.K8sAuth.DynamicClient
-
-	resource := client.Resource(MachineDeploymentSchemaV1beta1)
-	namespace := GetClusterNamespace(clusterName)
-	machineDeploymentsRaw, err := resource.Namespace(namespace).List(context.TODO(), metav1.ListOptions{})
+// ListMachineDeployment Show a list of MachineDeployment CR from a specific cluster, limited to one page by the options
+func (k Kubernetes) ListMachineDeployment(clusterName string, options ListOptions) (*clusterapiv1beta1.MachineDeploymentList, error) {
+	namespace, err := k.ClusterNamespace(clusterName)
 	if err != nil {
-		if errors.IsNotFound(err) {
+		return nil, err
+	}
+	machineDeploymentsRaw, err := k.ListResources(MachineDeploymentSchemaV1beta1, namespace, options)
+	if err != nil {
+		if clientErr, isClientErr := err.(*clientError.ClientError); isClientErr {
+			return nil, clientErr
+		} else if errors.IsNotFound(err) {
 			return nil, clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("No MachineDeployment was not found for the cluster %s!", clusterName))
 		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
 			return nil, fmt.Errorf("Error getting MachineDeployment list from Kubernetes API: %v\n", statusError.ErrStatus.Message)
@@ -72,9 +74,112 @@ func (k Kubernetes) ListMachineDeployment(clusterName string) (*clusterapiv1beta
 		return nil, fmt.Errorf("could not Unmarshal MachineDeployment JSON into clusterAPI list: %v", err)
 	}
 
-	if len(machineDeployments.Items) == 0 {
+	// The namespace may be shared by other clusters
+	items := machineDeployments.Items[:0]
+	for _, machineDeployment := range machineDeployments.Items {
+		if machineDeployment.Spec.ClusterName == clusterName {
+			items = append(items, machineDeployment)
+		}
+	}
+	machineDeployments.Items = items
+
+	if len(machineDeployments.Items) == 0 && machineDeployments.Continue == "" {
 		return nil, clientError.NewClientError(err, clientError.EmptyResponse, fmt.Sprintf("no MachineDeployments were found for the cluster %s!", clusterName))
 	}
 
 	return &machineDeployments, nil
 }
+
+// CreateMachineDeployment creates a MachineDeployment CR for a specific cluster and returns the created resource
+func (k Kubernetes) CreateMachineDeployment(clusterName string, machineDeployment *clusterapiv1beta1.MachineDeployment) (*clusterapiv1beta1.MachineDeployment, error) {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(MachineDeploymentSchemaV1beta1)
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	machineDeploymentRaw, err := ToUnstructured(machineDeployment)
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidResource, fmt.Sprintf("MachineDeployment %s could not be converted to a Kubernetes resource", machineDeployment.Name))
+	}
+
+	machineDeploymentRaw.SetNamespace(namespace)
+	createdRaw, err := resource.Namespace(namespace).Create(k.Context(), machineDeploymentRaw, metav1.CreateOptions{})
+	if err != nil {
+		if errors.IsAlreadyExists(err) {
+			return nil, clientError.NewClientError(err, clientError.ResourceAlreadyExists, fmt.Sprintf("The MachineDeployment %s already exists for the cluster %s!", machineDeployment.Name, clusterName))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return nil, fmt.Errorf("Error creating MachineDeployment in Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return nil, fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	var createdMachineDeployment clusterapiv1beta1.MachineDeployment
+	createdRawJson, err := createdRaw.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("could not Marshal MachineDeployment response: %v", err)
+	}
+
+	err = json.Unmarshal(createdRawJson, &createdMachineDeployment)
+	if err != nil {
+		return nil, fmt.Errorf("could not Unmarshal MachineDeployment JSON into clusterAPI: %v", err)
+	}
+
+	return &createdMachineDeployment, nil
+}
+
+// PatchMachineDeployment applies a JSON merge patch to a MachineDeployment CR from a specific cluster and returns the patched resource
+func (k Kubernetes) PatchMachineDeployment(clusterName string, machineDeploymentName string, patch []byte) (*clusterapiv1beta1.MachineDeployment, error) {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(MachineDeploymentSchemaV1beta1)
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	patchedRaw, err := resource.Namespace(namespace).Patch(k.Context(), machineDeploymentName, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("The requested MachineDeployment %s was not found for the cluster %s!", machineDeploymentName, clusterName))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return nil, fmt.Errorf("Error patching MachineDeployment in Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return nil, fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	var patchedMachineDeployment clusterapiv1beta1.MachineDeployment
+	patchedRawJson, err := patchedRaw.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("could not Marshal MachineDeployment response: %v", err)
+	}
+
+	err = json.Unmarshal(patchedRawJson, &patchedMachineDeployment)
+	if err != nil {
+		return nil, fmt.Errorf("could not Unmarshal MachineDeployment JSON into clusterAPI: %v", err)
+	}
+
+	return &patchedMachineDeployment, nil
+}
+
+// DeleteMachineDeployment deletes a MachineDeployment CR from a specific cluster
+func (k Kubernetes) DeleteMachineDeployment(clusterName string, machineDeploymentName string) error {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(MachineDeploymentSchemaV1beta1)
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return err
+	}
+	err = resource.Namespace(namespace).Delete(k.Context(), machineDeploymentName, metav1.DeleteOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("The requested MachineDeployment %s was not found for the cluster %s!", machineDeploymentName, clusterName))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return fmt.Errorf("Error deleting MachineDeployment from Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	return nil
+}
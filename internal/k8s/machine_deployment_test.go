@@ -179,7 +179,7 @@ func Test_ListMachineDeployment_Success(t *testing.T) {
 		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
 
 		t.Run(testCase.Name, func(t *testing.T) {
-			response, err := k.ListMachineDeployment(request.Cluster)
+			response, err := k.ListMachineDeployment(request.Cluster, ListOptions{})
 			assert.NilError(t, err)
 			assert.Assert(t, reflect.DeepEqual(expectedInfra, response))
 		})
@@ -232,7 +232,238 @@ func Test_ListMachineDeployment_Error(t *testing.T) {
 		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
 
 		t.Run(testCase.Name, func(t *testing.T) {
-			_, err := k.ListMachineDeployment(request.Cluster)
+			_, err := k.ListMachineDeployment(request.Cluster, ListOptions{})
+			assert.ErrorContains(t, err, testCase.ExpectedClientError.Error())
+			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
+		})
+	}
+}
+
+func Test_CreateMachineDeployment_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:                "CreateMachineDeployment should return Success for a new MachineDeployment",
+			ExpectedSuccess:     test.NewTestMachineDeployment("TestCluster1-TestMachineDeployment", "TestCluster1", "DockerMachineTemplate", "TestDockerMachineTemplate", "infrastructure.cluster.x-k8s.io/v1beta1"),
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				ResourceName: "TestCluster1-TestMachineDeployment",
+				Cluster:      "TestCluster1",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachineDeployment("TestCluster1-TestMachineDeployment2", "TestCluster1", "DockerMachineTemplate", "TestDockerMachineTemplate", "infrastructure.cluster.x-k8s.io/v1beta1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &Kubernetes{K8sAuth: &Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		expectedMachineDeployment, ok := testCase.ExpectedSuccess.(*clusterapiv1beta1.MachineDeployment)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to *clusterapiv1beta1.MachineDeployment", testCase.Name)
+		}
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			response, err := k.CreateMachineDeployment(request.Cluster, expectedMachineDeployment.DeepCopy())
+			assert.NilError(t, err)
+			assert.Assert(t, reflect.DeepEqual(expectedMachineDeployment, response))
+
+			createdMachineDeployment, err := k.GetMachineDeployment(request.Cluster, request.ResourceName)
+			assert.NilError(t, err)
+			assert.Assert(t, reflect.DeepEqual(expectedMachineDeployment, createdMachineDeployment))
+		})
+	}
+}
+
+func Test_CreateMachineDeployment_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "CreateMachineDeployment should return Error for an existent MachineDeployment",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The MachineDeployment TestCluster1-TestMachineDeployment already exists for the cluster TestCluster1!",
+				ErrorMessage:         clientError.ResourceAlreadyExists,
+			},
+			Request: &test.K8sRequest{
+				ResourceName: "TestCluster1-TestMachineDeployment",
+				Cluster:      "TestCluster1",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachineDeployment("TestCluster1-TestMachineDeployment", "TestCluster1", "DockerMachineTemplate", "TestDockerMachineTemplate", "infrastructure.cluster.x-k8s.io/v1beta1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &Kubernetes{K8sAuth: &Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := k.CreateMachineDeployment(request.Cluster, test.NewTestMachineDeployment(request.ResourceName, request.Cluster, "DockerMachineTemplate", "TestDockerMachineTemplate", "infrastructure.cluster.x-k8s.io/v1beta1"))
+			assert.ErrorContains(t, err, testCase.ExpectedClientError.Error())
+			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
+		})
+	}
+}
+
+func Test_PatchMachineDeployment_Success(t *testing.T) {
+	replicas := int32(3)
+	expectedMachineDeployment := test.NewTestMachineDeployment("TestCluster1-TestMachineDeployment", "TestCluster1", "DockerMachineTemplate", "TestDockerMachineTemplate", "infrastructure.cluster.x-k8s.io/v1beta1")
+	expectedMachineDeployment.Spec.Replicas = &replicas
+
+	testCases := []test.TestCase{
+		{
+			Name:                "PatchMachineDeployment should return Success changing the replicas",
+			ExpectedSuccess:     expectedMachineDeployment,
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				ResourceName: "TestCluster1-TestMachineDeployment",
+				Cluster:      "TestCluster1",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachineDeployment("TestCluster1-TestMachineDeployment", "TestCluster1", "DockerMachineTemplate", "TestDockerMachineTemplate", "infrastructure.cluster.x-k8s.io/v1beta1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &Kubernetes{K8sAuth: &Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		expectedMachineDeployment, ok := testCase.ExpectedSuccess.(*clusterapiv1beta1.MachineDeployment)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to *clusterapiv1beta1.MachineDeployment", testCase.Name)
+		}
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			response, err := k.PatchMachineDeployment(request.Cluster, request.ResourceName, []byte(`{"spec":{"replicas":3}}`))
+			assert.NilError(t, err)
+			assert.Assert(t, reflect.DeepEqual(expectedMachineDeployment, response))
+		})
+	}
+}
+
+func Test_PatchMachineDeployment_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "PatchMachineDeployment should return Error for a non-existent MachineDeployment",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The requested MachineDeployment nonexistent was not found for the cluster TestCluster1!",
+				ErrorMessage:         clientError.ResourceNotFound,
+			},
+			Request: &test.K8sRequest{
+				ResourceName: "nonexistent",
+				Cluster:      "TestCluster1",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachineDeployment("TestCluster1-TestMachineDeployment", "TestCluster1", "DockerMachineTemplate", "TestDockerMachineTemplate", "infrastructure.cluster.x-k8s.io/v1beta1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &Kubernetes{K8sAuth: &Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := k.PatchMachineDeployment(request.Cluster, request.ResourceName, []byte(`{"spec":{"replicas":3}}`))
+			assert.ErrorContains(t, err, testCase.ExpectedClientError.Error())
+			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
+		})
+	}
+}
+
+func Test_DeleteMachineDeployment_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:                "DeleteMachineDeployment should return Success for an existent MachineDeployment",
+			ExpectedSuccess:     nil,
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				ResourceName: "TestCluster1-TestMachineDeployment",
+				Cluster:      "TestCluster1",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachineDeployment("TestCluster1-TestMachineDeployment", "TestCluster1", "DockerMachineTemplate", "TestDockerMachineTemplate", "infrastructure.cluster.x-k8s.io/v1beta1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &Kubernetes{K8sAuth: &Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			err := k.DeleteMachineDeployment(request.Cluster, request.ResourceName)
+			assert.NilError(t, err)
+
+			_, err = k.GetMachineDeployment(request.Cluster, request.ResourceName)
+			assert.Assert(t, test.AssertClientError(err, &clientError.ClientError{
+				ErrorMessage:         clientError.ResourceNotFound,
+				ErrorDetailedMessage: "The requested MachineDeployment TestCluster1-TestMachineDeployment was not found for the cluster TestCluster1!",
+			}))
+		})
+	}
+}
+
+func Test_DeleteMachineDeployment_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "DeleteMachineDeployment should return Error for a non-existent MachineDeployment",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The requested MachineDeployment nonexistent was not found for the cluster TestCluster1!",
+				ErrorMessage:         clientError.ResourceNotFound,
+			},
+			Request: &test.K8sRequest{
+				ResourceName: "nonexistent",
+				Cluster:      "TestCluster1",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachineDeployment("TestCluster1-TestMachineDeployment", "TestCluster1", "DockerMachineTemplate", "TestDockerMachineTemplate", "infrastructure.cluster.x-k8s.io/v1beta1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &Kubernetes{K8sAuth: &Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			err := k.DeleteMachineDeployment(request.Cluster, request.ResourceName)
 			assert.ErrorContains(t, err, testCase.ExpectedClientError.Error())
 			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
 		})
@@ -1,23 +1,22 @@
 package k8s
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/topfreegames/kaas-management-api/util/clientError"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	clusterapiexpv1beta1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 )
 
 // GetMachinePool Returns a MachinePool CR from a specific cluster
 func (k Kubernetes) GetMachinePool(clusterName string, machinePoolName string) (*clusterapiexpv1beta1.MachinePool, error) {
-
-	client := k.K8sAuth.DynamicClient
-
-	resource := client.Resource(MachinePoolSchemaV1beta1)
-	namespace := GetClusterNamespace(clusterName)
-	machinePoolRaw, err := resource.Namespace(namespace).Get(context.TODO(), machinePoolName, metav1.GetOptions{})
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	machinePoolRaw, err := k.GetResource(MachinePoolSchemaV1beta1, namespace, machinePoolName)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			return nil, clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("The requested MachinePool %s was not found for the cluster %s!", machinePoolName, clusterName))
@@ -46,16 +45,17 @@ func (k Kubernetes) GetMachinePool(clusterName string, machinePoolName string) (
 	return &machinePool, nil
 }
 
-// ListMachinePool Show a list of MachinePool CR from a specific cluster
-func (k Kubernetes) ListMachinePool(clusterName string) (*clusterapiexpv1beta1.MachinePoolList, error) {
-
-	client := k.K8sAuth.DynamicClient
-
-	resource := client.Resource(MachinePoolSchemaV1beta1)
-	namespace := GetClusterNamespace(clusterName)
-	machinePoolsRaw, err := resource.Namespace(namespace).List(context.TODO(), metav1.ListOptions{})
+// ListMachinePool Show a list of MachinePool CR from a specific cluster, limited to one page by the options
+func (k Kubernetes) ListMachinePool(clusterName string, options ListOptions) (*clusterapiexpv1beta1.MachinePoolList, error) {
+	namespace, err := k.ClusterNamespace(clusterName)
 	if err != nil {
-		if errors.IsNotFound(err) {
+		return nil, err
+	}
+	machinePoolsRaw, err := k.ListResources(MachinePoolSchemaV1beta1, namespace, options)
+	if err != nil {
+		if clientErr, isClientErr := err.(*clientError.ClientError); isClientErr {
+			return nil, clientErr
+		} else if errors.IsNotFound(err) {
 			return nil, clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("no MachinePools were found for the cluster %s!", clusterName))
 		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
 			return nil, fmt.Errorf("Error getting MachinePool list from Kubernetes API: %s\n", statusError.ErrStatus.Message)
@@ -74,9 +74,112 @@ func (k Kubernetes) ListMachinePool(clusterName string) (*clusterapiexpv1beta1.M
 		return nil, fmt.Errorf("could not Unmarshal MachinePool JSON into clusterAPI list: %v", err)
 	}
 
-	if len(machinePools.Items) == 0 {
+	// The namespace may be shared by other clusters
+	items := machinePools.Items[:0]
+	for _, machinePool := range machinePools.Items {
+		if machinePool.Spec.ClusterName == clusterName {
+			items = append(items, machinePool)
+		}
+	}
+	machinePools.Items = items
+
+	if len(machinePools.Items) == 0 && machinePools.Continue == "" {
 		return nil, clientError.NewClientError(err, clientError.EmptyResponse, fmt.Sprintf("no MachinePools were found for the cluster %s!", clusterName))
 	}
 
 	return &machinePools, nil
 }
+
+// CreateMachinePool creates a MachinePool CR for a specific cluster and returns the created resource
+func (k Kubernetes) CreateMachinePool(clusterName string, machinePool *clusterapiexpv1beta1.MachinePool) (*clusterapiexpv1beta1.MachinePool, error) {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(MachinePoolSchemaV1beta1)
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	machinePoolRaw, err := ToUnstructured(machinePool)
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidResource, fmt.Sprintf("MachinePool %s could not be converted to a Kubernetes resource", machinePool.Name))
+	}
+
+	machinePoolRaw.SetNamespace(namespace)
+	createdRaw, err := resource.Namespace(namespace).Create(k.Context(), machinePoolRaw, metav1.CreateOptions{})
+	if err != nil {
+		if errors.IsAlreadyExists(err) {
+			return nil, clientError.NewClientError(err, clientError.ResourceAlreadyExists, fmt.Sprintf("The MachinePool %s already exists for the cluster %s!", machinePool.Name, clusterName))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return nil, fmt.Errorf("Error creating MachinePool in Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return nil, fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	var createdMachinePool clusterapiexpv1beta1.MachinePool
+	createdRawJson, err := createdRaw.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("could not Marshal MachinePool response: %v", err)
+	}
+
+	err = json.Unmarshal(createdRawJson, &createdMachinePool)
+	if err != nil {
+		return nil, fmt.Errorf("could not Unmarshal MachinePool JSON into clusterAPI: %v", err)
+	}
+
+	return &createdMachinePool, nil
+}
+
+// PatchMachinePool applies a JSON merge patch to a MachinePool CR from a specific cluster and returns the patched resource
+func (k Kubernetes) PatchMachinePool(clusterName string, machinePoolName string, patch []byte) (*clusterapiexpv1beta1.MachinePool, error) {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(MachinePoolSchemaV1beta1)
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	patchedRaw, err := resource.Namespace(namespace).Patch(k.Context(), machinePoolName, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("The requested MachinePool %s was not found for the cluster %s!", machinePoolName, clusterName))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return nil, fmt.Errorf("Error patching MachinePool in Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return nil, fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	var patchedMachinePool clusterapiexpv1beta1.MachinePool
+	patchedRawJson, err := patchedRaw.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("could not Marshal MachinePool response: %v", err)
+	}
+
+	err = json.Unmarshal(patchedRawJson, &patchedMachinePool)
+	if err != nil {
+		return nil, fmt.Errorf("could not Unmarshal MachinePool JSON into clusterAPI: %v", err)
+	}
+
+	return &patchedMachinePool, nil
+}
+
+// DeleteMachinePool deletes a MachinePool CR from a specific cluster
+func (k Kubernetes) DeleteMachinePool(clusterName string, machinePoolName string) error {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(MachinePoolSchemaV1beta1)
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return err
+	}
+	err = resource.Namespace(namespace).Delete(k.Context(), machinePoolName, metav1.DeleteOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("The requested MachinePool %s was not found for the cluster %s!", machinePoolName, clusterName))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return fmt.Errorf("Error deleting MachinePool from Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	return nil
+}
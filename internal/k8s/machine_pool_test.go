@@ -179,7 +179,7 @@ func Test_ListMachinePool_Success(t *testing.T) {
 		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
 
 		t.Run(testCase.Name, func(t *testing.T) {
-			response, err := k.ListMachinePool(request.Cluster)
+			response, err := k.ListMachinePool(request.Cluster, ListOptions{})
 			assert.NilError(t, err)
 			assert.Assert(t, reflect.DeepEqual(expectedInfra, response))
 		})
@@ -232,7 +232,238 @@ func Test_ListMachinePool_Error(t *testing.T) {
 		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
 
 		t.Run(testCase.Name, func(t *testing.T) {
-			_, err := k.ListMachinePool(request.Cluster)
+			_, err := k.ListMachinePool(request.Cluster, ListOptions{})
+			assert.ErrorContains(t, err, testCase.ExpectedClientError.Error())
+			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
+		})
+	}
+}
+
+func Test_CreateMachinePool_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:                "CreateMachinePool should return Success for a new MachinePool",
+			ExpectedSuccess:     test.NewTestMachinePool("TestCluster1-TestMachinePool", "TestCluster1", "KopsMachinePool", "TestKopsMachinePool", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				ResourceName: "TestCluster1-TestMachinePool",
+				Cluster:      "TestCluster1",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachinePool("TestCluster1-TestMachinePool2", "TestCluster1", "KopsMachinePool", "TestKopsMachinePool", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &Kubernetes{K8sAuth: &Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		expectedMachinePool, ok := testCase.ExpectedSuccess.(*clusterapiexpv1beta1.MachinePool)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to *clusterapiexpv1beta1.MachinePool", testCase.Name)
+		}
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			response, err := k.CreateMachinePool(request.Cluster, expectedMachinePool.DeepCopy())
+			assert.NilError(t, err)
+			assert.Assert(t, reflect.DeepEqual(expectedMachinePool, response))
+
+			createdMachinePool, err := k.GetMachinePool(request.Cluster, request.ResourceName)
+			assert.NilError(t, err)
+			assert.Assert(t, reflect.DeepEqual(expectedMachinePool, createdMachinePool))
+		})
+	}
+}
+
+func Test_CreateMachinePool_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "CreateMachinePool should return Error for an existent MachinePool",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The MachinePool TestCluster1-TestMachinePool already exists for the cluster TestCluster1!",
+				ErrorMessage:         clientError.ResourceAlreadyExists,
+			},
+			Request: &test.K8sRequest{
+				ResourceName: "TestCluster1-TestMachinePool",
+				Cluster:      "TestCluster1",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachinePool("TestCluster1-TestMachinePool", "TestCluster1", "KopsMachinePool", "TestKopsMachinePool", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &Kubernetes{K8sAuth: &Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := k.CreateMachinePool(request.Cluster, test.NewTestMachinePool(request.ResourceName, request.Cluster, "KopsMachinePool", "TestKopsMachinePool", "infrastructure.cluster.x-k8s.io/v1alpha1"))
+			assert.ErrorContains(t, err, testCase.ExpectedClientError.Error())
+			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
+		})
+	}
+}
+
+func Test_PatchMachinePool_Success(t *testing.T) {
+	replicas := int32(3)
+	expectedMachinePool := test.NewTestMachinePool("TestCluster1-TestMachinePool", "TestCluster1", "KopsMachinePool", "TestKopsMachinePool", "infrastructure.cluster.x-k8s.io/v1alpha1")
+	expectedMachinePool.Spec.Replicas = &replicas
+
+	testCases := []test.TestCase{
+		{
+			Name:                "PatchMachinePool should return Success changing the replicas",
+			ExpectedSuccess:     expectedMachinePool,
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				ResourceName: "TestCluster1-TestMachinePool",
+				Cluster:      "TestCluster1",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachinePool("TestCluster1-TestMachinePool", "TestCluster1", "KopsMachinePool", "TestKopsMachinePool", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &Kubernetes{K8sAuth: &Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		expectedMachinePool, ok := testCase.ExpectedSuccess.(*clusterapiexpv1beta1.MachinePool)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to *clusterapiexpv1beta1.MachinePool", testCase.Name)
+		}
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			response, err := k.PatchMachinePool(request.Cluster, request.ResourceName, []byte(`{"spec":{"replicas":3}}`))
+			assert.NilError(t, err)
+			assert.Assert(t, reflect.DeepEqual(expectedMachinePool, response))
+		})
+	}
+}
+
+func Test_PatchMachinePool_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "PatchMachinePool should return Error for a non-existent MachinePool",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The requested MachinePool nonexistent was not found for the cluster TestCluster1!",
+				ErrorMessage:         clientError.ResourceNotFound,
+			},
+			Request: &test.K8sRequest{
+				ResourceName: "nonexistent",
+				Cluster:      "TestCluster1",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachinePool("TestCluster1-TestMachinePool", "TestCluster1", "KopsMachinePool", "TestKopsMachinePool", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &Kubernetes{K8sAuth: &Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := k.PatchMachinePool(request.Cluster, request.ResourceName, []byte(`{"spec":{"replicas":3}}`))
+			assert.ErrorContains(t, err, testCase.ExpectedClientError.Error())
+			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
+		})
+	}
+}
+
+func Test_DeleteMachinePool_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:                "DeleteMachinePool should return Success for an existent MachinePool",
+			ExpectedSuccess:     nil,
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				ResourceName: "TestCluster1-TestMachinePool",
+				Cluster:      "TestCluster1",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachinePool("TestCluster1-TestMachinePool", "TestCluster1", "KopsMachinePool", "TestKopsMachinePool", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &Kubernetes{K8sAuth: &Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			err := k.DeleteMachinePool(request.Cluster, request.ResourceName)
+			assert.NilError(t, err)
+
+			_, err = k.GetMachinePool(request.Cluster, request.ResourceName)
+			assert.Assert(t, test.AssertClientError(err, &clientError.ClientError{
+				ErrorMessage:         clientError.ResourceNotFound,
+				ErrorDetailedMessage: "The requested MachinePool TestCluster1-TestMachinePool was not found for the cluster TestCluster1!",
+			}))
+		})
+	}
+}
+
+func Test_DeleteMachinePool_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "DeleteMachinePool should return Error for a non-existent MachinePool",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The requested MachinePool nonexistent was not found for the cluster TestCluster1!",
+				ErrorMessage:         clientError.ResourceNotFound,
+			},
+			Request: &test.K8sRequest{
+				ResourceName: "nonexistent",
+				Cluster:      "TestCluster1",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachinePool("TestCluster1-TestMachinePool", "TestCluster1", "KopsMachinePool", "TestKopsMachinePool", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &Kubernetes{K8sAuth: &Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			err := k.DeleteMachinePool(request.Cluster, request.ResourceName)
 			assert.ErrorContains(t, err, testCase.ExpectedClientError.Error())
 			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
 		})
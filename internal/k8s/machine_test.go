@@ -0,0 +1,108 @@
+package k8s
+
+import (
+	"github.com/topfreegames/kaas-management-api/test"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"log"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"testing"
+)
+
+func Test_ListMachines_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name: "ListMachines should return Success for the Machines matching the selector",
+			ExpectedSuccess: []string{
+				"TestCluster1-nodes-abcde",
+				"TestCluster1-nodes-fghij",
+			},
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				Cluster:      "TestCluster1",
+				ResourceName: clusterapiv1beta1.MachineDeploymentLabelName + "=TestCluster1-nodes",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachine("TestCluster1-nodes-abcde", "TestCluster1", "TestCluster1-nodes", "aws:///us-east-1a/i-0123456789abcdef0", "ip-10-0-0-1.ec2.internal"),
+				test.NewTestMachine("TestCluster1-nodes-fghij", "TestCluster1", "TestCluster1-nodes", "aws:///us-east-1a/i-0123456789abcdef1", "ip-10-0-0-2.ec2.internal"),
+				test.NewTestMachine("TestCluster1-other-klmno", "TestCluster1", "TestCluster1-other", "aws:///us-east-1a/i-0123456789abcdef2", "ip-10-0-0-3.ec2.internal"),
+			},
+		},
+		{
+			Name: "ListMachines should return Success for all the Machines of a cluster without selector",
+			ExpectedSuccess: []string{
+				"TestCluster2-nodes-abcde",
+			},
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				Cluster: "TestCluster2",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachine("TestCluster1-nodes-abcde", "TestCluster1", "TestCluster1-nodes", "aws:///us-east-1a/i-0123456789abcdef0", "ip-10-0-0-1.ec2.internal"),
+				test.NewTestMachine("TestCluster2-nodes-abcde", "TestCluster2", "TestCluster2-nodes", "aws:///us-east-1a/i-0123456789abcdef3", "ip-10-0-0-4.ec2.internal"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &Kubernetes{K8sAuth: &Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		expectedNames, ok := testCase.ExpectedSuccess.([]string)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to []string", testCase.Name)
+		}
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			// The ResourceName field of the request is used as the label selector
+			response, err := k.ListMachines(request.Cluster, request.ResourceName)
+			assert.NilError(t, err)
+			assert.Equal(t, len(expectedNames), len(response.Items))
+			for i, machine := range response.Items {
+				assert.Equal(t, expectedNames[i], machine.Name)
+			}
+		})
+	}
+}
+
+func Test_ListMachines_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "ListMachines should return Error when no Machine matches the selector",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "no Machines were found for the cluster TestCluster1!",
+				ErrorMessage:         clientError.EmptyResponse,
+			},
+			Request: &test.K8sRequest{
+				Cluster:      "TestCluster1",
+				ResourceName: clusterapiv1beta1.MachineDeploymentLabelName + "=TestCluster1-nonexistent",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachine("TestCluster1-nodes-abcde", "TestCluster1", "TestCluster1-nodes", "aws:///us-east-1a/i-0123456789abcdef0", "ip-10-0-0-1.ec2.internal"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &Kubernetes{K8sAuth: &Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := k.ListMachines(request.Cluster, request.ResourceName)
+			assert.ErrorContains(t, err, testCase.ExpectedClientError.Error())
+			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
+		})
+	}
+}
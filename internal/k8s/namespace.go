@@ -0,0 +1,48 @@
+package k8s
+
+import (
+	"fmt"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// CreateNamespace creates the namespace where a cluster resources are stored and returns whether it was created,
+// it does nothing if the namespace already exists
+func (k Kubernetes) CreateNamespace(namespace string) (bool, error) {
+	client := k.K8sAuth.DynamicClient
+
+	namespaceRaw := &unstructured.Unstructured{}
+	namespaceRaw.SetAPIVersion("v1")
+	namespaceRaw.SetKind("Namespace")
+	namespaceRaw.SetName(namespace)
+
+	_, err := client.Resource(NamespaceSchemaV1).Create(k.Context(), namespaceRaw, metav1.CreateOptions{})
+	if err != nil {
+		if errors.IsAlreadyExists(err) {
+			return false, nil
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return false, fmt.Errorf("Error creating Namespace in Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return false, fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	return true, nil
+}
+
+// DeleteNamespace deletes a namespace with all resources in it, it does nothing if the namespace doesn't exist
+func (k Kubernetes) DeleteNamespace(namespace string) error {
+	client := k.K8sAuth.DynamicClient
+
+	err := client.Resource(NamespaceSchemaV1).Delete(k.Context(), namespace, metav1.DeleteOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return fmt.Errorf("Error deleting Namespace in Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package k8s
+
+import (
+	"fmt"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"os"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"strings"
+)
+
+// Environment variables configuring how the namespace of the clusters is resolved
+const (
+	// NamespaceStrategyEnv is one of the NamespaceStrategy values, NamespaceStrategyPrefix by default
+	NamespaceStrategyEnv = "NAMESPACE_STRATEGY"
+	// NamespacePrefixEnv is the prefix of the cluster namespaces, DefaultNamespacePrefix by default
+	NamespacePrefixEnv = "NAMESPACE_PREFIX"
+	// NamespaceLabelEnv is the label with the cluster name used by NamespaceStrategyLabel, the cluster-API cluster name label by default
+	NamespaceLabelEnv = "NAMESPACE_LABEL"
+	// SharedNamespaceEnv is the namespace of every cluster used by NamespaceStrategyShared
+	SharedNamespaceEnv = "SHARED_NAMESPACE"
+)
+
+// Namespace strategies
+const (
+	NamespaceStrategyPrefix = "prefix"
+	NamespaceStrategyLabel  = "label"
+	NamespaceStrategyShared = "shared"
+)
+
+const DefaultNamespacePrefix = "kubernetes"
+
+// NamespaceResolver resolves the namespace of the clusters
+type NamespaceResolver interface {
+	// ClusterNamespace returns the namespace of an existing cluster
+	ClusterNamespace(k Kubernetes, clusterName string) (string, error)
+	// NewClusterNamespace returns the namespace where a new cluster is created
+	NewClusterNamespace(clusterName string) string
+	// NewClusterLabels returns the labels a new cluster must have to be resolved
+	NewClusterLabels(clusterName string) map[string]string
+}
+
+// NewNamespaceResolverFromEnv creates the namespace resolver configured in the environment
+func NewNamespaceResolverFromEnv() (NamespaceResolver, error) {
+	prefix := os.Getenv(NamespacePrefixEnv)
+	if prefix == "" {
+		prefix = DefaultNamespacePrefix
+	}
+
+	switch strategy := os.Getenv(NamespaceStrategyEnv); strategy {
+	case "", NamespaceStrategyPrefix:
+		return &PrefixNamespaceResolver{Prefix: prefix}, nil
+	case NamespaceStrategyLabel:
+		label := os.Getenv(NamespaceLabelEnv)
+		if label == "" {
+			label = clusterapiv1beta1.ClusterLabelName
+		}
+		if errs := validation.IsQualifiedName(label); len(errs) > 0 {
+			return nil, fmt.Errorf("%s is not a valid label: %s", label, strings.Join(errs, ", "))
+		}
+		return &LabelNamespaceResolver{Label: label, NewClusters: &PrefixNamespaceResolver{Prefix: prefix}}, nil
+	case NamespaceStrategyShared:
+		namespace := os.Getenv(SharedNamespaceEnv)
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return nil, fmt.Errorf("%s must be a valid namespace: %s", SharedNamespaceEnv, strings.Join(errs, ", "))
+		}
+		return &SharedNamespaceResolver{Namespace: namespace}, nil
+	default:
+		return nil, fmt.Errorf("unknown namespace strategy %s", strategy)
+	}
+}
+
+// PrefixNamespaceResolver resolves the namespace of a cluster from its name, every cluster has its own namespace named
+// <prefix>-<name with the dots replaced by dashes>
+type PrefixNamespaceResolver struct {
+	Prefix string
+}
+
+func (r *PrefixNamespaceResolver) ClusterNamespace(k Kubernetes, clusterName string) (string, error) {
+	return r.NewClusterNamespace(clusterName), nil
+}
+
+func (r *PrefixNamespaceResolver) NewClusterNamespace(clusterName string) string {
+	return fmt.Sprintf("%s-%s", r.Prefix, strings.ReplaceAll(clusterName, ".", "-"))
+}
+
+func (r *PrefixNamespaceResolver) NewClusterLabels(clusterName string) map[string]string {
+	return nil
+}
+
+// LabelNamespaceResolver finds the namespace of a cluster looking up in all namespaces the cluster-API cluster with the cluster name
+// in a label, the lookup fails if more than one namespace has the cluster. New clusters are created in the namespace of NewClusters.
+type LabelNamespaceResolver struct {
+	Label       string
+	NewClusters NamespaceResolver
+}
+
+func (r *LabelNamespaceResolver) ClusterNamespace(k Kubernetes, clusterName string) (string, error) {
+	if errs := validation.IsValidLabelValue(clusterName); len(errs) > 0 {
+		return "", clientError.NewClientError(nil, clientError.ResourceNotFound, fmt.Sprintf("The requested cluster %s was not found!", clusterName))
+	}
+
+	selector := labels.SelectorFromSet(labels.Set{r.Label: clusterName})
+	clusters, err := k.ListResources(ClusterResourceSchemaV1beta1, "", ListOptions{LabelSelector: selector})
+	if err != nil {
+		return "", fmt.Errorf("Error looking up the namespace of the cluster %s: %v\n", clusterName, err)
+	}
+
+	var namespaces []string
+	for _, cluster := range clusters.Items {
+		if cluster.GetName() == clusterName {
+			namespaces = append(namespaces, cluster.GetNamespace())
+		}
+	}
+
+	if len(namespaces) == 0 {
+		return "", clientError.NewClientError(nil, clientError.ResourceNotFound, fmt.Sprintf("The requested cluster %s was not found!", clusterName))
+	} else if len(namespaces) > 1 {
+		return "", clientError.NewClientError(nil, clientError.InvalidConfiguration, fmt.Sprintf("The cluster %s was found in more than one namespace: %s", clusterName, strings.Join(namespaces, ", ")))
+	}
+	return namespaces[0], nil
+}
+
+func (r *LabelNamespaceResolver) NewClusterNamespace(clusterName string) string {
+	return r.NewClusters.NewClusterNamespace(clusterName)
+}
+
+func (r *LabelNamespaceResolver) NewClusterLabels(clusterName string) map[string]string {
+	return map[string]string{r.Label: clusterName}
+}
+
+// SharedNamespaceResolver keeps every cluster in the same namespace
+type SharedNamespaceResolver struct {
+	Namespace string
+}
+
+func (r *SharedNamespaceResolver) ClusterNamespace(k Kubernetes, clusterName string) (string, error) {
+	return r.Namespace, nil
+}
+
+func (r *SharedNamespaceResolver) NewClusterNamespace(clusterName string) string {
+	return r.Namespace
+}
+
+func (r *SharedNamespaceResolver) NewClusterLabels(clusterName string) map[string]string {
+	return nil
+}
+
+// newClusterNamespaceResolver resolves every cluster to the namespace where it is created
+type newClusterNamespaceResolver struct {
+	NamespaceResolver
+}
+
+func (r *newClusterNamespaceResolver) ClusterNamespace(k Kubernetes, clusterName string) (string, error) {
+	return r.NewClusterNamespace(clusterName), nil
+}
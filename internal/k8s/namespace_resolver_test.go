@@ -0,0 +1,151 @@
+package k8s
+
+import (
+	"github.com/topfreegames/kaas-management-api/test"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"testing"
+)
+
+// newTestLabeledCluster returns a test cluster in a namespace that doesn't follow the prefix convention, labeled with its name
+func newTestLabeledCluster(name string, namespace string) *clusterapiv1beta1.Cluster {
+	cluster := test.NewTestCluster(name, "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1")
+	cluster.Namespace = namespace
+	cluster.Labels[clusterapiv1beta1.ClusterLabelName] = name
+	return cluster
+}
+
+func Test_NamespaceResolver_ClusterNamespace(t *testing.T) {
+	resolvers := map[string]NamespaceResolver{
+		NamespaceStrategyPrefix: &PrefixNamespaceResolver{Prefix: DefaultNamespacePrefix},
+		NamespaceStrategyShared: &SharedNamespaceResolver{Namespace: "clusters"},
+		NamespaceStrategyLabel:  &LabelNamespaceResolver{Label: clusterapiv1beta1.ClusterLabelName, NewClusters: &PrefixNamespaceResolver{Prefix: DefaultNamespacePrefix}},
+	}
+
+	testCases := []test.TestCase{
+		{
+			Name:            "Prefix resolver should return the prefixed namespace with dots replaced",
+			ExpectedSuccess: "kubernetes-testcluster-example-com",
+			Request: &test.K8sRequest{
+				Cluster:      "testcluster.example.com",
+				ResourceKind: NamespaceStrategyPrefix,
+			},
+		},
+		{
+			Name:            "Shared resolver should return the shared namespace",
+			ExpectedSuccess: "clusters",
+			Request: &test.K8sRequest{
+				Cluster:      "testcluster",
+				ResourceKind: NamespaceStrategyShared,
+			},
+		},
+		{
+			Name:            "Label resolver should return the namespace of the labeled cluster",
+			ExpectedSuccess: "team-a",
+			Request: &test.K8sRequest{
+				Cluster:      "testcluster",
+				ResourceKind: NamespaceStrategyLabel,
+			},
+		},
+		{
+			Name:            "Label resolver should return Error for a cluster without the label",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The requested cluster unlabeledcluster was not found!",
+				ErrorMessage:         clientError.ResourceNotFound,
+			},
+			Request: &test.K8sRequest{
+				Cluster:      "unlabeledcluster",
+				ResourceKind: NamespaceStrategyLabel,
+			},
+		},
+		{
+			Name:            "Label resolver should return Error for a cluster found in more than one namespace",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The cluster duplicatedcluster was found in more than one namespace: team-a, team-b",
+				ErrorMessage:         clientError.InvalidConfiguration,
+			},
+			Request: &test.K8sRequest{
+				Cluster:      "duplicatedcluster",
+				ResourceKind: NamespaceStrategyLabel,
+			},
+		},
+	}
+
+	k := Kubernetes{K8sAuth: &Auth{
+		DynamicClient: test.NewK8sFakeDynamicClientWithResources([]runtime.Object{
+			newTestLabeledCluster("testcluster", "team-a"),
+			newTestLabeledCluster("duplicatedcluster", "team-a"),
+			newTestLabeledCluster("duplicatedcluster", "team-b"),
+			test.NewTestCluster("unlabeledcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+		}...),
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			namespace, err := resolvers[request.ResourceKind].ClusterNamespace(k, request.Cluster)
+			if testCase.ExpectedSuccess != nil {
+				assert.NilError(t, err)
+				assert.Equal(t, namespace, testCase.ExpectedSuccess)
+			} else {
+				assert.Assert(t, err != nil)
+				assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
+			}
+		})
+	}
+}
+
+func Test_GetCluster_LabelNamespaceResolver(t *testing.T) {
+	k := newTestCachedKubernetes(t,
+		newTestLabeledCluster("testcluster", "team-a"),
+		test.NewTestMachinePool("TestMachinePool1", "testcluster", "KopsMachinePool", "TestKopsMachinePool1", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+		test.NewTestMachineDeployment("TestMachineDeployment1", "testcluster", "AWSMachineTemplate", "TestAWSMachineTemplate1", "infrastructure.cluster.x-k8s.io/v1beta1"),
+		test.NewTestKopsMachinePool("TestKopsMachinePool1", "testcluster"),
+	)
+	k.Namespaces = &LabelNamespaceResolver{Label: clusterapiv1beta1.ClusterLabelName, NewClusters: &PrefixNamespaceResolver{Prefix: DefaultNamespacePrefix}}
+
+	cluster, err := k.GetCluster("testcluster")
+	assert.NilError(t, err)
+	assert.Equal(t, cluster.Namespace, "team-a")
+
+	newClusters := k.ForNewClusters()
+	namespace, err := newClusters.ClusterNamespace("newcluster")
+	assert.NilError(t, err)
+	assert.Equal(t, namespace, "kubernetes-newcluster")
+	assert.DeepEqual(t, newClusters.NewClusterLabels("newcluster"), map[string]string{clusterapiv1beta1.ClusterLabelName: "newcluster"})
+}
+
+func Test_NewNamespaceResolverFromEnv(t *testing.T) {
+	t.Run("Should use the prefix strategy by default", func(t *testing.T) {
+		resolver, err := NewNamespaceResolverFromEnv()
+		assert.NilError(t, err)
+		assert.DeepEqual(t, resolver, &PrefixNamespaceResolver{Prefix: DefaultNamespacePrefix})
+	})
+
+	t.Run("Should configure the label strategy", func(t *testing.T) {
+		t.Setenv(NamespaceStrategyEnv, NamespaceStrategyLabel)
+		t.Setenv(NamespacePrefixEnv, "clusters")
+		resolver, err := NewNamespaceResolverFromEnv()
+		assert.NilError(t, err)
+		assert.DeepEqual(t, resolver, &LabelNamespaceResolver{Label: clusterapiv1beta1.ClusterLabelName, NewClusters: &PrefixNamespaceResolver{Prefix: "clusters"}})
+	})
+
+	t.Run("Should return Error for a shared strategy without namespace", func(t *testing.T) {
+		t.Setenv(NamespaceStrategyEnv, NamespaceStrategyShared)
+		_, err := NewNamespaceResolverFromEnv()
+		assert.Assert(t, err != nil)
+	})
+
+	t.Run("Should return Error for an unknown strategy", func(t *testing.T) {
+		t.Setenv(NamespaceStrategyEnv, "random")
+		_, err := NewNamespaceResolverFromEnv()
+		assert.ErrorContains(t, err, "unknown namespace strategy random")
+	})
+}
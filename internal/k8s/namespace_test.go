@@ -0,0 +1,97 @@
+package k8s
+
+import (
+	"context"
+	"github.com/topfreegames/kaas-management-api/test"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"testing"
+)
+
+func Test_CreateNamespace_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:                "CreateNamespace should return Success for a new namespace",
+			ExpectedSuccess:     true,
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				ResourceName: "kubernetes-testcluster",
+			},
+			K8sTestResources: []runtime.Object{},
+		},
+		{
+			Name:                "CreateNamespace should return Success for an existent namespace",
+			ExpectedSuccess:     false,
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				ResourceName: "kubernetes-testcluster",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestNamespace("kubernetes-testcluster"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &Kubernetes{K8sAuth: &Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			created, err := k.CreateNamespace(request.ResourceName)
+			assert.NilError(t, err)
+			assert.Equal(t, testCase.ExpectedSuccess, created)
+
+			namespace, err := k.K8sAuth.DynamicClient.Resource(NamespaceSchemaV1).Get(context.TODO(), request.ResourceName, metav1.GetOptions{})
+			assert.NilError(t, err)
+			assert.Equal(t, request.ResourceName, namespace.GetName())
+		})
+	}
+}
+
+func Test_DeleteNamespace_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:                "DeleteNamespace should return Success for an existent namespace",
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				ResourceName: "kubernetes-testcluster",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestNamespace("kubernetes-testcluster"),
+			},
+		},
+		{
+			Name:                "DeleteNamespace should return Success for a non-existent namespace",
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				ResourceName: "kubernetes-testcluster",
+			},
+			K8sTestResources: []runtime.Object{},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &Kubernetes{K8sAuth: &Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			err := k.DeleteNamespace(request.ResourceName)
+			assert.NilError(t, err)
+
+			_, err = k.K8sAuth.DynamicClient.Resource(NamespaceSchemaV1).Get(context.TODO(), request.ResourceName, metav1.GetOptions{})
+			assert.Assert(t, errors.IsNotFound(err))
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package k8s
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ListOptions are the pagination and filter options of the list functions, a zero Limit lists all resources
+type ListOptions struct {
+	// LabelSelector selects the resources listed by their labels, when nil every resource is listed
+	LabelSelector labels.Selector
+	// Limit is the maximum number of resources returned
+	Limit int64
+	// Continue is the opaque token returned by the previous page
+	Continue string
+}
+
+// continueToken is the content of the opaque continue token returned to the clients. Pages read from the Kubernetes API
+// keep its continue token, pages read from the cache keep the key of their last resource so resources added or removed
+// between two pages don't shift the next one.
+type continueToken struct {
+	Resource string `json:"r"`
+	Continue string `json:"c,omitempty"`
+	After    string `json:"a,omitempty"`
+}
+
+// encode returns the token as an opaque URL safe string
+func (t *continueToken) encode() string {
+	rawToken, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(rawToken)
+}
+
+// decodeContinueToken reads an opaque continue token checking it was created for the resource being listed
+func decodeContinueToken(token string, resource schema.GroupVersionResource) (*continueToken, error) {
+	rawToken, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidRequest, fmt.Sprintf("The continue token %s is invalid", token))
+	}
+
+	decoded := &continueToken{}
+	err = json.Unmarshal(rawToken, decoded)
+	if err != nil || decoded.Resource != resource.Resource || (decoded.Continue == "" && decoded.After == "") {
+		return nil, clientError.NewClientError(err, clientError.InvalidRequest, fmt.Sprintf("The continue token %s is invalid", token))
+	}
+
+	return decoded, nil
+}
+
+// IsContinueTokenOf returns whether an opaque continue token was created listing the resource
+func IsContinueTokenOf(token string, resource schema.GroupVersionResource) bool {
+	_, err := decodeContinueToken(token, resource)
+	return err == nil
+}
+
+// labelSelector returns the label selector of the options, selecting everything when it isn't set
+func (o ListOptions) labelSelector() labels.Selector {
+	if o.LabelSelector == nil {
+		return labels.Everything()
+	}
+	return o.LabelSelector
+}
+
+// paginateCachedList keeps in the list only the page requested by the options, the list items must be sorted
+func paginateCachedList(list *unstructured.UnstructuredList, resource schema.GroupVersionResource, options ListOptions, token *continueToken) {
+	start := 0
+	if token != nil {
+		for start < len(list.Items) && cachedItemKey(&list.Items[start]) <= token.After {
+			start++
+		}
+	}
+	list.Items = list.Items[start:]
+
+	if options.Limit > 0 && int64(len(list.Items)) > options.Limit {
+		list.Items = list.Items[:options.Limit]
+		next := &continueToken{Resource: resource.Resource, After: cachedItemKey(&list.Items[len(list.Items)-1])}
+		list.SetContinue(next.encode())
+	}
+}
+
+// cachedItemKey returns the key used to sort and paginate the cached resources
+func cachedItemKey(item *unstructured.Unstructured) string {
+	return item.GetNamespace() + "/" + item.GetName()
+}
@@ -0,0 +1,100 @@
+package k8s
+
+import (
+	"github.com/topfreegames/kaas-management-api/test"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"testing"
+)
+
+func Test_Cache_ListClusters_Pagination(t *testing.T) {
+	k := newTestCachedKubernetes(t,
+		test.NewTestCluster("testcluster3", "testcluster-kops-cp3", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster3", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+		test.NewTestCluster("testcluster1", "testcluster-kops-cp1", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster1", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+		test.NewTestCluster("testcluster2", "testcluster-kops-cp2", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster2", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+		test.NewTestMachinePool("TestMachinePool1", "testcluster1", "KopsMachinePool", "TestKopsMachinePool1", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+		test.NewTestMachineDeployment("TestMachineDeployment1", "testcluster2", "AWSMachineTemplate", "TestAWSMachineTemplate1", "infrastructure.cluster.x-k8s.io/v1beta1"),
+		test.NewTestKopsMachinePool("TestKopsMachinePool1", "testcluster1"),
+	)
+
+	firstPage, err := k.ListClusters(ListOptions{Limit: 2})
+	assert.NilError(t, err)
+	assert.Equal(t, len(firstPage.Items), 2)
+	assert.Equal(t, firstPage.Items[0].Name, "testcluster1")
+	assert.Equal(t, firstPage.Items[1].Name, "testcluster2")
+	assert.Assert(t, firstPage.Continue != "", "the first page should have a continue token")
+	assert.Assert(t, IsContinueTokenOf(firstPage.Continue, ClusterResourceSchemaV1beta1))
+	assert.Assert(t, !IsContinueTokenOf(firstPage.Continue, MachinePoolSchemaV1beta1))
+
+	secondPage, err := k.ListClusters(ListOptions{Limit: 2, Continue: firstPage.Continue})
+	assert.NilError(t, err)
+	assert.Equal(t, len(secondPage.Items), 1)
+	assert.Equal(t, secondPage.Items[0].Name, "testcluster3")
+	assert.Equal(t, secondPage.Continue, "")
+
+	allClusters, err := k.ListClusters(ListOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(allClusters.Items), 3)
+	assert.Equal(t, allClusters.Continue, "")
+}
+
+func Test_ListClusters_Pagination_Error(t *testing.T) {
+	cachedToken := (&continueToken{Resource: ClusterResourceSchemaV1beta1.Resource, After: "kubernetes-testcluster1/testcluster1"}).encode()
+	machinePoolToken := (&continueToken{Resource: MachinePoolSchemaV1beta1.Resource, Continue: "abc"}).encode()
+
+	testCases := []test.TestCase{
+		{
+			Name:            "ListClusters should return Error for a malformed continue token",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The continue token invalid!token is invalid",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &test.K8sRequest{
+				ResourceName: "invalid!token",
+			},
+		},
+		{
+			Name:            "ListClusters should return Error for a continue token of another resource",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The continue token " + machinePoolToken + " is invalid",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &test.K8sRequest{
+				ResourceName: machinePoolToken,
+			},
+		},
+		{
+			Name:            "ListClusters should return Error for a cache continue token when the cache isn't available",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The continue token " + cachedToken + " has expired, the list must be started again",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &test.K8sRequest{
+				ResourceName: cachedToken,
+			},
+		},
+	}
+
+	k := &Kubernetes{K8sAuth: &Auth{
+		DynamicClient: test.NewK8sFakeDynamicClientWithResources([]runtime.Object{
+			test.NewTestCluster("testcluster1", "testcluster-kops-cp1", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster1", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+		}...),
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := k.ListClusters(ListOptions{Limit: 1, Continue: request.ResourceName})
+			assert.Assert(t, err != nil)
+			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package aws
+
+import (
+	"fmt"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// AWSMachineTemplate api is handled as an unstructured resource to avoid depending on the cluster-api-provider-aws go module
+const (
+	AWSMachineTemplateKind       = "AWSMachineTemplate"
+	AWSMachineTemplateAPIVersion = "infrastructure.cluster.x-k8s.io/v1beta1"
+)
+
+// GetInstanceType returns the EC2 instance type used by the machines of an AWSMachineTemplate
+func GetInstanceType(awsMachineTemplate *unstructured.Unstructured) string {
+	instanceType, _, _ := unstructured.NestedString(awsMachineTemplate.Object, "spec", "template", "spec", "instanceType")
+	return instanceType
+}
+
+// CloneAWSMachineTemplate returns a copy of an AWSMachineTemplate with a new name and instance type, AWSMachineTemplates are immutable so changes require a new template
+func CloneAWSMachineTemplate(awsMachineTemplate *unstructured.Unstructured, name string, instanceType string) (*unstructured.Unstructured, error) {
+	spec, _, err := unstructured.NestedMap(awsMachineTemplate.Object, "spec")
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidResource, fmt.Sprintf("AWSMachineTemplate %s doesn't have a valid spec", awsMachineTemplate.GetName()))
+	}
+
+	clone := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": spec,
+		},
+	}
+	clone.SetAPIVersion(awsMachineTemplate.GetAPIVersion())
+	clone.SetKind(awsMachineTemplate.GetKind())
+	clone.SetName(name)
+	clone.SetNamespace(awsMachineTemplate.GetNamespace())
+	clone.SetLabels(awsMachineTemplate.GetLabels())
+
+	err = unstructured.SetNestedField(clone.Object, instanceType, "spec", "template", "spec", "instanceType")
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidResource, fmt.Sprintf("Could not set the instance type of AWSMachineTemplate %s", name))
+	}
+	return clone, nil
+}
+
+// GetAWSMachineTemplate Returns an AWSMachineTemplate CR from a specific cluster
+func GetAWSMachineTemplate(k *k8s.Kubernetes, clusterName string, name string) (*unstructured.Unstructured, error) {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(k8s.AWSMachineTemplateSchemaV1beta1)
+
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	awsMachineTemplate, err := resource.Namespace(namespace).Get(k.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("The requested AWSMachineTemplate %s was not found in namespace %s!", name, namespace))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return nil, fmt.Errorf("Error getting awsmachinetemplate from Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return nil, fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	return awsMachineTemplate, nil
+}
+
+// CreateAWSMachineTemplate creates an AWSMachineTemplate CR for a specific cluster and returns the created resource
+func CreateAWSMachineTemplate(k *k8s.Kubernetes, clusterName string, awsMachineTemplate *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(k8s.AWSMachineTemplateSchemaV1beta1)
+
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	awsMachineTemplate.SetNamespace(namespace)
+	createdRaw, err := resource.Namespace(namespace).Create(k.Context(), awsMachineTemplate, metav1.CreateOptions{})
+	if err != nil {
+		if errors.IsAlreadyExists(err) {
+			return nil, clientError.NewClientError(err, clientError.ResourceAlreadyExists, fmt.Sprintf("The AWSMachineTemplate %s already exists in namespace %s!", awsMachineTemplate.GetName(), namespace))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return nil, fmt.Errorf("Error creating awsmachinetemplate in Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return nil, fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	return createdRaw, nil
+}
+
+// DeleteAWSMachineTemplate deletes an AWSMachineTemplate CR from a specific cluster
+func DeleteAWSMachineTemplate(k *k8s.Kubernetes, clusterName string, name string) error {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(k8s.AWSMachineTemplateSchemaV1beta1)
+
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return err
+	}
+	err = resource.Namespace(namespace).Delete(k.Context(), name, metav1.DeleteOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("The requested AWSMachineTemplate %s was not found in namespace %s!", name, namespace))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return fmt.Errorf("Error deleting awsmachinetemplate from Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	return nil
+}
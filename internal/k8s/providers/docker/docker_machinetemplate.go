@@ -0,0 +1,81 @@
+package docker
+
+import (
+	"fmt"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DockerMachineTemplate api is a test resource for cluster-api and its go module breaks often, so it's handled as an unstructured resource
+const (
+	DockerMachineTemplateKind       = "DockerMachineTemplate"
+	DockerMachineTemplateAPIVersion = "infrastructure.cluster.x-k8s.io/v1beta1"
+)
+
+// NewDockerMachineTemplate returns an empty DockerMachineTemplate in the namespace of a cluster
+func NewDockerMachineTemplate(namespace string, name string) *unstructured.Unstructured {
+	dockerMachineTemplate := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{},
+				},
+			},
+		},
+	}
+	dockerMachineTemplate.SetAPIVersion(DockerMachineTemplateAPIVersion)
+	dockerMachineTemplate.SetKind(DockerMachineTemplateKind)
+	dockerMachineTemplate.SetName(name)
+	dockerMachineTemplate.SetNamespace(namespace)
+	return dockerMachineTemplate
+}
+
+// CreateDockerMachineTemplate creates a DockerMachineTemplate CR for a specific cluster and returns the created resource
+func CreateDockerMachineTemplate(k *k8s.Kubernetes, clusterName string, dockerMachineTemplate *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(k8s.DockerMachineTemplateSchemaV1beta1)
+
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	dockerMachineTemplate.SetNamespace(namespace)
+	createdRaw, err := resource.Namespace(namespace).Create(k.Context(), dockerMachineTemplate, metav1.CreateOptions{})
+	if err != nil {
+		if errors.IsAlreadyExists(err) {
+			return nil, clientError.NewClientError(err, clientError.ResourceAlreadyExists, fmt.Sprintf("The DockerMachineTemplate %s already exists in namespace %s!", dockerMachineTemplate.GetName(), namespace))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return nil, fmt.Errorf("Error creating dockermachinetemplate in Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return nil, fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	return createdRaw, nil
+}
+
+// DeleteDockerMachineTemplate deletes a DockerMachineTemplate CR from a specific cluster
+func DeleteDockerMachineTemplate(k *k8s.Kubernetes, clusterName string, name string) error {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(k8s.DockerMachineTemplateSchemaV1beta1)
+
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return err
+	}
+	err = resource.Namespace(namespace).Delete(k.Context(), name, metav1.DeleteOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("The requested DockerMachineTemplate %s was not found in namespace %s!", name, namespace))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return fmt.Errorf("Error deleting dockermachinetemplate from Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package kops
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	clusterapikopsv1alpha1 "github.com/topfreegames/kubernetes-kops-operator/apis/infrastructure/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateKopsAWSCluster creates a KopsAWSCluster CR for a specific cluster and returns the created resource
+func CreateKopsAWSCluster(k *k8s.Kubernetes, clusterName string, kopsAWSCluster *clusterapikopsv1alpha1.KopsAWSCluster) (*clusterapikopsv1alpha1.KopsAWSCluster, error) {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(k8s.KopsAWSClusterSchemaV1alpha1)
+
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	kopsAWSClusterRaw, err := k8s.ToUnstructured(kopsAWSCluster)
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidResource, "could not convert kopsawscluster into a Kubernetes resource")
+	}
+
+	kopsAWSClusterRaw.SetNamespace(namespace)
+	createdRaw, err := resource.Namespace(namespace).Create(k.Context(), kopsAWSClusterRaw, metav1.CreateOptions{})
+	if err != nil {
+		if errors.IsAlreadyExists(err) {
+			return nil, clientError.NewClientError(err, clientError.ResourceAlreadyExists, fmt.Sprintf("The KopsAWSCluster %s already exists in namespace %s!", kopsAWSCluster.Name, namespace))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return nil, fmt.Errorf("Error creating kopsawscluster in Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return nil, fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	var createdKopsAWSCluster clusterapikopsv1alpha1.KopsAWSCluster
+	createdRawJson, err := createdRaw.MarshalJSON()
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidResource, "could not Marshal kopsawscluster response")
+	}
+
+	err = json.Unmarshal(createdRawJson, &createdKopsAWSCluster)
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidResource, "could not Unmarshal kopsawscluster JSON into clusterAPI")
+	}
+
+	return &createdKopsAWSCluster, nil
+}
+
+// DeleteKopsAWSCluster deletes a KopsAWSCluster CR from a specific cluster
+func DeleteKopsAWSCluster(k *k8s.Kubernetes, clusterName string, kopsAWSClusterName string) error {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(k8s.KopsAWSClusterSchemaV1alpha1)
+
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return err
+	}
+	err = resource.Namespace(namespace).Delete(k.Context(), kopsAWSClusterName, metav1.DeleteOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("The requested KopsAWSCluster %s was not found in namespace %s!", kopsAWSClusterName, namespace))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return fmt.Errorf("Error deleting kopsawscluster from Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package kops
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	clusterapikopscontrolplanev1alpha1 "github.com/topfreegames/kubernetes-kops-operator/apis/controlplane/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"os"
+	"strings"
+)
+
+// StateStoreEnv is the environment variable with the kops state store where the cluster configurations are kept, eg. s3://my-kops-state
+const StateStoreEnv = "KOPS_STATE_STORE"
+
+// GetConfigBase returns the kops configBase of a cluster inside the state store configured for the API
+func GetConfigBase(clusterName string) (string, error) {
+	stateStore := os.Getenv(StateStoreEnv)
+	if stateStore == "" {
+		return "", clientError.NewClientError(nil, clientError.InvalidConfiguration, fmt.Sprintf("The environment variable %s is not set, kops clusters can't be created", StateStoreEnv))
+	}
+	return fmt.Sprintf("%s/%s", strings.TrimSuffix(stateStore, "/"), clusterName), nil
+}
+
+// CreateKopsControlPlane creates a KopsControlPlane CR for a specific cluster and returns the created resource
+func CreateKopsControlPlane(k *k8s.Kubernetes, clusterName string, kopsControlPlane *clusterapikopscontrolplanev1alpha1.KopsControlPlane) (*clusterapikopscontrolplanev1alpha1.KopsControlPlane, error) {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(k8s.KopsControlPlaneSchemaV1alpha1)
+
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	kopsControlPlaneRaw, err := k8s.ToUnstructured(kopsControlPlane)
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidResource, "could not convert kopscontrolplane into a Kubernetes resource")
+	}
+
+	kopsControlPlaneRaw.SetNamespace(namespace)
+	createdRaw, err := resource.Namespace(namespace).Create(k.Context(), kopsControlPlaneRaw, metav1.CreateOptions{})
+	if err != nil {
+		if errors.IsAlreadyExists(err) {
+			return nil, clientError.NewClientError(err, clientError.ResourceAlreadyExists, fmt.Sprintf("The KopsControlPlane %s already exists in namespace %s!", kopsControlPlane.Name, namespace))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return nil, fmt.Errorf("Error creating kopscontrolplane in Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return nil, fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	var createdKopsControlPlane clusterapikopscontrolplanev1alpha1.KopsControlPlane
+	createdRawJson, err := createdRaw.MarshalJSON()
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidResource, "could not Marshal kopscontrolplane response")
+	}
+
+	err = json.Unmarshal(createdRawJson, &createdKopsControlPlane)
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidResource, "could not Unmarshal kopscontrolplane JSON into clusterAPI")
+	}
+
+	return &createdKopsControlPlane, nil
+}
+
+// DeleteKopsControlPlane deletes a KopsControlPlane CR from a specific cluster
+func DeleteKopsControlPlane(k *k8s.Kubernetes, clusterName string, kopsControlPlaneName string) error {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(k8s.KopsControlPlaneSchemaV1alpha1)
+
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return err
+	}
+	err = resource.Namespace(namespace).Delete(k.Context(), kopsControlPlaneName, metav1.DeleteOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("The requested KopsControlPlane %s was not found in namespace %s!", kopsControlPlaneName, namespace))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return fmt.Errorf("Error deleting kopscontrolplane from Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	return nil
+}
+
+// GetKopsControlPlane Returns a KopsControlPlane CR from a specific cluster
+func GetKopsControlPlane(k *k8s.Kubernetes, clusterName string, kopsControlPlaneName string) (*clusterapikopscontrolplanev1alpha1.KopsControlPlane, error) {
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	kopsControlPlaneRaw, err := k.GetResource(k8s.KopsControlPlaneSchemaV1alpha1, namespace, kopsControlPlaneName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("The requested KopsControlPlane %s was not found in namespace %s!", kopsControlPlaneName, namespace))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return nil, fmt.Errorf("Error getting kopscontrolplane from Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return nil, fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	var kopsControlPlane clusterapikopscontrolplanev1alpha1.KopsControlPlane
+	kopsControlPlaneRawJson, err := kopsControlPlaneRaw.MarshalJSON()
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidResource, "could not Marshal kopscontrolplane response")
+	}
+
+	err = json.Unmarshal(kopsControlPlaneRawJson, &kopsControlPlane)
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidResource, "could not Unmarshal kopscontrolplane JSON into clusterAPI")
+	}
+
+	return &kopsControlPlane, nil
+}
+
+// PatchKopsControlPlane applies a JSON merge patch to a KopsControlPlane CR from a specific cluster
+func PatchKopsControlPlane(k *k8s.Kubernetes, clusterName string, kopsControlPlaneName string, patch []byte) error {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(k8s.KopsControlPlaneSchemaV1alpha1)
+
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return err
+	}
+	_, err = resource.Namespace(namespace).Patch(k.Context(), kopsControlPlaneName, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("The requested KopsControlPlane %s was not found in namespace %s!", kopsControlPlaneName, namespace))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return fmt.Errorf("Error patching kopscontrolplane in Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	return nil
+}
@@ -1,7 +1,6 @@
 package kops
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/topfreegames/kaas-management-api/internal/k8s"
@@ -9,16 +8,16 @@ import (
 	clusterapikopsv1alpha1 "github.com/topfreegames/kubernetes-kops-operator/apis/infrastructure/v1alpha1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // GetKopsMachinePool Returns a KopsMachinePool CR from a specific cluster
 func GetKopsMachinePool(k *k8s.Kubernetes, clusterName string, infrastructureName string) (*clusterapikopsv1alpha1.KopsMachinePool, error) {
-	client := k.K8sAuth.DynamicClient
-
-	resource := client.Resource(k8s.KopsMachinePoolSchemaV1alpha1)
-
-	namespace := k8s.GetClusterNamespace(clusterName)
-	kopsMachinePoolRaw, err := resource.Namespace(namespace).Get(context.TODO(), infrastructureName, metav1.GetOptions{})
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	kopsMachinePoolRaw, err := k.GetResource(k8s.KopsMachinePoolSchemaV1alpha1, namespace, infrastructureName)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			return nil, clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("The requested KopsMachinePool %s was not found in namespace %s!", infrastructureName, clusterName))
@@ -41,3 +40,100 @@ func GetKopsMachinePool(k *k8s.Kubernetes, clusterName string, infrastructureNam
 
 	return &kopsMachinePool, nil
 }
+
+// CreateKopsMachinePool creates a KopsMachinePool CR for a specific cluster and returns the created resource
+func CreateKopsMachinePool(k *k8s.Kubernetes, clusterName string, kopsMachinePool *clusterapikopsv1alpha1.KopsMachinePool) (*clusterapikopsv1alpha1.KopsMachinePool, error) {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(k8s.KopsMachinePoolSchemaV1alpha1)
+
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	kopsMachinePoolRaw, err := k8s.ToUnstructured(kopsMachinePool)
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidResource, "could not convert kopsmachinepool into a Kubernetes resource")
+	}
+
+	kopsMachinePoolRaw.SetNamespace(namespace)
+	createdRaw, err := resource.Namespace(namespace).Create(k.Context(), kopsMachinePoolRaw, metav1.CreateOptions{})
+	if err != nil {
+		if errors.IsAlreadyExists(err) {
+			return nil, clientError.NewClientError(err, clientError.ResourceAlreadyExists, fmt.Sprintf("The KopsMachinePool %s already exists in namespace %s!", kopsMachinePool.Name, namespace))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return nil, fmt.Errorf("Error creating kopsmachinepool in Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return nil, fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	var createdKopsMachinePool clusterapikopsv1alpha1.KopsMachinePool
+	createdRawJson, err := createdRaw.MarshalJSON()
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidResource, "could not Marshal kopsmachinepool response")
+	}
+
+	err = json.Unmarshal(createdRawJson, &createdKopsMachinePool)
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidResource, "could not Unmarshal kopsmachinepool JSON into clusterAPI")
+	}
+
+	return &createdKopsMachinePool, nil
+}
+
+// DeleteKopsMachinePool deletes a KopsMachinePool CR from a specific cluster
+func DeleteKopsMachinePool(k *k8s.Kubernetes, clusterName string, infrastructureName string) error {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(k8s.KopsMachinePoolSchemaV1alpha1)
+
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return err
+	}
+	err = resource.Namespace(namespace).Delete(k.Context(), infrastructureName, metav1.DeleteOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("The requested KopsMachinePool %s was not found in namespace %s!", infrastructureName, namespace))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return fmt.Errorf("Error deleting kopsmachinepool from Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	return nil
+}
+
+// PatchKopsMachinePool applies a JSON merge patch to a KopsMachinePool CR from a specific cluster and returns the patched resource
+func PatchKopsMachinePool(k *k8s.Kubernetes, clusterName string, infrastructureName string, patch []byte) (*clusterapikopsv1alpha1.KopsMachinePool, error) {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(k8s.KopsMachinePoolSchemaV1alpha1)
+
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	patchedRaw, err := resource.Namespace(namespace).Patch(k.Context(), infrastructureName, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("The requested KopsMachinePool %s was not found in namespace %s!", infrastructureName, namespace))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return nil, fmt.Errorf("Error patching kopsmachinepool in Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return nil, fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	var patchedKopsMachinePool clusterapikopsv1alpha1.KopsMachinePool
+	patchedRawJson, err := patchedRaw.MarshalJSON()
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidResource, "could not Marshal kopsmachinepool response")
+	}
+
+	err = json.Unmarshal(patchedRawJson, &patchedKopsMachinePool)
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidResource, "could not Unmarshal kopsmachinepool JSON into clusterAPI")
+	}
+
+	return &patchedKopsMachinePool, nil
+}
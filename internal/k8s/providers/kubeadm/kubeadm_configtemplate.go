@@ -0,0 +1,84 @@
+package kubeadm
+
+import (
+	"fmt"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	KubeadmConfigTemplateKind       = "KubeadmConfigTemplate"
+	KubeadmConfigTemplateAPIVersion = "bootstrap.cluster.x-k8s.io/v1beta1"
+)
+
+// NewKubeadmConfigTemplate returns a KubeadmConfigTemplate that joins the nodes of a node group to the cluster of a namespace
+func NewKubeadmConfigTemplate(namespace string, name string) *unstructured.Unstructured {
+	kubeadmConfigTemplate := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"joinConfiguration": map[string]interface{}{
+							"nodeRegistration": map[string]interface{}{},
+						},
+					},
+				},
+			},
+		},
+	}
+	kubeadmConfigTemplate.SetAPIVersion(KubeadmConfigTemplateAPIVersion)
+	kubeadmConfigTemplate.SetKind(KubeadmConfigTemplateKind)
+	kubeadmConfigTemplate.SetName(name)
+	kubeadmConfigTemplate.SetNamespace(namespace)
+	return kubeadmConfigTemplate
+}
+
+// CreateKubeadmConfigTemplate creates a KubeadmConfigTemplate CR for a specific cluster and returns the created resource
+func CreateKubeadmConfigTemplate(k *k8s.Kubernetes, clusterName string, kubeadmConfigTemplate *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(k8s.KubeadmConfigTemplateSchemaV1beta1)
+
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	kubeadmConfigTemplate.SetNamespace(namespace)
+	createdRaw, err := resource.Namespace(namespace).Create(k.Context(), kubeadmConfigTemplate, metav1.CreateOptions{})
+	if err != nil {
+		if errors.IsAlreadyExists(err) {
+			return nil, clientError.NewClientError(err, clientError.ResourceAlreadyExists, fmt.Sprintf("The KubeadmConfigTemplate %s already exists in namespace %s!", kubeadmConfigTemplate.GetName(), namespace))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return nil, fmt.Errorf("Error creating kubeadmconfigtemplate in Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return nil, fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	return createdRaw, nil
+}
+
+// DeleteKubeadmConfigTemplate deletes a KubeadmConfigTemplate CR from a specific cluster
+func DeleteKubeadmConfigTemplate(k *k8s.Kubernetes, clusterName string, name string) error {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(k8s.KubeadmConfigTemplateSchemaV1beta1)
+
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return err
+	}
+	err = resource.Namespace(namespace).Delete(k.Context(), name, metav1.DeleteOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("The requested KubeadmConfigTemplate %s was not found in namespace %s!", name, namespace))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return fmt.Errorf("Error deleting kubeadmconfigtemplate from Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package kubeadm
+
+import (
+	"fmt"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// GetKubeadmControlPlane Returns a KubeadmControlPlane CR from a specific cluster
+func GetKubeadmControlPlane(k *k8s.Kubernetes, clusterName string, name string) (*unstructured.Unstructured, error) {
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	kubeadmControlPlane, err := k.GetResource(k8s.KubeadmControlPlaneSchemaV1beta1, namespace, name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("The requested KubeadmControlPlane %s was not found in namespace %s!", name, namespace))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return nil, fmt.Errorf("Error getting kubeadmcontrolplane from Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return nil, fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	return kubeadmControlPlane, nil
+}
+
+// GetKubeadmControlPlaneRolledOutVersion returns the kubernetes version reported in the status of a KubeadmControlPlane,
+// the oldest version of its machines, empty until it is reported
+func GetKubeadmControlPlaneRolledOutVersion(kubeadmControlPlane *unstructured.Unstructured) string {
+	version, _, _ := unstructured.NestedString(kubeadmControlPlane.Object, "status", "version")
+	return version
+}
+
+// GetKubeadmControlPlaneVersion returns the kubernetes version of a KubeadmControlPlane
+func GetKubeadmControlPlaneVersion(kubeadmControlPlane *unstructured.Unstructured) string {
+	version, _, _ := unstructured.NestedString(kubeadmControlPlane.Object, "spec", "version")
+	return version
+}
+
+// PatchKubeadmControlPlane applies a JSON merge patch to a KubeadmControlPlane CR from a specific cluster
+func PatchKubeadmControlPlane(k *k8s.Kubernetes, clusterName string, name string, patch []byte) error {
+	client := k.K8sAuth.DynamicClient
+
+	resource := client.Resource(k8s.KubeadmControlPlaneSchemaV1beta1)
+
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return err
+	}
+	_, err = resource.Namespace(namespace).Patch(k.Context(), name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("The requested KubeadmControlPlane %s was not found in namespace %s!", name, namespace))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return fmt.Errorf("Error patching kubeadmcontrolplane in Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	return nil
+}
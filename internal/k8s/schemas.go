@@ -3,10 +3,20 @@ package k8s
 import "k8s.io/apimachinery/pkg/runtime/schema"
 
 var (
+	NamespaceSchemaV1 = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "namespaces"}
+
 	ClusterResourceSchemaV1beta1   = schema.GroupVersionResource{Group: "cluster.x-k8s.io", Version: "v1beta1", Resource: "clusters"}
 	MachinePoolSchemaV1beta1       = schema.GroupVersionResource{Group: "cluster.x-k8s.io", Version: "v1beta1", Resource: "machinepools"}
 	MachineDeploymentSchemaV1beta1 = schema.GroupVersionResource{Group: "cluster.x-k8s.io", Version: "v1beta1", Resource: "machinedeployments"}
+	MachineSchemaV1beta1           = schema.GroupVersionResource{Group: "cluster.x-k8s.io", Version: "v1beta1", Resource: "machines"}
+
+	KopsControlPlaneSchemaV1alpha1   = schema.GroupVersionResource{Group: "controlplane.cluster.x-k8s.io", Version: "v1alpha1", Resource: "kopscontrolplanes"}
+	KubeadmControlPlaneSchemaV1beta1 = schema.GroupVersionResource{Group: "controlplane.cluster.x-k8s.io", Version: "v1beta1", Resource: "kubeadmcontrolplanes"}
+
+	KubeadmConfigTemplateSchemaV1beta1 = schema.GroupVersionResource{Group: "bootstrap.cluster.x-k8s.io", Version: "v1beta1", Resource: "kubeadmconfigtemplates"}
 
+	AWSMachineTemplateSchemaV1beta1    = schema.GroupVersionResource{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Resource: "awsmachinetemplates"}
 	DockerMachineTemplateSchemaV1beta1 = schema.GroupVersionResource{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Resource: "dockermachinetemplates"}
 	KopsMachinePoolSchemaV1alpha1      = schema.GroupVersionResource{Group: "infrastructure.cluster.x-k8s.io", Version: "v1alpha1", Resource: "kopsmachinepools"}
+	KopsAWSClusterSchemaV1alpha1       = schema.GroupVersionResource{Group: "infrastructure.cluster.x-k8s.io", Version: "v1alpha1", Resource: "kopsawsclusters"}
 )
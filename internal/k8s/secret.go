@@ -0,0 +1,43 @@
+package k8s
+
+import (
+	"fmt"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KubeconfigSecretKey is the key of the kubeconfig in the secret written by cluster-API for each cluster
+const KubeconfigSecretKey = "value"
+
+// GetKubeconfigSecretName returns the name of the secret where cluster-API stores the kubeconfig of a cluster
+func GetKubeconfigSecretName(clusterName string) string {
+	return fmt.Sprintf("%s-kubeconfig", clusterName)
+}
+
+// GetClusterKubeconfig Returns the kubeconfig of a cluster stored by cluster-API in the cluster namespace
+func (k Kubernetes) GetClusterKubeconfig(clusterName string) ([]byte, error) {
+	client := k.K8sAuth.Clientset
+
+	namespace, err := k.ClusterNamespace(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	secretName := GetKubeconfigSecretName(clusterName)
+	secret, err := client.CoreV1().Secrets(namespace).Get(k.Context(), secretName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, clientError.NewClientError(err, clientError.ResourceNotFound, fmt.Sprintf("The kubeconfig secret %s was not found in namespace %s!", secretName, namespace))
+		} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return nil, fmt.Errorf("Error getting kubeconfig secret from Kubernetes API: %s\n", statusError.ErrStatus.Message)
+		}
+		return nil, fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	kubeconfig, ok := secret.Data[KubeconfigSecretKey]
+	if !ok || len(kubeconfig) == 0 {
+		return nil, clientError.NewClientError(nil, clientError.InvalidResource, fmt.Sprintf("The kubeconfig secret %s doesn't have the %s key", secretName, KubeconfigSecretKey))
+	}
+
+	return kubeconfig, nil
+}
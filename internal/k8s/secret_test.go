@@ -0,0 +1,96 @@
+package k8s
+
+import (
+	"github.com/topfreegames/kaas-management-api/test"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"log"
+	"testing"
+)
+
+func Test_GetClusterKubeconfig_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:                "GetClusterKubeconfig should return Success for a cluster with a kubeconfig secret",
+			ExpectedSuccess:     test.NewTestKubeconfig("testcluster", "https://10.0.0.1:6443"),
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				Cluster: "testcluster",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestKubeconfigSecret("testcluster", test.NewTestKubeconfig("testcluster", "https://10.0.0.1:6443")),
+				test.NewTestKubeconfigSecret("testcluster2", test.NewTestKubeconfig("testcluster2", "https://10.0.0.2:6443")),
+			},
+		},
+	}
+
+	k := &Kubernetes{K8sAuth: &Auth{
+		Clientset: test.NewK8sFakeClientset(),
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		expectedKubeconfig, ok := testCase.ExpectedSuccess.([]byte)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to []byte", testCase.Name)
+		}
+		k.K8sAuth.Clientset = test.NewK8sFakeClientset(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			response, err := k.GetClusterKubeconfig(request.Cluster)
+			assert.NilError(t, err)
+			assert.Equal(t, string(expectedKubeconfig), string(response))
+		})
+	}
+}
+
+func Test_GetClusterKubeconfig_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "GetClusterKubeconfig should return Error for a cluster without kubeconfig secret",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The kubeconfig secret testcluster-kubeconfig was not found in namespace kubernetes-testcluster!",
+				ErrorMessage:         clientError.ResourceNotFound,
+			},
+			Request: &test.K8sRequest{
+				Cluster: "testcluster",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestKubeconfigSecret("testcluster2", test.NewTestKubeconfig("testcluster2", "https://10.0.0.2:6443")),
+			},
+		},
+		{
+			Name:            "GetClusterKubeconfig should return Error for a kubeconfig secret without the kubeconfig",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The kubeconfig secret testcluster-kubeconfig doesn't have the value key",
+				ErrorMessage:         clientError.InvalidResource,
+			},
+			Request: &test.K8sRequest{
+				Cluster: "testcluster",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestKubeconfigSecret("testcluster", nil),
+			},
+		},
+	}
+
+	k := &Kubernetes{K8sAuth: &Auth{
+		Clientset: test.NewK8sFakeClientset(),
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		k.K8sAuth.Clientset = test.NewK8sFakeClientset(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := k.GetClusterKubeconfig(request.Cluster)
+			assert.ErrorContains(t, err, testCase.ExpectedClientError.Error())
+			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package k8s
+
+import (
+	"fmt"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ToUnstructured converts a typed Kubernetes resource into the unstructured format used by the dynamic client
+func ToUnstructured(resource interface{}) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(resource)
+	if err != nil {
+		return nil, fmt.Errorf("could not convert resource to unstructured: %v", err)
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
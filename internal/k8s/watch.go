@@ -0,0 +1,37 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// WatchResources starts a watch of the resources of a namespace, or of all namespaces when it is empty, in the Kubernetes API.
+// The watch starts after resourceVersion, or with the current resources when it is empty, and receives bookmarks.
+// The watch always goes to the Kubernetes API and must be stopped by the caller.
+func (k Kubernetes) WatchResources(ctx context.Context, resource schema.GroupVersionResource, namespace string, resourceVersion string) (watch.Interface, error) {
+	client := k.K8sAuth.DynamicClient
+
+	options := metav1.ListOptions{
+		ResourceVersion:     resourceVersion,
+		AllowWatchBookmarks: true,
+	}
+	var watcher watch.Interface
+	var err error
+	if namespace == "" {
+		watcher, err = client.Resource(resource).Watch(ctx, options)
+	} else {
+		watcher, err = client.Resource(resource).Namespace(namespace).Watch(ctx, options)
+	}
+	if err != nil {
+		if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			return nil, fmt.Errorf("Error watching %s in Kubernetes API: %s\n", resource.Resource, statusError.ErrStatus.Message)
+		}
+		return nil, fmt.Errorf("Kube go-client Error: %v\n", err)
+	}
+
+	return watcher, nil
+}
@@ -3,12 +3,38 @@ package kaas
 import (
 	"fmt"
 	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/internal/tracing"
 	"github.com/topfreegames/kaas-management-api/util/clientError"
-	"log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	"net"
 	"sigs.k8s.io/cluster-api/api/v1beta1"
 	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"strings"
+	"time"
 )
 
+// Defaults used when a new cluster specification doesn't set its networks
+const (
+	defaultNetworkCIDR    = "172.20.0.0/16"
+	defaultServiceCIDR    = "100.64.0.0/13"
+	defaultPodCIDR        = "100.96.0.0/11"
+	kopsNonMasqueradeCIDR = "100.64.0.0/10"
+	controlPlanePort      = 443
+)
+
+// Topologies of a new cluster, the control plane and nodes of a private cluster aren't reachable from outside its network
+const (
+	ClusterTopologyPrivate = "private"
+	ClusterTopologyPublic  = "public"
+)
+
+// DeletionProtectionKey is the label or annotation that protects a cluster from being deleted through the API when set to "true"
+const DeletionProtectionKey = "deletionProtection"
+
 type Cluster struct {
 	Name                     string
 	ApiEndpoint              string
@@ -18,11 +44,59 @@ type Cluster struct {
 	ClusterGroup             string
 	Environment              string
 	CIDR                     []string
-	ControlPlane             *ClusterControlPlane
-	Infrastructure           *ClusterInfrastructure
+	// Metadata are the values read from the labels and annotations declared in the metadata mapping
+	Metadata           map[string]interface{}
+	DeletionProtection bool
+	ControlPlane       *ClusterControlPlane
+	Infrastructure     *ClusterInfrastructure
+	Status             ClusterStatus
+}
+
+// ClusterStatus is the state of a cluster as reported by cluster-API
+type ClusterStatus struct {
+	Phase               string
+	ControlPlaneReady   bool
+	InfrastructureReady bool
+	FailureReason       string
+	FailureMessage      string
+	Conditions          []ClusterCondition
+}
+
+// ClusterCondition is an observation of a cluster state, like its control plane being ready
+type ClusterCondition struct {
+	Type               string
+	Status             string
+	Severity           string
+	Reason             string
+	Message            string
+	LastTransitionTime time.Time
+}
+
+// ClusterSpec is the provider agnostic specification used to create a new cluster
+type ClusterSpec struct {
+	Name                   string
+	KubernetesVersion      string
+	KubeProvider           string
+	InfrastructureProvider string
+	Region                 string
+	ClusterGroup           string
+	Environment            string
+	Zones                  []string
+	NetworkCIDR            string
+	ServiceCIDR            string
+	PodCIDR                string
+	// Topology is private or public, private by default
+	Topology string
+	// APIAccessCIDRs and SSHAccessCIDRs are the networks allowed to reach the kubernetes API and to SSH into the
+	// machines, only the cluster network by default
+	APIAccessCIDRs []string
+	SSHAccessCIDRs []string
 }
 
-func GetCluster(k *k8s.Kubernetes, name string) (*Cluster, error) {
+func GetCluster(k *k8s.Kubernetes, name string) (_ *Cluster, err error) {
+	ctx, span := tracing.Start(k.Context(), "kaas.GetCluster", attribute.String("kaas.cluster", name))
+	defer func() { tracing.End(span, err) }()
+	k = k.WithContext(ctx)
 
 	clusterAPICR, err := k.GetCluster(name)
 	if err != nil {
@@ -44,7 +118,7 @@ func GetCluster(k *k8s.Kubernetes, name string) (*Cluster, error) {
 	}
 
 	cluster := &Cluster{}
-	err = cluster.GetClusterProperties(clusterAPICR)
+	err = cluster.GetClusterProperties(clusterAPICR, GetMetadataMapping(k))
 	if err != nil {
 		clientErr, ok := err.(*clientError.ClientError)
 		if !ok {
@@ -55,26 +129,42 @@ func GetCluster(k *k8s.Kubernetes, name string) (*Cluster, error) {
 			}
 		}
 	}
+	cluster.readControlPlaneVersion(k, clusterAPICR)
 
 	return cluster, nil
 }
 
-func ListClusters(k *k8s.Kubernetes) ([]*Cluster, error) {
+// ListClusters returns one page of clusters and the token of the next page, which is empty in the last page.
+// The options label selector can also select the cluster properties, like KubeProviderProperty, which are selected
+// after listing the page so it may have fewer clusters than the limit.
+func ListClusters(k *k8s.Kubernetes, options k8s.ListOptions) (_ []*Cluster, _ string, err error) {
+	ctx, span := tracing.Start(k.Context(), "kaas.ListClusters")
+	defer func() { tracing.End(span, err) }()
+	k = k.WithContext(ctx)
 
-	var clusterList []*Cluster
+	var (
+		clusterList []*Cluster
+		hasInvalid  bool
+	)
 
-	clusterListAPICR, err := k.ListClusters()
+	mapping := GetMetadataMapping(k)
+	labelSelector, propertySelector := splitClusterSelector(options.LabelSelector, mapping)
+	options.LabelSelector = labelSelector
+
+	clusterListAPICR, err := k.ListClusters(options)
 	if err != nil {
 		clientErr, ok := err.(*clientError.ClientError)
 		if !ok {
-			return nil, clientError.NewClientError(clientErr, clientError.UnexpectedError, "Error listing clusters")
+			return nil, "", clientError.NewClientError(clientErr, clientError.UnexpectedError, "Error listing clusters")
 		} else {
 			if clientErr.ErrorMessage == clientError.ResourceNotFound {
-				return nil, clientErr
+				return nil, "", clientErr
 			} else if clientErr.ErrorMessage == clientError.EmptyResponse {
-				return nil, clientErr
+				return nil, "", clientErr
+			} else if clientErr.ErrorMessage == clientError.InvalidRequest {
+				return nil, "", clientErr
 			} else {
-				return nil, clientError.NewClientError(clientErr, clientError.UnexpectedError, "Something went wrong when listing clusters")
+				return nil, "", clientError.NewClientError(clientErr, clientError.UnexpectedError, "Something went wrong when listing clusters")
 			}
 		}
 	}
@@ -83,29 +173,39 @@ func ListClusters(k *k8s.Kubernetes) ([]*Cluster, error) {
 		cluster := &Cluster{}
 		err = ValidateClusterComponents(&clusterAPICR)
 		if err != nil {
-			log.Printf("Skipping cluster %s because of invalid configuration: %s", cluster.Name, err.Error())
+			k.Log().Warn("Skipping cluster with invalid configuration", zap.String("cluster", clusterAPICR.Name), zap.Error(err))
+			hasInvalid = true
 			continue
 		}
-		err = cluster.GetClusterProperties(&clusterAPICR)
+		err = cluster.GetClusterProperties(&clusterAPICR, mapping)
 		if err != nil {
 			clientErr, ok := err.(*clientError.ClientError)
 			if !ok {
-				log.Printf("Skipping cluster %s: An Unexpected error happened while reading the cluster properties: %s", clusterAPICR.Name, err.Error())
+				k.Log().Error("Skipping cluster, an unexpected error happened while reading the cluster properties", zap.String("cluster", clusterAPICR.Name), zap.Error(err))
 			} else {
 				if clientErr.ErrorMessage == clientError.InvalidConfiguration {
-					log.Printf("Skipping cluster %s: Cluster is invalid due to missing or invalid labels: %s", clusterAPICR.Name, err.Error())
+					k.Log().Warn("Skipping cluster with missing or invalid labels", zap.String("cluster", clusterAPICR.Name), zap.Error(err))
 				}
 			}
+			hasInvalid = true
+			continue
+		}
+		if !propertySelector.Matches(cluster.properties(mapping)) {
 			continue
 		}
+		cluster.readControlPlaneVersion(k, &clusterAPICR)
 		clusterList = append(clusterList, cluster)
 	}
 
-	if len(clusterList) == 0 {
-		return nil, clientError.NewClientError(nil, clientError.EmptyResponse, "No valid clusters were found, some clusters have invalid configuration")
+	// A page may only have invalid or filtered clusters while the next ones are selected
+	if len(clusterList) == 0 && clusterListAPICR.Continue == "" {
+		if hasInvalid {
+			return nil, "", clientError.NewClientError(nil, clientError.EmptyResponse, "No valid clusters were found, some clusters have invalid configuration")
+		}
+		return nil, "", clientError.NewClientError(nil, clientError.EmptyResponse, "No clusters were found")
 	}
 
-	return clusterList, nil
+	return clusterList, clusterListAPICR.Continue, nil
 }
 
 // TODO do the validation on each Get method from each component
@@ -124,16 +224,19 @@ func ValidateClusterComponents(cluster *clusterapiv1beta1.Cluster) error {
 	return nil
 }
 
-func (c *Cluster) GetClusterProperties(clusterAPICR *v1beta1.Cluster) error {
+// GetClusterProperties reads the properties of a cluster-API cluster, its metadata is read from the labels and annotations of the mapping
+func (c *Cluster) GetClusterProperties(clusterAPICR *v1beta1.Cluster, mapping *MetadataMapping) error {
 	c.Name = clusterAPICR.Name
 	c.ControlPlaneEndpointHost = clusterAPICR.Spec.ControlPlaneEndpoint.Host
 	c.ControlPlaneEndpointPort = clusterAPICR.Spec.ControlPlaneEndpoint.Port
 	c.ApiEndpoint = fmt.Sprintf("https://%s:%d", c.ControlPlaneEndpointHost, c.ControlPlaneEndpointPort)
-	// TODO: turn customizable
-	c.ClusterGroup = clusterAPICR.Labels["clusterGroup"]
-	c.Region = clusterAPICR.Labels["region"]
-	c.Environment = clusterAPICR.Labels["environment"]
+	c.Metadata = mapping.getClusterMetadata(clusterAPICR)
+	c.ClusterGroup = mapping.getStringMetadata(clusterAPICR, ClusterGroupMetadataKey)
+	c.Region = mapping.getStringMetadata(clusterAPICR, RegionMetadataKey)
+	c.Environment = mapping.getStringMetadata(clusterAPICR, EnvironmentMetadataKey)
 	c.CIDR = clusterAPICR.Spec.ClusterNetwork.Services.CIDRBlocks
+	c.DeletionProtection = IsDeletionProtected(clusterAPICR)
+	c.Status = getClusterStatus(clusterAPICR)
 
 	cp, err := GetControlPlane(clusterAPICR.Spec.ControlPlaneRef.Kind)
 	if err != nil {
@@ -165,3 +268,366 @@ func (c *Cluster) GetClusterProperties(clusterAPICR *v1beta1.Cluster) error {
 	}
 	return nil
 }
+
+// getClusterStatus returns the phase, readiness, failures and conditions reported in the status of a cluster-API cluster
+func getClusterStatus(clusterAPICR *clusterapiv1beta1.Cluster) ClusterStatus {
+	status := ClusterStatus{
+		Phase:               clusterAPICR.Status.Phase,
+		ControlPlaneReady:   clusterAPICR.Status.ControlPlaneReady,
+		InfrastructureReady: clusterAPICR.Status.InfrastructureReady,
+	}
+
+	if clusterAPICR.Status.FailureReason != nil {
+		status.FailureReason = string(*clusterAPICR.Status.FailureReason)
+	}
+	if clusterAPICR.Status.FailureMessage != nil {
+		status.FailureMessage = *clusterAPICR.Status.FailureMessage
+	}
+
+	for _, condition := range clusterAPICR.Status.Conditions {
+		status.Conditions = append(status.Conditions, ClusterCondition{
+			Type:               string(condition.Type),
+			Status:             string(condition.Status),
+			Severity:           string(condition.Severity),
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+			LastTransitionTime: condition.LastTransitionTime.UTC(),
+		})
+	}
+
+	return status
+}
+
+// GetControlPlaneEndpointHost returns the host of the kubernetes API of a new cluster
+func GetControlPlaneEndpointHost(clusterName string) string {
+	return fmt.Sprintf("api.%s", clusterName)
+}
+
+// CreateCluster creates the cluster-API cluster with its control plane and infrastructure resources from a provider agnostic specification
+func CreateCluster(k *k8s.Kubernetes, spec *ClusterSpec) (_ *Cluster, err error) {
+	ctx, span := tracing.Start(k.Context(), "kaas.CreateCluster", attribute.String("kaas.cluster", spec.Name))
+	defer func() { tracing.End(span, err) }()
+	k = k.WithContext(ctx)
+	err = spec.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = k.GetCluster(spec.Name)
+	if err == nil {
+		return nil, clientError.NewClientError(nil, clientError.ResourceAlreadyExists, fmt.Sprintf("Cluster %s already exists", spec.Name))
+	}
+	clientErr, ok := err.(*clientError.ClientError)
+	if !ok || clientErr.ErrorMessage != clientError.ResourceNotFound {
+		return nil, clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Something went wrong while checking if cluster %s exists", spec.Name))
+	}
+
+	// The new cluster resources are created in the namespace chosen for new clusters, since the cluster can't be found by lookups yet.
+	// They are rolled back with a detached context, so a canceled request doesn't leave them orphaned.
+	k = k.ForNewClusters()
+	namespaceCreated, err := k.CreateNamespace(k.NewClusterNamespace(spec.Name))
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Could not create the namespace for cluster %s", spec.Name))
+	}
+
+	controlPlaneRef, err := createControlPlane(k, spec)
+	if err != nil {
+		rollbackNewClusterNamespace(k.Detached(), spec.Name, namespaceCreated)
+		clientErr, ok := err.(*clientError.ClientError)
+		if ok && clientErr.ErrorMessage == clientError.InvalidConfiguration {
+			return nil, clientErr
+		}
+		return nil, clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Could not create the control plane for cluster %s", spec.Name))
+	}
+
+	infrastructureRef, err := createClusterInfrastructure(k, spec)
+	if err != nil {
+		rollbackErr := deleteControlPlane(k.Detached(), spec.Name, controlPlaneRef)
+		if rollbackErr != nil {
+			k.Log().Error("Could not rollback control plane", zap.String("controlPlane", controlPlaneRef.Name), zap.String("cluster", spec.Name), zap.Error(rollbackErr))
+		}
+		rollbackNewClusterNamespace(k.Detached(), spec.Name, namespaceCreated)
+		return nil, clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Could not create the infrastructure for cluster %s", spec.Name))
+	}
+
+	mapping := GetMetadataMapping(k)
+	clusterAPICR, err := k.CreateCluster(newClusterAPICR(k, spec, mapping, controlPlaneRef, infrastructureRef))
+	if err != nil {
+		rollbackErr := deleteControlPlane(k.Detached(), spec.Name, controlPlaneRef)
+		if rollbackErr != nil {
+			k.Log().Error("Could not rollback control plane", zap.String("controlPlane", controlPlaneRef.Name), zap.String("cluster", spec.Name), zap.Error(rollbackErr))
+		}
+		rollbackErr = deleteClusterInfrastructure(k.Detached(), spec.Name, infrastructureRef)
+		if rollbackErr != nil {
+			k.Log().Error("Could not rollback infrastructure", zap.String("infrastructure", infrastructureRef.Name), zap.String("cluster", spec.Name), zap.Error(rollbackErr))
+		}
+		rollbackNewClusterNamespace(k.Detached(), spec.Name, namespaceCreated)
+		return nil, clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Could not create cluster %s", spec.Name))
+	}
+
+	cluster := &Cluster{}
+	err = cluster.GetClusterProperties(clusterAPICR, mapping)
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Cluster %s was created but its properties could not be read", spec.Name))
+	}
+	cluster.ControlPlane.Version = spec.KubernetesVersion
+
+	return cluster, nil
+}
+
+// rollbackNewClusterNamespace deletes the namespace of a cluster whose creation failed, a namespace that already existed is kept
+// since it may have the resources of other clusters
+func rollbackNewClusterNamespace(k *k8s.Kubernetes, clusterName string, namespaceCreated bool) {
+	if !namespaceCreated {
+		return
+	}
+
+	namespace := k.NewClusterNamespace(clusterName)
+	err := k.DeleteNamespace(namespace)
+	if err != nil {
+		k.Log().Error("Could not rollback namespace", zap.String("namespace", namespace), zap.String("cluster", clusterName), zap.Error(err))
+	}
+}
+
+// validate checks if the cluster specification is complete and supported, filling the optional fields with defaults
+func (s *ClusterSpec) validate() error {
+	if s.Name == "" {
+		return clientError.NewClientError(nil, clientError.InvalidRequest, "Cluster name can't be empty")
+	}
+
+	if s.KubernetesVersion == "" {
+		return clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("Cluster %s must have a kubernetes version", s.Name))
+	}
+
+	if len(s.Zones) == 0 {
+		return clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("Cluster %s must have at least one zone", s.Name))
+	}
+
+	// Only kops clusters can be created for now
+	if s.KubeProvider != "kops" || s.InfrastructureProvider != "kops" {
+		return clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("The providers %s/%s are not supported for cluster creation", s.KubeProvider, s.InfrastructureProvider))
+	}
+
+	if s.NetworkCIDR == "" {
+		s.NetworkCIDR = defaultNetworkCIDR
+	}
+	if s.ServiceCIDR == "" {
+		s.ServiceCIDR = defaultServiceCIDR
+	}
+	if s.PodCIDR == "" {
+		s.PodCIDR = defaultPodCIDR
+	}
+
+	if s.Topology == "" {
+		s.Topology = ClusterTopologyPrivate
+	}
+	if s.Topology != ClusterTopologyPrivate && s.Topology != ClusterTopologyPublic {
+		return clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("Cluster %s topology must be %s or %s, not %s", s.Name, ClusterTopologyPrivate, ClusterTopologyPublic, s.Topology))
+	}
+
+	if len(s.APIAccessCIDRs) == 0 {
+		s.APIAccessCIDRs = []string{s.NetworkCIDR}
+	}
+	if len(s.SSHAccessCIDRs) == 0 {
+		s.SSHAccessCIDRs = []string{s.NetworkCIDR}
+	}
+	for _, cidr := range append(append([]string{s.NetworkCIDR}, s.APIAccessCIDRs...), s.SSHAccessCIDRs...) {
+		_, _, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return clientError.NewClientError(err, clientError.InvalidRequest, fmt.Sprintf("Cluster %s has an invalid CIDR %s", s.Name, cidr))
+		}
+	}
+	return nil
+}
+
+// newClusterAPICR returns the cluster-API cluster CR of a new cluster pointing to its control plane and infrastructure resources,
+// its namespace and labels are chosen by the namespace resolver and its metadata is written in the labels or annotations of the mapping
+func newClusterAPICR(k *k8s.Kubernetes, spec *ClusterSpec, mapping *MetadataMapping, controlPlaneRef *corev1.ObjectReference, infrastructureRef *corev1.ObjectReference) *clusterapiv1beta1.Cluster {
+	clusterAPICR := &clusterapiv1beta1.Cluster{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Cluster",
+			APIVersion: clusterapiv1beta1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      spec.Name,
+			Namespace: k.NewClusterNamespace(spec.Name),
+			Labels:    k.NewClusterLabels(spec.Name),
+		},
+		Spec: clusterapiv1beta1.ClusterSpec{
+			ClusterNetwork: &clusterapiv1beta1.ClusterNetwork{
+				Services:      &clusterapiv1beta1.NetworkRanges{CIDRBlocks: []string{spec.ServiceCIDR}},
+				Pods:          &clusterapiv1beta1.NetworkRanges{CIDRBlocks: []string{spec.PodCIDR}},
+				ServiceDomain: "cluster.local",
+			},
+			ControlPlaneEndpoint: clusterapiv1beta1.APIEndpoint{
+				Host: GetControlPlaneEndpointHost(spec.Name),
+				Port: controlPlanePort,
+			},
+			ControlPlaneRef:   controlPlaneRef,
+			InfrastructureRef: infrastructureRef,
+		},
+	}
+
+	mapping.setClusterMetadata(clusterAPICR, map[string]string{
+		ClusterGroupMetadataKey: spec.ClusterGroup,
+		RegionMetadataKey:       spec.Region,
+		EnvironmentMetadataKey:  spec.Environment,
+	})
+	return clusterAPICR
+}
+
+// IsDeletionProtected returns true if the cluster-API cluster has the deletion protection label or annotation enabled
+func IsDeletionProtected(clusterAPICR *clusterapiv1beta1.Cluster) bool {
+	return clusterAPICR.Labels[DeletionProtectionKey] == "true" || clusterAPICR.Annotations[DeletionProtectionKey] == "true"
+}
+
+// DeleteCluster deletes a cluster and all its resources, the confirmation must be the cluster name and protected clusters are refused
+func DeleteCluster(k *k8s.Kubernetes, name string, confirmation string) (err error) {
+	ctx, span := tracing.Start(k.Context(), "kaas.DeleteCluster", attribute.String("kaas.cluster", name))
+	defer func() { tracing.End(span, err) }()
+	k = k.WithContext(ctx)
+	if confirmation != name {
+		return clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("The cluster name must be confirmed to delete the cluster %s", name))
+	}
+
+	clusterAPICR, err := k.GetCluster(name)
+	if err != nil {
+		clientErr, ok := err.(*clientError.ClientError)
+		if ok && clientErr.ErrorMessage == clientError.ResourceNotFound {
+			return clientError.NewClientError(clientErr, clientError.ResourceNotFound, fmt.Sprintf("Could not find cluster %s", name))
+		}
+		return clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Error getting cluster %s", name))
+	}
+
+	if IsDeletionProtected(clusterAPICR) {
+		return clientError.NewClientError(nil, clientError.DeletionProtected, fmt.Sprintf("Cluster %s is protected against deletion, remove the %s label and annotation to delete it", name, DeletionProtectionKey))
+	}
+
+	err = k.DeleteCluster(name)
+	if err != nil {
+		clientErr, ok := err.(*clientError.ClientError)
+		if ok && clientErr.ErrorMessage == clientError.ResourceNotFound {
+			return clientError.NewClientError(clientErr, clientError.ResourceNotFound, fmt.Sprintf("Could not find cluster %s", name))
+		}
+		return clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Error deleting cluster %s", name))
+	}
+
+	return nil
+}
+
+// UpgradeCluster changes the kubernetes version of the cluster control plane or, when upgradeNodeGroups is set, of the
+// machines of all its node groups, which is only allowed once the control plane runs the desired version
+func UpgradeCluster(k *k8s.Kubernetes, name string, kubernetesVersion string, upgradeNodeGroups bool) (_ *Cluster, err error) {
+	ctx, span := tracing.Start(k.Context(), "kaas.UpgradeCluster", attribute.String("kaas.cluster", name))
+	defer func() { tracing.End(span, err) }()
+	k = k.WithContext(ctx)
+	desiredVersion, err := version.ParseSemantic(kubernetesVersion)
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidRequest, fmt.Sprintf("%s is not a valid kubernetes version", kubernetesVersion))
+	}
+
+	clusterAPICR, err := k.GetCluster(name)
+	if err != nil {
+		clientErr, ok := err.(*clientError.ClientError)
+		if ok && clientErr.ErrorMessage == clientError.ResourceNotFound {
+			return nil, clientError.NewClientError(clientErr, clientError.ResourceNotFound, fmt.Sprintf("Could not find cluster %s", name))
+		}
+		return nil, clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Error getting cluster %s", name))
+	}
+
+	err = ValidateClusterComponents(clusterAPICR)
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidConfiguration, fmt.Sprintf("Cluster %s have an invalid configuration", name))
+	}
+
+	cluster := &Cluster{}
+	err = cluster.GetClusterProperties(clusterAPICR, GetMetadataMapping(k))
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidConfiguration, fmt.Sprintf("Cluster %s is invalid due to missing or invalid labels", name))
+	}
+
+	// The versions are read from the Kubernetes API since the upgrade is decided on them
+	rawCurrentVersion, rawRunningVersion, err := getControlPlaneVersion(k.Uncached(), name, clusterAPICR.Spec.ControlPlaneRef)
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Could not get the kubernetes version of cluster %s", name))
+	}
+
+	currentVersion, err := version.ParseGeneric(rawCurrentVersion)
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidConfiguration, fmt.Sprintf("Cluster %s control plane has an invalid kubernetes version %s", name, rawCurrentVersion))
+	}
+	cluster.ControlPlane.Version = currentVersion.String()
+
+	if upgradeNodeGroups {
+		if clusterAPICR.Spec.ControlPlaneRef.Kind == "KopsControlPlane" {
+			return nil, clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("Upgrading the NodeGroups of cluster %s is not supported by the control plane provider kops, it doesn't report the version its control plane runs", name))
+		}
+
+		err = validateNodeGroupsUpgrade(name, currentVersion, rawRunningVersion, desiredVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		err = upgradeNodeGroupsVersion(k, name, desiredVersion)
+		if err != nil {
+			return nil, err
+		}
+		return cluster, nil
+	}
+
+	err = validateUpgradeVersion(name, currentVersion, desiredVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	err = updateControlPlaneVersion(k, name, clusterAPICR.Spec.ControlPlaneRef, desiredVersion)
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Could not upgrade the control plane of cluster %s", name))
+	}
+	cluster.ControlPlane.Version = desiredVersion.String()
+
+	return cluster, nil
+}
+
+// validateNodeGroupsUpgrade checks if the node groups of a cluster can be upgraded to the desired version, which is only
+// allowed after the control plane was upgraded to it and runs it, so the nodes are never newer than the control plane
+func validateNodeGroupsUpgrade(clusterName string, currentVersion *version.Version, rawRunningVersion string, desiredVersion *version.Version) error {
+	if currentVersion.String() != desiredVersion.String() {
+		return clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("The control plane of cluster %s is at version %s, it must be upgraded to %s without upgrading the NodeGroups first", clusterName, currentVersion, desiredVersion))
+	}
+
+	runningVersion, err := version.ParseGeneric(rawRunningVersion)
+	if err != nil || runningVersion.String() != desiredVersion.String() {
+		return clientError.NewClientError(err, clientError.InvalidRequest, fmt.Sprintf("The control plane of cluster %s is still being upgraded to %s, the NodeGroups can only be upgraded once it runs this version", clusterName, desiredVersion))
+	}
+
+	return nil
+}
+
+// upgradeNodeGroupsVersion changes the kubernetes version of the machines of every node group of a cluster, a failing node group doesn't stop the others from being upgraded
+func upgradeNodeGroupsVersion(k *k8s.Kubernetes, clusterName string, kubernetesVersion *version.Version) error {
+	nodeGroups, err := GetNodeGroupListConfig(k, clusterName)
+	if err != nil {
+		clientErr, ok := err.(*clientError.ClientError)
+		if ok && clientErr.ErrorMessage == clientError.EmptyResponse {
+			return nil
+		}
+		return clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("The NodeGroups of cluster %s could not be listed to be upgraded", clusterName))
+	}
+
+	var failedNodeGroups []string
+	for _, nodeGroup := range nodeGroups {
+		// cluster-API resources require the version with the "v" prefix
+		err = nodeGroup.updateVersion(k, fmt.Sprintf("v%s", kubernetesVersion))
+		if err != nil {
+			k.Log().Error("Could not upgrade NodeGroup", zap.String("nodeGroup", nodeGroup.Name), zap.String("cluster", clusterName), zap.Error(err))
+			failedNodeGroups = append(failedNodeGroups, nodeGroup.Name)
+		}
+	}
+
+	if len(failedNodeGroups) != 0 {
+		return clientError.NewClientError(nil, clientError.UnexpectedError, fmt.Sprintf("The NodeGroups %s of cluster %s could not be upgraded", strings.Join(failedNodeGroups, ", "), clusterName))
+	}
+
+	return nil
+}
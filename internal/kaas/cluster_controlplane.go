@@ -1,12 +1,25 @@
 package kaas
 
 import (
+	"encoding/json"
 	"fmt"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/internal/k8s/providers/kops"
+	"github.com/topfreegames/kaas-management-api/internal/k8s/providers/kubeadm"
 	"github.com/topfreegames/kaas-management-api/util/clientError"
+	clusterapikopscontrolplanev1alpha1 "github.com/topfreegames/kubernetes-kops-operator/apis/controlplane/v1alpha1"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	kopsv1alpha2 "k8s.io/kops/pkg/apis/kops/v1alpha2"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
 type ClusterControlPlane struct {
 	Provider string
+	// Version is the kubernetes version set in the control plane resource, it's empty when it can't be read
+	Version string
 }
 
 // TODO Change to get a Cluster CR as parameter, validate it and return all desired CP info
@@ -31,3 +44,244 @@ func GetControlPlane(controlPlaneKind string) (*ClusterControlPlane, error) {
 
 	return nil, clientError.NewClientError(nil, clientError.KindNotFound, fmt.Sprintf("The Kind %s could not be found", controlPlaneKind))
 }
+
+// GetControlPlaneName returns the name of the control plane resource of a cluster
+func GetControlPlaneName(clusterName string) string {
+	return fmt.Sprintf("%s-control-plane", clusterName)
+}
+
+// createControlPlane creates the control plane resource of the cluster provider and returns a reference to it
+func createControlPlane(k *k8s.Kubernetes, spec *ClusterSpec) (*corev1.ObjectReference, error) {
+	switch spec.KubeProvider {
+	case "kops":
+		kopsControlPlane, err := newKopsControlPlane(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		kopsControlPlane, err = kops.CreateKopsControlPlane(k, spec.Name, kopsControlPlane)
+		if err != nil {
+			return nil, err
+		}
+
+		controlPlaneRef := &corev1.ObjectReference{
+			APIVersion: kopsControlPlane.APIVersion,
+			Kind:       kopsControlPlane.Kind,
+			Name:       kopsControlPlane.Name,
+			Namespace:  kopsControlPlane.Namespace,
+		}
+		return controlPlaneRef, nil
+	}
+
+	return nil, clientError.NewClientError(nil, clientError.KindNotFound, fmt.Sprintf("The control plane provider %s is not supported", spec.KubeProvider))
+}
+
+// deleteControlPlane deletes the control plane resource referenced by a cluster
+func deleteControlPlane(k *k8s.Kubernetes, clusterName string, controlPlaneRef *corev1.ObjectReference) error {
+	switch controlPlaneRef.Kind {
+	case "KopsControlPlane":
+		return kops.DeleteKopsControlPlane(k, clusterName, controlPlaneRef.Name)
+	}
+
+	return clientError.NewClientError(nil, clientError.KindNotFound, fmt.Sprintf("The Kind %s could not be found", controlPlaneRef.Kind))
+}
+
+// getControlPlaneVersion returns the kubernetes version set in the control plane resource referenced by a cluster and
+// the version its machines run, which is empty until the provider reports it. Kops doesn't report the version of the
+// control plane it rolls out, so it's always empty for the KopsControlPlane
+func getControlPlaneVersion(k *k8s.Kubernetes, clusterName string, controlPlaneRef *corev1.ObjectReference) (string, string, error) {
+	switch controlPlaneRef.Kind {
+	case "KopsControlPlane":
+		kopsControlPlane, err := kops.GetKopsControlPlane(k, clusterName, controlPlaneRef.Name)
+		if err != nil {
+			return "", "", err
+		}
+		return kopsControlPlane.Spec.KopsClusterSpec.KubernetesVersion, "", nil
+	case "KubeadmControlPlane":
+		kubeadmControlPlane, err := kubeadm.GetKubeadmControlPlane(k, clusterName, controlPlaneRef.Name)
+		if err != nil {
+			return "", "", err
+		}
+		return kubeadm.GetKubeadmControlPlaneVersion(kubeadmControlPlane), kubeadm.GetKubeadmControlPlaneRolledOutVersion(kubeadmControlPlane), nil
+	}
+
+	return "", "", clientError.NewClientError(nil, clientError.KindNotFound, fmt.Sprintf("The Kind %s could not be found", controlPlaneRef.Kind))
+}
+
+// readControlPlaneVersion fills the kubernetes version of the cluster control plane, it's left empty when the control plane can't be read
+func (c *Cluster) readControlPlaneVersion(k *k8s.Kubernetes, clusterAPICR *clusterapiv1beta1.Cluster) {
+	rawVersion, _, err := getControlPlaneVersion(k, c.Name, clusterAPICR.Spec.ControlPlaneRef)
+	if err != nil {
+		k.Log().Warn("Could not read the kubernetes version of the control plane", zap.String("cluster", c.Name), zap.Error(err))
+		return
+	}
+
+	controlPlaneVersion, err := version.ParseGeneric(rawVersion)
+	if err != nil {
+		k.Log().Warn("Control plane has an invalid kubernetes version", zap.String("cluster", c.Name), zap.String("version", rawVersion), zap.Error(err))
+		return
+	}
+	c.ControlPlane.Version = controlPlaneVersion.String()
+}
+
+// updateControlPlaneVersion changes the kubernetes version of the control plane resource referenced by a cluster
+func updateControlPlaneVersion(k *k8s.Kubernetes, clusterName string, controlPlaneRef *corev1.ObjectReference, kubernetesVersion *version.Version) error {
+	switch controlPlaneRef.Kind {
+	case "KopsControlPlane":
+		patch, err := json.Marshal(map[string]interface{}{
+			"spec": map[string]interface{}{
+				"kopsClusterSpec": map[string]interface{}{
+					"kubernetesVersion": kubernetesVersion.String(),
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+		return kops.PatchKopsControlPlane(k, clusterName, controlPlaneRef.Name, patch)
+	case "KubeadmControlPlane":
+		// cluster-API resources require the version with the "v" prefix
+		patch, err := json.Marshal(map[string]interface{}{
+			"spec": map[string]interface{}{
+				"version": fmt.Sprintf("v%s", kubernetesVersion),
+			},
+		})
+		if err != nil {
+			return err
+		}
+		return kubeadm.PatchKubeadmControlPlane(k, clusterName, controlPlaneRef.Name, patch)
+	}
+
+	return clientError.NewClientError(nil, clientError.KindNotFound, fmt.Sprintf("The Kind %s could not be found", controlPlaneRef.Kind))
+}
+
+// validateUpgradeVersion checks if a cluster can go from its current kubernetes version to the desired one, only one minor version can be upgraded at a time
+func validateUpgradeVersion(clusterName string, currentVersion *version.Version, desiredVersion *version.Version) error {
+	if desiredVersion.Major() != currentVersion.Major() {
+		return clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("Cluster %s can't be upgraded across major versions, from %s to %s", clusterName, currentVersion, desiredVersion))
+	}
+
+	if !currentVersion.LessThan(desiredVersion) {
+		return clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("Cluster %s is at version %s and can't be upgraded to %s", clusterName, currentVersion, desiredVersion))
+	}
+
+	if desiredVersion.Minor() > currentVersion.Minor()+1 {
+		return clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("Cluster %s can't skip minor versions, it must be upgraded from %s to %d.%d first", clusterName, currentVersion, currentVersion.Major(), currentVersion.Minor()+1))
+	}
+
+	return nil
+}
+
+// newKopsControlPlane returns a KopsControlPlane built from the provider agnostic cluster specification
+func newKopsControlPlane(spec *ClusterSpec) (*clusterapikopscontrolplanev1alpha1.KopsControlPlane, error) {
+	configBase, err := kops.GetConfigBase(spec.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		subnets     []kopsv1alpha2.ClusterSubnetSpec
+		etcdMembers []kopsv1alpha2.EtcdMemberSpec
+	)
+
+	// Kubenet doesn't route the pods of private subnets, so private clusters use Calico
+	topology := &kopsv1alpha2.TopologySpec{
+		Masters: kopsv1alpha2.TopologyPrivate,
+		Nodes:   kopsv1alpha2.TopologyPrivate,
+		DNS: &kopsv1alpha2.DNSSpec{
+			Type: kopsv1alpha2.DNSTypePrivate,
+		},
+	}
+	subnetType := kopsv1alpha2.SubnetTypePrivate
+	networking := &kopsv1alpha2.NetworkingSpec{
+		Calico: &kopsv1alpha2.CalicoNetworkingSpec{},
+	}
+	api := &kopsv1alpha2.AccessSpec{
+		LoadBalancer: &kopsv1alpha2.LoadBalancerAccessSpec{
+			Type: kopsv1alpha2.LoadBalancerTypeInternal,
+		},
+	}
+	if spec.Topology == ClusterTopologyPublic {
+		topology = &kopsv1alpha2.TopologySpec{
+			Masters: kopsv1alpha2.TopologyPublic,
+			Nodes:   kopsv1alpha2.TopologyPublic,
+			DNS: &kopsv1alpha2.DNSSpec{
+				Type: kopsv1alpha2.DNSTypePublic,
+			},
+		}
+		subnetType = kopsv1alpha2.SubnetTypePublic
+		networking = &kopsv1alpha2.NetworkingSpec{
+			Kubenet: &kopsv1alpha2.KubenetNetworkingSpec{},
+		}
+		api = &kopsv1alpha2.AccessSpec{
+			DNS: &kopsv1alpha2.DNSAccessSpec{},
+		}
+	}
+
+	for _, zone := range spec.Zones {
+		// Subnets without CIDR have it assigned by kops from the network CIDR
+		subnets = append(subnets, kopsv1alpha2.ClusterSubnetSpec{
+			Name: zone,
+			Zone: zone,
+			Type: subnetType,
+		})
+		// The load balancers and NAT gateways of private subnets are created in utility subnets
+		if subnetType == kopsv1alpha2.SubnetTypePrivate {
+			subnets = append(subnets, kopsv1alpha2.ClusterSubnetSpec{
+				Name: fmt.Sprintf("utility-%s", zone),
+				Zone: zone,
+				Type: kopsv1alpha2.SubnetTypeUtility,
+			})
+		}
+
+		instanceGroup := fmt.Sprintf("master-%s", zone)
+		etcdMembers = append(etcdMembers, kopsv1alpha2.EtcdMemberSpec{
+			Name:          zone,
+			InstanceGroup: &instanceGroup,
+		})
+	}
+
+	kopsControlPlane := &clusterapikopscontrolplanev1alpha1.KopsControlPlane{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "KopsControlPlane",
+			APIVersion: clusterapikopscontrolplanev1alpha1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: GetControlPlaneName(spec.Name),
+		},
+		Spec: clusterapikopscontrolplanev1alpha1.KopsControlPlaneSpec{
+			KopsClusterSpec: kopsv1alpha2.ClusterSpec{
+				Channel:               "stable",
+				ConfigBase:            configBase,
+				CloudProvider:         "aws",
+				KubernetesVersion:     spec.KubernetesVersion,
+				Subnets:               subnets,
+				MasterPublicName:      GetControlPlaneEndpointHost(spec.Name),
+				NetworkCIDR:           spec.NetworkCIDR,
+				NonMasqueradeCIDR:     kopsNonMasqueradeCIDR,
+				ServiceClusterIPRange: spec.ServiceCIDR,
+				PodCIDR:               spec.PodCIDR,
+				Topology:              topology,
+				EtcdClusters: []kopsv1alpha2.EtcdClusterSpec{
+					{
+						Name:    "main",
+						Members: etcdMembers,
+					},
+					{
+						Name:    "events",
+						Members: etcdMembers,
+					},
+				},
+				Networking: networking,
+				API:        api,
+				Authorization: &kopsv1alpha2.AuthorizationSpec{
+					RBAC: &kopsv1alpha2.RBACAuthorizationSpec{},
+				},
+				KubernetesAPIAccess: spec.APIAccessCIDRs,
+				SSHAccess:           spec.SSHAccessCIDRs,
+			},
+		},
+	}
+
+	return kopsControlPlane, nil
+}
@@ -1,9 +1,12 @@
 package kaas
 
 import (
+	"github.com/topfreegames/kaas-management-api/internal/k8s/providers/kops"
 	"github.com/topfreegames/kaas-management-api/test"
 	"github.com/topfreegames/kaas-management-api/util/clientError"
 	"gotest.tools/assert"
+	kopsv1alpha2 "k8s.io/kops/pkg/apis/kops/v1alpha2"
+	"os"
 	"reflect"
 	"testing"
 )
@@ -62,3 +65,73 @@ func Test_GetControlPlane_ErrorKindNotFound(t *testing.T) {
 		assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
 	})
 }
+
+func Test_newKopsControlPlane_Success(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		spec                 *ClusterSpec
+		expectedTopology     string
+		expectedSubnets      []kopsv1alpha2.ClusterSubnetSpec
+		expectedAPIAccess    []string
+		expectedSSHAccess    []string
+		expectedLoadBalancer bool
+	}{
+		{
+			name: "newKopsControlPlane should return a private cluster only reachable from its network by default",
+			spec: &ClusterSpec{
+				Name:                   "testcluster",
+				KubernetesVersion:      "1.21.5",
+				KubeProvider:           "kops",
+				InfrastructureProvider: "kops",
+				Zones:                  []string{"us-east-1a"},
+			},
+			expectedTopology: kopsv1alpha2.TopologyPrivate,
+			expectedSubnets: []kopsv1alpha2.ClusterSubnetSpec{
+				{Name: "us-east-1a", Zone: "us-east-1a", Type: kopsv1alpha2.SubnetTypePrivate},
+				{Name: "utility-us-east-1a", Zone: "us-east-1a", Type: kopsv1alpha2.SubnetTypeUtility},
+			},
+			expectedAPIAccess:    []string{defaultNetworkCIDR},
+			expectedSSHAccess:    []string{defaultNetworkCIDR},
+			expectedLoadBalancer: true,
+		},
+		{
+			name: "newKopsControlPlane should return a public cluster reachable from the requested networks",
+			spec: &ClusterSpec{
+				Name:                   "testcluster",
+				KubernetesVersion:      "1.21.5",
+				KubeProvider:           "kops",
+				InfrastructureProvider: "kops",
+				Zones:                  []string{"us-east-1a"},
+				Topology:               ClusterTopologyPublic,
+				APIAccessCIDRs:         []string{"203.0.113.0/24"},
+				SSHAccessCIDRs:         []string{"198.51.100.10/32"},
+			},
+			expectedTopology: kopsv1alpha2.TopologyPublic,
+			expectedSubnets: []kopsv1alpha2.ClusterSubnetSpec{
+				{Name: "us-east-1a", Zone: "us-east-1a", Type: kopsv1alpha2.SubnetTypePublic},
+			},
+			expectedAPIAccess: []string{"203.0.113.0/24"},
+			expectedSSHAccess: []string{"198.51.100.10/32"},
+		},
+	}
+
+	os.Setenv(kops.StateStoreEnv, "s3://test-state-store")
+	defer os.Unsetenv(kops.StateStoreEnv)
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := testCase.spec.validate()
+			assert.NilError(t, err)
+
+			kopsControlPlane, err := newKopsControlPlane(testCase.spec)
+			assert.NilError(t, err)
+			clusterSpec := kopsControlPlane.Spec.KopsClusterSpec
+			assert.Equal(t, testCase.expectedTopology, clusterSpec.Topology.Masters)
+			assert.Equal(t, testCase.expectedTopology, clusterSpec.Topology.Nodes)
+			assert.DeepEqual(t, testCase.expectedSubnets, clusterSpec.Subnets)
+			assert.DeepEqual(t, testCase.expectedAPIAccess, clusterSpec.KubernetesAPIAccess)
+			assert.DeepEqual(t, testCase.expectedSSHAccess, clusterSpec.SSHAccess)
+			assert.Equal(t, testCase.expectedLoadBalancer, clusterSpec.API.LoadBalancer != nil)
+		})
+	}
+}
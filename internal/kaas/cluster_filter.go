@@ -0,0 +1,61 @@
+package kaas
+
+import (
+	"fmt"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Properties of the clusters that can be selected like labels in ListClusters, they aren't labels of the cluster-API clusters
+// so they are selected in memory after listing the clusters
+const (
+	KubeProviderProperty           = "kubeprovider"
+	InfrastructureProviderProperty = "infrastructureprovider"
+)
+
+// splitClusterSelector splits a selector into the requirements on labels, selected by Kubernetes, and the requirements on properties.
+// Requirements on metadata keys select the label of the key in the mapping, or are properties when the key is read from an annotation.
+func splitClusterSelector(selector labels.Selector, mapping *MetadataMapping) (labels.Selector, labels.Selector) {
+	labelSelector := labels.NewSelector()
+	propertySelector := labels.NewSelector()
+	if selector == nil {
+		return labelSelector, propertySelector
+	}
+
+	requirements, _ := selector.Requirements()
+	for _, requirement := range requirements {
+		if requirement.Key() == KubeProviderProperty || requirement.Key() == InfrastructureProviderProperty {
+			propertySelector = propertySelector.Add(requirement)
+			continue
+		}
+
+		field, ok := mapping.field(requirement.Key())
+		if !ok {
+			labelSelector = labelSelector.Add(requirement)
+		} else if field.Annotation != "" {
+			propertySelector = propertySelector.Add(requirement)
+		} else {
+			// The labels of the mapping are validated when it is parsed, and the values were already validated in the requirement
+			labelRequirement, err := labels.NewRequirement(field.Label, requirement.Operator(), requirement.Values().List())
+			if err != nil {
+				labelSelector = labelSelector.Add(requirement)
+				continue
+			}
+			labelSelector = labelSelector.Add(*labelRequirement)
+		}
+	}
+	return labelSelector, propertySelector
+}
+
+// properties returns the properties of a cluster that can be selected like labels, including its metadata read from annotations
+func (c *Cluster) properties(mapping *MetadataMapping) labels.Set {
+	properties := labels.Set{
+		KubeProviderProperty:           c.ControlPlane.Provider,
+		InfrastructureProviderProperty: c.Infrastructure.Provider,
+	}
+	for _, field := range mapping.Fields {
+		if field.Annotation != "" && c.Metadata[field.Key] != nil {
+			properties[field.Key] = fmt.Sprint(c.Metadata[field.Key])
+		}
+	}
+	return properties
+}
@@ -0,0 +1,124 @@
+package kaas
+
+import (
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/test"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"reflect"
+	"testing"
+)
+
+func newTestFilterClusters() []runtime.Object {
+	westCluster := test.NewTestCluster("testcluster2", "testcluster-kops-cp2", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster2", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1")
+	westCluster.Labels[RegionMetadataKey] = "us-west-2"
+
+	return []runtime.Object{
+		test.NewTestCluster("testcluster1", "testcluster-kops-cp1", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster1", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+		westCluster,
+		test.NewTestCluster("testcluster3", "testcluster-kubeadm-cp3", "KubeadmControlPlane", "controlplane.cluster.x-k8s.io/v1beta1", "docker-cluster3", "DockerCluster", "infrastructure.cluster.x-k8s.io/v1beta1"),
+	}
+}
+
+func Test_ListClusters_Filter_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "ListClusters should return the clusters selected by a label",
+			ExpectedSuccess: []string{"testcluster2"},
+			Request: &test.K8sRequest{
+				ResourceName: "region=us-west-2",
+			},
+			K8sTestResources: newTestFilterClusters(),
+		},
+		{
+			Name:            "ListClusters should return the clusters selected by a property",
+			ExpectedSuccess: []string{"testcluster3"},
+			Request: &test.K8sRequest{
+				ResourceName: "kubeprovider=kubeadm",
+			},
+			K8sTestResources: newTestFilterClusters(),
+		},
+		{
+			Name:            "ListClusters should return the clusters selected by set-based labels and properties",
+			ExpectedSuccess: []string{"testcluster1", "testcluster2"},
+			Request: &test.K8sRequest{
+				ResourceName: "region in (us-east-1,us-west-2),environment,infrastructureprovider notin (docker)",
+			},
+			K8sTestResources: newTestFilterClusters(),
+		},
+	}
+
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+		DynamicClient: test.NewK8sFakeDynamicClient(),
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			selector, err := labels.Parse(request.ResourceName)
+			assert.NilError(t, err)
+
+			response, _, err := ListClusters(k, k8s.ListOptions{LabelSelector: selector})
+			assert.NilError(t, err)
+
+			var names []string
+			for _, cluster := range response {
+				names = append(names, cluster.Name)
+			}
+			assert.Assert(t, reflect.DeepEqual(testCase.ExpectedSuccess, names), "got %v", names)
+		})
+	}
+}
+
+func Test_ListClusters_Filter_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "ListClusters should return EmptyResponse when a property selects no cluster",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "No clusters were found",
+				ErrorMessage:         clientError.EmptyResponse,
+			},
+			Request: &test.K8sRequest{
+				ResourceName: "kubeprovider=eks",
+			},
+			K8sTestResources: newTestFilterClusters(),
+		},
+		{
+			Name:            "ListClusters should return EmptyResponse when a label selects no cluster",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "no Clusters were found",
+				ErrorMessage:         clientError.EmptyResponse,
+			},
+			Request: &test.K8sRequest{
+				ResourceName: "environment=production",
+			},
+			K8sTestResources: newTestFilterClusters(),
+		},
+	}
+
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+		DynamicClient: test.NewK8sFakeDynamicClient(),
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			selector, err := labels.Parse(request.ResourceName)
+			assert.NilError(t, err)
+
+			_, _, err = ListClusters(k, k8s.ListOptions{LabelSelector: selector})
+			assert.Assert(t, err != nil)
+			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
+		})
+	}
+}
@@ -2,7 +2,12 @@ package kaas
 
 import (
 	"fmt"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/internal/k8s/providers/kops"
 	"github.com/topfreegames/kaas-management-api/util/clientError"
+	clusterapikopsv1alpha1 "github.com/topfreegames/kubernetes-kops-operator/apis/infrastructure/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type ClusterInfrastructure struct {
@@ -21,6 +26,11 @@ func GetClusterInfrastructure(infrastructureKind string) (*ClusterInfrastructure
 			Provider: "docker",
 		}
 		return infrastructure, nil
+	case "AWSCluster":
+		infrastructure = &ClusterInfrastructure{
+			Provider: "aws",
+		}
+		return infrastructure, nil
 	case "KopsAWSCluster":
 		infrastructure = &ClusterInfrastructure{
 			Provider: "kops",
@@ -34,3 +44,49 @@ func GetClusterInfrastructure(infrastructureKind string) (*ClusterInfrastructure
 	}
 	return nil, clientError.NewClientError(nil, clientError.KindNotFound, fmt.Sprintf("The Kind %s could not be found", infrastructureKind))
 }
+
+// GetClusterInfrastructureName returns the name of the infrastructure resource of a cluster
+func GetClusterInfrastructureName(clusterName string) string {
+	return fmt.Sprintf("%s-cluster", clusterName)
+}
+
+// createClusterInfrastructure creates the infrastructure resource of the cluster provider and returns a reference to it
+func createClusterInfrastructure(k *k8s.Kubernetes, spec *ClusterSpec) (*corev1.ObjectReference, error) {
+	switch spec.InfrastructureProvider {
+	case "kops":
+		kopsAWSCluster := &clusterapikopsv1alpha1.KopsAWSCluster{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "KopsAWSCluster",
+				APIVersion: clusterapikopsv1alpha1.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: GetClusterInfrastructureName(spec.Name),
+			},
+		}
+
+		kopsAWSCluster, err := kops.CreateKopsAWSCluster(k, spec.Name, kopsAWSCluster)
+		if err != nil {
+			return nil, err
+		}
+
+		infrastructureRef := &corev1.ObjectReference{
+			APIVersion: kopsAWSCluster.APIVersion,
+			Kind:       kopsAWSCluster.Kind,
+			Name:       kopsAWSCluster.Name,
+			Namespace:  kopsAWSCluster.Namespace,
+		}
+		return infrastructureRef, nil
+	}
+
+	return nil, clientError.NewClientError(nil, clientError.KindNotFound, fmt.Sprintf("The infrastructure provider %s is not supported", spec.InfrastructureProvider))
+}
+
+// deleteClusterInfrastructure deletes the infrastructure resource referenced by a cluster
+func deleteClusterInfrastructure(k *k8s.Kubernetes, clusterName string, infrastructureRef *corev1.ObjectReference) error {
+	switch infrastructureRef.Kind {
+	case "KopsAWSCluster":
+		return kops.DeleteKopsAWSCluster(k, clusterName, infrastructureRef.Name)
+	}
+
+	return clientError.NewClientError(nil, clientError.KindNotFound, fmt.Sprintf("The Kind %s could not be found", infrastructureRef.Kind))
+}
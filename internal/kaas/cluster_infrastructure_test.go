@@ -20,6 +20,16 @@ func Test_GetClusterInfrastructure_Success(t *testing.T) {
 				ResourceKind: "KopsAWSCluster",
 			},
 		},
+		{
+			Name: "GetClusterInfrastructure should return Success for AWS",
+			ExpectedSuccess: &ClusterInfrastructure{
+				Provider: "aws",
+			},
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				ResourceKind: "AWSCluster",
+			},
+		},
 		{
 			Name: "GetClusterInfrastructure should return Success for Docker",
 			ExpectedSuccess: &ClusterInfrastructure{
@@ -0,0 +1,112 @@
+package kaas
+
+import (
+	"fmt"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/internal/tracing"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"go.opentelemetry.io/otel/attribute"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	clientcmdlatest "k8s.io/client-go/tools/clientcmd/api/latest"
+	clientcmdapiv1 "k8s.io/client-go/tools/clientcmd/api/v1"
+	"net/url"
+	"sigs.k8s.io/yaml"
+)
+
+// KubeconfigOptions are the changes applied to a cluster kubeconfig before returning it, empty fields keep the kubeconfig unchanged
+type KubeconfigOptions struct {
+	// ContextName renames the current context
+	ContextName string
+	// Server replaces the server of every cluster in the kubeconfig
+	Server string
+	// UseApiEndpoint replaces the server of every cluster in the kubeconfig with the cluster ApiEndpoint
+	UseApiEndpoint bool
+}
+
+// GetClusterKubeconfig returns the kubeconfig written by cluster-API for a cluster as YAML
+func GetClusterKubeconfig(k *k8s.Kubernetes, name string, options *KubeconfigOptions) (_ []byte, err error) {
+	ctx, span := tracing.Start(k.Context(), "kaas.GetClusterKubeconfig", attribute.String("kaas.cluster", name))
+	defer func() { tracing.End(span, err) }()
+	k = k.WithContext(ctx)
+	err = options.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	cluster, err := GetCluster(k, name)
+	if err != nil {
+		return nil, err
+	}
+
+	rawKubeconfig, err := k.GetClusterKubeconfig(name)
+	if err != nil {
+		clientErr, ok := err.(*clientError.ClientError)
+		if ok && clientErr.ErrorMessage == clientError.ResourceNotFound {
+			return nil, clientError.NewClientError(clientErr, clientError.ResourceNotFound, fmt.Sprintf("The kubeconfig of cluster %s is not available yet", name))
+		}
+		return nil, clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Error getting the kubeconfig of cluster %s", name))
+	}
+
+	kubeconfig, err := clientcmd.Load(rawKubeconfig)
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.InvalidResource, fmt.Sprintf("The kubeconfig of cluster %s is invalid", name))
+	}
+
+	if options.ContextName != "" && options.ContextName != kubeconfig.CurrentContext {
+		currentContext, ok := kubeconfig.Contexts[kubeconfig.CurrentContext]
+		if !ok {
+			return nil, clientError.NewClientError(nil, clientError.InvalidResource, fmt.Sprintf("The kubeconfig of cluster %s doesn't have a current context", name))
+		}
+		delete(kubeconfig.Contexts, kubeconfig.CurrentContext)
+		kubeconfig.Contexts[options.ContextName] = currentContext
+		kubeconfig.CurrentContext = options.ContextName
+	}
+
+	server := options.Server
+	if options.UseApiEndpoint {
+		server = cluster.ApiEndpoint
+	}
+	if server != "" {
+		for _, kubeconfigCluster := range kubeconfig.Clusters {
+			kubeconfigCluster.Server = server
+		}
+	}
+
+	rawKubeconfig, err = writeKubeconfig(kubeconfig)
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Could not write the kubeconfig of cluster %s", name))
+	}
+
+	return rawKubeconfig, nil
+}
+
+// validate checks if the kubeconfig options don't conflict and the server is a valid https URL
+func (o *KubeconfigOptions) validate() error {
+	if o.Server != "" && o.UseApiEndpoint {
+		return clientError.NewClientError(nil, clientError.InvalidRequest, "The kubeconfig server can't be replaced by both a server URL and the cluster API endpoint")
+	}
+
+	if o.Server != "" {
+		serverURL, err := url.Parse(o.Server)
+		if err != nil || serverURL.Scheme != "https" || serverURL.Host == "" {
+			return clientError.NewClientError(err, clientError.InvalidRequest, fmt.Sprintf("The kubeconfig server %s must be a https URL", o.Server))
+		}
+	}
+
+	return nil
+}
+
+// writeKubeconfig serializes a kubeconfig as YAML in its v1 version, encoding/json is used instead of clientcmd.Write
+// since the json-iterator encoder used by the apimachinery serializer can't handle the kubeconfig maps
+func writeKubeconfig(kubeconfig *clientcmdapi.Config) ([]byte, error) {
+	versionedKubeconfig := &clientcmdapiv1.Config{}
+	err := clientcmdlatest.Scheme.Convert(kubeconfig, versionedKubeconfig, nil)
+	if err != nil {
+		return nil, err
+	}
+	versionedKubeconfig.APIVersion = clientcmdlatest.Version
+	versionedKubeconfig.Kind = "Config"
+
+	return yaml.Marshal(versionedKubeconfig)
+}
@@ -0,0 +1,214 @@
+package kaas
+
+import (
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/test"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	"log"
+	"testing"
+)
+
+// clusterKubeconfigTestRequest is the request of the cluster kubeconfig tests, the kubeconfig secret is only created when Kubeconfig is set
+type clusterKubeconfigTestRequest struct {
+	Cluster    string
+	Options    *KubeconfigOptions
+	Kubeconfig []byte
+}
+
+// expectedKubeconfig is the current context and server expected in the kubeconfig returned by the tests
+type expectedKubeconfig struct {
+	CurrentContext string
+	Server         string
+}
+
+func Test_GetClusterKubeconfig_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name: "GetClusterKubeconfig should return Success for an unchanged kubeconfig",
+			ExpectedSuccess: expectedKubeconfig{
+				CurrentContext: "testcluster-admin@testcluster",
+				Server:         "https://10.0.0.1:6443",
+			},
+			ExpectedClientError: nil,
+			Request: &clusterKubeconfigTestRequest{
+				Cluster:    "testcluster",
+				Options:    &KubeconfigOptions{},
+				Kubeconfig: test.NewTestKubeconfig("testcluster", "https://10.0.0.1:6443"),
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+		{
+			Name: "GetClusterKubeconfig should return Success renaming the context and replacing the server",
+			ExpectedSuccess: expectedKubeconfig{
+				CurrentContext: "testcluster",
+				Server:         "https://testcluster.example.com",
+			},
+			ExpectedClientError: nil,
+			Request: &clusterKubeconfigTestRequest{
+				Cluster: "testcluster",
+				Options: &KubeconfigOptions{
+					ContextName: "testcluster",
+					Server:      "https://testcluster.example.com",
+				},
+				Kubeconfig: test.NewTestKubeconfig("testcluster", "https://10.0.0.1:6443"),
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+		{
+			Name: "GetClusterKubeconfig should return Success replacing the server with the cluster API endpoint",
+			ExpectedSuccess: expectedKubeconfig{
+				CurrentContext: "testcluster-admin@testcluster",
+				Server:         "https://api.testcluster.cluster.example.com:443",
+			},
+			ExpectedClientError: nil,
+			Request: &clusterKubeconfigTestRequest{
+				Cluster: "testcluster",
+				Options: &KubeconfigOptions{
+					UseApiEndpoint: true,
+				},
+				Kubeconfig: test.NewTestKubeconfig("testcluster", "https://10.0.0.1:6443"),
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+	}
+
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+		DynamicClient: test.NewK8sFakeDynamicClient(),
+		Clientset:     test.NewK8sFakeClientset(),
+	}}
+
+	for _, testCase := range testCases {
+		request, ok := testCase.Request.(*clusterKubeconfigTestRequest)
+		if !ok {
+			log.Fatalf("Failed converting Request struct from test \"%s\" to *clusterKubeconfigTestRequest", testCase.Name)
+		}
+		expected, ok := testCase.ExpectedSuccess.(expectedKubeconfig)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to expectedKubeconfig", testCase.Name)
+		}
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+		k.K8sAuth.Clientset = test.NewK8sFakeClientset(test.NewTestKubeconfigSecret(request.Cluster, request.Kubeconfig))
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			response, err := GetClusterKubeconfig(k, request.Cluster, request.Options)
+			assert.NilError(t, err)
+
+			kubeconfig, err := clientcmd.Load(response)
+			assert.NilError(t, err)
+			assert.Equal(t, expected.CurrentContext, kubeconfig.CurrentContext)
+			_, ok := kubeconfig.Contexts[expected.CurrentContext]
+			assert.Assert(t, ok)
+			assert.Equal(t, expected.Server, kubeconfig.Clusters[request.Cluster].Server)
+			assert.Equal(t, "test-token", kubeconfig.AuthInfos[request.Cluster+"-admin"].Token)
+		})
+	}
+}
+
+func Test_GetClusterKubeconfig_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "GetClusterKubeconfig should return Error for non-existent cluster",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "Could not find cluster nonexistentcluster",
+				ErrorMessage:         clientError.ResourceNotFound,
+			},
+			Request: &clusterKubeconfigTestRequest{
+				Cluster:    "nonexistentcluster",
+				Options:    &KubeconfigOptions{},
+				Kubeconfig: test.NewTestKubeconfig("nonexistentcluster", "https://10.0.0.1:6443"),
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+		{
+			Name:            "GetClusterKubeconfig should return Error for a cluster without kubeconfig",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The kubeconfig of cluster testcluster is not available yet",
+				ErrorMessage:         clientError.ResourceNotFound,
+			},
+			Request: &clusterKubeconfigTestRequest{
+				Cluster: "testcluster",
+				Options: &KubeconfigOptions{},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+		{
+			Name:            "GetClusterKubeconfig should return Error for a server that isn't a https URL",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The kubeconfig server http://testcluster.example.com must be a https URL",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &clusterKubeconfigTestRequest{
+				Cluster: "testcluster",
+				Options: &KubeconfigOptions{
+					Server: "http://testcluster.example.com",
+				},
+				Kubeconfig: test.NewTestKubeconfig("testcluster", "https://10.0.0.1:6443"),
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+		{
+			Name:            "GetClusterKubeconfig should return Error replacing the server by both a URL and the API endpoint",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The kubeconfig server can't be replaced by both a server URL and the cluster API endpoint",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &clusterKubeconfigTestRequest{
+				Cluster: "testcluster",
+				Options: &KubeconfigOptions{
+					Server:         "https://testcluster.example.com",
+					UseApiEndpoint: true,
+				},
+				Kubeconfig: test.NewTestKubeconfig("testcluster", "https://10.0.0.1:6443"),
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+	}
+
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+		DynamicClient: test.NewK8sFakeDynamicClient(),
+		Clientset:     test.NewK8sFakeClientset(),
+	}}
+
+	for _, testCase := range testCases {
+		request, ok := testCase.Request.(*clusterKubeconfigTestRequest)
+		if !ok {
+			log.Fatalf("Failed converting Request struct from test \"%s\" to *clusterKubeconfigTestRequest", testCase.Name)
+		}
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+		k.K8sAuth.Clientset = test.NewK8sFakeClientset()
+		if request.Kubeconfig != nil {
+			k.K8sAuth.Clientset = test.NewK8sFakeClientset(test.NewTestKubeconfigSecret(request.Cluster, request.Kubeconfig))
+		}
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := GetClusterKubeconfig(k, request.Cluster, request.Options)
+			assert.ErrorContains(t, err, testCase.ExpectedClientError.Error())
+			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package kaas
+
+import (
+	"fmt"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/validation"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/yaml"
+	"strconv"
+	"strings"
+)
+
+// MetadataConfigKey is the key of the configuration ConfigMap with the cluster metadata mapping
+const MetadataConfigKey = "metadata.yaml"
+
+// Metadata keys used by the API besides being returned in the cluster metadata
+const (
+	RegionMetadataKey       = "region"
+	EnvironmentMetadataKey  = "environment"
+	ClusterGroupMetadataKey = "clusterGroup"
+)
+
+// Types of the metadata values
+const (
+	MetadataTypeString = "string"
+	MetadataTypeBool   = "bool"
+	MetadataTypeInt    = "int"
+	MetadataTypeFloat  = "float"
+	// MetadataTypeList is a comma separated list of strings
+	MetadataTypeList = "list"
+)
+
+// metadataTypes are the valid types of the metadata fields, an empty type is a string
+var metadataTypes = map[string]bool{
+	"":                 true,
+	MetadataTypeString: true,
+	MetadataTypeBool:   true,
+	MetadataTypeInt:    true,
+	MetadataTypeFloat:  true,
+	MetadataTypeList:   true,
+}
+
+// MetadataField declares a cluster metadata key read from a label or an annotation of the cluster-API cluster
+type MetadataField struct {
+	Key        string `json:"key"`
+	Label      string `json:"label,omitempty"`
+	Annotation string `json:"annotation,omitempty"`
+	// Type is the type of the value, MetadataTypeString when empty
+	Type string `json:"type,omitempty"`
+	// Default is used when the cluster doesn't have the label or annotation
+	Default string `json:"default,omitempty"`
+}
+
+// MetadataMapping declares which labels and annotations of the cluster-API clusters feed the cluster metadata
+type MetadataMapping struct {
+	Fields []MetadataField `json:"fields"`
+}
+
+// DefaultMetadataMapping is used when the configuration ConfigMap doesn't declare a valid mapping
+var DefaultMetadataMapping = &MetadataMapping{
+	Fields: []MetadataField{
+		{Key: ClusterGroupMetadataKey, Label: ClusterGroupMetadataKey},
+		{Key: RegionMetadataKey, Label: RegionMetadataKey},
+		{Key: EnvironmentMetadataKey, Label: EnvironmentMetadataKey},
+	},
+}
+
+// GetMetadataMapping returns the metadata mapping of the configuration ConfigMap, parsed again only when it changes so its changes
+// apply without a restart. The default mapping is returned when the ConfigMap doesn't have a mapping, and the last valid one when it is invalid.
+func GetMetadataMapping(k *k8s.Kubernetes) *MetadataMapping {
+	return k.GetParsedConfig(MetadataConfigKey, parseConfiguredMetadataMapping).(*MetadataMapping)
+}
+
+// parseConfiguredMetadataMapping parses the metadata mapping of the configuration ConfigMap, keeping the previous one when it is invalid
+func parseConfiguredMetadataMapping(rawMapping string, previous interface{}) interface{} {
+	if rawMapping == "" {
+		return DefaultMetadataMapping
+	}
+
+	mapping, err := ParseMetadataMapping([]byte(rawMapping))
+	if err != nil {
+		if previous == nil {
+			previous = DefaultMetadataMapping
+		}
+		zap.L().Warn("Keeping the previous cluster metadata mapping, the configured one is invalid", zap.Error(err))
+		return previous
+	}
+	zap.L().Info("Loaded the cluster metadata mapping", zap.Int("fields", len(mapping.Fields)))
+	return mapping
+}
+
+// ParseMetadataMapping reads and validates a metadata mapping in YAML
+func ParseMetadataMapping(rawMapping []byte) (*MetadataMapping, error) {
+	mapping := &MetadataMapping{}
+	err := yaml.UnmarshalStrict(rawMapping, mapping)
+	if err != nil {
+		return nil, fmt.Errorf("could not Unmarshal the metadata mapping: %v", err)
+	}
+
+	keys := map[string]bool{}
+	for _, field := range mapping.Fields {
+		if field.Key == "" {
+			return nil, fmt.Errorf("every metadata field must have a key")
+		}
+		if keys[field.Key] {
+			return nil, fmt.Errorf("the metadata key %s is declared more than once", field.Key)
+		}
+		keys[field.Key] = true
+
+		if (field.Label == "") == (field.Annotation == "") {
+			return nil, fmt.Errorf("the metadata key %s must be read from either a label or an annotation", field.Key)
+		}
+		if errs := validation.IsQualifiedName(field.Label + field.Annotation); len(errs) > 0 {
+			return nil, fmt.Errorf("the metadata key %s is read from an invalid label or annotation: %s", field.Key, strings.Join(errs, ", "))
+		}
+		if !metadataTypes[field.Type] {
+			return nil, fmt.Errorf("the metadata key %s has the unknown type %s", field.Key, field.Type)
+		}
+		if field.Default != "" {
+			_, err = field.convert(field.Default)
+			if err != nil {
+				return nil, fmt.Errorf("the default of the metadata key %s is invalid: %v", field.Key, err)
+			}
+		}
+	}
+
+	return mapping, nil
+}
+
+// convert converts a label or annotation value to the field type
+func (f *MetadataField) convert(value string) (interface{}, error) {
+	switch f.Type {
+	case "", MetadataTypeString:
+		return value, nil
+	case MetadataTypeBool:
+		return strconv.ParseBool(value)
+	case MetadataTypeInt:
+		return strconv.ParseInt(value, 10, 64)
+	case MetadataTypeFloat:
+		return strconv.ParseFloat(value, 64)
+	case MetadataTypeList:
+		return strings.Split(value, ","), nil
+	}
+	return nil, fmt.Errorf("unknown type %s", f.Type)
+}
+
+// rawValue returns the label or annotation of the field, or its default when the cluster doesn't have it
+func (f *MetadataField) rawValue(clusterAPICR *clusterapiv1beta1.Cluster) string {
+	value := clusterAPICR.Labels[f.Label]
+	if f.Annotation != "" {
+		value = clusterAPICR.Annotations[f.Annotation]
+	}
+	if value == "" {
+		value = f.Default
+	}
+	return value
+}
+
+// value returns the metadata value of a cluster, a string field is always returned while the other types are nil without a value
+func (f *MetadataField) value(clusterAPICR *clusterapiv1beta1.Cluster) interface{} {
+	rawValue := f.rawValue(clusterAPICR)
+	if rawValue == "" && f.Type != "" && f.Type != MetadataTypeString {
+		return nil
+	}
+
+	value, err := f.convert(rawValue)
+	if err != nil {
+		zap.L().Warn("Invalid cluster metadata, using its default", zap.String("key", f.Key), zap.String("cluster", clusterAPICR.Name), zap.String("type", f.Type), zap.Error(err))
+		if f.Default == "" {
+			return nil
+		}
+		value, _ = f.convert(f.Default)
+	}
+	return value
+}
+
+// field returns the field of a metadata key
+func (m *MetadataMapping) field(key string) (*MetadataField, bool) {
+	for i := range m.Fields {
+		if m.Fields[i].Key == key {
+			return &m.Fields[i], true
+		}
+	}
+	return nil, false
+}
+
+// getClusterMetadata returns the metadata of a cluster-API cluster
+func (m *MetadataMapping) getClusterMetadata(clusterAPICR *clusterapiv1beta1.Cluster) map[string]interface{} {
+	metadata := map[string]interface{}{}
+	for i := range m.Fields {
+		metadata[m.Fields[i].Key] = m.Fields[i].value(clusterAPICR)
+	}
+	return metadata
+}
+
+// getStringMetadata returns the value of a metadata key as it is written in the cluster-API cluster, empty when the key isn't mapped
+func (m *MetadataMapping) getStringMetadata(clusterAPICR *clusterapiv1beta1.Cluster, key string) string {
+	field, ok := m.field(key)
+	if !ok {
+		return ""
+	}
+	return field.rawValue(clusterAPICR)
+}
+
+// setClusterMetadata writes the metadata values of a new cluster in the labels or annotations of the cluster-API cluster,
+// values of keys that aren't mapped are ignored
+func (m *MetadataMapping) setClusterMetadata(clusterAPICR *clusterapiv1beta1.Cluster, values map[string]string) {
+	for key, value := range values {
+		field, ok := m.field(key)
+		if !ok {
+			continue
+		}
+
+		if field.Label != "" {
+			if clusterAPICR.Labels == nil {
+				clusterAPICR.Labels = map[string]string{}
+			}
+			clusterAPICR.Labels[field.Label] = value
+		} else {
+			if clusterAPICR.Annotations == nil {
+				clusterAPICR.Annotations = map[string]string{}
+			}
+			clusterAPICR.Annotations[field.Annotation] = value
+		}
+	}
+}
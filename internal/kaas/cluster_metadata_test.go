@@ -0,0 +1,215 @@
+package kaas
+
+import (
+	"context"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/test"
+	"gotest.tools/assert"
+	"gotest.tools/poll"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testMetadataMapping = `
+fields:
+  - key: region
+    label: topology.example.com/region
+  - key: environment
+    label: environment
+    default: development
+  - key: costCenter
+    annotation: example.com/cost-center
+  - key: critical
+    label: critical
+    type: bool
+    default: "false"
+  - key: teams
+    annotation: example.com/teams
+    type: list
+  - key: replicas
+    annotation: example.com/replicas
+    type: int
+`
+
+// newTestConfiguredKubernetes returns a Kubernetes instance with the resources and a synced configuration ConfigMap with the metadata mapping
+func newTestConfiguredKubernetes(t *testing.T, mapping string, resources ...runtime.Object) *k8s.Kubernetes {
+	stopCh := make(chan struct{})
+	t.Cleanup(func() { close(stopCh) })
+
+	clientset := test.NewK8sFakeClientset(test.NewTestConfigMap(k8s.DefaultConfigNamespace, k8s.DefaultConfigName, map[string]string{MetadataConfigKey: mapping}))
+	config := k8s.NewConfigWatcher(clientset, k8s.DefaultConfigNamespace, k8s.DefaultConfigName, time.Minute)
+	config.Start(stopCh)
+	if !config.WaitForCacheSync(stopCh) {
+		t.Fatal("Configuration didn't sync")
+	}
+
+	return &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{DynamicClient: test.NewK8sFakeDynamicClientWithResources(resources...), Clientset: clientset},
+		Config:  config,
+	}
+}
+
+func newTestMappedCluster(name string, labels map[string]string, annotations map[string]string) runtime.Object {
+	cluster := test.NewTestCluster(name, name+"-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", name+"-kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1")
+	cluster.Labels = labels
+	cluster.Annotations = annotations
+	return cluster
+}
+
+func Test_GetCluster_MetadataMapping(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name: "GetCluster should return the metadata of the configured mapping",
+			ExpectedSuccess: map[string]interface{}{
+				"region":      "us-west-2",
+				"environment": "production",
+				"costCenter":  "games",
+				"critical":    true,
+				"teams":       []string{"infra", "games"},
+				"replicas":    int64(3),
+			},
+			Request: &test.K8sRequest{
+				ResourceName: "testcluster",
+			},
+			K8sTestResources: []runtime.Object{
+				newTestMappedCluster("testcluster",
+					map[string]string{"topology.example.com/region": "us-west-2", "environment": "production", "critical": "true"},
+					map[string]string{"example.com/cost-center": "games", "example.com/teams": "infra,games", "example.com/replicas": "3"},
+				),
+			},
+		},
+		{
+			Name: "GetCluster should return the defaults of the configured mapping for missing or invalid values",
+			ExpectedSuccess: map[string]interface{}{
+				"region":      "",
+				"environment": "development",
+				"costCenter":  "",
+				"critical":    false,
+				"teams":       nil,
+				"replicas":    nil,
+			},
+			Request: &test.K8sRequest{
+				ResourceName: "testcluster",
+			},
+			K8sTestResources: []runtime.Object{
+				newTestMappedCluster("testcluster",
+					map[string]string{"critical": "maybe"},
+					map[string]string{"example.com/replicas": "three"},
+				),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			k := newTestConfiguredKubernetes(t, testMetadataMapping, testCase.K8sTestResources...)
+			response, err := GetCluster(k, request.ResourceName)
+			assert.NilError(t, err)
+			assert.Assert(t, reflect.DeepEqual(testCase.ExpectedSuccess, response.Metadata), "got %v", response.Metadata)
+			assert.Equal(t, response.Region, testCase.ExpectedSuccess.(map[string]interface{})["region"])
+		})
+	}
+}
+
+func Test_GetMetadataMapping_Invalid(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:    "GetMetadataMapping should return the default mapping for an unknown type",
+			Request: &test.K8sRequest{ResourceName: "fields:\n  - key: region\n    label: region\n    type: date\n"},
+		},
+		{
+			Name:    "GetMetadataMapping should return the default mapping for a field without label and annotation",
+			Request: &test.K8sRequest{ResourceName: "fields:\n  - key: region\n"},
+		},
+		{
+			Name:    "GetMetadataMapping should return the default mapping for a duplicated key",
+			Request: &test.K8sRequest{ResourceName: "fields:\n  - key: region\n    label: region\n  - key: region\n    annotation: region\n"},
+		},
+		{
+			Name:    "GetMetadataMapping should return the default mapping for an invalid default",
+			Request: &test.K8sRequest{ResourceName: "fields:\n  - key: critical\n    label: critical\n    type: bool\n    default: maybe\n"},
+		},
+		{
+			Name:    "GetMetadataMapping should return the default mapping for an invalid label",
+			Request: &test.K8sRequest{ResourceName: "fields:\n  - key: region\n    label: the region\n"},
+		},
+		{
+			Name:    "GetMetadataMapping should return the default mapping for unknown fields",
+			Request: &test.K8sRequest{ResourceName: "fields:\n  - key: region\n    labels: region\n"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := ParseMetadataMapping([]byte(request.ResourceName))
+			assert.Assert(t, err != nil)
+
+			k := newTestConfiguredKubernetes(t, request.ResourceName)
+			assert.Equal(t, GetMetadataMapping(k), DefaultMetadataMapping)
+		})
+	}
+
+	assert.Equal(t, GetMetadataMapping(&k8s.Kubernetes{}), DefaultMetadataMapping)
+}
+
+func Test_GetMetadataMapping_KeepLastValid(t *testing.T) {
+	k := newTestConfiguredKubernetes(t, testMetadataMapping)
+	mapping := GetMetadataMapping(k)
+	assert.Assert(t, mapping != DefaultMetadataMapping)
+
+	invalid := test.NewTestConfigMap(k8s.DefaultConfigNamespace, k8s.DefaultConfigName, map[string]string{MetadataConfigKey: "fields:\n  - key: region\n"})
+	_, err := k.K8sAuth.Clientset.CoreV1().ConfigMaps(k8s.DefaultConfigNamespace).Update(context.TODO(), invalid, metav1.UpdateOptions{})
+	assert.NilError(t, err)
+
+	// The invalid mapping is parsed by the ConfigMap event handler, the last valid one is kept until a valid one is configured
+	poll.WaitOn(t, func(t poll.LogT) poll.Result {
+		data, _ := k.GetConfigData()
+		if data[MetadataConfigKey] != invalid.Data[MetadataConfigKey] {
+			return poll.Continue("the ConfigMap update wasn't received")
+		}
+		return poll.Success()
+	}, poll.WithTimeout(5*time.Second), poll.WithDelay(10*time.Millisecond))
+	assert.Equal(t, mapping, GetMetadataMapping(k))
+
+	err = k.K8sAuth.Clientset.CoreV1().ConfigMaps(k8s.DefaultConfigNamespace).Delete(context.TODO(), k8s.DefaultConfigName, metav1.DeleteOptions{})
+	assert.NilError(t, err)
+	poll.WaitOn(t, func(t poll.LogT) poll.Result {
+		if GetMetadataMapping(k) != DefaultMetadataMapping {
+			return poll.Continue("the ConfigMap deletion wasn't received")
+		}
+		return poll.Success()
+	}, poll.WithTimeout(5*time.Second), poll.WithDelay(10*time.Millisecond))
+}
+
+func Test_ListClusters_MetadataMapping_Filter(t *testing.T) {
+	k := newTestConfiguredKubernetes(t, testMetadataMapping,
+		newTestMappedCluster("testcluster1", map[string]string{"topology.example.com/region": "us-east-1"}, map[string]string{"example.com/cost-center": "games"}),
+		newTestMappedCluster("testcluster2", map[string]string{"topology.example.com/region": "us-west-2"}, map[string]string{"example.com/cost-center": "infra"}),
+		newTestMappedCluster("testcluster3", map[string]string{"topology.example.com/region": "us-west-2"}, map[string]string{"example.com/cost-center": "games"}),
+	)
+
+	selector, err := labels.Parse("region=us-west-2,costCenter in (games)")
+	assert.NilError(t, err)
+
+	response, _, err := ListClusters(k, k8s.ListOptions{LabelSelector: selector})
+	assert.NilError(t, err)
+	assert.Equal(t, len(response), 1)
+	assert.Equal(t, response[0].Name, "testcluster3")
+}
+
+func Test_CreateCluster_MetadataMapping(t *testing.T) {
+	mapping, err := ParseMetadataMapping([]byte(testMetadataMapping))
+	assert.NilError(t, err)
+
+	clusterAPICR := newClusterAPICR(&k8s.Kubernetes{}, &ClusterSpec{Name: "testcluster", Region: "us-west-2", Environment: "production", ClusterGroup: "games"}, mapping, nil, nil)
+	assert.Assert(t, reflect.DeepEqual(map[string]string{"topology.example.com/region": "us-west-2", "environment": "production"}, clusterAPICR.Labels), "got %v", clusterAPICR.Labels)
+	assert.Assert(t, clusterAPICR.Annotations == nil)
+}
@@ -1,17 +1,46 @@
 package kaas
 
 import (
+	"context"
 	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/internal/k8s/providers/kops"
 	"github.com/topfreegames/kaas-management-api/test"
 	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"log"
+	"os"
 	"reflect"
 	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"strings"
 	"testing"
+	"time"
 )
 
+// newProvisionedTestCluster returns a test cluster with a ready control plane and infrastructure
+func newProvisionedTestCluster(name string) *clusterapiv1beta1.Cluster {
+	cluster := test.NewTestCluster(name, "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1")
+	cluster.Status = clusterapiv1beta1.ClusterStatus{
+		Phase:               string(clusterapiv1beta1.ClusterPhaseProvisioned),
+		ControlPlaneReady:   true,
+		InfrastructureReady: true,
+		Conditions: clusterapiv1beta1.Conditions{
+			{
+				Type:               clusterapiv1beta1.ReadyCondition,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)),
+			},
+		},
+	}
+	return cluster
+}
+
 func Test_GetCluster_Success(t *testing.T) {
 	testCases := []test.TestCase{
 		{
@@ -25,8 +54,13 @@ func Test_GetCluster_Success(t *testing.T) {
 				ClusterGroup:             "test-clusters",
 				Environment:              "test",
 				CIDR:                     []string{"192.168.0.0/24"},
-				ControlPlane:             &ClusterControlPlane{Provider: "kops"},
-				Infrastructure:           &ClusterInfrastructure{Provider: "kops"},
+				Metadata: map[string]interface{}{
+					"region":       "us-east-1",
+					"clusterGroup": "test-clusters",
+					"environment":  "test",
+				},
+				ControlPlane:   &ClusterControlPlane{Provider: "kops"},
+				Infrastructure: &ClusterInfrastructure{Provider: "kops"},
 			},
 			ExpectedClientError: nil,
 			Request: &test.K8sRequest{
@@ -37,6 +71,45 @@ func Test_GetCluster_Success(t *testing.T) {
 				test.NewTestCluster("testcluster2", "testcluster-kops-cp2", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster2", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
 			},
 		},
+		{
+			Name: "GetCluster should return Success for a provisioned cluster with its status",
+			ExpectedSuccess: &Cluster{
+				Name:                     "testcluster",
+				ApiEndpoint:              "https://api.testcluster.cluster.example.com:443",
+				ControlPlaneEndpointHost: "api.testcluster.cluster.example.com",
+				ControlPlaneEndpointPort: 443,
+				Region:                   "us-east-1",
+				ClusterGroup:             "test-clusters",
+				Environment:              "test",
+				CIDR:                     []string{"192.168.0.0/24"},
+				Metadata: map[string]interface{}{
+					"region":       "us-east-1",
+					"clusterGroup": "test-clusters",
+					"environment":  "test",
+				},
+				ControlPlane:   &ClusterControlPlane{Provider: "kops"},
+				Infrastructure: &ClusterInfrastructure{Provider: "kops"},
+				Status: ClusterStatus{
+					Phase:               "Provisioned",
+					ControlPlaneReady:   true,
+					InfrastructureReady: true,
+					Conditions: []ClusterCondition{
+						{
+							Type:               "Ready",
+							Status:             "True",
+							LastTransitionTime: time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC),
+						},
+					},
+				},
+			},
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				ResourceName: "testcluster",
+			},
+			K8sTestResources: []runtime.Object{
+				newProvisionedTestCluster("testcluster"),
+			},
+		},
 	}
 
 	fakeClient := test.NewK8sFakeDynamicClient()
@@ -140,8 +213,13 @@ func Test_ListClusters_Success(t *testing.T) {
 					ClusterGroup:             "test-clusters",
 					Environment:              "test",
 					CIDR:                     []string{"192.168.0.0/24"},
-					ControlPlane:             &ClusterControlPlane{Provider: "kops"},
-					Infrastructure:           &ClusterInfrastructure{Provider: "kops"},
+					Metadata: map[string]interface{}{
+						"region":       "us-east-1",
+						"clusterGroup": "test-clusters",
+						"environment":  "test",
+					},
+					ControlPlane:   &ClusterControlPlane{Provider: "kops"},
+					Infrastructure: &ClusterInfrastructure{Provider: "kops"},
 				},
 				&Cluster{
 					Name:                     "testcluster2",
@@ -152,8 +230,13 @@ func Test_ListClusters_Success(t *testing.T) {
 					ClusterGroup:             "test-clusters",
 					Environment:              "test",
 					CIDR:                     []string{"192.168.0.0/24"},
-					ControlPlane:             &ClusterControlPlane{Provider: "kops"},
-					Infrastructure:           &ClusterInfrastructure{Provider: "kops"},
+					Metadata: map[string]interface{}{
+						"region":       "us-east-1",
+						"clusterGroup": "test-clusters",
+						"environment":  "test",
+					},
+					ControlPlane:   &ClusterControlPlane{Provider: "kops"},
+					Infrastructure: &ClusterInfrastructure{Provider: "kops"},
 				},
 			},
 			ExpectedClientError: nil,
@@ -178,7 +261,7 @@ func Test_ListClusters_Success(t *testing.T) {
 		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
 
 		t.Run(testCase.Name, func(t *testing.T) {
-			response, err := ListClusters(k)
+			response, _, err := ListClusters(k, k8s.ListOptions{})
 			assert.NilError(t, err)
 			assert.Equal(t, len(expectedInfra), len(response))
 			for index, expectedCluster := range expectedInfra {
@@ -194,6 +277,28 @@ func Test_ListClusters_Success(t *testing.T) {
 	}
 }
 
+func Test_ListClusters_SkipsInvalidCluster(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	k := &k8s.Kubernetes{
+		K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClientWithResources(
+				test.NewTestCluster("testcluster1", "testcluster-kops-cp1", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster1", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestCluster("testcluster2", "", "", "", "kops-cluster2", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			),
+		},
+		Logger: zap.New(core),
+	}
+
+	response, _, err := ListClusters(k, k8s.ListOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(response))
+	assert.Equal(t, "testcluster1", response[0].Name)
+
+	skipped := logs.FilterMessage("Skipping cluster with invalid configuration").All()
+	assert.Equal(t, 1, len(skipped))
+	assert.Equal(t, "testcluster2", skipped[0].ContextMap()["cluster"])
+}
+
 func Test_ValidateClusterComponents_Success(t *testing.T) {
 	testCases := []test.TestCase{
 		{
@@ -268,3 +373,716 @@ func Test_ValidateClusterComponents_Error(t *testing.T) {
 		})
 	}
 }
+
+func Test_CreateCluster_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name: "CreateCluster should return Success for a kops cluster",
+			ExpectedSuccess: &Cluster{
+				Name:                     "testcluster",
+				ApiEndpoint:              "https://api.testcluster:443",
+				ControlPlaneEndpointHost: "api.testcluster",
+				ControlPlaneEndpointPort: 443,
+				Region:                   "us-east-1",
+				ClusterGroup:             "test-clusters",
+				Environment:              "test",
+				CIDR:                     []string{"100.64.0.0/13"},
+				Metadata: map[string]interface{}{
+					"region":       "us-east-1",
+					"clusterGroup": "test-clusters",
+					"environment":  "test",
+				},
+				ControlPlane:   &ClusterControlPlane{Provider: "kops", Version: "1.21.5"},
+				Infrastructure: &ClusterInfrastructure{Provider: "kops"},
+			},
+			ExpectedClientError: nil,
+			Request: &ClusterSpec{
+				Name:                   "testcluster",
+				KubernetesVersion:      "1.21.5",
+				KubeProvider:           "kops",
+				InfrastructureProvider: "kops",
+				Region:                 "us-east-1",
+				ClusterGroup:           "test-clusters",
+				Environment:            "test",
+				Zones:                  []string{"us-east-1a", "us-east-1b"},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster2", "testcluster-kops-cp2", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster2", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	os.Setenv(kops.StateStoreEnv, "s3://test-state-store")
+	defer os.Unsetenv(kops.StateStoreEnv)
+
+	for _, testCase := range testCases {
+		request, ok := testCase.Request.(*ClusterSpec)
+		if !ok {
+			log.Fatalf("Failed converting Request struct from test \"%s\" to *ClusterSpec", testCase.Name)
+		}
+		expectedCluster, ok := testCase.ExpectedSuccess.(*Cluster)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to *Cluster", testCase.Name)
+		}
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			response, err := CreateCluster(k, request)
+			assert.NilError(t, err)
+			assert.Assert(t, reflect.DeepEqual(expectedCluster, response))
+
+			createdCluster, err := GetCluster(k, request.Name)
+			assert.NilError(t, err)
+			assert.Assert(t, reflect.DeepEqual(expectedCluster, createdCluster))
+
+			kopsControlPlane, err := k.K8sAuth.DynamicClient.Resource(k8s.KopsControlPlaneSchemaV1alpha1).Namespace(test.GetTestClusterNamespace(request.Name)).Get(context.TODO(), GetControlPlaneName(request.Name), metav1.GetOptions{})
+			assert.NilError(t, err)
+			configBase, _, err := unstructured.NestedString(kopsControlPlane.Object, "spec", "kopsClusterSpec", "configBase")
+			assert.NilError(t, err)
+			assert.Equal(t, "s3://test-state-store/testcluster", configBase)
+
+			_, err = k.K8sAuth.DynamicClient.Resource(k8s.KopsAWSClusterSchemaV1alpha1).Namespace(test.GetTestClusterNamespace(request.Name)).Get(context.TODO(), GetClusterInfrastructureName(request.Name), metav1.GetOptions{})
+			assert.NilError(t, err)
+		})
+	}
+}
+
+func Test_CreateCluster_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "CreateCluster should return Error for an existent cluster",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "Cluster testcluster already exists",
+				ErrorMessage:         clientError.ResourceAlreadyExists,
+			},
+			Request: &ClusterSpec{
+				Name:                   "testcluster",
+				KubernetesVersion:      "1.21.5",
+				KubeProvider:           "kops",
+				InfrastructureProvider: "kops",
+				Zones:                  []string{"us-east-1a"},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+		{
+			Name:            "CreateCluster should return Error for an unsupported provider",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The providers kubeadm/docker are not supported for cluster creation",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &ClusterSpec{
+				Name:                   "testcluster",
+				KubernetesVersion:      "1.21.5",
+				KubeProvider:           "kubeadm",
+				InfrastructureProvider: "docker",
+				Zones:                  []string{"local"},
+			},
+			K8sTestResources: []runtime.Object{},
+		},
+		{
+			Name:            "CreateCluster should return Error for a cluster without zones",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "Cluster testcluster must have at least one zone",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &ClusterSpec{
+				Name:                   "testcluster",
+				KubernetesVersion:      "1.21.5",
+				KubeProvider:           "kops",
+				InfrastructureProvider: "kops",
+			},
+			K8sTestResources: []runtime.Object{},
+		},
+		{
+			Name:            "CreateCluster should return Error for an unknown topology",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "Cluster testcluster topology must be private or public, not shared",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &ClusterSpec{
+				Name:                   "testcluster",
+				KubernetesVersion:      "1.21.5",
+				KubeProvider:           "kops",
+				InfrastructureProvider: "kops",
+				Zones:                  []string{"us-east-1a"},
+				Topology:               "shared",
+			},
+			K8sTestResources: []runtime.Object{},
+		},
+		{
+			Name:            "CreateCluster should return Error for an invalid access CIDR",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "Cluster testcluster has an invalid CIDR 10.0.0.1",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &ClusterSpec{
+				Name:                   "testcluster",
+				KubernetesVersion:      "1.21.5",
+				KubeProvider:           "kops",
+				InfrastructureProvider: "kops",
+				Zones:                  []string{"us-east-1a"},
+				APIAccessCIDRs:         []string{"10.0.0.1"},
+			},
+			K8sTestResources: []runtime.Object{},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	os.Setenv(kops.StateStoreEnv, "s3://test-state-store")
+	defer os.Unsetenv(kops.StateStoreEnv)
+
+	for _, testCase := range testCases {
+		request, ok := testCase.Request.(*ClusterSpec)
+		if !ok {
+			log.Fatalf("Failed converting Request struct from test \"%s\" to *ClusterSpec", testCase.Name)
+		}
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := CreateCluster(k, request)
+			assert.ErrorContains(t, err, testCase.ExpectedClientError.Error())
+			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
+		})
+	}
+}
+
+func Test_CreateCluster_ErrorRollback(t *testing.T) {
+	testCases := []struct {
+		name             string
+		resources        []runtime.Object
+		expectsNamespace bool
+	}{
+		{
+			name: "CreateCluster should delete the namespace it created when the cluster can't be created",
+			resources: []runtime.Object{
+				test.NewTestKopsControlPlane(GetControlPlaneName("testcluster"), "testcluster", "1.21.5"),
+			},
+			expectsNamespace: false,
+		},
+		{
+			name: "CreateCluster should keep a namespace that already existed when the cluster can't be created",
+			resources: []runtime.Object{
+				test.NewTestNamespace(test.GetTestClusterNamespace("testcluster")),
+				test.NewTestKopsControlPlane(GetControlPlaneName("testcluster"), "testcluster", "1.21.5"),
+			},
+			expectsNamespace: true,
+		},
+	}
+
+	os.Setenv(kops.StateStoreEnv, "s3://test-state-store")
+	defer os.Unsetenv(kops.StateStoreEnv)
+
+	for _, testCase := range testCases {
+		k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClientWithResources(testCase.resources...),
+		}}
+
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := CreateCluster(k, &ClusterSpec{
+				Name:                   "testcluster",
+				KubernetesVersion:      "1.21.5",
+				KubeProvider:           "kops",
+				InfrastructureProvider: "kops",
+				Zones:                  []string{"us-east-1a"},
+			})
+			assert.ErrorContains(t, err, "Could not create the control plane for cluster testcluster")
+
+			_, err = k.K8sAuth.DynamicClient.Resource(k8s.NamespaceSchemaV1).Get(context.TODO(), test.GetTestClusterNamespace("testcluster"), metav1.GetOptions{})
+			assert.Equal(t, testCase.expectsNamespace, err == nil)
+		})
+	}
+}
+
+func Test_CreateCluster_ErrorStateStore(t *testing.T) {
+	testCase := test.TestCase{
+		Name:            "CreateCluster should return Error when the kops state store is not configured",
+		ExpectedSuccess: nil,
+		ExpectedClientError: &clientError.ClientError{
+			ErrorCause:           nil,
+			ErrorDetailedMessage: "The environment variable KOPS_STATE_STORE is not set, kops clusters can't be created",
+			ErrorMessage:         clientError.InvalidConfiguration,
+		},
+		Request: &ClusterSpec{
+			Name:                   "testcluster",
+			KubernetesVersion:      "1.21.5",
+			KubeProvider:           "kops",
+			InfrastructureProvider: "kops",
+			Zones:                  []string{"us-east-1a"},
+		},
+	}
+
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+		DynamicClient: test.NewK8sFakeDynamicClient(),
+	}}
+	request, _ := testCase.Request.(*ClusterSpec)
+
+	t.Run(testCase.Name, func(t *testing.T) {
+		_, err := CreateCluster(k, request)
+		assert.ErrorContains(t, err, testCase.ExpectedClientError.Error())
+		assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
+	})
+}
+
+// newProtectedTestCluster returns a test cluster with the deletion protection enabled in a label or annotation
+func newProtectedTestCluster(name string, useAnnotation bool) *clusterapiv1beta1.Cluster {
+	cluster := test.NewTestCluster(name, "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1")
+	if useAnnotation {
+		cluster.Annotations = map[string]string{DeletionProtectionKey: "true"}
+	} else {
+		cluster.Labels[DeletionProtectionKey] = "true"
+	}
+	return cluster
+}
+
+func Test_DeleteCluster_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:                "DeleteCluster should return Success for a confirmed cluster",
+			ExpectedSuccess:     nil,
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				ResourceName: "testcluster",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			err := DeleteCluster(k, request.ResourceName, request.ResourceName)
+			assert.NilError(t, err)
+
+			_, err = GetCluster(k, request.ResourceName)
+			assert.Assert(t, test.AssertClientError(err, &clientError.ClientError{
+				ErrorMessage:         clientError.ResourceNotFound,
+				ErrorDetailedMessage: "Could not find cluster testcluster",
+			}))
+		})
+	}
+}
+
+func Test_DeleteCluster_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "DeleteCluster should return Error for a cluster without confirmation",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The cluster name must be confirmed to delete the cluster testcluster",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &test.K8sRequest{
+				ResourceName: "testcluster",
+				Cluster:      "",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+		{
+			Name:            "DeleteCluster should return Error for non-existent cluster",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "Could not find cluster nonexistentcluster",
+				ErrorMessage:         clientError.ResourceNotFound,
+			},
+			Request: &test.K8sRequest{
+				ResourceName: "nonexistentcluster",
+				Cluster:      "nonexistentcluster",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+		{
+			Name:            "DeleteCluster should return Error for a cluster protected by label",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "Cluster testcluster is protected against deletion, remove the deletionProtection label and annotation to delete it",
+				ErrorMessage:         clientError.DeletionProtected,
+			},
+			Request: &test.K8sRequest{
+				ResourceName: "testcluster",
+				Cluster:      "testcluster",
+			},
+			K8sTestResources: []runtime.Object{
+				newProtectedTestCluster("testcluster", false),
+			},
+		},
+		{
+			Name:            "DeleteCluster should return Error for a cluster protected by annotation",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "Cluster testcluster is protected against deletion, remove the deletionProtection label and annotation to delete it",
+				ErrorMessage:         clientError.DeletionProtected,
+			},
+			Request: &test.K8sRequest{
+				ResourceName: "testcluster",
+				Cluster:      "testcluster",
+			},
+			K8sTestResources: []runtime.Object{
+				newProtectedTestCluster("testcluster", true),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			// The Cluster field of the request is used as the confirmation
+			err := DeleteCluster(k, request.ResourceName, request.Cluster)
+			assert.ErrorContains(t, err, testCase.ExpectedClientError.Error())
+			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
+		})
+	}
+}
+
+// clusterUpgradeTestRequest is the request of the cluster upgrade tests
+type clusterUpgradeTestRequest struct {
+	Cluster    string
+	Version    string
+	NodeGroups bool
+}
+
+func Test_UpgradeCluster_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:                "UpgradeCluster should return Success upgrading a KopsControlPlane one minor version",
+			ExpectedSuccess:     "1.22.3",
+			ExpectedClientError: nil,
+			Request: &clusterUpgradeTestRequest{
+				Cluster: "testcluster",
+				Version: "1.22.3",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestKopsControlPlane("testcluster-kops-cp", "testcluster", "1.21.5"),
+			},
+		},
+		{
+			Name:                "UpgradeCluster should return Success upgrading a KubeadmControlPlane one minor version",
+			ExpectedSuccess:     "v1.22.0",
+			ExpectedClientError: nil,
+			Request: &clusterUpgradeTestRequest{
+				Cluster: "dockercluster",
+				Version: "1.22.0",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("dockercluster", "dockercluster-control-plane", "KubeadmControlPlane", "controlplane.cluster.x-k8s.io/v1beta1", "dockercluster", "DockerCluster", "infrastructure.cluster.x-k8s.io/v1beta1"),
+				test.NewTestKubeadmControlPlaneWithStatus("dockercluster-control-plane", "dockercluster", "v1.21.2", "v1.21.2"),
+			},
+		},
+		{
+			Name:                "UpgradeCluster should return Success upgrading the NodeGroups of a KubeadmControlPlane cluster without NodeGroups",
+			ExpectedSuccess:     "v1.22.0",
+			ExpectedClientError: nil,
+			Request: &clusterUpgradeTestRequest{
+				Cluster:    "dockercluster",
+				Version:    "1.22.0",
+				NodeGroups: true,
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("dockercluster", "dockercluster-control-plane", "KubeadmControlPlane", "controlplane.cluster.x-k8s.io/v1beta1", "dockercluster", "DockerCluster", "infrastructure.cluster.x-k8s.io/v1beta1"),
+				test.NewTestKubeadmControlPlaneWithStatus("dockercluster-control-plane", "dockercluster", "v1.22.0", "v1.22.0"),
+				test.NewTestMachinePool("othercluster-nodes", "othercluster", "KopsMachinePool", "othercluster-nodes", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestMachineDeployment("othercluster-nodes", "othercluster", "DockerMachineTemplate", "othercluster-nodes", "infrastructure.cluster.x-k8s.io/v1beta1"),
+			},
+		},
+		{
+			Name:                "UpgradeCluster should return Success upgrading the NodeGroups once the KubeadmControlPlane runs the version",
+			ExpectedSuccess:     "v1.22.0",
+			ExpectedClientError: nil,
+			Request: &clusterUpgradeTestRequest{
+				Cluster:    "dockercluster",
+				Version:    "1.22.0",
+				NodeGroups: true,
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("dockercluster", "dockercluster-control-plane", "KubeadmControlPlane", "controlplane.cluster.x-k8s.io/v1beta1", "dockercluster", "DockerCluster", "infrastructure.cluster.x-k8s.io/v1beta1"),
+				test.NewTestKubeadmControlPlaneWithStatus("dockercluster-control-plane", "dockercluster", "v1.22.0", "v1.22.0"),
+				test.NewTestMachinePool("othercluster-nodes", "othercluster", "KopsMachinePool", "othercluster-nodes", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestMachineDeployment("dockercluster-nodes", "dockercluster", "DockerMachineTemplate", "dockercluster-nodes", "infrastructure.cluster.x-k8s.io/v1beta1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request, ok := testCase.Request.(*clusterUpgradeTestRequest)
+		if !ok {
+			log.Fatalf("Failed converting Request struct from test \"%s\" to *clusterUpgradeTestRequest", testCase.Name)
+		}
+		expectedVersion, ok := testCase.ExpectedSuccess.(string)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to string", testCase.Name)
+		}
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			cluster, err := UpgradeCluster(k, request.Cluster, request.Version, request.NodeGroups)
+			assert.NilError(t, err)
+			assert.Equal(t, cluster.ControlPlane.Version, strings.TrimPrefix(expectedVersion, "v"))
+
+			clusterAPICR, err := k.GetCluster(request.Cluster)
+			assert.NilError(t, err)
+			controlPlaneVersion, _, err := getControlPlaneVersion(k, request.Cluster, clusterAPICR.Spec.ControlPlaneRef)
+			assert.NilError(t, err)
+			assert.Equal(t, controlPlaneVersion, expectedVersion)
+
+			if !request.NodeGroups {
+				return
+			}
+			nodeGroups, err := GetNodeGroupListConfig(k, request.Cluster)
+			if err != nil {
+				assert.Equal(t, err.(*clientError.ClientError).ErrorMessage, clientError.EmptyResponse)
+				return
+			}
+			for _, nodeGroup := range nodeGroups {
+				machineDeployment, err := k.GetMachineDeployment(request.Cluster, GetNodeGroupFullName(request.Cluster, nodeGroup.Name))
+				assert.NilError(t, err)
+				assert.Equal(t, *machineDeployment.Spec.Template.Spec.Version, expectedVersion)
+			}
+		})
+	}
+}
+
+func Test_UpgradeCluster_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "UpgradeCluster should return Error for an invalid version",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "latest is not a valid kubernetes version",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &clusterUpgradeTestRequest{
+				Cluster: "testcluster",
+				Version: "latest",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestKopsControlPlane("testcluster-kops-cp", "testcluster", "1.21.5"),
+			},
+		},
+		{
+			Name:            "UpgradeCluster should return Error for non-existent cluster",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "Could not find cluster nonexistentcluster",
+				ErrorMessage:         clientError.ResourceNotFound,
+			},
+			Request: &clusterUpgradeTestRequest{
+				Cluster: "nonexistentcluster",
+				Version: "1.22.3",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+		{
+			Name:            "UpgradeCluster should return Error skipping a minor version",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "Cluster testcluster can't skip minor versions, it must be upgraded from 1.21.5 to 1.22 first",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &clusterUpgradeTestRequest{
+				Cluster: "testcluster",
+				Version: "1.23.0",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestKopsControlPlane("testcluster-kops-cp", "testcluster", "1.21.5"),
+			},
+		},
+		{
+			Name:            "UpgradeCluster should return Error for a downgrade",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "Cluster testcluster is at version 1.21.5 and can't be upgraded to 1.20.0",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &clusterUpgradeTestRequest{
+				Cluster: "testcluster",
+				Version: "1.20.0",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestKopsControlPlane("testcluster-kops-cp", "testcluster", "1.21.5"),
+			},
+		},
+		{
+			Name:            "UpgradeCluster should return Error across major versions",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "Cluster testcluster can't be upgraded across major versions, from 1.21.5 to 2.0.0",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &clusterUpgradeTestRequest{
+				Cluster: "testcluster",
+				Version: "2.0.0",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestKopsControlPlane("testcluster-kops-cp", "testcluster", "1.21.5"),
+			},
+		},
+		{
+			Name:            "UpgradeCluster should return Error upgrading the NodeGroups before the control plane",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The control plane of cluster dockercluster is at version 1.21.2, it must be upgraded to 1.22.0 without upgrading the NodeGroups first",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &clusterUpgradeTestRequest{
+				Cluster:    "dockercluster",
+				Version:    "1.22.0",
+				NodeGroups: true,
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("dockercluster", "dockercluster-control-plane", "KubeadmControlPlane", "controlplane.cluster.x-k8s.io/v1beta1", "dockercluster", "DockerCluster", "infrastructure.cluster.x-k8s.io/v1beta1"),
+				test.NewTestKubeadmControlPlaneWithStatus("dockercluster-control-plane", "dockercluster", "v1.21.2", "v1.21.2"),
+			},
+		},
+		{
+			Name:            "UpgradeCluster should return Error upgrading the NodeGroups of a KopsControlPlane cluster",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "Upgrading the NodeGroups of cluster testcluster is not supported by the control plane provider kops, it doesn't report the version its control plane runs",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &clusterUpgradeTestRequest{
+				Cluster:    "testcluster",
+				Version:    "1.21.9",
+				NodeGroups: true,
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestKopsControlPlane("testcluster-kops-cp", "testcluster", "1.21.9"),
+			},
+		},
+		{
+			Name:            "UpgradeCluster should return Error upgrading the NodeGroups while the KubeadmControlPlane is rolled out",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The control plane of cluster dockercluster is still being upgraded to 1.22.0, the NodeGroups can only be upgraded once it runs this version",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &clusterUpgradeTestRequest{
+				Cluster:    "dockercluster",
+				Version:    "1.22.0",
+				NodeGroups: true,
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("dockercluster", "dockercluster-control-plane", "KubeadmControlPlane", "controlplane.cluster.x-k8s.io/v1beta1", "dockercluster", "DockerCluster", "infrastructure.cluster.x-k8s.io/v1beta1"),
+				test.NewTestKubeadmControlPlaneWithStatus("dockercluster-control-plane", "dockercluster", "v1.22.0", "v1.21.2"),
+			},
+		},
+		{
+			Name:            "UpgradeCluster should return Error upgrading the NodeGroups before the KubeadmControlPlane reports its version",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "The control plane of cluster dockercluster is still being upgraded to 1.22.0, the NodeGroups can only be upgraded once it runs this version",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &clusterUpgradeTestRequest{
+				Cluster:    "dockercluster",
+				Version:    "1.22.0",
+				NodeGroups: true,
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("dockercluster", "dockercluster-control-plane", "KubeadmControlPlane", "controlplane.cluster.x-k8s.io/v1beta1", "dockercluster", "DockerCluster", "infrastructure.cluster.x-k8s.io/v1beta1"),
+				test.NewTestKubeadmControlPlane("dockercluster-control-plane", "dockercluster", "v1.22.0"),
+			},
+		},
+		{
+			Name:            "UpgradeCluster should return Error when the control plane doesn't exist",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "Could not get the kubernetes version of cluster testcluster",
+				ErrorMessage:         clientError.UnexpectedError,
+			},
+			Request: &clusterUpgradeTestRequest{
+				Cluster: "testcluster",
+				Version: "1.22.3",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request, ok := testCase.Request.(*clusterUpgradeTestRequest)
+		if !ok {
+			log.Fatalf("Failed converting Request struct from test \"%s\" to *clusterUpgradeTestRequest", testCase.Name)
+		}
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := UpgradeCluster(k, request.Cluster, request.Version, request.NodeGroups)
+			assert.ErrorContains(t, err, testCase.ExpectedClientError.Error())
+			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
+		})
+	}
+}
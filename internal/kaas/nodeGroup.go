@@ -1,24 +1,77 @@
 package kaas
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/internal/tracing"
 	"github.com/topfreegames/kaas-management-api/util/clientError"
-	"log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterapiexpv1beta1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	"strings"
+	"time"
 )
 
 type NodeGroup struct {
 	Name               string
 	Cluster            string
+	Kind               string
 	Environment        string
 	Region             string
 	InfrastructureName string
 	InfrastructureKind string
 	Replicas           *int32
+	Rollout            *NodeGroupRollout
+	Status             NodeGroupStatus
 	Infrastructure     *NodeInfrastructure
 }
 
+// NodeGroupStatus is the state of the node group machines as reported by its machinePool or machineDeployment
+type NodeGroupStatus struct {
+	Phase               string
+	Replicas            int32
+	ReadyReplicas       int32
+	AvailableReplicas   int32
+	UnavailableReplicas int32
+	// Generation is the generation of the machinePool or machineDeployment spec, the status is outdated while ObservedGeneration is lower
+	Generation         int64
+	ObservedGeneration int64
+	FailureReason      string
+	FailureMessage     string
+}
+
+// States of a node group computed from the status of its machinePool or machineDeployment
+const (
+	NodeGroupStateReady    = "Ready"
+	NodeGroupStateScaling  = "Scaling"
+	NodeGroupStateDegraded = "Degraded"
+	NodeGroupStateFailed   = "Failed"
+)
+
+// nodeGroupScalingPhases are the machinePool and machineDeployment phases reported while machines are being created or removed
+var nodeGroupScalingPhases = map[string]bool{
+	"Pending":      true,
+	"Provisioning": true,
+	"Provisioned":  true,
+	"Scaling":      true,
+	"ScalingUp":    true,
+	"ScalingDown":  true,
+}
+
+// NodeGroupRollout is the progress of the replacement of the node group machines after a change in its machine template,
+// or in the machine type of its kops instance group.
+type NodeGroupRollout struct {
+	Replicas        int32
+	UpdatedReplicas int32
+	ReadyReplicas   int32
+}
+
 // GetNodeGroupFullName Returns the real nodeGroup name stored in Kubernetes with the cluster name prefix
 func GetNodeGroupFullName(clusterName string, nodeGroupName string) string {
 	return fmt.Sprintf("%s-%s", clusterName, nodeGroupName)
@@ -30,14 +83,17 @@ func GetNodeGroupShortName(clusterName string, nodeGroupFullName string) string
 }
 
 // GetNodeGroup checks which CRD the cluster is using for its node groups (eg machinepool or machinedeployment) and returns a specific node group in the Nodegroup struct format
-func GetNodeGroup(k *k8s.Kubernetes, clusterName string, nodeGroupName string) (*NodeGroup, error) {
+func GetNodeGroup(k *k8s.Kubernetes, clusterName string, nodeGroupName string) (_ *NodeGroup, err error) {
+	ctx, span := tracing.Start(k.Context(), "kaas.GetNodeGroup", attribute.String("kaas.cluster", clusterName), attribute.String("kaas.nodegroup", nodeGroupName))
+	defer func() { tracing.End(span, err) }()
+	k = k.WithContext(ctx)
 
 	nodeGroup := &NodeGroup{
 		Name:    nodeGroupName,
 		Cluster: clusterName,
 	}
 
-	err := nodeGroup.getNodeGroupConfig(k)
+	err = nodeGroup.getNodeGroupConfig(k)
 	if err != nil {
 		clienterr, ok := err.(*clientError.ClientError)
 		if !ok {
@@ -71,7 +127,10 @@ func GetNodeGroup(k *k8s.Kubernetes, clusterName string, nodeGroupName string) (
 }
 
 // getNodeGroupConfig returns the machinePool or machineDeployment configurations used by the nodeGroup.
-func (ng *NodeGroup) getNodeGroupConfig(k *k8s.Kubernetes) error {
+func (ng *NodeGroup) getNodeGroupConfig(k *k8s.Kubernetes) (err error) {
+	ctx, span := tracing.Start(k.Context(), "kaas.getNodeGroupConfig", attribute.String("kaas.cluster", ng.Cluster), attribute.String("kaas.nodegroup", ng.Name))
+	defer func() { tracing.End(span, err) }()
+	k = k.WithContext(ctx)
 	// Check if is machinePool
 	machinePool, machinePoolErr := k.GetMachinePool(ng.Cluster, GetNodeGroupFullName(ng.Cluster, ng.Name))
 	if machinePoolErr != nil {
@@ -84,9 +143,12 @@ func (ng *NodeGroup) getNodeGroupConfig(k *k8s.Kubernetes) error {
 		}
 	} else {
 		ng.Cluster = machinePool.Spec.ClusterName
+		ng.Kind = "MachinePool"
 		ng.InfrastructureKind = machinePool.Spec.Template.Spec.InfrastructureRef.Kind
 		ng.InfrastructureName = machinePool.Spec.Template.Spec.InfrastructureRef.Name
 		ng.Replicas = machinePool.Spec.Replicas
+		ng.Rollout = getMachinePoolRollout(machinePool)
+		ng.Status = getMachinePoolStatus(machinePool)
 		return nil
 	}
 
@@ -100,9 +162,12 @@ func (ng *NodeGroup) getNodeGroupConfig(k *k8s.Kubernetes) error {
 			return clientError.NewClientError(clientErr, clientError.InvalidConfiguration, fmt.Sprintf("MachineDeployment %s configuration is invalid", ng.Name))
 		}
 	} else {
+		ng.Kind = "MachineDeployment"
 		ng.InfrastructureKind = machineDeployment.Spec.Template.Spec.InfrastructureRef.Kind
 		ng.InfrastructureName = machineDeployment.Spec.Template.Spec.InfrastructureRef.Name
 		ng.Replicas = machineDeployment.Spec.Replicas
+		ng.Rollout = getMachineDeploymentRollout(machineDeployment)
+		ng.Status = getMachineDeploymentStatus(machineDeployment)
 		return nil
 	}
 
@@ -110,33 +175,157 @@ func (ng *NodeGroup) getNodeGroupConfig(k *k8s.Kubernetes) error {
 	return clientError.NewClientError(finalError, clientError.ResourceNotFound, fmt.Sprintf("Could not find the NodeGroup %s in the cluster %s", ng.Name, ng.Cluster))
 }
 
-// ListNodeGroups Returns a list in the Nodegroup struct format
-func ListNodeGroups(k *k8s.Kubernetes, clusterName string) ([]*NodeGroup, error) {
+// getMachineDeploymentRollout returns the rollout of a machineDeployment or nil if none of its machines use an outdated template
+func getMachineDeploymentRollout(machineDeployment *clusterapiv1beta1.MachineDeployment) *NodeGroupRollout {
+	status := machineDeployment.Status
+	inProgress := status.ObservedGeneration < machineDeployment.Generation || status.Replicas > status.UpdatedReplicas
+	if !inProgress {
+		return nil
+	}
+
+	return &NodeGroupRollout{
+		Replicas:        status.Replicas,
+		UpdatedReplicas: status.UpdatedReplicas,
+		ReadyReplicas:   status.ReadyReplicas,
+	}
+}
+
+// getMachinePoolRollout returns the rollout of a machinePool or nil if none of its instances were outdated by a machine type change
+func getMachinePoolRollout(machinePool *clusterapiexpv1beta1.MachinePool) *NodeGroupRollout {
+	outdatedInstances := machinePool.Annotations[OutdatedInstancesAnnotation]
+	if outdatedInstances == "" {
+		return nil
+	}
+
+	outdated := map[string]bool{}
+	for _, providerID := range strings.Split(outdatedInstances, ",") {
+		outdated[providerID] = true
+	}
+
+	var updatedReplicas int32
+	for _, providerID := range machinePool.Spec.ProviderIDList {
+		if !outdated[providerID] {
+			updatedReplicas++
+		}
+	}
+	if updatedReplicas == int32(len(machinePool.Spec.ProviderIDList)) {
+		return nil
+	}
+
+	return &NodeGroupRollout{
+		Replicas:        machinePool.Status.Replicas,
+		UpdatedReplicas: updatedReplicas,
+		ReadyReplicas:   machinePool.Status.ReadyReplicas,
+	}
+}
+
+// getMachinePoolStatus returns the status of the nodeGroup machines reported by a machinePool
+func getMachinePoolStatus(machinePool *clusterapiexpv1beta1.MachinePool) NodeGroupStatus {
+	status := NodeGroupStatus{
+		Phase:               machinePool.Status.Phase,
+		Replicas:            machinePool.Status.Replicas,
+		ReadyReplicas:       machinePool.Status.ReadyReplicas,
+		AvailableReplicas:   machinePool.Status.AvailableReplicas,
+		UnavailableReplicas: machinePool.Status.UnavailableReplicas,
+		Generation:          machinePool.Generation,
+		ObservedGeneration:  machinePool.Status.ObservedGeneration,
+	}
+
+	if machinePool.Status.FailureReason != nil {
+		status.FailureReason = string(*machinePool.Status.FailureReason)
+	}
+	if machinePool.Status.FailureMessage != nil {
+		status.FailureMessage = *machinePool.Status.FailureMessage
+	}
+	if status.FailureReason == "" && status.FailureMessage == "" {
+		status.FailureReason, status.FailureMessage = getConditionsFailure(machinePool.Status.Conditions)
+	}
+
+	return status
+}
+
+// getMachineDeploymentStatus returns the status of the nodeGroup machines reported by a machineDeployment
+func getMachineDeploymentStatus(machineDeployment *clusterapiv1beta1.MachineDeployment) NodeGroupStatus {
+	status := NodeGroupStatus{
+		Phase:               machineDeployment.Status.Phase,
+		Replicas:            machineDeployment.Status.Replicas,
+		ReadyReplicas:       machineDeployment.Status.ReadyReplicas,
+		AvailableReplicas:   machineDeployment.Status.AvailableReplicas,
+		UnavailableReplicas: machineDeployment.Status.UnavailableReplicas,
+		Generation:          machineDeployment.Generation,
+		ObservedGeneration:  machineDeployment.Status.ObservedGeneration,
+	}
+
+	// machineDeployments don't have failure fields, their failures are only reported in the conditions
+	status.FailureReason, status.FailureMessage = getConditionsFailure(machineDeployment.Status.Conditions)
+
+	return status
+}
+
+// getConditionsFailure returns the reason and message of the first false condition with error severity
+func getConditionsFailure(conditions clusterapiv1beta1.Conditions) (string, string) {
+	for _, condition := range conditions {
+		if condition.Status == corev1.ConditionFalse && condition.Severity == clusterapiv1beta1.ConditionSeverityError {
+			return condition.Reason, condition.Message
+		}
+	}
+	return "", ""
+}
+
+// GetState returns Failed if the nodeGroup reports failures, Scaling while machines are being created, removed or replaced,
+// Degraded when some of the desired machines aren't ready and Ready otherwise
+func (ng *NodeGroup) GetState() string {
+	status := ng.Status
+
+	// Without desired replicas the number of machines is managed outside the nodeGroup, like by an autoscaler
+	desiredReplicas := status.Replicas
+	if ng.Replicas != nil {
+		desiredReplicas = *ng.Replicas
+	}
+
+	switch {
+	case status.Phase == "Failed" || status.FailureReason != "" || status.FailureMessage != "":
+		return NodeGroupStateFailed
+	case nodeGroupScalingPhases[status.Phase] || ng.Rollout != nil || status.ObservedGeneration < status.Generation || status.Replicas != desiredReplicas:
+		return NodeGroupStateScaling
+	case status.ReadyReplicas < desiredReplicas || status.UnavailableReplicas > 0:
+		return NodeGroupStateDegraded
+	}
+	return NodeGroupStateReady
+}
+
+// ListNodeGroups Returns one page of the node groups in the Nodegroup struct format and the token of the next page, which is empty in the last page
+func ListNodeGroups(k *k8s.Kubernetes, clusterName string, options k8s.ListOptions) (_ []*NodeGroup, _ string, err error) {
+	ctx, span := tracing.Start(k.Context(), "kaas.ListNodeGroups", attribute.String("kaas.cluster", clusterName))
+	defer func() { tracing.End(span, err) }()
+	k = k.WithContext(ctx)
 
 	var (
 		nodeGroups []*NodeGroup
 		hasErrors  bool
 	)
 
-	nodeGroupsConfigs, err := GetNodeGroupListConfig(k, clusterName)
+	nodeGroupsConfigs, next, err := getNodeGroupListConfigPage(k, clusterName, options)
 	if err != nil {
 		clienterr, ok := err.(*clientError.ClientError)
 		if !ok {
-			return nil, clientError.NewClientError(clienterr, clientError.UnexpectedError, fmt.Sprintf("Something went wrong while getting NodeGroups configurations for cluster %s", clusterName))
+			return nil, "", clientError.NewClientError(clienterr, clientError.UnexpectedError, fmt.Sprintf("Something went wrong while getting NodeGroups configurations for cluster %s", clusterName))
 		} else {
 			if clienterr.ErrorMessage == clientError.ResourceNotFound {
-				return nil, clienterr
+				return nil, "", clienterr
 			} else if clienterr.ErrorMessage == clientError.EmptyResponse {
-				return nil, clienterr
+				return nil, "", clienterr
+			} else if clienterr.ErrorMessage == clientError.InvalidRequest {
+				return nil, "", clienterr
 			}
-			return nil, clientError.NewClientError(clienterr, clientError.UnexpectedError, fmt.Sprintf("Something went wrong while getting NodeGroup configurations for cluster %s", clusterName))
+			return nil, "", clientError.NewClientError(clienterr, clientError.UnexpectedError, fmt.Sprintf("Something went wrong while getting NodeGroup configurations for cluster %s", clusterName))
 		}
 	}
 
 	for _, nodeGroup := range nodeGroupsConfigs {
 		infrastructure, err := nodeGroup.getNodeInfrastructure(k)
 		if err != nil {
-			log.Printf("Error getting NodeInfrastructure for nodegroup %s: %s", nodeGroup.Name, err.Error())
+			k.Log().Warn("Error getting NodeInfrastructure", zap.String("nodeGroup", nodeGroup.Name), zap.Error(err))
 			hasErrors = true
 		} else {
 			nodeGroup.Infrastructure = infrastructure
@@ -144,18 +333,29 @@ func ListNodeGroups(k *k8s.Kubernetes, clusterName string) ([]*NodeGroup, error)
 		}
 	}
 
-	if len(nodeGroups) < 1 {
+	// A page may only have invalid node groups while the next ones are valid
+	if len(nodeGroups) < 1 && next == "" {
 		if hasErrors {
-			return nil, clientError.NewClientError(nil, clientError.EmptyResponse, fmt.Sprintf("No valid NodeGroups were found for cluster %s, some nodeGroups reported infrastructure resource errors", clusterName))
+			return nil, "", clientError.NewClientError(nil, clientError.EmptyResponse, fmt.Sprintf("No valid NodeGroups were found for cluster %s, some nodeGroups reported infrastructure resource errors", clusterName))
 		}
-		return nil, clientError.NewClientError(nil, clientError.EmptyResponse, fmt.Sprintf("No NodeGroups were found for cluster %s", clusterName))
+		return nil, "", clientError.NewClientError(nil, clientError.EmptyResponse, fmt.Sprintf("No NodeGroups were found for cluster %s", clusterName))
 	}
 
-	return nodeGroups, nil
+	return nodeGroups, next, nil
 }
 
 // GetNodeGroupListConfig returns the machinePool or machineDeployment configurations used by each nodeGroup.
-func GetNodeGroupListConfig(k *k8s.Kubernetes, clusterName string) ([]*NodeGroup, error) {
+func GetNodeGroupListConfig(k *k8s.Kubernetes, clusterName string) (_ []*NodeGroup, err error) {
+	ctx, span := tracing.Start(k.Context(), "kaas.GetNodeGroupListConfig", attribute.String("kaas.cluster", clusterName))
+	defer func() { tracing.End(span, err) }()
+	k = k.WithContext(ctx)
+	nodeGroups, _, err := getNodeGroupListConfigPage(k, clusterName, k8s.ListOptions{})
+	return nodeGroups, err
+}
+
+// getNodeGroupListConfigPage returns one page of the machinePool or machineDeployment configurations used by each nodeGroup,
+// and the token of the next page. The continue token says which of them is being listed.
+func getNodeGroupListConfigPage(k *k8s.Kubernetes, clusterName string, options k8s.ListOptions) ([]*NodeGroup, string, error) {
 
 	var nodeGroups []*NodeGroup
 	var validationErr error
@@ -165,49 +365,52 @@ func GetNodeGroupListConfig(k *k8s.Kubernetes, clusterName string) ([]*NodeGroup
 		"machinePoolErr":       nil,
 	}
 
-	// Check if is machinePool
-	machinePools, machinePoolErr := k.ListMachinePool(clusterName)
-	if machinePoolErr != nil {
-		clientErr, ok := machinePoolErr.(*clientError.ClientError)
-		if !ok {
-			nodePoolErr["machinePoolErr"] = clientError.NewClientError(machinePoolErr, clientError.UnexpectedError, fmt.Sprintf("Error while listing MachinePool for all NodeGroups of the cluster %s", clusterName))
-		} else {
-			if clientErr.ErrorMessage != clientError.EmptyResponse {
-				nodePoolErr["machinePoolErr"] = clientError.NewClientError(clientErr, clientError.UnexpectedError, fmt.Sprintf("Error while listing MachinePool for all NodeGroups of the cluster %s", clusterName))
-			}
-		}
-	} else {
-		if len(machinePools.Items) != 0 {
-			for _, machinePool := range machinePools.Items {
-				validationErr = k8s.ValidateMachineTemplateComponents(machinePool.Spec.Template)
-				if validationErr != nil {
-					log.Printf("Skipping invalid MachinePool %s: %s", machinePool.Name, validationErr.Error())
-					continue
-				}
-				nodeGroup := &NodeGroup{
-					Name:               GetNodeGroupShortName(machinePool.Spec.ClusterName, machinePool.Name),
-					Cluster:            machinePool.Spec.ClusterName,
-					InfrastructureKind: machinePool.Spec.Template.Spec.InfrastructureRef.Kind,
-					InfrastructureName: machinePool.Spec.Template.Spec.InfrastructureRef.Name,
-					Replicas:           machinePool.Spec.Replicas,
+	// Check if is machinePool, unless the previous page was of machineDeployments
+	if !k8s.IsContinueTokenOf(options.Continue, k8s.MachineDeploymentSchemaV1beta1) {
+		machinePools, machinePoolErr := k.ListMachinePool(clusterName, options)
+		if machinePoolErr != nil {
+			clientErr, ok := machinePoolErr.(*clientError.ClientError)
+			if !ok {
+				nodePoolErr["machinePoolErr"] = clientError.NewClientError(machinePoolErr, clientError.UnexpectedError, fmt.Sprintf("Error while listing MachinePool for all NodeGroups of the cluster %s", clusterName))
+			} else if clientErr.ErrorMessage == clientError.InvalidRequest {
+				return nil, "", clientErr
+			} else {
+				if clientErr.ErrorMessage != clientError.EmptyResponse {
+					nodePoolErr["machinePoolErr"] = clientError.NewClientError(clientErr, clientError.UnexpectedError, fmt.Sprintf("Error while listing MachinePool for all NodeGroups of the cluster %s", clusterName))
 				}
-				nodeGroups = append(nodeGroups, nodeGroup)
 			}
+		} else {
+			if len(machinePools.Items) != 0 || machinePools.Continue != "" {
+				for _, machinePool := range machinePools.Items {
+					validationErr = k8s.ValidateMachineTemplateComponents(machinePool.Spec.Template)
+					if validationErr != nil {
+						k.Log().Warn("Skipping invalid MachinePool", zap.String("machinePool", machinePool.Name), zap.Error(validationErr))
+						continue
+					}
+					nodeGroups = append(nodeGroups, newNodeGroupFromMachinePool(&machinePool))
+				}
 
-			if len(nodeGroups) == 0 {
-				return nil, clientError.NewClientError(validationErr, clientError.EmptyResponse, fmt.Sprintf("No valid NodeGroups were found in the cluster %v, some Nodegroups have invalid configuration", clusterName))
-			}
-			if nodePoolErr["machinePoolErr"] == nil {
-				return nodeGroups, nil
+				if len(nodeGroups) == 0 && machinePools.Continue == "" {
+					return nil, "", clientError.NewClientError(validationErr, clientError.EmptyResponse, fmt.Sprintf("No valid NodeGroups were found in the cluster %v, some Nodegroups have invalid configuration", clusterName))
+				}
+				if nodePoolErr["machinePoolErr"] == nil {
+					return nodeGroups, machinePools.Continue, nil
+				}
 			}
 		}
 	}
 
-	machineDeployments, machineDeploymentErr := k.ListMachineDeployment(clusterName)
+	machineDeploymentOptions := options
+	if !k8s.IsContinueTokenOf(options.Continue, k8s.MachineDeploymentSchemaV1beta1) {
+		machineDeploymentOptions.Continue = ""
+	}
+	machineDeployments, machineDeploymentErr := k.ListMachineDeployment(clusterName, machineDeploymentOptions)
 	if machineDeploymentErr != nil {
 		clientErr, ok := machineDeploymentErr.(*clientError.ClientError)
 		if !ok {
 			nodePoolErr["machineDeploymentErr"] = clientError.NewClientError(clientErr, clientError.UnexpectedError, fmt.Sprintf("Error while listing MachineDeployment for all NodeGroups of the cluster %s", clusterName))
+		} else if clientErr.ErrorMessage == clientError.InvalidRequest {
+			return nil, "", clientErr
 		} else {
 			if clientErr.ErrorMessage != clientError.EmptyResponse {
 				nodePoolErr["machineDeploymentErr"] = clientError.NewClientError(machineDeploymentErr, clientErr.ErrorMessage, fmt.Sprintf("Error while listing MachineDeployment for all NodeGroups of the cluster %s", clusterName))
@@ -218,35 +421,595 @@ func GetNodeGroupListConfig(k *k8s.Kubernetes, clusterName string) ([]*NodeGroup
 			for _, machineDeployment := range machineDeployments.Items {
 				validationErr = k8s.ValidateMachineTemplateComponents(machineDeployment.Spec.Template)
 				if validationErr != nil {
-					log.Printf("Skipping invalid MachineDeployment %s: %s", machineDeployment.Name, validationErr.Error())
+					k.Log().Warn("Skipping invalid MachineDeployment", zap.String("machineDeployment", machineDeployment.Name), zap.Error(validationErr))
 					continue
 				}
-				nodeGroup := &NodeGroup{
-					Name:               GetNodeGroupShortName(machineDeployment.Spec.ClusterName, machineDeployment.Name),
-					Cluster:            machineDeployment.Spec.ClusterName,
-					InfrastructureKind: machineDeployment.Spec.Template.Spec.InfrastructureRef.Kind,
-					InfrastructureName: machineDeployment.Spec.Template.Spec.InfrastructureRef.Name,
-					Replicas:           machineDeployment.Spec.Replicas,
-				}
-				nodeGroups = append(nodeGroups, nodeGroup)
+				nodeGroups = append(nodeGroups, newNodeGroupFromMachineDeployment(&machineDeployment))
 			}
 
-			if len(nodeGroups) == 0 {
-				return nil, clientError.NewClientError(nil, clientError.EmptyResponse, fmt.Sprintf("No valid NodeGroups were found in the cluster %s, some Nodegroups have invalid configuration", clusterName))
+			if len(nodeGroups) == 0 && machineDeployments.Continue == "" {
+				return nil, "", clientError.NewClientError(nil, clientError.EmptyResponse, fmt.Sprintf("No valid NodeGroups were found in the cluster %s, some Nodegroups have invalid configuration", clusterName))
 			}
 
-			if nodePoolErr["machineDeploymentErr"] == nil {
-				return nodeGroups, nil
+			return nodeGroups, machineDeployments.Continue, nil
+		}
+	}
+
+	if nodePoolErr["machineDeploymentErr"] != nil || nodePoolErr["machinePoolErr"] != nil {
+		finalErr := fmt.Errorf("%v | %v", nodePoolErr["machineDeploymentErr"], nodePoolErr["machinePoolErr"])
+		return nil, "", clientError.NewClientError(finalErr, clientError.UnexpectedError, fmt.Sprintf("Error while listing infrastructure resources for cluster %s", clusterName))
+	}
+
+	return nil, "", clientError.NewClientError(fmt.Errorf("no nodegroup infrastructure found"), clientError.EmptyResponse, fmt.Sprintf("No NodeGroups were found in the cluster %s", clusterName))
+}
+
+// newNodeGroupFromMachinePool returns the node group configuration of a MachinePool, without its infrastructure
+func newNodeGroupFromMachinePool(machinePool *clusterapiexpv1beta1.MachinePool) *NodeGroup {
+	return &NodeGroup{
+		Name:               GetNodeGroupShortName(machinePool.Spec.ClusterName, machinePool.Name),
+		Cluster:            machinePool.Spec.ClusterName,
+		Kind:               "MachinePool",
+		InfrastructureKind: machinePool.Spec.Template.Spec.InfrastructureRef.Kind,
+		InfrastructureName: machinePool.Spec.Template.Spec.InfrastructureRef.Name,
+		Replicas:           machinePool.Spec.Replicas,
+		Rollout:            getMachinePoolRollout(machinePool),
+		Status:             getMachinePoolStatus(machinePool),
+	}
+}
+
+// newNodeGroupFromMachineDeployment returns the node group configuration of a MachineDeployment, without its infrastructure
+func newNodeGroupFromMachineDeployment(machineDeployment *clusterapiv1beta1.MachineDeployment) *NodeGroup {
+	return &NodeGroup{
+		Name:               GetNodeGroupShortName(machineDeployment.Spec.ClusterName, machineDeployment.Name),
+		Cluster:            machineDeployment.Spec.ClusterName,
+		Kind:               "MachineDeployment",
+		InfrastructureKind: machineDeployment.Spec.Template.Spec.InfrastructureRef.Kind,
+		InfrastructureName: machineDeployment.Spec.Template.Spec.InfrastructureRef.Name,
+		Replicas:           machineDeployment.Spec.Replicas,
+		Rollout:            getMachineDeploymentRollout(machineDeployment),
+		Status:             getMachineDeploymentStatus(machineDeployment),
+	}
+}
+
+// NodeGroupSpec is the provider agnostic specification used to create a new node group
+type NodeGroupSpec struct {
+	Name        string
+	MachineType string
+	Zones       []string
+	Replicas    *int32
+	Min         *int32
+	Max         *int32
+}
+
+// CreateNodeGroup creates a node group in an existing cluster using the node group CRD of the cluster infrastructure provider (eg machinepool or machinedeployment)
+func CreateNodeGroup(k *k8s.Kubernetes, clusterName string, spec *NodeGroupSpec) (_ *NodeGroup, err error) {
+	ctx, span := tracing.Start(k.Context(), "kaas.CreateNodeGroup", attribute.String("kaas.cluster", clusterName), attribute.String("kaas.nodegroup", spec.Name))
+	defer func() { tracing.End(span, err) }()
+	k = k.WithContext(ctx)
+	err = spec.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	cluster, err := GetCluster(k, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	existingNodeGroup := &NodeGroup{
+		Name:    spec.Name,
+		Cluster: clusterName,
+	}
+	err = existingNodeGroup.getNodeGroupConfig(k)
+	if err == nil {
+		return nil, clientError.NewClientError(nil, clientError.ResourceAlreadyExists, fmt.Sprintf("NodeGroup %s already exists in the cluster %s", spec.Name, clusterName))
+	}
+	clientErr, ok := err.(*clientError.ClientError)
+	if !ok || clientErr.ErrorMessage != clientError.ResourceNotFound {
+		return nil, clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Something went wrong while checking if NodeGroup %s exists in the cluster %s", spec.Name, clusterName))
+	}
+
+	switch cluster.Infrastructure.Provider {
+	case "kops":
+		err = createMachinePoolNodeGroup(k, cluster, spec)
+	case "docker":
+		err = createMachineDeploymentNodeGroup(k, cluster, spec)
+	default:
+		return nil, clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("The infrastructure provider %s is not supported for node group creation", cluster.Infrastructure.Provider))
+	}
+	if err != nil {
+		clientErr, ok := err.(*clientError.ClientError)
+		if ok && (clientErr.ErrorMessage == clientError.InvalidRequest || clientErr.ErrorMessage == clientError.ResourceAlreadyExists) {
+			return nil, clientErr
+		}
+		return nil, clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Could not create NodeGroup %s in the cluster %s", spec.Name, clusterName))
+	}
+
+	// The created resources may not be in the cache yet
+	return GetNodeGroup(k.Uncached(), clusterName, spec.Name)
+}
+
+// validate checks if the node group specification is complete and its sizes are consistent
+func (s *NodeGroupSpec) validate() error {
+	if s.Name == "" {
+		return clientError.NewClientError(nil, clientError.InvalidRequest, "NodeGroup name can't be empty")
+	}
+
+	return validateNodeGroupSize(s.Name, s.Replicas, s.Min, s.Max)
+}
+
+// validateNodeGroupSize checks if the node group sizes are not negative and min <= replicas <= max, nil sizes are not checked
+func validateNodeGroupSize(name string, replicas *int32, min *int32, max *int32) error {
+	for field, value := range map[string]*int32{"replicas": replicas, "min": min, "max": max} {
+		if value != nil && *value < 0 {
+			return clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("NodeGroup %s %s can't be negative", name, field))
+		}
+	}
+
+	if min != nil && max != nil && *min > *max {
+		return clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("NodeGroup %s min can't be greater than max", name))
+	}
+
+	if replicas != nil {
+		if min != nil && *replicas < *min {
+			return clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("NodeGroup %s replicas can't be lower than min", name))
+		}
+		if max != nil && *replicas > *max {
+			return clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("NodeGroup %s replicas can't be greater than max", name))
+		}
+	}
+	return nil
+}
+
+// createMachinePoolNodeGroup creates the node group as a MachinePool pointing to its infrastructure resource
+func createMachinePoolNodeGroup(k *k8s.Kubernetes, cluster *Cluster, spec *NodeGroupSpec) error {
+	clusterName := cluster.Name
+	infrastructureRef, err := createNodeInfrastructure(k, clusterName, cluster.Infrastructure.Provider, spec)
+	if err != nil {
+		return err
+	}
+
+	_, err = k.CreateMachinePool(clusterName, newMachinePool(clusterName, spec, infrastructureRef))
+	if err != nil {
+		rollbackErr := deleteNodeInfrastructure(k.Detached(), clusterName, infrastructureRef)
+		if rollbackErr != nil {
+			k.Log().Error("Could not rollback infrastructure", zap.String("infrastructure", infrastructureRef.Name), zap.String("nodeGroup", spec.Name), zap.Error(rollbackErr))
+		}
+		return err
+	}
+	return nil
+}
+
+// createMachineDeploymentNodeGroup creates the node group as a MachineDeployment with its bootstrap and infrastructure templates
+func createMachineDeploymentNodeGroup(k *k8s.Kubernetes, cluster *Cluster, spec *NodeGroupSpec) error {
+	clusterName := cluster.Name
+	if spec.Min != nil || spec.Max != nil {
+		return clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("NodeGroup %s doesn't support min and max, only replicas can be set", spec.Name))
+	}
+	if spec.MachineType != "" || len(spec.Zones) > 0 {
+		return clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("NodeGroup %s doesn't support machine type and zones in the infrastructure provider %s", spec.Name, cluster.Infrastructure.Provider))
+	}
+
+	infrastructureRef, err := createNodeInfrastructure(k, clusterName, cluster.Infrastructure.Provider, spec)
+	if err != nil {
+		return err
+	}
+
+	bootstrapRef, err := createNodeBootstrap(k, clusterName, cluster.ControlPlane.Provider, spec)
+	if err != nil {
+		rollbackErr := deleteNodeInfrastructure(k.Detached(), clusterName, infrastructureRef)
+		if rollbackErr != nil {
+			k.Log().Error("Could not rollback infrastructure", zap.String("infrastructure", infrastructureRef.Name), zap.String("nodeGroup", spec.Name), zap.Error(rollbackErr))
+		}
+		return err
+	}
+
+	_, err = k.CreateMachineDeployment(clusterName, newMachineDeployment(clusterName, spec, infrastructureRef, bootstrapRef))
+	if err != nil {
+		rollbackErr := deleteNodeInfrastructure(k.Detached(), clusterName, infrastructureRef)
+		if rollbackErr != nil {
+			k.Log().Error("Could not rollback infrastructure", zap.String("infrastructure", infrastructureRef.Name), zap.String("nodeGroup", spec.Name), zap.Error(rollbackErr))
+		}
+		rollbackErr = deleteNodeBootstrap(k.Detached(), clusterName, bootstrapRef)
+		if rollbackErr != nil {
+			k.Log().Error("Could not rollback bootstrap", zap.String("bootstrap", bootstrapRef.Name), zap.String("nodeGroup", spec.Name), zap.Error(rollbackErr))
+		}
+		return err
+	}
+	return nil
+}
+
+// newMachinePool returns the MachinePool CR of a new node group
+func newMachinePool(clusterName string, spec *NodeGroupSpec, infrastructureRef *corev1.ObjectReference) *clusterapiexpv1beta1.MachinePool {
+	dataSecretName := ""
+	return &clusterapiexpv1beta1.MachinePool{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "MachinePool",
+			APIVersion: clusterapiexpv1beta1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: GetNodeGroupFullName(clusterName, spec.Name),
+		},
+		Spec: clusterapiexpv1beta1.MachinePoolSpec{
+			ClusterName: clusterName,
+			Replicas:    spec.Replicas,
+			Template: clusterapiv1beta1.MachineTemplateSpec{
+				Spec: clusterapiv1beta1.MachineSpec{
+					ClusterName: clusterName,
+					Bootstrap: clusterapiv1beta1.Bootstrap{
+						// kops bootstraps its own nodes, the machinePool only needs an empty data secret
+						DataSecretName: &dataSecretName,
+					},
+					InfrastructureRef: *infrastructureRef,
+				},
+			},
+		},
+	}
+}
+
+// newMachineDeployment returns the MachineDeployment CR of a new node group
+func newMachineDeployment(clusterName string, spec *NodeGroupSpec, infrastructureRef *corev1.ObjectReference, bootstrapRef *corev1.ObjectReference) *clusterapiv1beta1.MachineDeployment {
+	name := GetNodeGroupFullName(clusterName, spec.Name)
+	labels := map[string]string{
+		clusterapiv1beta1.ClusterLabelName:           clusterName,
+		clusterapiv1beta1.MachineDeploymentLabelName: name,
+	}
+	return &clusterapiv1beta1.MachineDeployment{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "MachineDeployment",
+			APIVersion: clusterapiv1beta1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: clusterapiv1beta1.MachineDeploymentSpec{
+			ClusterName: clusterName,
+			Replicas:    spec.Replicas,
+			Selector: metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Template: clusterapiv1beta1.MachineTemplateSpec{
+				ObjectMeta: clusterapiv1beta1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: clusterapiv1beta1.MachineSpec{
+					ClusterName: clusterName,
+					Bootstrap: clusterapiv1beta1.Bootstrap{
+						ConfigRef: bootstrapRef,
+					},
+					InfrastructureRef: *infrastructureRef,
+				},
+			},
+		},
+	}
+}
+
+// NodeGroupUpdateSpec is the provider agnostic specification of the changes to an existing node group, empty fields are kept unchanged
+type NodeGroupUpdateSpec struct {
+	MachineType string
+	Replicas    *int32
+	Min         *int32
+	Max         *int32
+}
+
+// UpdateNodeGroup changes an existing node group, scaling its MachinePool or MachineDeployment and changing the sizes and machine type of its infrastructure
+func UpdateNodeGroup(k *k8s.Kubernetes, clusterName string, nodeGroupName string, spec *NodeGroupUpdateSpec) (_ *NodeGroup, err error) {
+	ctx, span := tracing.Start(k.Context(), "kaas.UpdateNodeGroup", attribute.String("kaas.cluster", clusterName), attribute.String("kaas.nodegroup", nodeGroupName))
+	defer func() { tracing.End(span, err) }()
+	k = k.WithContext(ctx)
+	if spec.MachineType == "" && spec.Replicas == nil && spec.Min == nil && spec.Max == nil {
+		return nil, clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("No changes were requested for NodeGroup %s", nodeGroupName))
+	}
+
+	nodeGroup, err := GetNodeGroup(k, clusterName, nodeGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	if (spec.Min != nil || spec.Max != nil) && nodeGroup.InfrastructureKind != "KopsMachinePool" {
+		return nil, clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("NodeGroup %s doesn't support min and max, only replicas can be set", nodeGroupName))
+	}
+
+	// The sizes not being changed are validated with their current values
+	replicas, min, max := nodeGroup.Replicas, nodeGroup.Infrastructure.Min, nodeGroup.Infrastructure.Max
+	if spec.Replicas != nil {
+		replicas = spec.Replicas
+	}
+	if spec.Min != nil {
+		min = spec.Min
+	}
+	if spec.Max != nil {
+		max = spec.Max
+	}
+	err = validateNodeGroupSize(nodeGroupName, replicas, min, max)
+	if err != nil {
+		return nil, err
+	}
+
+	// The machine templates replaced by a previous machine type change are deleted once its rollout has completed
+	err = nodeGroup.deleteReplacedTemplates(k)
+	if err != nil {
+		k.Log().Error("Could not delete the replaced machine templates", zap.String("nodeGroup", nodeGroupName), zap.String("cluster", clusterName), zap.Error(err))
+	}
+
+	if spec.MachineType != "" && spec.MachineType != nodeGroup.Infrastructure.MachineType {
+		err = nodeGroup.updateMachineType(k, spec.MachineType)
+		if err != nil {
+			clientErr, ok := err.(*clientError.ClientError)
+			if ok && clientErr.ErrorMessage == clientError.InvalidRequest {
+				return nil, clientErr
 			}
+			return nil, clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Could not update the machine type of NodeGroup %s in the cluster %s", nodeGroupName, clusterName))
+		}
+	}
 
-			return nodeGroups, nil
+	if spec.Min != nil || spec.Max != nil {
+		err = nodeGroup.updateNodeInfrastructureSize(k, spec.Min, spec.Max)
+		if err != nil {
+			return nil, clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Could not update the min and max of NodeGroup %s in the cluster %s", nodeGroupName, clusterName))
 		}
 	}
 
-	if nodePoolErr["machineDeploymentErr"] != nil || nodePoolErr["machinePoolErr"] != nil {
-		finalErr := fmt.Errorf(nodePoolErr["machineDeploymentErr"].Error() + " | " + nodePoolErr["machinePoolErr"].Error())
-		return nil, clientError.NewClientError(finalErr, clientError.UnexpectedError, fmt.Sprintf("Error while listing infrastructure resources for cluster %s", clusterName))
+	if spec.Replicas != nil {
+		err = nodeGroup.updateReplicas(k, *spec.Replicas)
+		if err != nil {
+			return nil, clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Could not update the replicas of NodeGroup %s in the cluster %s", nodeGroupName, clusterName))
+		}
+	}
+
+	// The cache may still have the node group before the update
+	return GetNodeGroup(k.Uncached(), clusterName, nodeGroupName)
+}
+
+// updateReplicas changes the replicas of the machinePool or machineDeployment used by the nodeGroup
+func (ng *NodeGroup) updateReplicas(k *k8s.Kubernetes, replicas int32) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return ng.patchNodeGroupConfig(k, patch)
+}
+
+// updateVersion changes the kubernetes version of the machines of the machinePool or machineDeployment used by the nodeGroup
+func (ng *NodeGroup) updateVersion(k *k8s.Kubernetes, version string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"version": version,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return ng.patchNodeGroupConfig(k, patch)
+}
+
+// patchNodeGroupConfig applies a JSON merge patch to the machinePool or machineDeployment used by the nodeGroup
+func (ng *NodeGroup) patchNodeGroupConfig(k *k8s.Kubernetes, patch []byte) error {
+	switch ng.Kind {
+	case "MachinePool":
+		_, err := k.PatchMachinePool(ng.Cluster, GetNodeGroupFullName(ng.Cluster, ng.Name), patch)
+		return err
+	case "MachineDeployment":
+		_, err := k.PatchMachineDeployment(ng.Cluster, GetNodeGroupFullName(ng.Cluster, ng.Name), patch)
+		return err
+	}
+
+	return clientError.NewClientError(nil, clientError.KindNotFound, fmt.Sprintf("The Kind %s could not be found", ng.Kind))
+}
+
+// Polling used to wait for the machines of a node group to be gone before deleting it
+var (
+	drainPollInterval = 10 * time.Second
+	drainTimeout      = 10 * time.Minute
+)
+
+// DrainNodeGroup scales a node group to zero so its machines are removed before it is deleted with DeleteDrainedNodeGroup
+func DrainNodeGroup(k *k8s.Kubernetes, clusterName string, nodeGroupName string) (err error) {
+	ctx, span := tracing.Start(k.Context(), "kaas.DrainNodeGroup", attribute.String("kaas.cluster", clusterName), attribute.String("kaas.nodegroup", nodeGroupName))
+	defer func() { tracing.End(span, err) }()
+	k = k.WithContext(ctx)
+	nodeGroup := &NodeGroup{
+		Name:    nodeGroupName,
+		Cluster: clusterName,
+	}
+
+	err = nodeGroup.getNodeGroupConfig(k)
+	if err != nil {
+		clientErr, ok := err.(*clientError.ClientError)
+		if ok && clientErr.ErrorMessage == clientError.ResourceNotFound {
+			return clientErr
+		}
+		return clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Something went wrong while getting NodeGroup %s config", nodeGroupName))
+	}
+
+	err = nodeGroup.scaleToZero(k)
+	if err != nil {
+		return clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Could not scale NodeGroup %s to zero before deleting it", nodeGroupName))
+	}
+	return nil
+}
+
+// DeleteDrainedNodeGroup waits until all the machines of a drained node group are gone and deletes it. The wait ends after the drain
+// timeout or with the context of k, leaving the node group scaled to zero without deleting it.
+func DeleteDrainedNodeGroup(k *k8s.Kubernetes, clusterName string, nodeGroupName string) (err error) {
+	ctx, span := tracing.Start(k.Context(), "kaas.DeleteDrainedNodeGroup", attribute.String("kaas.cluster", clusterName), attribute.String("kaas.nodegroup", nodeGroupName))
+	defer func() { tracing.End(span, err) }()
+	k = k.WithContext(ctx)
+	nodeGroup := &NodeGroup{
+		Name:    nodeGroupName,
+		Cluster: clusterName,
+	}
+
+	err = nodeGroup.getNodeGroupConfig(k)
+	if err != nil {
+		clientErr, ok := err.(*clientError.ClientError)
+		if ok && clientErr.ErrorMessage == clientError.ResourceNotFound {
+			return clientErr
+		}
+		return clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Something went wrong while getting NodeGroup %s config", nodeGroupName))
+	}
+
+	err = nodeGroup.waitForNoMachines(k)
+	if err != nil {
+		clientErr, ok := err.(*clientError.ClientError)
+		if ok && clientErr.ErrorMessage == clientError.Timeout {
+			return clientErr
+		}
+		return clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Stopped waiting for the machines of NodeGroup %s to be gone, it was left scaled to zero and wasn't deleted", nodeGroupName))
+	}
+
+	return DeleteNodeGroup(k, clusterName, nodeGroupName)
+}
+
+// DeleteNodeGroup deletes a node group with its infrastructure and bootstrap resources
+func DeleteNodeGroup(k *k8s.Kubernetes, clusterName string, nodeGroupName string) (err error) {
+	ctx, span := tracing.Start(k.Context(), "kaas.DeleteNodeGroup", attribute.String("kaas.cluster", clusterName), attribute.String("kaas.nodegroup", nodeGroupName))
+	defer func() { tracing.End(span, err) }()
+	k = k.WithContext(ctx)
+	nodeGroup := &NodeGroup{
+		Name:    nodeGroupName,
+		Cluster: clusterName,
+	}
+
+	err = nodeGroup.getNodeGroupConfig(k)
+	if err != nil {
+		clientErr, ok := err.(*clientError.ClientError)
+		if ok && clientErr.ErrorMessage == clientError.ResourceNotFound {
+			return clientErr
+		}
+		return clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Something went wrong while getting NodeGroup %s config", nodeGroupName))
+	}
+
+	bootstrapRef, replacedTemplates, err := nodeGroup.deleteNodeGroupConfig(k)
+	if err != nil {
+		clientErr, ok := err.(*clientError.ClientError)
+		if ok && clientErr.ErrorMessage == clientError.ResourceNotFound {
+			return clientError.NewClientError(clientErr, clientError.ResourceNotFound, fmt.Sprintf("Could not find the NodeGroup %s in the cluster %s", nodeGroupName, clusterName))
+		}
+		return clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Could not delete NodeGroup %s in the cluster %s", nodeGroupName, clusterName))
+	}
+
+	infrastructureRef := &corev1.ObjectReference{
+		Kind: nodeGroup.InfrastructureKind,
+		Name: nodeGroup.InfrastructureName,
+	}
+	err = deleteNodeInfrastructure(k, clusterName, infrastructureRef)
+	if err != nil && !isMissingResource(err) {
+		return clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("NodeGroup %s was deleted but its infrastructure %s could not be deleted", nodeGroupName, infrastructureRef.Name))
+	} else if err != nil {
+		k.Log().Warn("Skipping missing infrastructure", zap.String("infrastructure", infrastructureRef.Name), zap.String("nodeGroup", nodeGroupName), zap.Error(err))
+	}
+
+	for _, templateName := range replacedTemplates {
+		err = deleteNodeInfrastructure(k, clusterName, &corev1.ObjectReference{Kind: nodeGroup.InfrastructureKind, Name: templateName})
+		if err != nil && !isMissingResource(err) {
+			return clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("NodeGroup %s was deleted but its replaced infrastructure %s could not be deleted", nodeGroupName, templateName))
+		}
+	}
+
+	if bootstrapRef != nil {
+		err = deleteNodeBootstrap(k, clusterName, bootstrapRef)
+		if err != nil && !isMissingResource(err) {
+			return clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("NodeGroup %s was deleted but its bootstrap %s could not be deleted", nodeGroupName, bootstrapRef.Name))
+		} else if err != nil {
+			k.Log().Warn("Skipping missing bootstrap", zap.String("bootstrap", bootstrapRef.Name), zap.String("nodeGroup", nodeGroupName), zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// isMissingResource returns true if the error reports that a resource or its kind doesn't exist, meaning there's nothing left to delete
+func isMissingResource(err error) bool {
+	clientErr, ok := err.(*clientError.ClientError)
+	return ok && (clientErr.ErrorMessage == clientError.ResourceNotFound || clientErr.ErrorMessage == clientError.KindNotFound)
+}
+
+// scaleToZero scales the nodeGroup to zero replicas
+func (ng *NodeGroup) scaleToZero(k *k8s.Kubernetes) error {
+	// kops doesn't scale an instance group below its min size
+	if ng.InfrastructureKind == "KopsMachinePool" {
+		minSize := int32(0)
+		err := ng.updateNodeInfrastructureSize(k, &minSize, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return ng.updateReplicas(k, 0)
+}
+
+// waitForNoMachines waits until the status of the nodeGroup reports no machines, up to the drain timeout or the end of the context of k
+func (ng *NodeGroup) waitForNoMachines(k *k8s.Kubernetes) error {
+	ctx, cancel := context.WithTimeout(k.Context(), drainTimeout)
+	defer cancel()
+
+	err := wait.PollImmediateUntil(drainPollInterval, func() (bool, error) {
+		replicas, err := ng.getCurrentReplicas(k)
+		if err != nil {
+			return false, err
+		}
+		return replicas == 0, nil
+	}, ctx.Done())
+	if err == wait.ErrWaitTimeout {
+		if k.Context().Err() != nil {
+			return k.Context().Err()
+		}
+		return clientError.NewClientError(err, clientError.Timeout, fmt.Sprintf("NodeGroup %s still has machines after %s, it was left scaled to zero and wasn't deleted", ng.Name, drainTimeout))
+	}
+	return err
+}
+
+// getCurrentReplicas returns the number of machines reported in the status of the machinePool or machineDeployment used by the nodeGroup
+func (ng *NodeGroup) getCurrentReplicas(k *k8s.Kubernetes) (int32, error) {
+	switch ng.Kind {
+	case "MachinePool":
+		machinePool, err := k.GetMachinePool(ng.Cluster, GetNodeGroupFullName(ng.Cluster, ng.Name))
+		if err != nil {
+			return 0, err
+		}
+		return machinePool.Status.Replicas, nil
+	case "MachineDeployment":
+		machineDeployment, err := k.GetMachineDeployment(ng.Cluster, GetNodeGroupFullName(ng.Cluster, ng.Name))
+		if err != nil {
+			return 0, err
+		}
+		return machineDeployment.Status.Replicas, nil
+	}
+
+	return 0, clientError.NewClientError(nil, clientError.KindNotFound, fmt.Sprintf("The Kind %s could not be found", ng.Kind))
+}
+
+// deleteNodeGroupConfig deletes the machinePool or machineDeployment used by the nodeGroup, returning the bootstrap template
+// it referenced and the machine templates replaced by machine type changes that weren't deleted yet
+func (ng *NodeGroup) deleteNodeGroupConfig(k *k8s.Kubernetes) (*corev1.ObjectReference, []string, error) {
+	fullName := GetNodeGroupFullName(ng.Cluster, ng.Name)
+
+	switch ng.Kind {
+	case "MachinePool":
+		return nil, nil, k.DeleteMachinePool(ng.Cluster, fullName)
+	case "MachineDeployment":
+		// Read from the Kubernetes API so the templates replaced by the last machine type change are deleted too
+		machineDeployment, err := k.Uncached().GetMachineDeployment(ng.Cluster, fullName)
+		if err != nil {
+			return nil, nil, err
+		}
+		err = k.DeleteMachineDeployment(ng.Cluster, fullName)
+		if err != nil {
+			return nil, nil, err
+		}
+		return machineDeployment.Spec.Template.Spec.Bootstrap.ConfigRef, getReplacedTemplates(machineDeployment), nil
 	}
 
-	return nil, clientError.NewClientError(fmt.Errorf("no nodegroup infrastructure found"), clientError.EmptyResponse, fmt.Sprintf("No NodeGroups were found in the cluster %s", clusterName))
+	return nil, nil, clientError.NewClientError(nil, clientError.KindNotFound, fmt.Sprintf("The Kind %s could not be found", ng.Kind))
 }
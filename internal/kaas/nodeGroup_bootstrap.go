@@ -0,0 +1,46 @@
+package kaas
+
+import (
+	"fmt"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/internal/k8s/providers/kubeadm"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// createNodeBootstrap creates the bootstrap config template of a new node group and returns a reference to it
+func createNodeBootstrap(k *k8s.Kubernetes, clusterName string, provider string, spec *NodeGroupSpec) (*corev1.ObjectReference, error) {
+	switch provider {
+	case "kubeadm":
+		namespace, err := k.ClusterNamespace(clusterName)
+		if err != nil {
+			return nil, err
+		}
+
+		name := GetNodeGroupFullName(clusterName, spec.Name)
+		kubeadmConfigTemplate, err := kubeadm.CreateKubeadmConfigTemplate(k, clusterName, kubeadm.NewKubeadmConfigTemplate(namespace, name))
+		if err != nil {
+			return nil, err
+		}
+
+		bootstrapRef := &corev1.ObjectReference{
+			APIVersion: kubeadmConfigTemplate.GetAPIVersion(),
+			Kind:       kubeadmConfigTemplate.GetKind(),
+			Name:       kubeadmConfigTemplate.GetName(),
+			Namespace:  kubeadmConfigTemplate.GetNamespace(),
+		}
+		return bootstrapRef, nil
+	}
+
+	return nil, clientError.NewClientError(nil, clientError.KindNotFound, fmt.Sprintf("The bootstrap provider %s is not supported", provider))
+}
+
+// deleteNodeBootstrap deletes the bootstrap config template referenced by a node group
+func deleteNodeBootstrap(k *k8s.Kubernetes, clusterName string, bootstrapRef *corev1.ObjectReference) error {
+	switch bootstrapRef.Kind {
+	case "KubeadmConfigTemplate":
+		return kubeadm.DeleteKubeadmConfigTemplate(k, clusterName, bootstrapRef.Name)
+	}
+
+	return clientError.NewClientError(nil, clientError.KindNotFound, fmt.Sprintf("The Kind %s could not be found", bootstrapRef.Kind))
+}
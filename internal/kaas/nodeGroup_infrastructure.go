@@ -1,10 +1,23 @@
 package kaas
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/internal/k8s/providers/aws"
+	"github.com/topfreegames/kaas-management-api/internal/k8s/providers/docker"
 	"github.com/topfreegames/kaas-management-api/internal/k8s/providers/kops"
+	"github.com/topfreegames/kaas-management-api/internal/tracing"
 	"github.com/topfreegames/kaas-management-api/util/clientError"
+	clusterapikopsv1alpha1 "github.com/topfreegames/kubernetes-kops-operator/apis/infrastructure/v1alpha1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	kopsv1alpha2 "k8s.io/kops/pkg/apis/kops/v1alpha2"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"strings"
 )
 
 type NodeInfrastructure struct {
@@ -19,7 +32,10 @@ type NodeInfrastructure struct {
 }
 
 // GetNodeInfrastructure returns a nodegroup infrastructure resource in a generic format using the NodeInfrastructure struct
-func (ng *NodeGroup) getNodeInfrastructure(k *k8s.Kubernetes) (*NodeInfrastructure, error) {
+func (ng *NodeGroup) getNodeInfrastructure(k *k8s.Kubernetes) (_ *NodeInfrastructure, err error) {
+	ctx, span := tracing.Start(k.Context(), "kaas.getNodeInfrastructure", attribute.String("kaas.cluster", ng.Cluster), attribute.String("kaas.nodegroup", ng.Name))
+	defer func() { tracing.End(span, err) }()
+	k = k.WithContext(ctx)
 	var infrastructure *NodeInfrastructure
 
 	switch ng.InfrastructureKind {
@@ -47,18 +63,333 @@ func (ng *NodeGroup) getNodeInfrastructure(k *k8s.Kubernetes) (*NodeInfrastructu
 			}
 			return nil, clientError.NewClientError(clientErr, clientErr.ErrorMessage, "Could not retrieve the infrastructure")
 		}
+		return newKopsNodeInfrastructure(kops), nil
+
+	case aws.AWSMachineTemplateKind:
+		awsMachineTemplate, err := aws.GetAWSMachineTemplate(k, ng.Cluster, ng.InfrastructureName)
+		if err != nil {
+			clientErr, ok := err.(*clientError.ClientError)
+			if !ok {
+				return nil, fmt.Errorf("an error has ocurred while feching awsmachinetemplate infrastructure: %s", err.Error())
+			}
+			return nil, clientError.NewClientError(clientErr, clientErr.ErrorMessage, "Could not retrieve the infrastructure")
+		}
 		infrastructure = &NodeInfrastructure{
-			Name:        kops.Name,
-			Provider:    "kops",
-			Cluster:     kops.ClusterName,
-			Az:          kops.Spec.KopsInstanceGroupSpec.Subnets,
-			MachineType: kops.Spec.KopsInstanceGroupSpec.MachineType,
-			Min:         kops.Spec.KopsInstanceGroupSpec.MinSize,
-			Max:         kops.Spec.KopsInstanceGroupSpec.MaxSize,
-			Spec:        kops.Spec,
+			Name:        awsMachineTemplate.GetName(),
+			Provider:    "aws",
+			Cluster:     ng.Cluster,
+			MachineType: aws.GetInstanceType(awsMachineTemplate),
+			Spec:        awsMachineTemplate.Object["spec"],
 		}
 		return infrastructure, nil
 	}
 
 	return nil, clientError.NewClientError(nil, clientError.KindNotFound, fmt.Sprintf("The Kind %s could not be found", ng.InfrastructureKind))
 }
+
+// newKopsNodeInfrastructure returns the infrastructure of a node group using a KopsMachinePool
+func newKopsNodeInfrastructure(kopsMachinePool *clusterapikopsv1alpha1.KopsMachinePool) *NodeInfrastructure {
+	return &NodeInfrastructure{
+		Name:        kopsMachinePool.Name,
+		Provider:    "kops",
+		Cluster:     kopsMachinePool.ClusterName,
+		Az:          kopsMachinePool.Spec.KopsInstanceGroupSpec.Subnets,
+		MachineType: kopsMachinePool.Spec.KopsInstanceGroupSpec.MachineType,
+		Min:         kopsMachinePool.Spec.KopsInstanceGroupSpec.MinSize,
+		Max:         kopsMachinePool.Spec.KopsInstanceGroupSpec.MaxSize,
+		Spec:        kopsMachinePool.Spec,
+	}
+}
+
+// createNodeInfrastructure creates the infrastructure resource of a new node group and returns a reference to it
+func createNodeInfrastructure(k *k8s.Kubernetes, clusterName string, provider string, spec *NodeGroupSpec) (*corev1.ObjectReference, error) {
+	name := GetNodeGroupFullName(clusterName, spec.Name)
+
+	switch provider {
+	case "kops":
+		if spec.MachineType == "" {
+			return nil, clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("NodeGroup %s must have a machine type", spec.Name))
+		}
+		if len(spec.Zones) == 0 {
+			return nil, clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("NodeGroup %s must have at least one zone", spec.Name))
+		}
+
+		kopsMachinePool := &clusterapikopsv1alpha1.KopsMachinePool{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "KopsMachinePool",
+				APIVersion: clusterapikopsv1alpha1.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				ClusterName: clusterName,
+			},
+			Spec: clusterapikopsv1alpha1.KopsMachinePoolSpec{
+				KopsInstanceGroupSpec: kopsv1alpha2.InstanceGroupSpec{
+					Role:        "Node",
+					MachineType: spec.MachineType,
+					MinSize:     spec.Min,
+					MaxSize:     spec.Max,
+					Subnets:     spec.Zones,
+				},
+			},
+		}
+
+		kopsMachinePool, err := kops.CreateKopsMachinePool(k, clusterName, kopsMachinePool)
+		if err != nil {
+			return nil, err
+		}
+
+		infrastructureRef := &corev1.ObjectReference{
+			APIVersion: kopsMachinePool.APIVersion,
+			Kind:       kopsMachinePool.Kind,
+			Name:       kopsMachinePool.Name,
+			Namespace:  kopsMachinePool.Namespace,
+		}
+		return infrastructureRef, nil
+
+	case "docker":
+		namespace, err := k.ClusterNamespace(clusterName)
+		if err != nil {
+			return nil, err
+		}
+
+		dockerMachineTemplate, err := docker.CreateDockerMachineTemplate(k, clusterName, docker.NewDockerMachineTemplate(namespace, name))
+		if err != nil {
+			return nil, err
+		}
+
+		infrastructureRef := &corev1.ObjectReference{
+			APIVersion: dockerMachineTemplate.GetAPIVersion(),
+			Kind:       dockerMachineTemplate.GetKind(),
+			Name:       dockerMachineTemplate.GetName(),
+			Namespace:  dockerMachineTemplate.GetNamespace(),
+		}
+		return infrastructureRef, nil
+	}
+
+	return nil, clientError.NewClientError(nil, clientError.KindNotFound, fmt.Sprintf("The infrastructure provider %s is not supported", provider))
+}
+
+// deleteNodeInfrastructure deletes the infrastructure resource referenced by a node group
+func deleteNodeInfrastructure(k *k8s.Kubernetes, clusterName string, infrastructureRef *corev1.ObjectReference) error {
+	switch infrastructureRef.Kind {
+	case "KopsMachinePool":
+		return kops.DeleteKopsMachinePool(k, clusterName, infrastructureRef.Name)
+	case "DockerMachineTemplate":
+		return docker.DeleteDockerMachineTemplate(k, clusterName, infrastructureRef.Name)
+	case aws.AWSMachineTemplateKind:
+		return aws.DeleteAWSMachineTemplate(k, clusterName, infrastructureRef.Name)
+	}
+
+	return clientError.NewClientError(nil, clientError.KindNotFound, fmt.Sprintf("The Kind %s could not be found", infrastructureRef.Kind))
+}
+
+// updateNodeInfrastructureSize changes the min and max sizes of the nodegroup infrastructure resource, nil sizes are kept unchanged
+func (ng *NodeGroup) updateNodeInfrastructureSize(k *k8s.Kubernetes, min *int32, max *int32) error {
+	switch ng.InfrastructureKind {
+	case "KopsMachinePool":
+		instanceGroupSpec := map[string]interface{}{}
+		if min != nil {
+			instanceGroupSpec["minSize"] = *min
+		}
+		if max != nil {
+			instanceGroupSpec["maxSize"] = *max
+		}
+		patch, err := json.Marshal(map[string]interface{}{
+			"spec": map[string]interface{}{
+				"kopsInstanceGroupSpec": instanceGroupSpec,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = kops.PatchKopsMachinePool(k, ng.Cluster, ng.InfrastructureName, patch)
+		return err
+	}
+
+	return clientError.NewClientError(nil, clientError.KindNotFound, fmt.Sprintf("The Kind %s could not be found", ng.InfrastructureKind))
+}
+
+// OutdatedInstancesAnnotation is the annotation of a machinePool with the provider IDs of the instances using the previous machine type
+const OutdatedInstancesAnnotation = "kaas-management-api/outdated-instances"
+
+// ReplacedTemplatesAnnotation is the annotation of a machineDeployment with the names of the machine templates replaced by
+// machine type changes, they are kept for the machines still using them and deleted once the rollout completes
+const ReplacedTemplatesAnnotation = "kaas-management-api/replaced-templates"
+
+// updateMachineType changes the machine type of the nodegroup infrastructure, the machines are replaced with a rolling update
+// tracked in the rollout of the nodegroup
+func (ng *NodeGroup) updateMachineType(k *k8s.Kubernetes, machineType string) error {
+	switch {
+	case ng.Kind == "MachinePool" && ng.InfrastructureKind == "KopsMachinePool":
+		return ng.updateKopsMachineType(k, machineType)
+	case ng.Kind == "MachineDeployment" && ng.InfrastructureKind == aws.AWSMachineTemplateKind:
+		return ng.replaceMachineTemplate(k, machineType)
+	}
+
+	return clientError.NewClientError(nil, clientError.InvalidRequest, fmt.Sprintf("Changing the machine type of NodeGroup %s is not supported by the infrastructure provider %s", ng.Name, ng.Infrastructure.Provider))
+}
+
+// updateKopsMachineType changes the machine type of the kops instance group, the instances are replaced by kops with a rolling update.
+// The instances using the previous machine type are recorded in the machinePool to track the rollout.
+func (ng *NodeGroup) updateKopsMachineType(k *k8s.Kubernetes, machineType string) error {
+	machinePool, err := k.GetMachinePool(ng.Cluster, GetNodeGroupFullName(ng.Cluster, ng.Name))
+	if err != nil {
+		return err
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"kopsInstanceGroupSpec": map[string]interface{}{
+				"machineType": machineType,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = kops.PatchKopsMachinePool(k, ng.Cluster, ng.InfrastructureName, patch)
+	if err != nil {
+		return err
+	}
+
+	// A null annotation removes the one of a previous rollout when the machinePool has no instances
+	var outdatedInstances interface{}
+	if len(machinePool.Spec.ProviderIDList) != 0 {
+		outdatedInstances = strings.Join(machinePool.Spec.ProviderIDList, ",")
+	}
+	patch, err = json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				OutdatedInstancesAnnotation: outdatedInstances,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = k.PatchMachinePool(ng.Cluster, machinePool.Name, patch)
+	if err != nil {
+		// The machine type was already changed, only the rollout progress is lost
+		k.Log().Error("Could not record the outdated instances of the MachinePool", zap.String("machinePool", machinePool.Name), zap.String("nodeGroup", ng.Name), zap.Error(err))
+	}
+	return nil
+}
+
+// replaceMachineTemplate points the machineDeployment to a clone of its machine template with another machine type, since
+// the templates are immutable, and cluster-API replaces its machines with a rolling update. The replaced template is
+// recorded in the machineDeployment to be deleted once the rollout completes.
+func (ng *NodeGroup) replaceMachineTemplate(k *k8s.Kubernetes, machineType string) error {
+	// The replaced templates are read from the Kubernetes API, the cache may not have the last ones yet
+	machineDeployment, err := k.Uncached().GetMachineDeployment(ng.Cluster, GetNodeGroupFullName(ng.Cluster, ng.Name))
+	if err != nil {
+		return err
+	}
+
+	awsMachineTemplate, err := aws.GetAWSMachineTemplate(k, ng.Cluster, ng.InfrastructureName)
+	if err != nil {
+		return err
+	}
+
+	templateName := fmt.Sprintf("%s-%s", GetNodeGroupFullName(ng.Cluster, ng.Name), rand.String(5))
+	newTemplate, err := aws.CloneAWSMachineTemplate(awsMachineTemplate, templateName, machineType)
+	if err != nil {
+		return err
+	}
+
+	replacedTemplates := append(getReplacedTemplates(machineDeployment), ng.InfrastructureName)
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				ReplacedTemplatesAnnotation: strings.Join(replacedTemplates, ","),
+			},
+		},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"infrastructureRef": map[string]interface{}{
+						"name": templateName,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = aws.CreateAWSMachineTemplate(k, ng.Cluster, newTemplate)
+	if err != nil {
+		return err
+	}
+
+	_, err = k.PatchMachineDeployment(ng.Cluster, machineDeployment.Name, patch)
+	if err != nil {
+		rollbackErr := aws.DeleteAWSMachineTemplate(k.Detached(), ng.Cluster, templateName)
+		if rollbackErr != nil {
+			k.Log().Error("Could not rollback AWSMachineTemplate", zap.String("template", templateName), zap.String("nodeGroup", ng.Name), zap.Error(rollbackErr))
+		}
+		return err
+	}
+
+	ng.InfrastructureName = templateName
+	return nil
+}
+
+// deleteReplacedTemplates deletes the machine templates replaced by machine type changes once the rollout of the machineDeployment
+// has completed, the templates that can't be deleted are kept in its annotation to be deleted later
+func (ng *NodeGroup) deleteReplacedTemplates(k *k8s.Kubernetes) error {
+	if ng.Kind != "MachineDeployment" {
+		return nil
+	}
+
+	// The rollout is read from the Kubernetes API, the cache may not have the last machine type change yet
+	machineDeployment, err := k.Uncached().GetMachineDeployment(ng.Cluster, GetNodeGroupFullName(ng.Cluster, ng.Name))
+	if err != nil {
+		return err
+	}
+	replacedTemplates := getReplacedTemplates(machineDeployment)
+	if len(replacedTemplates) == 0 || getMachineDeploymentRollout(machineDeployment) != nil {
+		return nil
+	}
+
+	var remainingTemplates []string
+	for _, templateName := range replacedTemplates {
+		err = deleteNodeInfrastructure(k, ng.Cluster, &corev1.ObjectReference{Kind: ng.InfrastructureKind, Name: templateName})
+		if err != nil && !isMissingResource(err) {
+			k.Log().Error("Could not delete the replaced machine template", zap.String("template", templateName), zap.String("nodeGroup", ng.Name), zap.Error(err))
+			remainingTemplates = append(remainingTemplates, templateName)
+		}
+	}
+
+	// A null annotation removes it once every replaced template was deleted
+	var annotation interface{}
+	if len(remainingTemplates) != 0 {
+		annotation = strings.Join(remainingTemplates, ",")
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				ReplacedTemplatesAnnotation: annotation,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = k.PatchMachineDeployment(ng.Cluster, machineDeployment.Name, patch)
+	return err
+}
+
+// getReplacedTemplates returns the names of the machine templates replaced by machine type changes that weren't deleted yet
+func getReplacedTemplates(machineDeployment *clusterapiv1beta1.MachineDeployment) []string {
+	replacedTemplates := machineDeployment.Annotations[ReplacedTemplatesAnnotation]
+	if replacedTemplates == "" {
+		return nil
+	}
+	return strings.Split(replacedTemplates, ",")
+}
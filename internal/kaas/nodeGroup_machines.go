@@ -0,0 +1,137 @@
+package kaas
+
+import (
+	"fmt"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/internal/tracing"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"go.opentelemetry.io/otel/attribute"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"time"
+)
+
+// NodeGroupMachine is a machine of a node group, identified by the provider ID of its cloud instance
+type NodeGroupMachine struct {
+	Name       string
+	ProviderID string
+	NodeName   string
+	// Phase and CreationTime are only known for machines with a Machine resource, machinePool instances don't have one
+	Phase        string
+	CreationTime *time.Time
+	Version      string
+}
+
+// ListNodeGroupMachines returns the machines of a node group from the Machines of its machineDeployment or the instances of its machinePool
+func ListNodeGroupMachines(k *k8s.Kubernetes, clusterName string, nodeGroupName string) (_ []*NodeGroupMachine, err error) {
+	ctx, span := tracing.Start(k.Context(), "kaas.ListNodeGroupMachines", attribute.String("kaas.cluster", clusterName), attribute.String("kaas.nodegroup", nodeGroupName))
+	defer func() { tracing.End(span, err) }()
+	k = k.WithContext(ctx)
+	nodeGroup := &NodeGroup{
+		Name:    nodeGroupName,
+		Cluster: clusterName,
+	}
+
+	err = nodeGroup.getNodeGroupConfig(k)
+	if err != nil {
+		clientErr, ok := err.(*clientError.ClientError)
+		if ok && clientErr.ErrorMessage == clientError.ResourceNotFound {
+			return nil, clientErr
+		}
+		return nil, clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Something went wrong while getting NodeGroup %s config", nodeGroupName))
+	}
+
+	var machines []*NodeGroupMachine
+	switch nodeGroup.Kind {
+	case "MachinePool":
+		machines, err = nodeGroup.listMachinePoolMachines(k)
+	case "MachineDeployment":
+		machines, err = nodeGroup.listMachineDeploymentMachines(k)
+	default:
+		err = clientError.NewClientError(nil, clientError.KindNotFound, fmt.Sprintf("The Kind %s could not be found", nodeGroup.Kind))
+	}
+	if err != nil {
+		return nil, clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Could not list the machines of NodeGroup %s in the cluster %s", nodeGroupName, clusterName))
+	}
+
+	return machines, nil
+}
+
+// listMachineDeploymentMachines returns the Machines selected by the labels of the machineDeployment used by the nodeGroup
+func (ng *NodeGroup) listMachineDeploymentMachines(k *k8s.Kubernetes) ([]*NodeGroupMachine, error) {
+	machineDeployment, err := k.GetMachineDeployment(ng.Cluster, GetNodeGroupFullName(ng.Cluster, ng.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	// An empty selector would match every Machine of the cluster, so the label set by cluster-API on the machineDeployment Machines is used instead
+	selector := labels.SelectorFromSet(labels.Set{clusterapiv1beta1.MachineDeploymentLabelName: machineDeployment.Name})
+	if len(machineDeployment.Spec.Selector.MatchLabels) != 0 || len(machineDeployment.Spec.Selector.MatchExpressions) != 0 {
+		selector, err = metav1.LabelSelectorAsSelector(&machineDeployment.Spec.Selector)
+		if err != nil {
+			return nil, clientError.NewClientError(err, clientError.InvalidConfiguration, fmt.Sprintf("MachineDeployment %s has an invalid selector", machineDeployment.Name))
+		}
+	}
+
+	machineList, err := k.ListMachines(ng.Cluster, selector.String())
+	if err != nil {
+		clientErr, ok := err.(*clientError.ClientError)
+		if ok && clientErr.ErrorMessage == clientError.EmptyResponse {
+			return []*NodeGroupMachine{}, nil
+		}
+		return nil, err
+	}
+
+	machines := []*NodeGroupMachine{}
+	for _, machine := range machineList.Items {
+		creationTime := machine.CreationTimestamp.UTC()
+		nodeGroupMachine := &NodeGroupMachine{
+			Name:         machine.Name,
+			Phase:        machine.Status.Phase,
+			CreationTime: &creationTime,
+		}
+		if machine.Spec.ProviderID != nil {
+			nodeGroupMachine.ProviderID = *machine.Spec.ProviderID
+		}
+		if machine.Status.NodeRef != nil {
+			nodeGroupMachine.NodeName = machine.Status.NodeRef.Name
+		}
+		if machine.Spec.Version != nil {
+			nodeGroupMachine.Version = *machine.Spec.Version
+		}
+		machines = append(machines, nodeGroupMachine)
+	}
+
+	return machines, nil
+}
+
+// listMachinePoolMachines returns the instances listed in the machinePool used by the nodeGroup
+func (ng *NodeGroup) listMachinePoolMachines(k *k8s.Kubernetes) ([]*NodeGroupMachine, error) {
+	machinePool, err := k.GetMachinePool(ng.Cluster, GetNodeGroupFullName(ng.Cluster, ng.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	var version string
+	if machinePool.Spec.Template.Spec.Version != nil {
+		version = *machinePool.Spec.Template.Spec.Version
+	}
+
+	// cluster-API fills the node references following the order of the provider IDs, they can only be paired when every instance has a node
+	pairNodes := len(machinePool.Status.NodeRefs) == len(machinePool.Spec.ProviderIDList)
+
+	machines := []*NodeGroupMachine{}
+	for i, providerID := range machinePool.Spec.ProviderIDList {
+		nodeGroupMachine := &NodeGroupMachine{
+			ProviderID: providerID,
+			Version:    version,
+		}
+		if pairNodes {
+			nodeGroupMachine.NodeName = machinePool.Status.NodeRefs[i].Name
+		}
+		machines = append(machines, nodeGroupMachine)
+	}
+
+	return machines, nil
+}
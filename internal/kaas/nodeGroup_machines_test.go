@@ -0,0 +1,174 @@
+package kaas
+
+import (
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/test"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"log"
+	"reflect"
+	clusterapiexpv1beta1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	"testing"
+	"time"
+)
+
+// newInstancesTestMachinePool returns a test MachinePool with cloud instances, each one registered as a node when withNodes is set
+func newInstancesTestMachinePool(name string, clusterName string, withNodes bool) *clusterapiexpv1beta1.MachinePool {
+	machinePool := test.NewTestMachinePool(name, clusterName, "KopsMachinePool", name, "infrastructure.cluster.x-k8s.io/v1alpha1")
+	version := "v1.21.5"
+	machinePool.Spec.Template.Spec.Version = &version
+	machinePool.Spec.ProviderIDList = []string{"aws:///us-east-1a/i-0123456789abcdef0", "aws:///us-east-1a/i-0123456789abcdef1"}
+	if withNodes {
+		machinePool.Status.NodeRefs = []corev1.ObjectReference{
+			{Kind: "Node", Name: "ip-10-0-0-1.ec2.internal"},
+			{Kind: "Node", Name: "ip-10-0-0-2.ec2.internal"},
+		}
+	}
+	return machinePool
+}
+
+func Test_ListNodeGroupMachines_Success(t *testing.T) {
+	creationTime := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	testCases := []test.TestCase{
+		{
+			Name: "ListNodeGroupMachines should return Success for the Machines of a MachineDeployment",
+			ExpectedSuccess: []*NodeGroupMachine{
+				{
+					Name:         "testcluster-nodes-abcde",
+					ProviderID:   "aws:///us-east-1a/i-0123456789abcdef0",
+					NodeName:     "ip-10-0-0-1.ec2.internal",
+					Phase:        "Running",
+					CreationTime: &creationTime,
+					Version:      "v1.21.5",
+				},
+			},
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				ResourceName: "nodes",
+				Cluster:      "testcluster",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachineDeployment("testcluster-nodes", "testcluster", "AWSMachineTemplate", "testcluster-nodes", "infrastructure.cluster.x-k8s.io/v1beta1"),
+				test.NewTestMachine("testcluster-nodes-abcde", "testcluster", "testcluster-nodes", "aws:///us-east-1a/i-0123456789abcdef0", "ip-10-0-0-1.ec2.internal"),
+				test.NewTestMachine("testcluster-other-fghij", "testcluster", "testcluster-other", "aws:///us-east-1a/i-0123456789abcdef1", "ip-10-0-0-2.ec2.internal"),
+			},
+		},
+		{
+			Name:                "ListNodeGroupMachines should return Success for a MachineDeployment without Machines",
+			ExpectedSuccess:     []*NodeGroupMachine{},
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				ResourceName: "nodes",
+				Cluster:      "testcluster",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachineDeployment("testcluster-nodes", "testcluster", "AWSMachineTemplate", "testcluster-nodes", "infrastructure.cluster.x-k8s.io/v1beta1"),
+				test.NewTestMachine("testcluster-other-fghij", "testcluster", "testcluster-other", "aws:///us-east-1a/i-0123456789abcdef1", "ip-10-0-0-2.ec2.internal"),
+			},
+		},
+		{
+			Name: "ListNodeGroupMachines should return Success for the instances of a MachinePool",
+			ExpectedSuccess: []*NodeGroupMachine{
+				{
+					ProviderID: "aws:///us-east-1a/i-0123456789abcdef0",
+					NodeName:   "ip-10-0-0-1.ec2.internal",
+					Version:    "v1.21.5",
+				},
+				{
+					ProviderID: "aws:///us-east-1a/i-0123456789abcdef1",
+					NodeName:   "ip-10-0-0-2.ec2.internal",
+					Version:    "v1.21.5",
+				},
+			},
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				ResourceName: "nodes",
+				Cluster:      "testcluster",
+			},
+			K8sTestResources: []runtime.Object{
+				newInstancesTestMachinePool("testcluster-nodes", "testcluster", true),
+			},
+		},
+		{
+			Name: "ListNodeGroupMachines should return Success for the instances of a MachinePool without nodes",
+			ExpectedSuccess: []*NodeGroupMachine{
+				{
+					ProviderID: "aws:///us-east-1a/i-0123456789abcdef0",
+					Version:    "v1.21.5",
+				},
+				{
+					ProviderID: "aws:///us-east-1a/i-0123456789abcdef1",
+					Version:    "v1.21.5",
+				},
+			},
+			ExpectedClientError: nil,
+			Request: &test.K8sRequest{
+				ResourceName: "nodes",
+				Cluster:      "testcluster",
+			},
+			K8sTestResources: []runtime.Object{
+				newInstancesTestMachinePool("testcluster-nodes", "testcluster", false),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		expectedMachines, ok := testCase.ExpectedSuccess.([]*NodeGroupMachine)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to []*NodeGroupMachine", testCase.Name)
+		}
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			response, err := ListNodeGroupMachines(k, request.Cluster, request.ResourceName)
+			assert.NilError(t, err)
+			assert.Assert(t, reflect.DeepEqual(expectedMachines, response))
+		})
+	}
+}
+
+func Test_ListNodeGroupMachines_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "ListNodeGroupMachines should return Error for non-existent NodeGroup",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "Could not find the NodeGroup nonexistent in the cluster testcluster",
+				ErrorMessage:         clientError.ResourceNotFound,
+			},
+			Request: &test.K8sRequest{
+				ResourceName: "nonexistent",
+				Cluster:      "testcluster",
+			},
+			K8sTestResources: []runtime.Object{
+				newInstancesTestMachinePool("testcluster-nodes", "testcluster", true),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request := testCase.GetK8sRequest()
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := ListNodeGroupMachines(k, request.Cluster, request.ResourceName)
+			assert.ErrorContains(t, err, testCase.ExpectedClientError.Error())
+			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
+		})
+	}
+}
@@ -1,14 +1,27 @@
 package kaas
 
 import (
+	"context"
+	"fmt"
 	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/internal/k8s/providers/aws"
+	"github.com/topfreegames/kaas-management-api/internal/k8s/providers/docker"
+	"github.com/topfreegames/kaas-management-api/internal/k8s/providers/kubeadm"
 	"github.com/topfreegames/kaas-management-api/test"
 	"github.com/topfreegames/kaas-management-api/util/clientError"
+	clusterapikopsv1alpha1 "github.com/topfreegames/kubernetes-kops-operator/apis/infrastructure/v1alpha1"
 	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"log"
 	"reflect"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterapiexpv1beta1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	"testing"
+	"time"
 )
 
 var TestKopsInfrastructure NodeInfrastructure = NodeInfrastructure{
@@ -41,6 +54,7 @@ func Test_GetNodeGroup_Success(t *testing.T) {
 				Name:               "TestMachinePool",
 				Cluster:            "TestCluster1",
 				InfrastructureName: "TestKopsMachinePool",
+				Kind:               "MachinePool",
 				InfrastructureKind: "KopsMachinePool",
 				Infrastructure:     nil,
 				Replicas:           nil,
@@ -62,6 +76,7 @@ func Test_GetNodeGroup_Success(t *testing.T) {
 				Name:               "TestMachineDeployment",
 				Cluster:            "TestCluster2",
 				InfrastructureName: "TestDockerMachineTemplate",
+				Kind:               "MachineDeployment",
 				InfrastructureKind: "DockerMachineTemplate",
 				Infrastructure:     nil,
 				Replicas:           nil,
@@ -180,6 +195,7 @@ func Test_ListNodeGroup_Success(t *testing.T) {
 					Name:               "TestMachinePool1",
 					Cluster:            "TestCluster1",
 					InfrastructureName: "TestKopsMachinePool1",
+					Kind:               "MachinePool",
 					InfrastructureKind: "KopsMachinePool",
 					Infrastructure:     nil,
 					Replicas:           nil,
@@ -188,6 +204,7 @@ func Test_ListNodeGroup_Success(t *testing.T) {
 					Name:               "TestMachinePool2",
 					Cluster:            "TestCluster1",
 					InfrastructureName: "TestKopsMachinePool2",
+					Kind:               "MachinePool",
 					InfrastructureKind: "KopsMachinePool",
 					Infrastructure:     nil,
 					Replicas:           nil,
@@ -212,6 +229,7 @@ func Test_ListNodeGroup_Success(t *testing.T) {
 					Name:               "TestMachineDeployment1",
 					Cluster:            "TestCluster2",
 					InfrastructureName: "TestDockerMachineTemplate1",
+					Kind:               "MachineDeployment",
 					InfrastructureKind: "DockerMachineTemplate",
 					Infrastructure:     nil,
 					Replicas:           nil,
@@ -220,6 +238,7 @@ func Test_ListNodeGroup_Success(t *testing.T) {
 					Name:               "TestMachineDeployment2",
 					Cluster:            "TestCluster2",
 					InfrastructureName: "TestDockerMachineTemplate2",
+					Kind:               "MachineDeployment",
 					InfrastructureKind: "DockerMachineTemplate",
 					Infrastructure:     nil,
 					Replicas:           nil,
@@ -252,7 +271,7 @@ func Test_ListNodeGroup_Success(t *testing.T) {
 		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
 
 		t.Run(testCase.Name, func(t *testing.T) {
-			response, err := ListNodeGroups(k, request.Cluster)
+			response, _, err := ListNodeGroups(k, request.Cluster, k8s.ListOptions{})
 			for _, ng := range response {
 				ng.Infrastructure = nil
 			}
@@ -328,7 +347,7 @@ func Test_ListNodeGroup_Error(t *testing.T) {
 		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
 
 		t.Run(testCase.Name, func(t *testing.T) {
-			_, err := ListNodeGroups(k, request.Cluster)
+			_, _, err := ListNodeGroups(k, request.Cluster, k8s.ListOptions{})
 			assert.ErrorContains(t, err, testCase.ExpectedClientError.Error())
 			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
 		})
@@ -343,6 +362,7 @@ func Test_GetNodeGroupConfig_Success(t *testing.T) {
 				Name:               "TestMachinePool",
 				Cluster:            "TestCluster1",
 				InfrastructureName: "TestKopsMachinePool",
+				Kind:               "MachinePool",
 				InfrastructureKind: "KopsMachinePool",
 				Infrastructure:     nil,
 				Replicas:           nil,
@@ -363,6 +383,7 @@ func Test_GetNodeGroupConfig_Success(t *testing.T) {
 				Name:               "TestMachineDeployment",
 				Cluster:            "TestCluster2",
 				InfrastructureName: "TestDockerMachineTemplate",
+				Kind:               "MachineDeployment",
 				InfrastructureKind: "DockerMachineTemplate",
 				Infrastructure:     nil,
 				Replicas:           nil,
@@ -488,6 +509,7 @@ func Test_GetNodeGroupListConfig_Success(t *testing.T) {
 					Name:               "TestMachinePool1",
 					Cluster:            "TestCluster1",
 					InfrastructureName: "TestKopsMachinePool1",
+					Kind:               "MachinePool",
 					InfrastructureKind: "KopsMachinePool",
 					Infrastructure:     nil,
 					Replicas:           nil,
@@ -496,6 +518,7 @@ func Test_GetNodeGroupListConfig_Success(t *testing.T) {
 					Name:               "TestMachinePool2",
 					Cluster:            "TestCluster1",
 					InfrastructureName: "TestKopsMachinePool2",
+					Kind:               "MachinePool",
 					InfrastructureKind: "KopsMachinePool",
 					Infrastructure:     nil,
 					Replicas:           nil,
@@ -518,6 +541,7 @@ func Test_GetNodeGroupListConfig_Success(t *testing.T) {
 					Name:               "TestMachineDeployment1",
 					Cluster:            "TestCluster2",
 					InfrastructureName: "TestDockerMachineTemplate1",
+					Kind:               "MachineDeployment",
 					InfrastructureKind: "DockerMachineTemplate",
 					Infrastructure:     nil,
 					Replicas:           nil,
@@ -526,6 +550,7 @@ func Test_GetNodeGroupListConfig_Success(t *testing.T) {
 					Name:               "TestMachineDeployment2",
 					Cluster:            "TestCluster2",
 					InfrastructureName: "TestDockerMachineTemplate2",
+					Kind:               "MachineDeployment",
 					InfrastructureKind: "DockerMachineTemplate",
 					Infrastructure:     nil,
 					Replicas:           nil,
@@ -636,3 +661,1092 @@ func Test_GetNodeGroupListConfig_Error(t *testing.T) {
 		})
 	}
 }
+
+// nodeGroupCreateTestRequest is the request of the node group creation tests
+type nodeGroupCreateTestRequest struct {
+	Cluster string
+	Spec    *NodeGroupSpec
+}
+
+func Test_CreateNodeGroup_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name: "CreateNodeGroup should return Success for a MachinePool in a kops cluster",
+			ExpectedSuccess: &NodeGroup{
+				Name:               "nodes",
+				Cluster:            "testcluster",
+				InfrastructureName: "testcluster-nodes",
+				Kind:               "MachinePool",
+				InfrastructureKind: "KopsMachinePool",
+				Replicas:           int32Ptr(2),
+				Infrastructure: &NodeInfrastructure{
+					Name:        "testcluster-nodes",
+					Cluster:     "testcluster",
+					Provider:    "kops",
+					Az:          []string{"us-east-1a", "us-east-1b"},
+					MachineType: "m5.xlarge",
+					Min:         int32Ptr(1),
+					Max:         int32Ptr(3),
+				},
+			},
+			ExpectedClientError: nil,
+			Request: &nodeGroupCreateTestRequest{
+				Cluster: "testcluster",
+				Spec: &NodeGroupSpec{
+					Name:        "nodes",
+					MachineType: "m5.xlarge",
+					Zones:       []string{"us-east-1a", "us-east-1b"},
+					Replicas:    int32Ptr(2),
+					Min:         int32Ptr(1),
+					Max:         int32Ptr(3),
+				},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+		{
+			Name: "CreateNodeGroup should return Success for a MachineDeployment in a docker cluster",
+			ExpectedSuccess: &NodeGroup{
+				Name:               "nodes",
+				Cluster:            "dockercluster",
+				InfrastructureName: "dockercluster-nodes",
+				Kind:               "MachineDeployment",
+				InfrastructureKind: "DockerMachineTemplate",
+				Replicas:           int32Ptr(1),
+				Infrastructure:     &TestDockerInfrastructure,
+			},
+			ExpectedClientError: nil,
+			Request: &nodeGroupCreateTestRequest{
+				Cluster: "dockercluster",
+				Spec: &NodeGroupSpec{
+					Name:     "nodes",
+					Replicas: int32Ptr(1),
+				},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("dockercluster", "dockercluster-control-plane", "KubeadmControlPlane", "controlplane.cluster.x-k8s.io/v1beta1", "dockercluster", "DockerCluster", "infrastructure.cluster.x-k8s.io/v1beta1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request, ok := testCase.Request.(*nodeGroupCreateTestRequest)
+		if !ok {
+			log.Fatalf("Failed converting Request struct from test \"%s\" to *nodeGroupCreateTestRequest", testCase.Name)
+		}
+		expectedNodeGroup, ok := testCase.ExpectedSuccess.(*NodeGroup)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to *NodeGroup", testCase.Name)
+		}
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			response, err := CreateNodeGroup(k, request.Cluster, request.Spec)
+			assert.NilError(t, err)
+			response.Infrastructure.Spec = nil
+			assert.Assert(t, reflect.DeepEqual(expectedNodeGroup, response))
+
+			createdNodeGroup, err := GetNodeGroup(k, request.Cluster, request.Spec.Name)
+			assert.NilError(t, err)
+			createdNodeGroup.Infrastructure.Spec = nil
+			assert.Assert(t, reflect.DeepEqual(expectedNodeGroup, createdNodeGroup))
+		})
+	}
+}
+
+func Test_CreateNodeGroup_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "CreateNodeGroup should return Error for an existent node group",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "NodeGroup nodes already exists in the cluster testcluster",
+				ErrorMessage:         clientError.ResourceAlreadyExists,
+			},
+			Request: &nodeGroupCreateTestRequest{
+				Cluster: "testcluster",
+				Spec: &NodeGroupSpec{
+					Name:        "nodes",
+					MachineType: "m5.xlarge",
+					Zones:       []string{"us-east-1a"},
+				},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestMachinePool("testcluster-nodes", "testcluster", "KopsMachinePool", "testcluster-nodes", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestKopsMachinePool("testcluster-nodes", "testcluster"),
+			},
+		},
+		{
+			Name:            "CreateNodeGroup should return Error for a non-existent cluster",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "Could not find cluster nonexistent",
+				ErrorMessage:         clientError.ResourceNotFound,
+			},
+			Request: &nodeGroupCreateTestRequest{
+				Cluster: "nonexistent",
+				Spec: &NodeGroupSpec{
+					Name:        "nodes",
+					MachineType: "m5.xlarge",
+					Zones:       []string{"us-east-1a"},
+				},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+		{
+			Name:            "CreateNodeGroup should return Error for replicas greater than max",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "NodeGroup nodes replicas can't be greater than max",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &nodeGroupCreateTestRequest{
+				Cluster: "testcluster",
+				Spec: &NodeGroupSpec{
+					Name:        "nodes",
+					MachineType: "m5.xlarge",
+					Zones:       []string{"us-east-1a"},
+					Replicas:    int32Ptr(4),
+					Min:         int32Ptr(1),
+					Max:         int32Ptr(3),
+				},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+		{
+			Name:            "CreateNodeGroup should return Error for a kops node group without zones",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "NodeGroup nodes must have at least one zone",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &nodeGroupCreateTestRequest{
+				Cluster: "testcluster",
+				Spec: &NodeGroupSpec{
+					Name:        "nodes",
+					MachineType: "m5.xlarge",
+				},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+			},
+		},
+		{
+			Name:            "CreateNodeGroup should return Error for min and max in a MachineDeployment",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "NodeGroup nodes doesn't support min and max, only replicas can be set",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &nodeGroupCreateTestRequest{
+				Cluster: "dockercluster",
+				Spec: &NodeGroupSpec{
+					Name: "nodes",
+					Min:  int32Ptr(1),
+					Max:  int32Ptr(3),
+				},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("dockercluster", "dockercluster-control-plane", "KubeadmControlPlane", "controlplane.cluster.x-k8s.io/v1beta1", "dockercluster", "DockerCluster", "infrastructure.cluster.x-k8s.io/v1beta1"),
+			},
+		},
+		{
+			Name:            "CreateNodeGroup should return Error for a machine type in a docker cluster",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "NodeGroup nodes doesn't support machine type and zones in the infrastructure provider docker",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &nodeGroupCreateTestRequest{
+				Cluster: "dockercluster",
+				Spec: &NodeGroupSpec{
+					Name:        "nodes",
+					MachineType: "m5.xlarge",
+				},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("dockercluster", "dockercluster-control-plane", "KubeadmControlPlane", "controlplane.cluster.x-k8s.io/v1beta1", "dockercluster", "DockerCluster", "infrastructure.cluster.x-k8s.io/v1beta1"),
+			},
+		},
+		{
+			Name:            "CreateNodeGroup should return Error for zones in a docker cluster",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "NodeGroup nodes doesn't support machine type and zones in the infrastructure provider docker",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &nodeGroupCreateTestRequest{
+				Cluster: "dockercluster",
+				Spec: &NodeGroupSpec{
+					Name:  "nodes",
+					Zones: []string{"us-east-1a"},
+				},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestCluster("dockercluster", "dockercluster-control-plane", "KubeadmControlPlane", "controlplane.cluster.x-k8s.io/v1beta1", "dockercluster", "DockerCluster", "infrastructure.cluster.x-k8s.io/v1beta1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request, ok := testCase.Request.(*nodeGroupCreateTestRequest)
+		if !ok {
+			log.Fatalf("Failed converting Request struct from test \"%s\" to *nodeGroupCreateTestRequest", testCase.Name)
+		}
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := CreateNodeGroup(k, request.Cluster, request.Spec)
+			assert.ErrorContains(t, err, testCase.ExpectedClientError.Error())
+			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
+		})
+	}
+}
+
+// nodeGroupUpdateTestRequest is the request of the node group update tests
+type nodeGroupUpdateTestRequest struct {
+	Cluster   string
+	NodeGroup string
+	Spec      *NodeGroupUpdateSpec
+}
+
+func Test_UpdateNodeGroup_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name: "UpdateNodeGroup should return Success scaling a MachinePool and its KopsMachinePool",
+			ExpectedSuccess: &NodeGroup{
+				Name:               "nodes",
+				Cluster:            "testcluster",
+				Kind:               "MachinePool",
+				InfrastructureName: "testcluster-nodes",
+				InfrastructureKind: "KopsMachinePool",
+				Replicas:           int32Ptr(4),
+				Infrastructure: &NodeInfrastructure{
+					Name:        "testcluster-nodes",
+					Cluster:     "testcluster",
+					Provider:    "kops",
+					Az:          []string{"us-east-1a"},
+					MachineType: "m5.xlarge",
+					Min:         int32Ptr(2),
+					Max:         int32Ptr(5),
+				},
+			},
+			ExpectedClientError: nil,
+			Request: &nodeGroupUpdateTestRequest{
+				Cluster:   "testcluster",
+				NodeGroup: "nodes",
+				Spec: &NodeGroupUpdateSpec{
+					Replicas: int32Ptr(4),
+					Min:      int32Ptr(2),
+					Max:      int32Ptr(5),
+				},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachinePool("testcluster-nodes", "testcluster", "KopsMachinePool", "testcluster-nodes", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestKopsMachinePool("testcluster-nodes", "testcluster"),
+			},
+		},
+		{
+			Name: "UpdateNodeGroup should return Success changing the machine type of a KopsMachinePool",
+			ExpectedSuccess: &NodeGroup{
+				Name:               "nodes",
+				Cluster:            "testcluster",
+				Kind:               "MachinePool",
+				InfrastructureName: "testcluster-nodes",
+				InfrastructureKind: "KopsMachinePool",
+				Infrastructure: &NodeInfrastructure{
+					Name:        "testcluster-nodes",
+					Cluster:     "testcluster",
+					Provider:    "kops",
+					Az:          []string{"us-east-1a"},
+					MachineType: "m5.2xlarge",
+				},
+			},
+			ExpectedClientError: nil,
+			Request: &nodeGroupUpdateTestRequest{
+				Cluster:   "testcluster",
+				NodeGroup: "nodes",
+				Spec: &NodeGroupUpdateSpec{
+					MachineType: "m5.2xlarge",
+				},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachinePool("testcluster-nodes", "testcluster", "KopsMachinePool", "testcluster-nodes", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestKopsMachinePool("testcluster-nodes", "testcluster"),
+			},
+		},
+		{
+			Name: "UpdateNodeGroup should return Success scaling a MachineDeployment",
+			ExpectedSuccess: &NodeGroup{
+				Name:               "nodes",
+				Cluster:            "dockercluster",
+				Kind:               "MachineDeployment",
+				InfrastructureName: "dockercluster-nodes",
+				InfrastructureKind: "DockerMachineTemplate",
+				Replicas:           int32Ptr(3),
+				Infrastructure:     &TestDockerInfrastructure,
+			},
+			ExpectedClientError: nil,
+			Request: &nodeGroupUpdateTestRequest{
+				Cluster:   "dockercluster",
+				NodeGroup: "nodes",
+				Spec: &NodeGroupUpdateSpec{
+					Replicas: int32Ptr(3),
+				},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachineDeployment("dockercluster-nodes", "dockercluster", "DockerMachineTemplate", "dockercluster-nodes", "infrastructure.cluster.x-k8s.io/v1beta1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request, ok := testCase.Request.(*nodeGroupUpdateTestRequest)
+		if !ok {
+			log.Fatalf("Failed converting Request struct from test \"%s\" to *nodeGroupUpdateTestRequest", testCase.Name)
+		}
+		expectedNodeGroup, ok := testCase.ExpectedSuccess.(*NodeGroup)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to *NodeGroup", testCase.Name)
+		}
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			response, err := UpdateNodeGroup(k, request.Cluster, request.NodeGroup, request.Spec)
+			assert.NilError(t, err)
+			response.Infrastructure.Spec = nil
+			assert.Assert(t, reflect.DeepEqual(expectedNodeGroup, response))
+		})
+	}
+}
+
+func Test_UpdateNodeGroup_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "UpdateNodeGroup should return Error for a non-existent node group",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "Could not find the NodeGroup nonexistent in the cluster testcluster",
+				ErrorMessage:         clientError.ResourceNotFound,
+			},
+			Request: &nodeGroupUpdateTestRequest{
+				Cluster:   "testcluster",
+				NodeGroup: "nonexistent",
+				Spec: &NodeGroupUpdateSpec{
+					Replicas: int32Ptr(1),
+				},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachinePool("testcluster-nodes", "testcluster", "KopsMachinePool", "testcluster-nodes", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestKopsMachinePool("testcluster-nodes", "testcluster"),
+			},
+		},
+		{
+			Name:            "UpdateNodeGroup should return Error for an empty update",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "No changes were requested for NodeGroup nodes",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &nodeGroupUpdateTestRequest{
+				Cluster:   "testcluster",
+				NodeGroup: "nodes",
+				Spec:      &NodeGroupUpdateSpec{},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachinePool("testcluster-nodes", "testcluster", "KopsMachinePool", "testcluster-nodes", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestKopsMachinePool("testcluster-nodes", "testcluster"),
+			},
+		},
+		{
+			Name:            "UpdateNodeGroup should return Error for replicas lower than the current min",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "NodeGroup nodes replicas can't be lower than min",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &nodeGroupUpdateTestRequest{
+				Cluster:   "testcluster",
+				NodeGroup: "nodes",
+				Spec: &NodeGroupUpdateSpec{
+					Replicas: int32Ptr(0),
+				},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachinePool("testcluster-nodes", "testcluster", "KopsMachinePool", "testcluster-nodes", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				newSizedTestKopsMachinePool("testcluster-nodes", "testcluster", 1, 3),
+			},
+		},
+		{
+			Name:            "UpdateNodeGroup should return Error for the machine type of a DockerMachineTemplate",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "Changing the machine type of NodeGroup nodes is not supported by the infrastructure provider docker",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &nodeGroupUpdateTestRequest{
+				Cluster:   "dockercluster",
+				NodeGroup: "nodes",
+				Spec: &NodeGroupUpdateSpec{
+					MachineType: "m5.2xlarge",
+				},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachineDeployment("dockercluster-nodes", "dockercluster", "DockerMachineTemplate", "dockercluster-nodes", "infrastructure.cluster.x-k8s.io/v1beta1"),
+			},
+		},
+		{
+			Name:            "UpdateNodeGroup should return Error for min and max in a MachineDeployment",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "NodeGroup nodes doesn't support min and max, only replicas can be set",
+				ErrorMessage:         clientError.InvalidRequest,
+			},
+			Request: &nodeGroupUpdateTestRequest{
+				Cluster:   "dockercluster",
+				NodeGroup: "nodes",
+				Spec: &NodeGroupUpdateSpec{
+					Min: int32Ptr(1),
+				},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachineDeployment("dockercluster-nodes", "dockercluster", "DockerMachineTemplate", "dockercluster-nodes", "infrastructure.cluster.x-k8s.io/v1beta1"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request, ok := testCase.Request.(*nodeGroupUpdateTestRequest)
+		if !ok {
+			log.Fatalf("Failed converting Request struct from test \"%s\" to *nodeGroupUpdateTestRequest", testCase.Name)
+		}
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := UpdateNodeGroup(k, request.Cluster, request.NodeGroup, request.Spec)
+			assert.ErrorContains(t, err, testCase.ExpectedClientError.Error())
+			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
+		})
+	}
+}
+
+// newSizedTestKopsMachinePool returns a test KopsMachinePool with min and max sizes
+func newSizedTestKopsMachinePool(name string, clusterName string, min int32, max int32) *clusterapikopsv1alpha1.KopsMachinePool {
+	kopsMachinePool := test.NewTestKopsMachinePool(name, clusterName)
+	kopsMachinePool.Spec.KopsInstanceGroupSpec.MinSize = &min
+	kopsMachinePool.Spec.KopsInstanceGroupSpec.MaxSize = &max
+	return kopsMachinePool
+}
+
+func Test_UpdateNodeGroup_SuccessMachineTypeRollout(t *testing.T) {
+	machinePool := test.NewTestMachinePool("testcluster-nodes", "testcluster", "KopsMachinePool", "testcluster-nodes", "infrastructure.cluster.x-k8s.io/v1alpha1")
+	machinePool.Spec.ProviderIDList = []string{"aws:///us-east-1a/i-0001", "aws:///us-east-1a/i-0002"}
+	machinePool.Status.Replicas = 2
+	machinePool.Status.ReadyReplicas = 2
+
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+		DynamicClient: test.NewK8sFakeDynamicClientWithResources(machinePool, test.NewTestKopsMachinePool("testcluster-nodes", "testcluster")),
+	}}
+
+	response, err := UpdateNodeGroup(k, "testcluster", "nodes", &NodeGroupUpdateSpec{MachineType: "m5.2xlarge"})
+	assert.NilError(t, err)
+	assert.Equal(t, "m5.2xlarge", response.Infrastructure.MachineType)
+	assert.Assert(t, reflect.DeepEqual(&NodeGroupRollout{Replicas: 2, UpdatedReplicas: 0, ReadyReplicas: 2}, response.Rollout))
+
+	updatedMachinePool, err := k.GetMachinePool("testcluster", "testcluster-nodes")
+	assert.NilError(t, err)
+	assert.Equal(t, "aws:///us-east-1a/i-0001,aws:///us-east-1a/i-0002", updatedMachinePool.Annotations[OutdatedInstancesAnnotation])
+}
+
+func Test_UpdateNodeGroup_SuccessNewMachineTemplate(t *testing.T) {
+	machineDeployment := test.NewTestMachineDeployment("awscluster-nodes", "awscluster", "AWSMachineTemplate", "awscluster-nodes", "infrastructure.cluster.x-k8s.io/v1beta1")
+	machineDeployment.Annotations = map[string]string{ReplacedTemplatesAnnotation: "awscluster-nodes-abcde"}
+	machineDeployment.Status.Replicas = 3
+	machineDeployment.Status.UpdatedReplicas = 1
+
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+		DynamicClient: test.NewK8sFakeDynamicClientWithResources(
+			machineDeployment,
+			test.NewTestAWSMachineTemplate("awscluster-nodes", "awscluster", "m5.xlarge"),
+			test.NewTestAWSMachineTemplate("awscluster-nodes-abcde", "awscluster", "m5.large"),
+		),
+	}}
+
+	response, err := UpdateNodeGroup(k, "awscluster", "nodes", &NodeGroupUpdateSpec{MachineType: "m5.2xlarge"})
+	assert.NilError(t, err)
+	assert.Equal(t, "m5.2xlarge", response.Infrastructure.MachineType)
+	assert.Assert(t, response.InfrastructureName != "awscluster-nodes")
+	assert.Assert(t, response.Rollout != nil)
+
+	updatedMachineDeployment, err := k.GetMachineDeployment("awscluster", "awscluster-nodes")
+	assert.NilError(t, err)
+	assert.Equal(t, response.InfrastructureName, updatedMachineDeployment.Spec.Template.Spec.InfrastructureRef.Name)
+	assert.Equal(t, "awscluster-nodes-abcde,awscluster-nodes", updatedMachineDeployment.Annotations[ReplacedTemplatesAnnotation])
+
+	// The replaced templates are kept for the machines still using them while the rollout is in progress
+	for _, templateName := range []string{"awscluster-nodes", "awscluster-nodes-abcde"} {
+		_, err = aws.GetAWSMachineTemplate(k, "awscluster", templateName)
+		assert.NilError(t, err)
+	}
+}
+
+func Test_UpdateNodeGroup_DeleteReplacedTemplates(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:                "UpdateNodeGroup should delete the replaced machine templates once the rollout completed",
+			ExpectedSuccess:     true,
+			ExpectedClientError: nil,
+			Request:             &clusterapiv1beta1.MachineDeploymentStatus{Replicas: 2, UpdatedReplicas: 2},
+		},
+		{
+			Name:                "UpdateNodeGroup should keep the replaced machine templates while the rollout is in progress",
+			ExpectedSuccess:     false,
+			ExpectedClientError: nil,
+			Request:             &clusterapiv1beta1.MachineDeploymentStatus{Replicas: 3, UpdatedReplicas: 1},
+		},
+	}
+
+	for _, testCase := range testCases {
+		status, ok := testCase.Request.(*clusterapiv1beta1.MachineDeploymentStatus)
+		if !ok {
+			log.Fatalf("Failed converting Request struct from test \"%s\" to *clusterapiv1beta1.MachineDeploymentStatus", testCase.Name)
+		}
+		expectedDeleted, ok := testCase.ExpectedSuccess.(bool)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to bool", testCase.Name)
+		}
+
+		machineDeployment := test.NewTestMachineDeployment("awscluster-nodes", "awscluster", "AWSMachineTemplate", "awscluster-nodes-fghij", "infrastructure.cluster.x-k8s.io/v1beta1")
+		machineDeployment.Annotations = map[string]string{ReplacedTemplatesAnnotation: "awscluster-nodes,awscluster-nodes-abcde"}
+		machineDeployment.Status = *status
+		k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+			DynamicClient: test.NewK8sFakeDynamicClientWithResources(
+				machineDeployment,
+				test.NewTestAWSMachineTemplate("awscluster-nodes-fghij", "awscluster", "m5.2xlarge"),
+				test.NewTestAWSMachineTemplate("awscluster-nodes", "awscluster", "m5.xlarge"),
+			),
+		}}
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := UpdateNodeGroup(k, "awscluster", "nodes", &NodeGroupUpdateSpec{Replicas: int32Ptr(3)})
+			assert.NilError(t, err)
+
+			updatedMachineDeployment, err := k.GetMachineDeployment("awscluster", "awscluster-nodes")
+			assert.NilError(t, err)
+			_, err = aws.GetAWSMachineTemplate(k, "awscluster", "awscluster-nodes")
+			if expectedDeleted {
+				assert.Assert(t, test.AssertClientError(err, &clientError.ClientError{
+					ErrorMessage:         clientError.ResourceNotFound,
+					ErrorDetailedMessage: "The requested AWSMachineTemplate awscluster-nodes was not found in namespace kubernetes-awscluster!",
+				}))
+				assert.Equal(t, "", updatedMachineDeployment.Annotations[ReplacedTemplatesAnnotation])
+			} else {
+				assert.NilError(t, err)
+				assert.Equal(t, "awscluster-nodes,awscluster-nodes-abcde", updatedMachineDeployment.Annotations[ReplacedTemplatesAnnotation])
+			}
+
+			_, err = aws.GetAWSMachineTemplate(k, "awscluster", "awscluster-nodes-fghij")
+			assert.NilError(t, err)
+		})
+	}
+}
+
+func Test_GetMachineDeploymentRollout(t *testing.T) {
+	outdatedMachineDeployment := test.NewTestMachineDeployment("testcluster-nodes", "testcluster", "AWSMachineTemplate", "testcluster-nodes", "infrastructure.cluster.x-k8s.io/v1beta1")
+	outdatedMachineDeployment.Status.Replicas = 3
+	outdatedMachineDeployment.Status.UpdatedReplicas = 1
+	outdatedMachineDeployment.Status.ReadyReplicas = 3
+
+	testCases := []test.TestCase{
+		{
+			Name:                "getMachineDeploymentRollout should return nil for an up to date MachineDeployment",
+			ExpectedSuccess:     (*NodeGroupRollout)(nil),
+			ExpectedClientError: nil,
+			Request:             test.NewTestMachineDeployment("testcluster-nodes", "testcluster", "AWSMachineTemplate", "testcluster-nodes", "infrastructure.cluster.x-k8s.io/v1beta1"),
+		},
+		{
+			Name: "getMachineDeploymentRollout should return the progress of a MachineDeployment with outdated machines",
+			ExpectedSuccess: &NodeGroupRollout{
+				Replicas:        3,
+				UpdatedReplicas: 1,
+				ReadyReplicas:   3,
+			},
+			ExpectedClientError: nil,
+			Request:             outdatedMachineDeployment,
+		},
+	}
+
+	for _, testCase := range testCases {
+		machineDeployment, ok := testCase.Request.(*clusterapiv1beta1.MachineDeployment)
+		if !ok {
+			log.Fatalf("Failed converting Request struct from test \"%s\" to *clusterapiv1beta1.MachineDeployment", testCase.Name)
+		}
+		expectedRollout, ok := testCase.ExpectedSuccess.(*NodeGroupRollout)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to *NodeGroupRollout", testCase.Name)
+		}
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			rollout := getMachineDeploymentRollout(machineDeployment)
+			assert.Assert(t, reflect.DeepEqual(expectedRollout, rollout))
+		})
+	}
+}
+
+func Test_GetMachinePoolRollout(t *testing.T) {
+	newMachinePool := func(outdatedInstances string, providerIDs ...string) *clusterapiexpv1beta1.MachinePool {
+		machinePool := test.NewTestMachinePool("testcluster-nodes", "testcluster", "KopsMachinePool", "testcluster-nodes", "infrastructure.cluster.x-k8s.io/v1alpha1")
+		if outdatedInstances != "" {
+			machinePool.Annotations = map[string]string{OutdatedInstancesAnnotation: outdatedInstances}
+		}
+		machinePool.Spec.ProviderIDList = providerIDs
+		machinePool.Status.Replicas = int32(len(providerIDs))
+		machinePool.Status.ReadyReplicas = int32(len(providerIDs))
+		return machinePool
+	}
+
+	testCases := []test.TestCase{
+		{
+			Name:                "getMachinePoolRollout should return nil for a MachinePool without outdated instances",
+			ExpectedSuccess:     (*NodeGroupRollout)(nil),
+			ExpectedClientError: nil,
+			Request:             newMachinePool("", "i-0001", "i-0002"),
+		},
+		{
+			Name: "getMachinePoolRollout should return the progress of a MachinePool with outdated instances",
+			ExpectedSuccess: &NodeGroupRollout{
+				Replicas:        3,
+				UpdatedReplicas: 2,
+				ReadyReplicas:   3,
+			},
+			ExpectedClientError: nil,
+			Request:             newMachinePool("i-0001,i-0002", "i-0002", "i-0003", "i-0004"),
+		},
+		{
+			Name:                "getMachinePoolRollout should return nil once the outdated instances were replaced",
+			ExpectedSuccess:     (*NodeGroupRollout)(nil),
+			ExpectedClientError: nil,
+			Request:             newMachinePool("i-0001,i-0002", "i-0003", "i-0004"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		machinePool, ok := testCase.Request.(*clusterapiexpv1beta1.MachinePool)
+		if !ok {
+			log.Fatalf("Failed converting Request struct from test \"%s\" to *clusterapiexpv1beta1.MachinePool", testCase.Name)
+		}
+		expectedRollout, ok := testCase.ExpectedSuccess.(*NodeGroupRollout)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to *NodeGroupRollout", testCase.Name)
+		}
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			rollout := getMachinePoolRollout(machinePool)
+			assert.Assert(t, reflect.DeepEqual(expectedRollout, rollout))
+		})
+	}
+}
+
+// nodeGroupDeleteTestRequest is the request of the node group deletion tests, a drained node group is deleted with DrainNodeGroup
+// and DeleteDrainedNodeGroup, waiting with a canceled context when Canceled is set
+type nodeGroupDeleteTestRequest struct {
+	Cluster   string
+	NodeGroup string
+	Drain     bool
+	Canceled  bool
+}
+
+// deleteTestNodeGroup deletes the node group of a deletion test request
+func deleteTestNodeGroup(k *k8s.Kubernetes, request *nodeGroupDeleteTestRequest) error {
+	if !request.Drain {
+		return DeleteNodeGroup(k, request.Cluster, request.NodeGroup)
+	}
+
+	err := DrainNodeGroup(k, request.Cluster, request.NodeGroup)
+	if err != nil {
+		return err
+	}
+	if request.Canceled {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		k = k.WithContext(ctx)
+	}
+	return DeleteDrainedNodeGroup(k, request.Cluster, request.NodeGroup)
+}
+
+func Test_DeleteNodeGroup_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:                "DeleteNodeGroup should return Success deleting a MachinePool and its KopsMachinePool",
+			ExpectedSuccess:     []schema.GroupVersionResource{k8s.MachinePoolSchemaV1beta1, k8s.KopsMachinePoolSchemaV1alpha1},
+			ExpectedClientError: nil,
+			Request: &nodeGroupDeleteTestRequest{
+				Cluster:   "testcluster",
+				NodeGroup: "nodes",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachinePool("testcluster-nodes", "testcluster", "KopsMachinePool", "testcluster-nodes", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestKopsMachinePool("testcluster-nodes", "testcluster"),
+			},
+		},
+		{
+			Name:                "DeleteNodeGroup should return Success draining and deleting a MachinePool and its KopsMachinePool",
+			ExpectedSuccess:     []schema.GroupVersionResource{k8s.MachinePoolSchemaV1beta1, k8s.KopsMachinePoolSchemaV1alpha1},
+			ExpectedClientError: nil,
+			Request: &nodeGroupDeleteTestRequest{
+				Cluster:   "testcluster",
+				NodeGroup: "nodes",
+				Drain:     true,
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachinePool("testcluster-nodes", "testcluster", "KopsMachinePool", "testcluster-nodes", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				newSizedTestKopsMachinePool("testcluster-nodes", "testcluster", 1, 3),
+			},
+		},
+		{
+			Name:                "DeleteNodeGroup should return Success deleting a MachineDeployment and its templates",
+			ExpectedSuccess:     []schema.GroupVersionResource{k8s.MachineDeploymentSchemaV1beta1, k8s.DockerMachineTemplateSchemaV1beta1, k8s.KubeadmConfigTemplateSchemaV1beta1},
+			ExpectedClientError: nil,
+			Request: &nodeGroupDeleteTestRequest{
+				Cluster:   "dockercluster",
+				NodeGroup: "nodes",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachineDeployment("dockercluster-nodes", "dockercluster", "DockerMachineTemplate", "dockercluster-nodes", "infrastructure.cluster.x-k8s.io/v1beta1"),
+				docker.NewDockerMachineTemplate(test.GetTestClusterNamespace("dockercluster"), "dockercluster-nodes"),
+				kubeadm.NewKubeadmConfigTemplate(test.GetTestClusterNamespace("dockercluster"), "dockercluster-nodes"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	for _, testCase := range testCases {
+		request, ok := testCase.Request.(*nodeGroupDeleteTestRequest)
+		if !ok {
+			log.Fatalf("Failed converting Request struct from test \"%s\" to *nodeGroupDeleteTestRequest", testCase.Name)
+		}
+		deletedResources, ok := testCase.ExpectedSuccess.([]schema.GroupVersionResource)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to []schema.GroupVersionResource", testCase.Name)
+		}
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			err := deleteTestNodeGroup(k, request)
+			assert.NilError(t, err)
+
+			for _, resource := range deletedResources {
+				_, err = k.K8sAuth.DynamicClient.Resource(resource).Namespace(test.GetTestClusterNamespace(request.Cluster)).Get(context.TODO(), GetNodeGroupFullName(request.Cluster, request.NodeGroup), metav1.GetOptions{})
+				assert.Assert(t, errors.IsNotFound(err))
+			}
+		})
+	}
+}
+
+func Test_DeleteNodeGroup_SuccessReplacedTemplates(t *testing.T) {
+	machineDeployment := test.NewTestMachineDeployment("awscluster-nodes", "awscluster", "AWSMachineTemplate", "awscluster-nodes-fghij", "infrastructure.cluster.x-k8s.io/v1beta1")
+	machineDeployment.Annotations = map[string]string{ReplacedTemplatesAnnotation: "awscluster-nodes,awscluster-nodes-abcde"}
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+		DynamicClient: test.NewK8sFakeDynamicClientWithResources(
+			machineDeployment,
+			test.NewTestAWSMachineTemplate("awscluster-nodes-fghij", "awscluster", "m5.2xlarge"),
+			test.NewTestAWSMachineTemplate("awscluster-nodes", "awscluster", "m5.xlarge"),
+			kubeadm.NewKubeadmConfigTemplate(test.GetTestClusterNamespace("awscluster"), "awscluster-nodes"),
+		),
+	}}
+
+	err := DeleteNodeGroup(k, "awscluster", "nodes")
+	assert.NilError(t, err)
+
+	for _, templateName := range []string{"awscluster-nodes-fghij", "awscluster-nodes"} {
+		_, err = aws.GetAWSMachineTemplate(k, "awscluster", templateName)
+		assert.Assert(t, test.AssertClientError(err, &clientError.ClientError{
+			ErrorMessage:         clientError.ResourceNotFound,
+			ErrorDetailedMessage: fmt.Sprintf("The requested AWSMachineTemplate %s was not found in namespace kubernetes-awscluster!", templateName),
+		}))
+	}
+}
+
+func Test_DeleteNodeGroup_Error(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:            "DeleteNodeGroup should return Error for a non-existent node group",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "Could not find the NodeGroup nonexistent in the cluster testcluster",
+				ErrorMessage:         clientError.ResourceNotFound,
+			},
+			Request: &nodeGroupDeleteTestRequest{
+				Cluster:   "testcluster",
+				NodeGroup: "nonexistent",
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachinePool("testcluster-nodes", "testcluster", "KopsMachinePool", "testcluster-nodes", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestKopsMachinePool("testcluster-nodes", "testcluster"),
+			},
+		},
+		{
+			Name:            "DeleteNodeGroup should return Error when the machines are not gone after draining",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           nil,
+				ErrorDetailedMessage: "NodeGroup nodes still has machines after 10ms, it was left scaled to zero and wasn't deleted",
+				ErrorMessage:         clientError.Timeout,
+			},
+			Request: &nodeGroupDeleteTestRequest{
+				Cluster:   "testcluster",
+				NodeGroup: "nodes",
+				Drain:     true,
+			},
+			K8sTestResources: []runtime.Object{
+				newRunningTestMachinePool("testcluster-nodes", "testcluster", 2),
+				test.NewTestKopsMachinePool("testcluster-nodes", "testcluster"),
+			},
+		},
+		{
+			Name:            "DeleteNodeGroup should return Error when the context ends while waiting for the machines to be gone",
+			ExpectedSuccess: nil,
+			ExpectedClientError: &clientError.ClientError{
+				ErrorCause:           context.Canceled,
+				ErrorDetailedMessage: "Stopped waiting for the machines of NodeGroup nodes to be gone, it was left scaled to zero and wasn't deleted",
+				ErrorMessage:         clientError.UnexpectedError,
+			},
+			Request: &nodeGroupDeleteTestRequest{
+				Cluster:   "testcluster",
+				NodeGroup: "nodes",
+				Drain:     true,
+				Canceled:  true,
+			},
+			K8sTestResources: []runtime.Object{
+				newRunningTestMachinePool("testcluster-nodes", "testcluster", 2),
+				test.NewTestKopsMachinePool("testcluster-nodes", "testcluster"),
+			},
+		},
+	}
+
+	fakeClient := test.NewK8sFakeDynamicClient()
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{
+		DynamicClient: fakeClient,
+	}}
+
+	defaultPollInterval, defaultTimeout := drainPollInterval, drainTimeout
+	drainPollInterval, drainTimeout = time.Millisecond, 10*time.Millisecond
+	defer func() {
+		drainPollInterval, drainTimeout = defaultPollInterval, defaultTimeout
+	}()
+
+	for _, testCase := range testCases {
+		request, ok := testCase.Request.(*nodeGroupDeleteTestRequest)
+		if !ok {
+			log.Fatalf("Failed converting Request struct from test \"%s\" to *nodeGroupDeleteTestRequest", testCase.Name)
+		}
+		k.K8sAuth.DynamicClient = test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			err := deleteTestNodeGroup(k, request)
+			assert.ErrorContains(t, err, testCase.ExpectedClientError.Error())
+			assert.Assert(t, test.AssertClientError(err, testCase.ExpectedClientError))
+
+			// A node group that couldn't be drained is left scaled to zero
+			if request.Drain {
+				machinePool, err := k.GetMachinePool(request.Cluster, GetNodeGroupFullName(request.Cluster, request.NodeGroup))
+				assert.NilError(t, err)
+				assert.Equal(t, int32(0), *machinePool.Spec.Replicas)
+			}
+		})
+	}
+}
+
+func int32Ptr(value int32) *int32 {
+	return &value
+}
+
+// newRunningTestMachinePool returns a test MachinePool that reports running machines in its status
+func newRunningTestMachinePool(name string, clusterName string, replicas int32) *clusterapiexpv1beta1.MachinePool {
+	machinePool := test.NewTestMachinePool(name, clusterName, "KopsMachinePool", name, "infrastructure.cluster.x-k8s.io/v1alpha1")
+	machinePool.Status.Replicas = replicas
+	return machinePool
+}
+
+func Test_GetMachineDeploymentStatus(t *testing.T) {
+	failedMachineDeployment := test.NewTestMachineDeployment("testcluster-nodes", "testcluster", "AWSMachineTemplate", "testcluster-nodes", "infrastructure.cluster.x-k8s.io/v1beta1")
+	failedMachineDeployment.Generation = 2
+	failedMachineDeployment.Status = clusterapiv1beta1.MachineDeploymentStatus{
+		ObservedGeneration:  2,
+		Replicas:            2,
+		ReadyReplicas:       1,
+		AvailableReplicas:   1,
+		UnavailableReplicas: 1,
+		Phase:               "Running",
+		Conditions: clusterapiv1beta1.Conditions{
+			{
+				Type:   clusterapiv1beta1.ReadyCondition,
+				Status: corev1.ConditionTrue,
+			},
+			{
+				Type:     clusterapiv1beta1.MachineDeploymentAvailableCondition,
+				Status:   corev1.ConditionFalse,
+				Severity: clusterapiv1beta1.ConditionSeverityError,
+				Reason:   clusterapiv1beta1.WaitingForAvailableMachinesReason,
+				Message:  "Minimum availability requires 2 replicas, current 1 available",
+			},
+		},
+	}
+
+	testCases := []test.TestCase{
+		{
+			Name:                "getMachineDeploymentStatus should return an empty status for a MachineDeployment without status",
+			ExpectedSuccess:     NodeGroupStatus{},
+			ExpectedClientError: nil,
+			Request:             test.NewTestMachineDeployment("testcluster-nodes", "testcluster", "AWSMachineTemplate", "testcluster-nodes", "infrastructure.cluster.x-k8s.io/v1beta1"),
+		},
+		{
+			Name: "getMachineDeploymentStatus should return the failure reported in the conditions of a MachineDeployment",
+			ExpectedSuccess: NodeGroupStatus{
+				Phase:               "Running",
+				Replicas:            2,
+				ReadyReplicas:       1,
+				AvailableReplicas:   1,
+				UnavailableReplicas: 1,
+				Generation:          2,
+				ObservedGeneration:  2,
+				FailureReason:       "WaitingForAvailableMachines",
+				FailureMessage:      "Minimum availability requires 2 replicas, current 1 available",
+			},
+			ExpectedClientError: nil,
+			Request:             failedMachineDeployment,
+		},
+	}
+
+	for _, testCase := range testCases {
+		machineDeployment, ok := testCase.Request.(*clusterapiv1beta1.MachineDeployment)
+		if !ok {
+			log.Fatalf("Failed converting Request struct from test \"%s\" to *clusterapiv1beta1.MachineDeployment", testCase.Name)
+		}
+		expectedStatus, ok := testCase.ExpectedSuccess.(NodeGroupStatus)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to NodeGroupStatus", testCase.Name)
+		}
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			status := getMachineDeploymentStatus(machineDeployment)
+			assert.Assert(t, reflect.DeepEqual(expectedStatus, status))
+		})
+	}
+}
+
+func Test_NodeGroup_GetState(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name:                "GetState should return Ready when all the desired machines are ready",
+			ExpectedSuccess:     NodeGroupStateReady,
+			ExpectedClientError: nil,
+			Request: &NodeGroup{
+				Replicas: int32Ptr(3),
+				Status:   NodeGroupStatus{Phase: "Running", Replicas: 3, ReadyReplicas: 3, AvailableReplicas: 3},
+			},
+		},
+		{
+			Name:                "GetState should return Ready for a NodeGroup without desired replicas",
+			ExpectedSuccess:     NodeGroupStateReady,
+			ExpectedClientError: nil,
+			Request: &NodeGroup{
+				Status: NodeGroupStatus{Replicas: 2, ReadyReplicas: 2, AvailableReplicas: 2},
+			},
+		},
+		{
+			Name:                "GetState should return Scaling while machines are being created",
+			ExpectedSuccess:     NodeGroupStateScaling,
+			ExpectedClientError: nil,
+			Request: &NodeGroup{
+				Replicas: int32Ptr(3),
+				Status:   NodeGroupStatus{Phase: "ScalingUp", Replicas: 3, ReadyReplicas: 1},
+			},
+		},
+		{
+			Name:                "GetState should return Scaling while the status doesn't observe the last change",
+			ExpectedSuccess:     NodeGroupStateScaling,
+			ExpectedClientError: nil,
+			Request: &NodeGroup{
+				Replicas: int32Ptr(3),
+				Status:   NodeGroupStatus{Phase: "Running", Replicas: 3, ReadyReplicas: 3, Generation: 2, ObservedGeneration: 1},
+			},
+		},
+		{
+			Name:                "GetState should return Degraded when machines aren't ready",
+			ExpectedSuccess:     NodeGroupStateDegraded,
+			ExpectedClientError: nil,
+			Request: &NodeGroup{
+				Replicas: int32Ptr(3),
+				Status:   NodeGroupStatus{Phase: "Running", Replicas: 3, ReadyReplicas: 0, UnavailableReplicas: 3},
+			},
+		},
+		{
+			Name:                "GetState should return Failed when a failure is reported",
+			ExpectedSuccess:     NodeGroupStateFailed,
+			ExpectedClientError: nil,
+			Request: &NodeGroup{
+				Replicas: int32Ptr(3),
+				Status:   NodeGroupStatus{Phase: "Running", Replicas: 3, ReadyReplicas: 3, FailureReason: "UpdateError"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		nodeGroup, ok := testCase.Request.(*NodeGroup)
+		if !ok {
+			log.Fatalf("Failed converting Request struct from test \"%s\" to *NodeGroup", testCase.Name)
+		}
+		expectedState, ok := testCase.ExpectedSuccess.(string)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to string", testCase.Name)
+		}
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			assert.Equal(t, expectedState, nodeGroup.GetState())
+		})
+	}
+}
@@ -0,0 +1,324 @@
+package kaas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	clusterapikopsv1alpha1 "github.com/topfreegames/kubernetes-kops-operator/apis/infrastructure/v1alpha1"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	clusterapiexpv1beta1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
+	"sync"
+	"time"
+)
+
+// Event types, the same used by the Kubernetes watch API
+const (
+	EventAdded    = string(watch.Added)
+	EventModified = string(watch.Modified)
+	EventDeleted  = string(watch.Deleted)
+)
+
+// Event is a change of a cluster or of a node group, only one of Cluster and NodeGroup is set.
+// ResourceVersion is the version of the changed resource, used to watch again from this event.
+type Event struct {
+	Type            string
+	ResourceVersion string
+	Cluster         *Cluster
+	NodeGroup       *NodeGroup
+}
+
+// watchedResources are the cluster-API resources watched to stream the changes of clusters and node groups,
+// kops instance groups are watched since changes to them aren't reflected in their machinePools
+var watchedResources = []schema.GroupVersionResource{
+	k8s.ClusterResourceSchemaV1beta1,
+	k8s.MachinePoolSchemaV1beta1,
+	k8s.MachineDeploymentSchemaV1beta1,
+	k8s.KopsMachinePoolSchemaV1alpha1,
+}
+
+// watchRetryPeriod is how long a watch closed by the Kubernetes API waits before being established again
+var watchRetryPeriod = time.Second
+
+// Watch streams the changes of the clusters and their node groups, or of a single cluster when clusterName isn't empty,
+// after resourceVersion or starting with the current resources when it is empty.
+// The watches closed by the Kubernetes API are established again from the last resourceVersion received,
+// the channel is only closed when the context is done or when a watch can't be established again, so the caller should watch again.
+// Invalid clusters and node groups are skipped like in the list functions.
+func Watch(ctx context.Context, k *k8s.Kubernetes, clusterName string, resourceVersion string) (<-chan Event, error) {
+	namespace := ""
+	if clusterName != "" {
+		var err error
+		namespace, err = k.ClusterNamespace(clusterName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var watchers []watch.Interface
+	for _, resource := range watchedResources {
+		watcher, err := k.WatchResources(ctx, resource, namespace, resourceVersion)
+		if err != nil {
+			cancel()
+			for _, started := range watchers {
+				started.Stop()
+			}
+			return nil, clientError.NewClientError(err, clientError.UnexpectedError, fmt.Sprintf("Could not watch the %s", resource.Resource))
+		}
+		watchers = append(watchers, watcher)
+	}
+
+	events := make(chan Event)
+	var wg sync.WaitGroup
+	for i, watcher := range watchers {
+		wg.Add(1)
+		go func(resource schema.GroupVersionResource, watcher watch.Interface) {
+			defer wg.Done()
+			defer cancel()
+			watchResource(ctx, k, resource, namespace, clusterName, watcher, resourceVersion, events)
+		}(watchedResources[i], watcher)
+	}
+
+	go func() {
+		wg.Wait()
+		cancel()
+		close(events)
+	}()
+
+	return events, nil
+}
+
+// watchResource sends the events of a watched resource until the context is done, establishing the watch again
+// from the last resourceVersion received whenever the Kubernetes API closes it
+func watchResource(ctx context.Context, k *k8s.Kubernetes, resource schema.GroupVersionResource, namespace string, clusterName string, watcher watch.Interface, resourceVersion string, events chan<- Event) {
+	for {
+		var closed bool
+		resourceVersion, closed = sendWatchEvents(ctx, k, watcher, clusterName, resourceVersion, events)
+		watcher.Stop()
+		if !closed {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(watchRetryPeriod):
+		}
+
+		var err error
+		watcher, err = k.WatchResources(ctx, resource, namespace, resourceVersion)
+		if err != nil {
+			k.Log().Warn("Could not watch again", zap.String("resource", resource.Resource), zap.String("resourceVersion", resourceVersion), zap.Error(err))
+			return
+		}
+	}
+}
+
+// sendWatchEvents sends the events of a watch and returns the last resourceVersion received,
+// and true when the Kubernetes API closed the watch so it can be established again from this resourceVersion
+func sendWatchEvents(ctx context.Context, k *k8s.Kubernetes, watcher watch.Interface, clusterName string, resourceVersion string, events chan<- Event) (string, bool) {
+	for {
+		select {
+		case <-ctx.Done():
+			return resourceVersion, false
+		case watchEvent, ok := <-watcher.ResultChan():
+			if !ok {
+				return resourceVersion, true
+			}
+			if watchEvent.Type == watch.Error {
+				// An expired resourceVersion can't be watched again, the caller must read the resources again
+				err := errors.FromObject(watchEvent.Object)
+				k.Log().Warn("Watch ended with an error", zap.String("resourceVersion", resourceVersion), zap.Error(err))
+				return resourceVersion, !errors.IsResourceExpired(err) && !errors.IsGone(err)
+			}
+			if object, ok := watchEvent.Object.(*unstructured.Unstructured); ok && object.GetResourceVersion() != "" {
+				resourceVersion = object.GetResourceVersion()
+			}
+
+			event, ok := newEvent(k, watchEvent)
+			if !ok || !event.isOfCluster(clusterName) {
+				continue
+			}
+			select {
+			case events <- *event:
+			case <-ctx.Done():
+				return resourceVersion, false
+			}
+		}
+	}
+}
+
+// isOfCluster returns true if the event is a change of the cluster or of one of its node groups, or if clusterName is empty.
+// A namespace may be shared by many clusters, so the events of a single cluster watch must be filtered.
+func (e *Event) isOfCluster(clusterName string) bool {
+	if clusterName == "" {
+		return true
+	}
+	if e.Cluster != nil {
+		return e.Cluster.Name == clusterName
+	}
+	return e.NodeGroup.Cluster == clusterName
+}
+
+// newEvent translates a Kubernetes watch event into a cluster or node group event, returning false for events that should be skipped
+func newEvent(k *k8s.Kubernetes, watchEvent watch.Event) (*Event, bool) {
+	if watchEvent.Type != watch.Added && watchEvent.Type != watch.Modified && watchEvent.Type != watch.Deleted {
+		return nil, false
+	}
+
+	object, ok := watchEvent.Object.(*unstructured.Unstructured)
+	if !ok {
+		return nil, false
+	}
+
+	event := &Event{Type: string(watchEvent.Type), ResourceVersion: object.GetResourceVersion()}
+	var err error
+	switch object.GetKind() {
+	case "Cluster":
+		event.Cluster, err = newEventCluster(k, object)
+	case "MachinePool":
+		event.NodeGroup, err = newEventMachinePoolNodeGroup(k, object)
+	case "MachineDeployment":
+		event.NodeGroup, err = newEventMachineDeploymentNodeGroup(k, object)
+	case "KopsMachinePool":
+		// kops instance groups are created and deleted with their machinePools, which already report these changes
+		if watchEvent.Type != watch.Modified {
+			return nil, false
+		}
+		event.NodeGroup, err = newEventKopsMachinePoolNodeGroup(k, object)
+		if err == nil && event.NodeGroup == nil {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+	if err != nil {
+		k.Log().Warn("Skipping watch event", zap.String("type", string(event.Type)), zap.String("kind", object.GetKind()), zap.String("name", object.GetName()), zap.Error(err))
+		return nil, false
+	}
+
+	return event, true
+}
+
+// newEventCluster reads the cluster of a watch event
+func newEventCluster(k *k8s.Kubernetes, object *unstructured.Unstructured) (*Cluster, error) {
+	var clusterAPICR clusterapiv1beta1.Cluster
+	err := fromUnstructured(object, &clusterAPICR)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ValidateClusterComponents(&clusterAPICR)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := &Cluster{}
+	err = cluster.GetClusterProperties(&clusterAPICR, GetMetadataMapping(k))
+	if err != nil {
+		return nil, err
+	}
+	cluster.readControlPlaneVersion(k, &clusterAPICR)
+	return cluster, nil
+}
+
+// newEventMachinePoolNodeGroup reads the node group of a MachinePool watch event
+func newEventMachinePoolNodeGroup(k *k8s.Kubernetes, object *unstructured.Unstructured) (*NodeGroup, error) {
+	var machinePool clusterapiexpv1beta1.MachinePool
+	err := fromUnstructured(object, &machinePool)
+	if err != nil {
+		return nil, err
+	}
+
+	err = k8s.ValidateMachineTemplateComponents(machinePool.Spec.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeGroup := newNodeGroupFromMachinePool(&machinePool)
+	nodeGroup.Infrastructure = getEventNodeInfrastructure(k, nodeGroup)
+	return nodeGroup, nil
+}
+
+// newEventMachineDeploymentNodeGroup reads the node group of a MachineDeployment watch event
+func newEventMachineDeploymentNodeGroup(k *k8s.Kubernetes, object *unstructured.Unstructured) (*NodeGroup, error) {
+	var machineDeployment clusterapiv1beta1.MachineDeployment
+	err := fromUnstructured(object, &machineDeployment)
+	if err != nil {
+		return nil, err
+	}
+
+	err = k8s.ValidateMachineTemplateComponents(machineDeployment.Spec.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeGroup := newNodeGroupFromMachineDeployment(&machineDeployment)
+	nodeGroup.Infrastructure = getEventNodeInfrastructure(k, nodeGroup)
+	return nodeGroup, nil
+}
+
+// newEventKopsMachinePoolNodeGroup reads the node group of a KopsMachinePool watch event from the machinePool using it,
+// returning nil when no machinePool uses it
+func newEventKopsMachinePoolNodeGroup(k *k8s.Kubernetes, object *unstructured.Unstructured) (*NodeGroup, error) {
+	var kopsMachinePool clusterapikopsv1alpha1.KopsMachinePool
+	err := fromUnstructured(object, &kopsMachinePool)
+	if err != nil {
+		return nil, err
+	}
+
+	machinePools, err := k.ListResources(k8s.MachinePoolSchemaV1beta1, kopsMachinePool.Namespace, k8s.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range machinePools.Items {
+		var machinePool clusterapiexpv1beta1.MachinePool
+		err = fromUnstructured(&item, &machinePool)
+		if err != nil {
+			return nil, err
+		}
+
+		infrastructureRef := machinePool.Spec.Template.Spec.InfrastructureRef
+		if infrastructureRef.Kind != "KopsMachinePool" || infrastructureRef.Name != kopsMachinePool.Name {
+			continue
+		}
+
+		nodeGroup := newNodeGroupFromMachinePool(&machinePool)
+		nodeGroup.Infrastructure = newKopsNodeInfrastructure(&kopsMachinePool)
+		return nodeGroup, nil
+	}
+	return nil, nil
+}
+
+// getEventNodeInfrastructure returns the infrastructure of an event node group, or an empty one when it can't be read,
+// which is expected when the node group was deleted together with its infrastructure
+func getEventNodeInfrastructure(k *k8s.Kubernetes, nodeGroup *NodeGroup) *NodeInfrastructure {
+	infrastructure, err := nodeGroup.getNodeInfrastructure(k)
+	if err != nil {
+		k.Log().Warn("Could not get the infrastructure of NodeGroup", zap.String("nodeGroup", nodeGroup.Name), zap.String("cluster", nodeGroup.Cluster), zap.Error(err))
+		return &NodeInfrastructure{}
+	}
+	return infrastructure
+}
+
+// fromUnstructured converts a resource received from the dynamic client into its cluster-API struct
+func fromUnstructured(object *unstructured.Unstructured, resource interface{}) error {
+	rawJson, err := object.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("could not Marshal %s: %v", object.GetKind(), err)
+	}
+
+	err = json.Unmarshal(rawJson, resource)
+	if err != nil {
+		return fmt.Errorf("could not Unmarshal %s JSON into clusterAPI: %v", object.GetKind(), err)
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package kaas
+
+import (
+	"context"
+	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/test"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"log"
+	"sort"
+	"testing"
+	"time"
+)
+
+// watchTestRequest is the request of the watch tests, the resources are created, updated and deleted in order after the watch has started
+type watchTestRequest struct {
+	Cluster string
+	Created []runtime.Object
+	Updated []runtime.Object
+	Deleted []runtime.Object
+}
+
+// expectedWatchEvent is the type, kind and name of an event expected by the watch tests
+type expectedWatchEvent struct {
+	Type string
+	Kind string
+	Name string
+}
+
+func Test_Watch_Success(t *testing.T) {
+	testCases := []test.TestCase{
+		{
+			Name: "Watch should return Success streaming the changes of clusters and node groups",
+			ExpectedSuccess: []expectedWatchEvent{
+				{Type: EventAdded, Kind: "Cluster", Name: "testcluster"},
+				{Type: EventAdded, Kind: "MachinePool", Name: "ng1"},
+				{Type: EventDeleted, Kind: "MachineDeployment", Name: "ng2"},
+			},
+			Request: &watchTestRequest{
+				Created: []runtime.Object{
+					test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+					test.NewTestMachinePool("testcluster-ng1", "testcluster", "KopsMachinePool", "testcluster-ng1", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				},
+				Deleted: []runtime.Object{
+					test.NewTestMachineDeployment("othercluster-ng2", "othercluster", "DockerMachineTemplate", "othercluster-ng2", "infrastructure.cluster.x-k8s.io/v1beta1"),
+				},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestKopsMachinePool("testcluster-ng1", "testcluster"),
+				test.NewTestMachineDeployment("othercluster-ng2", "othercluster", "DockerMachineTemplate", "othercluster-ng2", "infrastructure.cluster.x-k8s.io/v1beta1"),
+			},
+		},
+		{
+			Name: "Watch should return Success streaming only the changes of one cluster",
+			ExpectedSuccess: []expectedWatchEvent{
+				{Type: EventAdded, Kind: "MachinePool", Name: "ng1"},
+			},
+			Request: &watchTestRequest{
+				Cluster: "testcluster",
+				Created: []runtime.Object{
+					test.NewTestCluster("othercluster", "othercluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1"),
+					test.NewTestMachinePool("testcluster-ng1", "testcluster", "KopsMachinePool", "testcluster-ng1", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestKopsMachinePool("testcluster-ng1", "testcluster"),
+			},
+		},
+		{
+			Name: "Watch should return Success streaming the changes of kops instance groups as changes of their node groups",
+			ExpectedSuccess: []expectedWatchEvent{
+				{Type: EventModified, Kind: "MachinePool", Name: "ng1"},
+			},
+			Request: &watchTestRequest{
+				Cluster: "testcluster",
+				Updated: []runtime.Object{
+					newSizedTestKopsMachinePool("testcluster-ng1", "testcluster", 1, 3),
+				},
+			},
+			K8sTestResources: []runtime.Object{
+				test.NewTestMachinePool("testcluster-ng1", "testcluster", "KopsMachinePool", "testcluster-ng1", "infrastructure.cluster.x-k8s.io/v1alpha1"),
+				test.NewTestKopsMachinePool("testcluster-ng1", "testcluster"),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		request, ok := testCase.Request.(*watchTestRequest)
+		if !ok {
+			log.Fatalf("Failed converting Request struct from test \"%s\" to *watchTestRequest", testCase.Name)
+		}
+		expectedEvents, ok := testCase.ExpectedSuccess.([]expectedWatchEvent)
+		if !ok {
+			log.Fatalf("Failed converting Success struct from test \"%s\" to []expectedWatchEvent", testCase.Name)
+		}
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			client := test.NewK8sFakeDynamicClientWithResources(testCase.K8sTestResources...)
+			k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{DynamicClient: client}}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			events, err := Watch(ctx, k, request.Cluster, "")
+			assert.NilError(t, err)
+
+			applyWatchTestChanges(t, client, request)
+
+			// The resources are watched concurrently so the events of different resources may arrive in any order
+			var receivedEvents []expectedWatchEvent
+			for range expectedEvents {
+				event, ok := <-events
+				assert.Assert(t, ok, "the watch ended before all events were received")
+				if event.Cluster != nil {
+					receivedEvents = append(receivedEvents, expectedWatchEvent{Type: event.Type, Kind: "Cluster", Name: event.Cluster.Name})
+				} else {
+					assert.Assert(t, event.NodeGroup.Infrastructure != nil)
+					receivedEvents = append(receivedEvents, expectedWatchEvent{Type: event.Type, Kind: event.NodeGroup.Kind, Name: event.NodeGroup.Name})
+				}
+			}
+			assert.DeepEqual(t, sortWatchTestEvents(expectedEvents), sortWatchTestEvents(receivedEvents))
+
+			cancel()
+			for range events {
+			}
+		})
+	}
+}
+
+// applyWatchTestChanges creates and deletes the resources of a watch test request in the fake Kubernetes API
+func applyWatchTestChanges(t *testing.T, client *fake.FakeDynamicClient, request *watchTestRequest) {
+	for _, resource := range request.Created {
+		object, err := k8s.ToUnstructured(resource)
+		assert.NilError(t, err)
+		assert.NilError(t, client.Tracker().Add(object))
+	}
+	for _, resource := range request.Updated {
+		object, err := k8s.ToUnstructured(resource)
+		assert.NilError(t, err)
+		assert.NilError(t, client.Tracker().Update(watchTestResources[object.GetKind()], object, object.GetNamespace()))
+	}
+	for _, resource := range request.Deleted {
+		object, err := k8s.ToUnstructured(resource)
+		assert.NilError(t, err)
+		assert.NilError(t, client.Tracker().Delete(watchTestResources[object.GetKind()], object.GetNamespace(), object.GetName()))
+	}
+}
+
+// watchTestResources are the resources of the kinds changed by the watch tests
+var watchTestResources = map[string]schema.GroupVersionResource{
+	"MachinePool":       k8s.MachinePoolSchemaV1beta1,
+	"MachineDeployment": k8s.MachineDeploymentSchemaV1beta1,
+	"KopsMachinePool":   k8s.KopsMachinePoolSchemaV1alpha1,
+}
+
+func Test_Watch_SuccessWatchAgain(t *testing.T) {
+	defaultRetryPeriod := watchRetryPeriod
+	watchRetryPeriod = time.Millisecond
+	defer func() { watchRetryPeriod = defaultRetryPeriod }()
+
+	// The first cluster watch is closed by the API after one event and must be established again from its resourceVersion
+	watchers := []*watch.FakeWatcher{watch.NewFake(), watch.NewFake()}
+	var resourceVersions []string
+	client := test.NewK8sFakeDynamicClient()
+	client.PrependWatchReactor("clusters", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		resourceVersions = append(resourceVersions, action.(k8stesting.WatchActionImpl).WatchRestrictions.ResourceVersion)
+		return true, watchers[len(resourceVersions)-1], nil
+	})
+	k := &k8s.Kubernetes{K8sAuth: &k8s.Auth{DynamicClient: client}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, err := Watch(ctx, k, "", "")
+	assert.NilError(t, err)
+
+	newCluster := func(resourceVersion string) runtime.Object {
+		cluster := test.NewTestCluster("testcluster", "testcluster-kops-cp", "KopsControlPlane", "controlplane.cluster.x-k8s.io/v1alpha1", "kops-cluster", "KopsAWSCluster", "controlplane.cluster.x-k8s.io/v1alpha1")
+		cluster.ResourceVersion = resourceVersion
+		object, err := k8s.ToUnstructured(cluster)
+		assert.NilError(t, err)
+		return object
+	}
+
+	go func() {
+		watchers[0].Add(newCluster("10"))
+		watchers[0].Stop()
+	}()
+	event := <-events
+	assert.Equal(t, EventAdded, event.Type)
+	assert.Equal(t, "10", event.ResourceVersion)
+
+	go watchers[1].Modify(newCluster("11"))
+	event = <-events
+	assert.Equal(t, EventModified, event.Type)
+	assert.Equal(t, "11", event.ResourceVersion)
+	assert.DeepEqual(t, []string{"", "10"}, resourceVersions)
+
+	cancel()
+	for range events {
+	}
+}
+
+// sortWatchTestEvents sorts the events of a watch test by kind and name
+func sortWatchTestEvents(events []expectedWatchEvent) []expectedWatchEvent {
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Kind != events[j].Kind {
+			return events[i].Kind < events[j].Kind
+		}
+		return events[i].Name < events[j].Name
+	})
+	return events
+}
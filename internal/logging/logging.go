@@ -0,0 +1,169 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Environment variables configuring the logs, info level in JSON by default
+const (
+	LevelEnv  = "LOG_LEVEL"
+	FormatEnv = "LOG_FORMAT"
+)
+
+// Log formats
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+const DefaultLevel = zapcore.InfoLevel
+
+const DefaultFormat = FormatJSON
+
+// RequestIDHeader is the header with the ID of a request, it is accepted from the caller and returned in the response
+const RequestIDHeader = "X-Request-ID"
+
+// Fields correlating the log lines of a request
+const (
+	RequestIDField = "requestID"
+	TraceIDField   = "traceID"
+)
+
+// maxRequestIDLength limits the request IDs accepted from the callers, longer IDs are replaced by a generated one
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// NewLoggerFromEnv creates the logger configured in the environment
+func NewLoggerFromEnv() (*zap.Logger, error) {
+	level := DefaultLevel
+	if rawLevel := os.Getenv(LevelEnv); rawLevel != "" {
+		if err := level.UnmarshalText([]byte(rawLevel)); err != nil {
+			return nil, fmt.Errorf("invalid %s %s, must be debug, info, warn or error", LevelEnv, rawLevel)
+		}
+	}
+
+	format := os.Getenv(FormatEnv)
+	if format == "" {
+		format = DefaultFormat
+	}
+	if format != FormatJSON && format != FormatConsole {
+		return nil, fmt.Errorf("invalid %s %s, must be %s or %s", FormatEnv, format, FormatJSON, FormatConsole)
+	}
+
+	config := zap.NewProductionConfig()
+	config.Level = zap.NewAtomicLevelAt(level)
+	config.Encoding = format
+	config.EncoderConfig.TimeKey = "time"
+	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	config.Sampling = nil
+	return config.Build()
+}
+
+// Middleware returns a middleware identifying each request by the ID in its X-Request-ID header, or a new one when it
+// has none, returning it in the response header. Each request is logged when it ends, replacing the access log of gin.
+func Middleware(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(RequestIDHeader)
+		if !isValidRequestID(requestID) {
+			requestID = string(uuid.NewUUID())
+		}
+		c.Header(RequestIDHeader, requestID)
+		c.Set(clientError.RequestIDContextKey, requestID)
+		c.Request = c.Request.WithContext(WithRequestID(c.Request.Context(), requestID))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		fields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+			zap.String("route", c.FullPath()),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("clientIP", c.ClientIP()),
+		}
+		if len(c.Errors) > 0 {
+			fields = append(fields, zap.String("errors", c.Errors.String()))
+		}
+
+		requestLogger := ForRequest(logger, c.Request.Context())
+		switch {
+		case status >= 500:
+			requestLogger.Error("Request failed", fields...)
+		case status >= 400:
+			requestLogger.Warn("Request refused", fields...)
+		default:
+			requestLogger.Info("Request served", fields...)
+		}
+	}
+}
+
+// Recovery returns a middleware recovering the panics of the handlers, logging them with their stack and answering
+// an Internal Server Error, replacing the recovery of gin. It is registered after the logging middleware, so the
+// panics are logged with the ID of their request and the request log has the status of the response.
+func Recovery(logger *zap.Logger) gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered interface{}) {
+		ForRequest(logger, c.Request.Context()).Error("Request panicked", zap.Any("panic", recovered), zap.Stack("stack"))
+		clientError.ErrorHandler(c, fmt.Errorf("panic: %v", recovered), "Internal Server Error", http.StatusInternalServerError)
+		c.Abort()
+	})
+}
+
+// ForRequest returns the logger with the request ID and the trace ID of the request of the context, if any
+func ForRequest(logger *zap.Logger, ctx context.Context) *zap.Logger {
+	if ctx == nil {
+		return logger
+	}
+
+	var fields []zap.Field
+	if requestID := RequestID(ctx); requestID != "" {
+		fields = append(fields, zap.String(RequestIDField, requestID))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		fields = append(fields, zap.String(TraceIDField, spanContext.TraceID().String()))
+	}
+	return logger.With(fields...)
+}
+
+// FromContext returns the global logger with the request ID and the trace ID of the request of the context, if any,
+// used by the components without an injected logger
+func FromContext(ctx context.Context) *zap.Logger {
+	return ForRequest(zap.L(), ctx)
+}
+
+// WithRequestID returns a copy of the context with the ID of a request, for the work of a request that continues after it ends
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the ID of the request of the context, empty outside of requests
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// isValidRequestID accepts the printable ASCII IDs, so the IDs of the callers can't forge log lines or response headers
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, char := range requestID {
+		if char < ' ' || char > '~' {
+			return false
+		}
+	}
+	return true
+}
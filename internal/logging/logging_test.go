@@ -0,0 +1,191 @@
+package logging
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	apiError "github.com/topfreegames/kaas-management-api/api/error"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestMiddleware(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	var handlerRequestID string
+	router := gin.New()
+	router.Use(Middleware(zap.New(core)))
+	router.Handle(http.MethodGet, "/v1/clusters/:clusterName/", func(c *gin.Context) {
+		handlerRequestID = RequestID(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+	router.Handle(http.MethodGet, "/v1/failing/", func(c *gin.Context) {
+		handlerRequestID = RequestID(c.Request.Context())
+		c.Status(http.StatusInternalServerError)
+	})
+
+	testCases := []struct {
+		name              string
+		path              string
+		requestID         string
+		expectedRequestID string
+		expectedLevel     zapcore.Level
+		expectedStatus    int
+	}{
+		{
+			name:              "Request ID of the caller is kept",
+			path:              "/v1/clusters/test-cluster/",
+			requestID:         "test-request-id",
+			expectedRequestID: "test-request-id",
+			expectedLevel:     zapcore.InfoLevel,
+			expectedStatus:    http.StatusOK,
+		},
+		{
+			name:           "Request without ID gets a generated one",
+			path:           "/v1/clusters/test-cluster/",
+			expectedLevel:  zapcore.InfoLevel,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Request ID too long is replaced",
+			path:           "/v1/clusters/test-cluster/",
+			requestID:      strings.Repeat("a", maxRequestIDLength+1),
+			expectedLevel:  zapcore.InfoLevel,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Request ID with non printable characters is replaced",
+			path:           "/v1/clusters/test-cluster/",
+			requestID:      "forged\tid",
+			expectedLevel:  zapcore.InfoLevel,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Server errors are logged as errors",
+			path:           "/v1/failing/",
+			expectedLevel:  zapcore.ErrorLevel,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "Client errors are logged as warnings",
+			path:           "/unknown",
+			expectedLevel:  zapcore.WarnLevel,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			logs.TakeAll()
+			handlerRequestID = ""
+			request := httptest.NewRequest(http.MethodGet, testCase.path, nil)
+			if testCase.requestID != "" {
+				request.Header.Set(RequestIDHeader, testCase.requestID)
+			}
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, request)
+
+			requestID := response.Header().Get(RequestIDHeader)
+			assert.NotEmpty(t, requestID)
+			if testCase.expectedRequestID != "" {
+				assert.Equal(t, testCase.expectedRequestID, requestID)
+			} else {
+				assert.NotEqual(t, testCase.requestID, requestID)
+			}
+			if handlerRequestID != "" {
+				assert.Equal(t, requestID, handlerRequestID)
+			}
+
+			entries := logs.TakeAll()
+			assert.Len(t, entries, 1)
+			if len(entries) == 0 {
+				return
+			}
+			fields := entries[0].ContextMap()
+			assert.Equal(t, testCase.expectedLevel, entries[0].Level)
+			assert.Equal(t, requestID, fields[RequestIDField])
+			assert.Equal(t, int64(testCase.expectedStatus), fields["status"])
+			assert.Equal(t, testCase.path, fields["path"])
+		})
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core)
+
+	router := gin.New()
+	router.Use(Middleware(logger))
+	router.Use(Recovery(logger))
+	router.Handle(http.MethodGet, "/v1/panicking/", func(c *gin.Context) {
+		panic("test panic")
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/v1/panicking/", nil)
+	request.Header.Set(RequestIDHeader, "test-request-id")
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+	assert.Equal(t, "test-request-id", response.Header().Get(RequestIDHeader))
+	var errorResponse apiError.ClientErrorResponse
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &errorResponse))
+	assert.Equal(t, "Internal Server Error", errorResponse.ErrorMessage)
+	assert.Equal(t, http.StatusInternalServerError, errorResponse.HttpCode)
+	assert.Equal(t, "test-request-id", errorResponse.RequestID)
+
+	entries := logs.TakeAll()
+	assert.Len(t, entries, 2)
+	if len(entries) != 2 {
+		return
+	}
+	panicFields := entries[0].ContextMap()
+	assert.Equal(t, zapcore.ErrorLevel, entries[0].Level)
+	assert.Equal(t, "test panic", panicFields["panic"])
+	assert.Equal(t, "test-request-id", panicFields[RequestIDField])
+	assert.NotEmpty(t, panicFields["stack"])
+	requestFields := entries[1].ContextMap()
+	assert.Equal(t, zapcore.ErrorLevel, entries[1].Level)
+	assert.Equal(t, int64(http.StatusInternalServerError), requestFields["status"])
+}
+
+func TestNewLoggerFromEnv(t *testing.T) {
+	testCases := []struct {
+		name          string
+		level         string
+		format        string
+		expectedLevel zapcore.Level
+		expectedError bool
+	}{
+		{name: "Info level in JSON by default", expectedLevel: zapcore.InfoLevel},
+		{name: "Debug level", level: "debug", expectedLevel: zapcore.DebugLevel},
+		{name: "Error level in the console format", level: "error", format: FormatConsole, expectedLevel: zapcore.ErrorLevel},
+		{name: "Invalid level", level: "verbose", expectedError: true},
+		{name: "Invalid format", format: "xml", expectedError: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			os.Setenv(LevelEnv, testCase.level)
+			os.Setenv(FormatEnv, testCase.format)
+			defer os.Unsetenv(LevelEnv)
+			defer os.Unsetenv(FormatEnv)
+
+			logger, err := NewLoggerFromEnv()
+			if testCase.expectedError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.True(t, logger.Core().Enabled(testCase.expectedLevel))
+			assert.False(t, logger.Core().Enabled(testCase.expectedLevel-1))
+		})
+	}
+}
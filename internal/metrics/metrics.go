@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"strconv"
+	"time"
+)
+
+// Path is the path where the metrics are exposed in the Prometheus format
+const Path = "/metrics"
+
+const namespace = "kaas_management_api"
+
+// UnmatchedRoute is the route label of the requests that don't match any route, so unknown paths don't create new series
+const UnmatchedRoute = "unmatched"
+
+// Registry has the metrics of the API and of the Go runtime and process
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests by route template, method and status code",
+	}, []string{"route", "method", "code"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of the HTTP requests by route template, method and status code",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "code"})
+
+	kubernetesRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "kubernetes_request_duration_seconds",
+		Help:      "Latency of the Kubernetes API calls by group, version, resource and verb",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"group", "version", "resource", "verb"})
+
+	kubernetesRequestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kubernetes_request_errors_total",
+		Help:      "Number of failed Kubernetes API calls by group, version, resource, verb and the reason of the status error",
+	}, []string{"group", "version", "resource", "verb", "reason"})
+
+	clientErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "client_errors_total",
+		Help:      "Number of error responses by client error type",
+	}, []string{"type"})
+)
+
+func init() {
+	Registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		httpRequests,
+		httpRequestDuration,
+		kubernetesRequestDuration,
+		kubernetesRequestErrors,
+		clientErrors,
+	)
+}
+
+// Handler serves the metrics of the registry
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+}
+
+// Middleware returns a middleware counting the requests and observing their latency by the route templates of the router,
+// the error responses are also counted by the type of their client error
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = UnmatchedRoute
+		}
+		code := strconv.Itoa(c.Writer.Status())
+		httpRequests.WithLabelValues(route, c.Request.Method, code).Inc()
+		httpRequestDuration.WithLabelValues(route, c.Request.Method, code).Observe(time.Since(start).Seconds())
+		if errorType := c.GetString(clientError.ErrorTypeContextKey); errorType != "" {
+			clientErrors.WithLabelValues(errorType).Inc()
+		}
+	}
+}
+
+// ObserveKubernetesRequest records the latency of a Kubernetes API call on a resource, and the reason of its error when it failed
+func ObserveKubernetesRequest(resource schema.GroupVersionResource, verb string, duration time.Duration, errorReason string) {
+	kubernetesRequestDuration.WithLabelValues(resource.Group, resource.Version, resource.Resource, verb).Observe(duration.Seconds())
+	if errorReason != "" {
+		kubernetesRequestErrors.WithLabelValues(resource.Group, resource.Version, resource.Resource, verb, errorReason).Inc()
+	}
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/topfreegames/kaas-management-api/util/clientError"
+)
+
+func TestMiddleware(t *testing.T) {
+	router := gin.New()
+	router.Use(Middleware())
+	router.Handle(http.MethodGet, "/v1/clusters/:clusterName/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.Handle(http.MethodGet, Path, Handler())
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+	for _, path := range []string{"/v1/clusters/a/", "/v1/clusters/b/", "/unknown"} {
+		get(path)
+	}
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(httpRequests.WithLabelValues("/v1/clusters/:clusterName/", http.MethodGet, "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(httpRequests.WithLabelValues(UnmatchedRoute, http.MethodGet, "404")))
+
+	w := get(Path)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `kaas_management_api_http_request_duration_seconds_count{code="200",method="GET",route="/v1/clusters/:clusterName/"} 2`)
+	assert.Contains(t, w.Body.String(), "go_goroutines")
+}
+
+func TestMiddleware_ClientErrors(t *testing.T) {
+	router := gin.New()
+	router.Use(Middleware())
+	router.Handle(http.MethodGet, "/v1/clusters/:clusterName/", func(c *gin.Context) {
+		// Only the error of the response is counted, not the ones created along the way
+		_ = clientError.NewClientError(nil, clientError.InvalidRequest, "The continue token is invalid")
+		err := clientError.NewClientError(nil, clientError.ResourceNotFound, "The cluster was not found")
+		clientError.ErrorHandler(c, err, "Cluster not found", http.StatusNotFound)
+	})
+	router.Handle(http.MethodGet, "/v1/clusters/", func(c *gin.Context) {
+		err := clientError.NewClientError(nil, clientError.InvalidRequest, "The limit is invalid")
+		clientError.ErrorHandler(c, err, "Invalid limit", http.StatusBadRequest)
+	})
+	router.Handle(http.MethodGet, "/healthcheck", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, path := range []string{"/v1/clusters/a/", "/v1/clusters/b/", "/v1/clusters/", "/healthcheck"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	expected := `
+# HELP kaas_management_api_client_errors_total Number of error responses by client error type
+# TYPE kaas_management_api_client_errors_total counter
+kaas_management_api_client_errors_total{type="INVALID_REQUEST"} 1
+kaas_management_api_client_errors_total{type="RESOURCE_NOT_FOUND"} 2
+`
+	assert.Nil(t, testutil.GatherAndCompare(Registry, strings.NewReader(expected), "kaas_management_api_client_errors_total"))
+}
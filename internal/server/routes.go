@@ -4,10 +4,12 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	auditv1 "github.com/topfreegames/kaas-management-api/api/audit/v1"
 	clusterv1 "github.com/topfreegames/kaas-management-api/api/cluster/v1"
 	"github.com/topfreegames/kaas-management-api/api/healthCheck"
 	nodegroupv1 "github.com/topfreegames/kaas-management-api/api/nodeGroup/v1"
 	"github.com/topfreegames/kaas-management-api/internal/controller"
+	"github.com/topfreegames/kaas-management-api/internal/metrics"
 	"net/http"
 )
 
@@ -28,19 +30,39 @@ func param(param string) string {
 
 func (r RouterConfig) setupRoutes() {
 	r.setupClusterV1Routes()
+	r.setupAuditV1Routes()
 	r.setupHealthCheckRoutes()
+	r.setupMetricsRoutes()
 	r.setupDocsRoutes()
 }
 
 func (r RouterConfig) setupClusterV1Routes() {
 	r.router.Handle(http.MethodGet, clusterv1.Endpoint.Path, r.controller.ClusterListHandler)
+	r.router.Handle(http.MethodPost, clusterv1.Endpoint.Path, r.controller.ClusterCreateHandler)
+	r.router.Handle(http.MethodGet, clusterv1.Endpoint.Path+path(clusterv1.WatchEndpointName), r.controller.ClusterWatchHandler)
 	r.router.Handle(http.MethodGet, clusterv1.Endpoint.Path+param(clusterv1.ClusterNameParameter), r.controller.ClusterHandler)
+	r.router.Handle(http.MethodDelete, clusterv1.Endpoint.Path+param(clusterv1.ClusterNameParameter), r.controller.ClusterDeleteHandler)
+	r.router.Handle(http.MethodPost, clusterv1.Endpoint.Path+param(clusterv1.ClusterNameParameter)+path(clusterv1.UpgradeEndpointName), r.controller.ClusterUpgradeHandler)
+	r.router.Handle(http.MethodGet, clusterv1.Endpoint.Path+param(clusterv1.ClusterNameParameter)+path(clusterv1.KubeconfigEndpointName), r.controller.ClusterKubeconfigHandler)
 	r.router.Handle(http.MethodGet, clusterv1.Endpoint.Path+param(clusterv1.ClusterNameParameter)+path(nodegroupv1.Endpoint.EndpointName), r.controller.NodeGroupListByClusterHandler)
+	r.router.Handle(http.MethodPost, clusterv1.Endpoint.Path+param(clusterv1.ClusterNameParameter)+path(nodegroupv1.Endpoint.EndpointName), r.controller.NodeGroupCreateHandler)
 	r.router.Handle(http.MethodGet, clusterv1.Endpoint.Path+param(clusterv1.ClusterNameParameter)+path(nodegroupv1.Endpoint.EndpointName)+param(nodegroupv1.NodeGroupNameParameter), r.controller.NodeGroupByClusterHandler)
+	r.router.Handle(http.MethodPatch, clusterv1.Endpoint.Path+param(clusterv1.ClusterNameParameter)+path(nodegroupv1.Endpoint.EndpointName)+param(nodegroupv1.NodeGroupNameParameter), r.controller.NodeGroupUpdateHandler)
+	r.router.Handle(http.MethodDelete, clusterv1.Endpoint.Path+param(clusterv1.ClusterNameParameter)+path(nodegroupv1.Endpoint.EndpointName)+param(nodegroupv1.NodeGroupNameParameter), r.controller.NodeGroupDeleteHandler)
+	r.router.Handle(http.MethodGet, clusterv1.Endpoint.Path+param(clusterv1.ClusterNameParameter)+path(nodegroupv1.Endpoint.EndpointName)+param(nodegroupv1.NodeGroupNameParameter)+path(nodegroupv1.MachinesEndpointName), r.controller.NodeGroupMachinesHandler)
+}
+
+func (r RouterConfig) setupAuditV1Routes() {
+	r.router.Handle(http.MethodGet, auditv1.Endpoint.Path, r.controller.AuditHandler)
 }
 
 func (r RouterConfig) setupHealthCheckRoutes() {
 	r.router.Handle(http.MethodGet, healthCheck.Endpoint.Path, controller.HealthCheckHandler)
+	r.router.Handle(http.MethodGet, healthCheck.ReadinessEndpoint.Path, r.controller.ReadinessHandler)
+}
+
+func (r RouterConfig) setupMetricsRoutes() {
+	r.router.Handle(http.MethodGet, metrics.Path, metrics.Handler())
 }
 
 func (r RouterConfig) setupDocsRoutes() {
@@ -1,16 +1,35 @@
 package server
 
 import (
+	"context"
 	"github.com/gin-gonic/gin"
+	"github.com/topfreegames/kaas-management-api/api/healthCheck"
 	"github.com/topfreegames/kaas-management-api/docs"
+	"github.com/topfreegames/kaas-management-api/internal/audit"
+	"github.com/topfreegames/kaas-management-api/internal/auth"
 	"github.com/topfreegames/kaas-management-api/internal/controller"
+	"github.com/topfreegames/kaas-management-api/internal/fleet"
 	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/internal/logging"
+	"github.com/topfreegames/kaas-management-api/internal/metrics"
+	"github.com/topfreegames/kaas-management-api/internal/tracing"
+	"go.uber.org/zap"
+	"net/http"
+	"os"
+	"time"
 )
 
+// ShutdownTimeout is how long the server waits for the requests in progress to finish when it is stopped
+const ShutdownTimeout = 30 * time.Second
+
 // @securityDefinitions.basic  BasicAuth
 
-// InitServer - Initializes the serves
-func InitServer(k8sInstance *k8s.Kubernetes) error {
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+
+// InitServer - Initializes the server and serves until stopCh is closed, the watches are ended and the other requests in progress are drained
+func InitServer(k8sInstance *k8s.Kubernetes, logger *zap.Logger, stopCh <-chan struct{}) error {
 
 	// programmatically set swagger info
 	docs.SwaggerInfo.Title = "Kubernetes as a service API"
@@ -19,13 +38,78 @@ func InitServer(k8sInstance *k8s.Kubernetes) error {
 	docs.SwaggerInfo.BasePath = "/"
 	docs.SwaggerInfo.Schemes = []string{"https"}
 
-	router := gin.Default()
+	err := tracing.Setup()
+	if err != nil {
+		return err
+	}
+
+	authenticators, err := auth.NewAuthenticatorsFromEnv(stopCh)
+	if err != nil {
+		return err
+	}
+
+	auditSink, err := audit.NewSinkFromEnv(k8sInstance)
+	if err != nil {
+		return err
+	}
+
+	fleetCollector := fleet.NewCollector(k8sInstance)
+	metrics.Registry.MustRegister(fleetCollector)
+	go fleetCollector.Run(fleet.RefreshPeriod, stopCh)
+
+	// The access log of gin is replaced by the structured one of the logging middleware, registered first so every
+	// response has a request ID, and the panics are recovered after the metrics and the tracing to count them as errors
+	router := gin.New()
+	router.Use(logging.Middleware(logger))
+	router.Use(metrics.Middleware())
+	router.Use(tracing.Middleware(healthCheck.Endpoint.Path, healthCheck.ReadinessEndpoint.Path, metrics.Path))
+	router.Use(logging.Recovery(logger))
+	router.Use(audit.Middleware(auditSink))
+	router.Use(auth.Middleware(authenticators, auth.GetExemptPaths()))
 	controllerInstance := controller.ConfigureControllers(k8sInstance)
+	controllerInstance.AuditSink = auditSink
+	controllerInstance.Logger = logger
+	controllerInstance.ShutdownCh = stopCh
 
 	routerConfig := &RouterConfig{
 		controller: controllerInstance,
 		router:     router,
 	}
 	routerConfig.setupRoutes()
-	return router.Run()
+	return serve(router, logger, stopCh)
+}
+
+// serve listens on the address gin would use, the port of PORT or 8080, until stopCh is closed
+func serve(handler http.Handler, logger *zap.Logger, stopCh <-chan struct{}) error {
+	address := ":8080"
+	if port := os.Getenv("PORT"); port != "" {
+		address = ":" + port
+	}
+
+	httpServer := &http.Server{
+		Addr:    address,
+		Handler: handler,
+	}
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		<-stopCh
+		// The watches end on their own when stopCh is closed, the other requests can finish within the ShutdownTimeout
+		logger.Info("Shutting down the server")
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+		defer shutdownCancel()
+		err := httpServer.Shutdown(shutdownCtx)
+		tracingErr := tracing.Shutdown(shutdownCtx)
+		if tracingErr != nil {
+			logger.Error("Could not export the remaining spans", zap.Error(tracingErr))
+		}
+		shutdownErr <- err
+	}()
+
+	logger.Info("Listening", zap.String("address", address))
+	err := httpServer.ListenAndServe()
+	if err != http.ErrServerClosed {
+		return err
+	}
+	return <-shutdownErr
 }
@@ -0,0 +1,158 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Environment variables configuring where the spans are exported, nowhere by default
+const (
+	ExporterEnv = "TRACING_EXPORTER"
+	// OTLPEndpointEnv is the standard OpenTelemetry variable with the base URL of the OTLP/HTTP receiver, the spans are sent to
+	// <endpoint>/v1/traces. The exporter also reads the other standard variables, like OTEL_EXPORTER_OTLP_HEADERS.
+	OTLPEndpointEnv = "OTEL_EXPORTER_OTLP_ENDPOINT"
+)
+
+// Span exporters
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+const DefaultExporter = ExporterNone
+
+// DefaultOTLPEndpoint is the host and port of the OTLP/HTTP receiver, over plain HTTP, when OTLPEndpointEnv is unset
+const DefaultOTLPEndpoint = "localhost:4318"
+
+// ServiceName is the service.name of the resource of the exported spans
+const ServiceName = "kaas-management-api"
+
+const instrumentationName = "github.com/topfreegames/kaas-management-api"
+
+// provider is the tracer provider created by Setup, nil when the spans aren't exported
+var provider *sdktrace.TracerProvider
+
+// Setup configures the global tracer provider with the exporter of the environment and propagates the W3C trace context
+// and baggage of the requests. The provider is only a no-op when no exporter is configured.
+func Setup() error {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	exporter, err := NewExporterFromEnv()
+	if err != nil {
+		return err
+	}
+	if exporter == nil {
+		return nil
+	}
+
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(sdkresource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+	return nil
+}
+
+// Shutdown exports the spans not exported yet and stops the exporter, it does nothing when no exporter is configured
+func Shutdown(ctx context.Context) error {
+	if provider == nil {
+		return nil
+	}
+	return provider.Shutdown(ctx)
+}
+
+// NewExporterFromEnv creates the span exporter configured in the environment, nil when the spans aren't exported
+func NewExporterFromEnv() (sdktrace.SpanExporter, error) {
+	exporter := os.Getenv(ExporterEnv)
+	if exporter == "" {
+		exporter = DefaultExporter
+	}
+
+	switch exporter {
+	case ExporterNone:
+		return nil, nil
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		if os.Getenv(OTLPEndpointEnv) == "" {
+			options = append(options, otlptracehttp.WithEndpoint(DefaultOTLPEndpoint), otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(context.Background(), options...)
+	default:
+		return nil, fmt.Errorf("invalid %s %s, must be %s, %s or %s", ExporterEnv, exporter, ExporterNone, ExporterStdout, ExporterOTLP)
+	}
+}
+
+// Tracer returns the tracer of the API from the global tracer provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start starts an internal span as a child of the span in the context
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// End records the error of the operation of a span, if any, and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Middleware returns a middleware starting a server span for each request, child of the trace context in its headers,
+// named by the method and route template. The handlers get the span in the context of the request.
+// Requests to the untraced paths, like the probes, don't start spans.
+func Middleware(untracedPaths ...string) gin.HandlerFunc {
+	untraced := map[string]bool{}
+	for _, path := range untracedPaths {
+		untraced[strings.TrimSuffix(path, "/")] = true
+	}
+
+	return func(c *gin.Context) {
+		if untraced[strings.TrimSuffix(c.Request.URL.Path, "/")] {
+			c.Next()
+			return
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		name := c.Request.Method + " " + route
+		if route == "" {
+			name = "HTTP " + c.Request.Method
+		}
+		ctx, span := Tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest("", route, c.Request)...))
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(status)...)
+		// Client errors are the caller's, only the server errors fail the span
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	testTraceID      = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testParentSpanID = "00f067aa0ba902b7"
+)
+
+func setupTestTracing() *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return exporter
+}
+
+func TestMiddleware(t *testing.T) {
+	exporter := setupTestTracing()
+
+	router := gin.New()
+	router.Use(Middleware("/healthcheck/"))
+	router.Handle(http.MethodGet, "/v1/clusters/:clusterName/", func(c *gin.Context) {
+		_, span := Start(c.Request.Context(), "handler")
+		span.End()
+		c.Status(http.StatusOK)
+	})
+	router.Handle(http.MethodGet, "/v1/failing/", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+	router.Handle(http.MethodGet, "/healthcheck/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	testCases := []struct {
+		name           string
+		path           string
+		traceParent    string
+		expectedSpans  []string
+		expectedStatus codes.Code
+		expectedRemote bool
+	}{
+		{
+			name:           "Request continues the trace of its traceparent header",
+			path:           "/v1/clusters/test-cluster/",
+			traceParent:    "00-" + testTraceID + "-" + testParentSpanID + "-01",
+			expectedSpans:  []string{"handler", "GET /v1/clusters/:clusterName/"},
+			expectedStatus: codes.Unset,
+			expectedRemote: true,
+		},
+		{
+			name:           "Request without trace context starts a trace",
+			path:           "/v1/clusters/test-cluster/",
+			expectedSpans:  []string{"handler", "GET /v1/clusters/:clusterName/"},
+			expectedStatus: codes.Unset,
+		},
+		{
+			name:           "Server errors fail the span",
+			path:           "/v1/failing/",
+			expectedSpans:  []string{"GET /v1/failing/"},
+			expectedStatus: codes.Error,
+		},
+		{
+			name:           "Unmatched routes are named by the method",
+			path:           "/unknown",
+			expectedSpans:  []string{"HTTP GET"},
+			expectedStatus: codes.Unset,
+		},
+		{
+			name: "Untraced paths don't start spans",
+			path: "/healthcheck/",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			exporter.Reset()
+			request := httptest.NewRequest(http.MethodGet, testCase.path, nil)
+			if testCase.traceParent != "" {
+				request.Header.Set("traceparent", testCase.traceParent)
+			}
+			router.ServeHTTP(httptest.NewRecorder(), request)
+
+			spans := exporter.GetSpans()
+			var names []string
+			for _, span := range spans {
+				names = append(names, span.Name)
+			}
+			assert.Equal(t, testCase.expectedSpans, names)
+			if len(spans) == 0 {
+				return
+			}
+
+			serverSpan := spans[len(spans)-1]
+			assert.Equal(t, trace.SpanKindServer, serverSpan.SpanKind)
+			assert.Equal(t, testCase.expectedStatus, serverSpan.Status.Code)
+			assert.Equal(t, testCase.expectedRemote, serverSpan.Parent.IsRemote())
+			if testCase.expectedRemote {
+				assert.Equal(t, testTraceID, serverSpan.SpanContext.TraceID().String())
+				assert.Equal(t, testParentSpanID, serverSpan.Parent.SpanID().String())
+			}
+			for _, span := range spans[:len(spans)-1] {
+				assert.Equal(t, serverSpan.SpanContext.SpanID(), span.Parent.SpanID())
+			}
+		})
+	}
+}
+
+func TestNewExporterFromEnv(t *testing.T) {
+	testCases := []struct {
+		name          string
+		exporter      string
+		endpoint      string
+		expectedType  interface{}
+		expectedError bool
+	}{
+		{name: "No exporter by default"},
+		{name: "No exporter", exporter: ExporterNone},
+		{name: "Stdout exporter", exporter: ExporterStdout, expectedType: &stdouttrace.Exporter{}},
+		{name: "OTLP exporter with the default endpoint", exporter: ExporterOTLP, expectedType: &otlptrace.Exporter{}},
+		{name: "OTLP exporter with an endpoint", exporter: ExporterOTLP, endpoint: "http://collector:4318/", expectedType: &otlptrace.Exporter{}},
+		{name: "Invalid exporter", exporter: "jaeger", expectedError: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			os.Setenv(ExporterEnv, testCase.exporter)
+			os.Setenv(OTLPEndpointEnv, testCase.endpoint)
+			defer os.Unsetenv(ExporterEnv)
+			defer os.Unsetenv(OTLPEndpointEnv)
+
+			exporter, err := NewExporterFromEnv()
+			if testCase.expectedError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			if testCase.expectedType == nil {
+				assert.Nil(t, exporter)
+				return
+			}
+			assert.IsType(t, testCase.expectedType, exporter)
+			assert.Nil(t, exporter.Shutdown(context.Background()))
+		})
+	}
+}
@@ -1,15 +1,34 @@
 package main
 
 import (
+	"context"
 	"github.com/topfreegames/kaas-management-api/internal/k8s"
+	"github.com/topfreegames/kaas-management-api/internal/logging"
 	"github.com/topfreegames/kaas-management-api/internal/server"
+	"go.uber.org/zap"
 	"log"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
-	k8sClient := k8s.CreateK8sInstance()
-	err := server.InitServer(k8sClient)
+	logger, err := logging.NewLoggerFromEnv()
 	if err != nil {
-		log.Fatalf("Error initializing server: %s", err.Error())
+		log.Fatalf("Error configuring the logs: %s", err.Error())
+	}
+	defer logger.Sync()
+	zap.ReplaceGlobals(logger)
+
+	// The informers, the background refreshes and the server stop when the pod is terminated
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	k8sClient, err := k8s.CreateK8sInstance(logger, ctx.Done())
+	if err != nil {
+		logger.Fatal("Error connecting to Kubernetes", zap.Error(err))
+	}
+	err = server.InitServer(k8sClient, logger, ctx.Done())
+	if err != nil {
+		logger.Fatal("Error initializing server", zap.Error(err))
 	}
 }
@@ -18,6 +18,7 @@ type HTTPTestRequest struct {
 	Method string
 	Body   io.Reader
 	Path   string
+	Header http.Header
 }
 
 // GetK8sRequest returns the request of the test as an instance of the struct *HTTPTestRequest
@@ -40,6 +41,9 @@ func Path(path string) string {
 // RunHTTPTest executes the Cases
 func (r *HTTPTestRequest) RunHTTPTest(handler http.Handler) *httptest.ResponseRecorder {
 	req, _ := http.NewRequest(r.Method, r.Path, r.Body)
+	for key, values := range r.Header {
+		req.Header[key] = values
+	}
 	w := httptest.NewRecorder()
 	handler.ServeHTTP(w, req)
 	return w
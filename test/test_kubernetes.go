@@ -2,16 +2,21 @@ package test
 
 import (
 	"fmt"
+	clusterapikopscontrolplanev1alpha1 "github.com/topfreegames/kubernetes-kops-operator/apis/controlplane/v1alpha1"
 	clusterapikopsv1alpha1 "github.com/topfreegames/kubernetes-kops-operator/apis/infrastructure/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic/fake"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/kops/pkg/apis/kops/v1alpha2"
 	"log"
 	clusterapiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	clusterapiexpv1beta1 "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	"strings"
+	"time"
 )
 
 type K8sRequest struct {
@@ -29,18 +34,40 @@ func (t TestCase) GetK8sRequest() *K8sRequest {
 	return request
 }
 
+// newTestScheme returns the scheme with the kubernetes core types known by the fake clients
+func newTestScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	err := corev1.AddToScheme(scheme)
+	if err != nil {
+		log.Fatalf("Could not add core types to the test scheme: %v", err)
+	}
+
+	// The lists of the control planes are known even without control planes, they are listed by the informer cache
+	for _, listKind := range []schema.GroupVersionKind{
+		clusterapikopscontrolplanev1alpha1.GroupVersion.WithKind("KopsControlPlaneList"),
+		{Group: "controlplane.cluster.x-k8s.io", Version: "v1beta1", Kind: "KubeadmControlPlaneList"},
+	} {
+		scheme.AddKnownTypeWithName(listKind, &unstructured.UnstructuredList{})
+	}
+	return scheme
+}
+
 func NewK8sFakeDynamicClient() *fake.FakeDynamicClient {
-	client := fake.NewSimpleDynamicClient(runtime.NewScheme())
+	client := fake.NewSimpleDynamicClient(newTestScheme())
 	return client
 }
 
 func NewK8sFakeDynamicClientWithResources(resources ...runtime.Object) *fake.FakeDynamicClient {
 
-	client := fake.NewSimpleDynamicClient(runtime.NewScheme(), resources...)
+	client := fake.NewSimpleDynamicClient(newTestScheme(), resources...)
 
 	return client
 }
 
+func NewK8sFakeClientset(resources ...runtime.Object) *kubernetesfake.Clientset {
+	return kubernetesfake.NewSimpleClientset(resources...)
+}
+
 func GetTestClusterNamespace(clusterName string) string {
 	prefix := "kubernetes"
 	clusterNamespace := strings.ReplaceAll(clusterName, ".", "-")
@@ -48,6 +75,20 @@ func GetTestClusterNamespace(clusterName string) string {
 	return namespace
 }
 
+func NewTestNamespace(name string) *corev1.Namespace {
+	testResource := corev1.Namespace{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Namespace",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+	}
+
+	return &testResource
+}
+
 func NewTestCluster(name string, controlPlaneName string, controPlaneKind string, controlPlaneApiVersion string, infrastructureName string, infrastructureKind string, infrastructureApiVersion string) *clusterapiv1beta1.Cluster {
 
 	var (
@@ -110,6 +151,55 @@ func NewTestCluster(name string, controlPlaneName string, controPlaneKind string
 	return &testResource
 }
 
+func NewTestKopsControlPlane(name string, clusterName string, kubernetesVersion string) *clusterapikopscontrolplanev1alpha1.KopsControlPlane {
+	testResource := clusterapikopscontrolplanev1alpha1.KopsControlPlane{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "KopsControlPlane",
+			APIVersion: "controlplane.cluster.x-k8s.io/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: GetTestClusterNamespace(clusterName),
+		},
+		Spec: clusterapikopscontrolplanev1alpha1.KopsControlPlaneSpec{
+			KopsClusterSpec: v1alpha2.ClusterSpec{
+				KubernetesVersion: kubernetesVersion,
+			},
+		},
+	}
+
+	return &testResource
+}
+
+func NewTestKubeadmControlPlane(name string, clusterName string, kubernetesVersion string) *unstructured.Unstructured {
+	testResource := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "controlplane.cluster.x-k8s.io/v1beta1",
+			"kind":       "KubeadmControlPlane",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": GetTestClusterNamespace(clusterName),
+			},
+			"spec": map[string]interface{}{
+				"replicas": int64(3),
+				"version":  kubernetesVersion,
+			},
+		},
+	}
+
+	return testResource
+}
+
+// NewTestKubeadmControlPlaneWithStatus returns a KubeadmControlPlane whose machines report the rolled out kubernetes version
+func NewTestKubeadmControlPlaneWithStatus(name string, clusterName string, kubernetesVersion string, rolledOutVersion string) *unstructured.Unstructured {
+	testResource := NewTestKubeadmControlPlane(name, clusterName, kubernetesVersion)
+	testResource.Object["status"] = map[string]interface{}{
+		"version": rolledOutVersion,
+	}
+
+	return testResource
+}
+
 func NewTestKopsMachinePool(name string, clusterName string) *clusterapikopsv1alpha1.KopsMachinePool {
 	namespace := GetTestClusterNamespace(clusterName)
 	testResource := clusterapikopsv1alpha1.KopsMachinePool{
@@ -217,3 +307,121 @@ func NewTestMachineDeployment(name string, clusterName string, infrastructureKin
 
 	return &testResource
 }
+
+func NewTestMachine(name string, clusterName string, machineDeploymentName string, providerID string, nodeName string) *clusterapiv1beta1.Machine {
+	version := "v1.21.5"
+	testResource := clusterapiv1beta1.Machine{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Machine",
+			APIVersion: "cluster.x-k8s.io/v1beta1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: GetTestClusterNamespace(clusterName),
+			Labels: map[string]string{
+				clusterapiv1beta1.ClusterLabelName:           clusterName,
+				clusterapiv1beta1.MachineDeploymentLabelName: machineDeploymentName,
+			},
+			CreationTimestamp: metav1.NewTime(time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)),
+		},
+		Spec: clusterapiv1beta1.MachineSpec{
+			ClusterName: clusterName,
+			Version:     &version,
+			ProviderID:  &providerID,
+		},
+		Status: clusterapiv1beta1.MachineStatus{
+			NodeRef: &corev1.ObjectReference{
+				Kind:       "Node",
+				APIVersion: "v1",
+				Name:       nodeName,
+			},
+			Phase: string(clusterapiv1beta1.MachinePhaseRunning),
+		},
+	}
+
+	return &testResource
+}
+
+func NewTestAWSMachineTemplate(name string, clusterName string, instanceType string) *unstructured.Unstructured {
+	testResource := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "infrastructure.cluster.x-k8s.io/v1beta1",
+			"kind":       "AWSMachineTemplate",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": GetTestClusterNamespace(clusterName),
+			},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"instanceType":       instanceType,
+						"iamInstanceProfile": "nodes.cluster-api-provider-aws.sigs.k8s.io",
+					},
+				},
+			},
+		},
+	}
+
+	return testResource
+}
+
+// NewTestKubeconfig returns the kubeconfig written by cluster-API for a cluster, with a single context named after the cluster admin
+func NewTestKubeconfig(clusterName string, server string) []byte {
+	return []byte(fmt.Sprintf(`apiVersion: v1
+clusters:
+- cluster:
+    certificate-authority-data: dGVzdC1jYQ==
+    server: %[2]s
+  name: %[1]s
+contexts:
+- context:
+    cluster: %[1]s
+    user: %[1]s-admin
+  name: %[1]s-admin@%[1]s
+current-context: %[1]s-admin@%[1]s
+kind: Config
+preferences: {}
+users:
+- name: %[1]s-admin
+  user:
+    token: test-token
+`, clusterName, server))
+}
+
+func NewTestKubeconfigSecret(clusterName string, kubeconfig []byte) *corev1.Secret {
+	testResource := corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterName + "-kubeconfig",
+			Namespace: GetTestClusterNamespace(clusterName),
+			Labels: map[string]string{
+				clusterapiv1beta1.ClusterLabelName: clusterName,
+			},
+		},
+		Data: map[string][]byte{
+			"value": kubeconfig,
+		},
+		Type: clusterapiv1beta1.ClusterSecretType,
+	}
+
+	return &testResource
+}
+
+func NewTestConfigMap(namespace string, name string, data map[string]string) *corev1.ConfigMap {
+	testResource := corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Data: data,
+	}
+
+	return &testResource
+}
@@ -19,6 +19,13 @@ func (e ClientError) Error() string {
 	return e.ErrorMessage + ": " + e.ErrorDetailedMessage + " caused by: " + e.ErrorCause.Error()
 }
 
+// ErrorTypeContextKey is the key of the gin context with the type of the error response of the request, read to count them by type
+const ErrorTypeContextKey = "errorType"
+
+// RequestIDContextKey is the key of the gin context with the ID of the request, set by the logging middleware and
+// returned in the error responses
+const RequestIDContextKey = "requestID"
+
 func NewClientError(errorCause error, errorMessage string, errorDetailedMessage string) error {
 	clientError := &ClientError{
 		ErrorCause:           errorCause,
@@ -42,6 +49,8 @@ func ErrorHandler(c *gin.Context, err error, errorMessage string, httpCode int)
 		ErrorMessage: errorMessage,
 		ErrorType:    errorMsg,
 		HttpCode:     httpCode,
+		RequestID:    c.GetString(RequestIDContextKey),
 	}
+	c.Set(ErrorTypeContextKey, errorMsg)
 	c.JSON(httpCode, clientErrorResponse)
 }
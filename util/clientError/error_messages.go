@@ -1,10 +1,16 @@
 package clientError
 
 const (
-	ResourceNotFound     = "RESOURCE_NOT_FOUND"
-	KindNotFound         = "KIND_NOT_FOUND"
-	InvalidResource      = "INVALID_RESOURCE"
-	EmptyResponse        = "EMPTY_RESPONSE"
-	InvalidConfiguration = "INVALID_CONFIGURATION"
-	UnexpectedError      = "UNEXPECTED_ERROR"
+	ResourceNotFound      = "RESOURCE_NOT_FOUND"
+	ResourceAlreadyExists = "RESOURCE_ALREADY_EXISTS"
+	KindNotFound          = "KIND_NOT_FOUND"
+	InvalidResource       = "INVALID_RESOURCE"
+	InvalidRequest        = "INVALID_REQUEST"
+	EmptyResponse         = "EMPTY_RESPONSE"
+	InvalidConfiguration  = "INVALID_CONFIGURATION"
+	DeletionProtected     = "DELETION_PROTECTED"
+	Timeout               = "TIMEOUT"
+	Unauthorized          = "UNAUTHORIZED"
+	Forbidden             = "FORBIDDEN"
+	UnexpectedError       = "UNEXPECTED_ERROR"
 )